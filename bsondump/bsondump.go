@@ -116,8 +116,18 @@ func (bd *BSONDump) Close() error {
 	return bd.OutputWriter.Close()
 }
 
-func formatJSON(doc *bson.Raw, pretty bool) ([]byte, error) {
-	extendedJSON, err := bsonutil.MarshalExtJSONReversible(doc, true, false)
+func formatJSON(doc *bson.Raw, outputMode string, pretty bool) ([]byte, error) {
+	var extendedJSON []byte
+	var err error
+	if outputMode == LegacyOutputMode {
+		extendedJSON, err = formatLegacyJSON(doc)
+	} else {
+		extendedJSON, err = bsonutil.MarshalExtJSONReversible(
+			doc,
+			outputMode != RelaxedOutputMode,
+			false,
+		)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error converting BSON to extended JSON: %v", err)
 	}
@@ -132,6 +142,79 @@ func formatJSON(doc *bson.Raw, pretty bool) ([]byte, error) {
 	return extendedJSON, nil
 }
 
+// formatLegacyJSON renders doc in the legacy extended JSON format understood
+// by the common/json scanner, e.g. NumberLong values as {"$numberLong": "5"},
+// non-finite doubles as bare NaN or +Infinity literals and binary data as
+// BinData(subtype, "base64") constructors.
+func formatLegacyJSON(doc *bson.Raw) ([]byte, error) {
+	var d bson.D
+	if err := bson.Unmarshal(*doc, &d); err != nil {
+		return nil, err
+	}
+	converted, err := bsonutil.ConvertBSONValueToLegacyExtJSON(d)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(binDataConstructors(converted))
+}
+
+// binDataConstructors replaces each json.BinData in a value converted to
+// legacy extended JSON with a json.BinDataConstructor, and returns the value.
+func binDataConstructors(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.BinData:
+		return json.BinDataConstructor(v)
+	case bsonutil.MarshalD:
+		for i := range v {
+			v[i].Value = binDataConstructors(v[i].Value)
+		}
+	case bson.M:
+		for key := range v {
+			v[key] = binDataConstructors(v[key])
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = binDataConstructors(v[i])
+		}
+	}
+	return value
+}
+
+// validateDocument checks the structure of a single BSON document (its length
+// prefix, element types, and cstring terminators). On failure it reports the
+// 1-based index of the document and its byte offset within the input.
+func validateDocument(doc bson.Raw, index int, offset int64) error {
+	if err := doc.Validate(); err != nil {
+		return fmt.Errorf(
+			"invalid BSON in document %v at byte offset %v: %v", index, offset, err)
+	}
+	return nil
+}
+
+// skipDocuments advances the input past the number of documents given by
+// --skip, without decoding them. It returns the byte offset of the first
+// document after those skipped.
+func (bd *BSONDump) skipDocuments() (int64, error) {
+	var offset int64
+	for i := 0; i < bd.OutputOptions.Skip; i++ {
+		size := bd.InputSource.SkipNext()
+		if size == 0 {
+			if err := bd.InputSource.Err(); err != nil {
+				return offset, fmt.Errorf(
+					"error skipping document %v at byte offset %v: %v", i+1, offset, err)
+			}
+			break
+		}
+		offset += int64(size)
+	}
+	return offset, nil
+}
+
+// limitReached reports whether numFound documents satisfy --limit.
+func (bd *BSONDump) limitReached(numFound int) bool {
+	return bd.OutputOptions.Limit > 0 && numFound >= bd.OutputOptions.Limit
+}
+
 // JSON iterates through the BSON file and for each document it finds,
 // recursively descends into objects and arrays and prints the human readable
 // JSON representation.
@@ -144,14 +227,30 @@ func (bd *BSONDump) JSON() (int, error) {
 		panic("Tried to call JSON() before opening file")
 	}
 
-	for {
+	offset, err := bd.skipDocuments()
+	if err != nil {
+		return numFound, err
+	}
+	skipped := bd.OutputOptions.Skip
+	for !bd.limitReached(numFound) {
 		result := bson.Raw(bd.InputSource.LoadNext())
 		if result == nil {
 			break
 		}
 
-		if bytes, err := formatJSON(&result, bd.OutputOptions.Pretty); err != nil {
-			log.Logvf(log.Always, "unable to dump document %v: %v", numFound+1, err)
+		if bd.OutputOptions.ObjCheck {
+			if err := validateDocument(result, skipped+numFound+1, offset); err != nil {
+				return numFound, err
+			}
+		}
+
+		if bytes, err := formatJSON(
+			&result,
+			bd.OutputOptions.OutputMode,
+			bd.OutputOptions.Pretty,
+		); err != nil {
+			log.Logvf(log.Always, "unable to dump document %v at byte offset %v: %v",
+				skipped+numFound+1, offset, err)
 
 			//if objcheck is turned on, stop now. otherwise keep on dumpin'
 			if bd.OutputOptions.ObjCheck {
@@ -164,13 +263,15 @@ func (bd *BSONDump) JSON() (int, error) {
 				return numFound, err
 			}
 		}
+		offset += int64(len(result))
 		numFound++
 		if failpoint.Enabled(failpoint.SlowBSONDump) {
 			time.Sleep(2 * time.Second)
 		}
 	}
 	if err := bd.InputSource.Err(); err != nil {
-		return numFound, err
+		return numFound, fmt.Errorf(
+			"error reading document %v at byte offset %v: %v", skipped+numFound+1, offset, err)
 	}
 
 	return numFound, nil
@@ -188,24 +289,33 @@ func (bd *BSONDump) Debug() (int, error) {
 		panic("Tried to call Debug() before opening file")
 	}
 
-	for {
+	offset, err := bd.skipDocuments()
+	if err != nil {
+		return numFound, err
+	}
+	skipped := bd.OutputOptions.Skip
+	printer := &debugPrinter{
+		out:         bd.OutputWriter,
+		maxDepth:    bd.OutputOptions.MaxDepth,
+		maxArrayLen: bd.OutputOptions.MaxArrayLen,
+	}
+	for !bd.limitReached(numFound) {
 		result := bson.Raw(bd.InputSource.LoadNext())
 		if result == nil {
 			break
 		}
 
 		if bd.OutputOptions.ObjCheck {
-			validated := bson.M{}
-			err := bson.Unmarshal(result, &validated)
-			if err != nil {
-				// ObjCheck is turned on and we hit an error, so short-circuit now.
+			// ObjCheck is turned on, so short-circuit on the first invalid document.
+			if err := validateDocument(result, skipped+numFound+1, offset); err != nil {
 				return numFound, fmt.Errorf("failed to validate bson during objcheck: %v", err)
 			}
 		}
-		err := printBSON(result, 0, bd.OutputWriter)
+		err := printer.printBSON(result, 1, false)
 		if err != nil {
 			log.Logvf(log.Always, "encountered error debugging BSON data: %v", err)
 		}
+		offset += int64(len(result))
 		numFound++
 	}
 
@@ -214,25 +324,45 @@ func (bd *BSONDump) Debug() (int, error) {
 		// either the 4-byte header couldn't be read in full, or
 		// the size in the header would require reading more bytes
 		// than the file has left
-		return numFound, err
+		return numFound, fmt.Errorf(
+			"error reading document %v at byte offset %v: %v", skipped+numFound+1, offset, err)
 	}
 	return numFound, nil
 }
 
-func printBSON(raw bson.Raw, indentLevel int, out io.Writer) error {
-	indent := strings.Repeat("\t", indentLevel)
-	fmt.Fprintf(out, "%v--- new object ---\n", indent)
-	fmt.Fprintf(out, "%v\tsize : %v\n", indent, len(raw))
+// debugPrinter prints the human readable representation of BSON documents
+// used by --type=debug.
+type debugPrinter struct {
+	out io.Writer
+
+	// maxDepth is the number of levels of nesting to print, counting the
+	// top-level document, or 0 for no limit.
+	maxDepth int
+
+	// maxArrayLen is the number of elements of each array to print, or 0 for
+	// no limit.
+	maxArrayLen int
+}
+
+func (p *debugPrinter) printBSON(raw bson.Raw, depth int, isArray bool) error {
+	indent := strings.Repeat("\t", (depth-1)*3)
+	fmt.Fprintf(p.out, "%v--- new object ---\n", indent)
+	fmt.Fprintf(p.out, "%v\tsize : %v\n", indent, len(raw))
 
 	elements, err := raw.Elements()
 	if err != nil {
 		return err
 	}
-	for _, rawElem := range elements {
+	for i, rawElem := range elements {
+		if isArray && p.maxArrayLen > 0 && i == p.maxArrayLen {
+			fmt.Fprintf(p.out, "%v\t\t... %v more elements\n", indent, len(elements)-i)
+			break
+		}
+
 		key := rawElem.Key()
 		value := rawElem.Value()
 
-		fmt.Fprintf(out, "%v\t\t%v\n", indent, key)
+		fmt.Fprintf(p.out, "%v\t\t%v\n", indent, key)
 
 		// the size of an element is the combined size of the following:
 		// 1. 1 byte for the BSON type
@@ -240,14 +370,25 @@ func printBSON(raw bson.Raw, indentLevel int, out io.Writer) error {
 		// 3. The BSON value
 		// So size == 1 [size of type byte] +  1 [null byte for cstring key] + len(bson key) + len(bson value)
 		// see http://bsonspec.org/spec.html for more details
-		fmt.Fprintf(out, "%v\t\t\ttype: %4v size: %v\n", indent, int8(value.Type), len(rawElem))
+		fmt.Fprintf(p.out, "%v\t\t\ttype: %4v size: %v\n", indent, int8(value.Type), len(rawElem))
 
 		//For nested objects or arrays, recurse.
-		if value.Type == bson.TypeEmbeddedDocument || value.Type == bson.TypeArray {
-			err = printBSON(value.Value, indentLevel+3, out)
+		if value.Type != bson.TypeEmbeddedDocument && value.Type != bson.TypeArray {
+			continue
+		}
+		if p.maxDepth > 0 && depth >= p.maxDepth {
+			nested, err := bson.Raw(value.Value).Elements()
 			if err != nil {
 				return err
 			}
+			if len(nested) > 0 {
+				fmt.Fprintf(p.out, "%v\t\t\t... %v more elements\n", indent, len(nested))
+			}
+			continue
+		}
+		err = p.printBSON(value.Value, depth+1, value.Type == bson.TypeArray)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -9,17 +9,24 @@ package bsondump
 import (
 	"bytes"
 	"crypto/rand"
+	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	"github.com/mongodb/mongo-tools/common/testutil"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestBsondump(t *testing.T) {
@@ -356,3 +363,385 @@ func runBsondump(args ...string) (string, error) {
 	out, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
 	return string(out), err
 }
+
+func TestBsondumpOutputMode(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	decimal, err := primitive.ParseDecimal128("1.5")
+	require.NoError(t, err)
+
+	doc := bson.D{
+		{"int", int32(5)},
+		{"long", int64(6)},
+		{"nan", math.NaN()},
+		{"inf", math.Inf(1)},
+		{"negInf", math.Inf(-1)},
+		{"decimal", decimal},
+		{"beforeEpoch", primitive.DateTime(-1000)},
+		{"bin", primitive.Binary{Subtype: 0x80, Data: []byte("xyz")}},
+		{"nested", bson.D{{"bins", bson.A{primitive.Binary{Data: []byte("abc")}}}}},
+	}
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	rawDoc := bson.Raw(raw)
+
+	t.Run("canonical", func(t *testing.T) {
+		out, err := formatJSON(&rawDoc, CanonicalOutputMode, false)
+		require.NoError(t, err)
+		require.Contains(t, string(out), `"int":{"$numberInt":"5"}`)
+		require.Contains(t, string(out), `"long":{"$numberLong":"6"}`)
+		require.Contains(t, string(out), `"nan":{"$numberDouble":"NaN"}`)
+		require.Contains(t, string(out), `"inf":{"$numberDouble":"Infinity"}`)
+		require.Contains(t, string(out), `"decimal":{"$numberDecimal":"1.5"}`)
+		require.Contains(t, string(out), `"beforeEpoch":{"$date":{"$numberLong":"-1000"}}`)
+	})
+
+	t.Run("relaxed", func(t *testing.T) {
+		out, err := formatJSON(&rawDoc, RelaxedOutputMode, false)
+		require.NoError(t, err)
+		require.Contains(t, string(out), `"int":5`)
+		require.Contains(t, string(out), `"long":6`)
+		require.Contains(t, string(out), `"nan":{"$numberDouble":"NaN"}`)
+		require.Contains(t, string(out), `"negInf":{"$numberDouble":"-Infinity"}`)
+		require.Contains(t, string(out), `"beforeEpoch":{"$date":{"$numberLong":"-1000"}}`)
+	})
+
+	t.Run("legacy round-trips through the json scanner", func(t *testing.T) {
+		out, err := formatJSON(&rawDoc, LegacyOutputMode, false)
+		require.NoError(t, err)
+		require.Contains(t, string(out), `"int":5`)
+		require.Contains(t, string(out), `"inf":+Infinity`)
+		require.Contains(t, string(out), `"bin":BinData(128,"eHl6")`)
+		require.Contains(t, string(out), `"nested":{"bins":[BinData(0,"YWJj")]}`)
+
+		parsed := bson.D{}
+		require.NoError(t, json.Unmarshal(out, &parsed))
+		roundTripped, err := bsonutil.GetExtendedBsonD(parsed)
+		require.NoError(t, err)
+
+		require.Len(t, roundTripped, len(doc))
+		require.Equal(t, int32(5), roundTripped[0].Value)
+		require.Equal(t, int64(6), roundTripped[1].Value)
+		require.True(t, math.IsNaN(roundTripped[2].Value.(float64)))
+		require.True(t, math.IsInf(roundTripped[3].Value.(float64), 1))
+		require.True(t, math.IsInf(roundTripped[4].Value.(float64), -1))
+		require.Equal(t, decimal, roundTripped[5].Value)
+		require.Equal(t, int64(-1000), roundTripped[6].Value.(time.Time).UnixMilli())
+		require.Equal(t, doc[7].Value, roundTripped[7].Value)
+	})
+}
+
+func TestBsondumpObjCheck(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	good, err := bson.Marshal(bson.D{{"a", int32(1)}})
+	require.NoError(t, err)
+	// A document with a valid length prefix whose only element has an
+	// unknown type byte (0x7e).
+	bad := []byte{0x0c, 0x00, 0x00, 0x00, 0x7e, 'b', 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}
+	input := append(append(append([]byte{}, good...), good...), bad...)
+
+	newDumper := func(objCheck bool) (*BSONDump, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &BSONDump{
+			OutputOptions: &OutputOptions{
+				ObjCheck:   objCheck,
+				OutputMode: CanonicalOutputMode,
+			},
+			InputSource:  db.NewBSONSource(io.NopCloser(bytes.NewReader(input))),
+			OutputWriter: WriteNopCloser{out},
+		}, out
+	}
+
+	t.Run("json reports the index and offset of the invalid document", func(t *testing.T) {
+		dumper, out := newDumper(true)
+		numFound, err := dumper.JSON()
+		require.Error(t, err)
+		require.Equal(t, 2, numFound)
+		require.Contains(
+			t,
+			err.Error(),
+			fmt.Sprintf("invalid BSON in document 3 at byte offset %v", 2*len(good)),
+		)
+		require.Equal(t, 2, strings.Count(out.String(), "\n"))
+	})
+
+	t.Run("debug reports the index and offset of the invalid document", func(t *testing.T) {
+		dumper, _ := newDumper(true)
+		numFound, err := dumper.Debug()
+		require.Error(t, err)
+		require.Equal(t, 2, numFound)
+		require.Contains(
+			t,
+			err.Error(),
+			fmt.Sprintf("invalid BSON in document 3 at byte offset %v", 2*len(good)),
+		)
+	})
+
+	t.Run("json without objcheck keeps going", func(t *testing.T) {
+		dumper, _ := newDumper(false)
+		numFound, err := dumper.JSON()
+		require.NoError(t, err)
+		require.Equal(t, 3, numFound)
+	})
+
+	t.Run("truncated input reports the offset of the partial document", func(t *testing.T) {
+		dumper, _ := newDumper(true)
+		dumper.InputSource = db.NewBSONSource(
+			io.NopCloser(bytes.NewReader(append(append([]byte{}, good...), good[:5]...))),
+		)
+		numFound, err := dumper.JSON()
+		require.Error(t, err)
+		require.Equal(t, 1, numFound)
+		require.Contains(
+			t,
+			err.Error(),
+			fmt.Sprintf("error reading document 2 at byte offset %v", len(good)),
+		)
+	})
+}
+
+func TestBsondumpSkipLimit(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	var input []byte
+	for i := 0; i < 10; i++ {
+		doc, err := bson.Marshal(bson.D{{"i", int32(i)}})
+		require.NoError(t, err)
+		input = append(input, doc...)
+	}
+	docSize := len(input) / 10
+
+	bsonFile := filepath.Join(t.TempDir(), "input.bson")
+	require.NoError(t, os.WriteFile(bsonFile, input, 0o644))
+
+	sources := map[string]func(t *testing.T, data []byte) *db.BSONSource{
+		"seekable file": func(t *testing.T, data []byte) *db.BSONSource {
+			path := bsonFile
+			if len(data) != len(input) {
+				path = filepath.Join(t.TempDir(), "truncated.bson")
+				require.NoError(t, os.WriteFile(path, data, 0o644))
+			}
+			file, err := os.Open(path)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = file.Close() })
+			return db.NewBSONSource(file)
+		},
+		"stream": func(_ *testing.T, data []byte) *db.BSONSource {
+			return db.NewBSONSource(ReadNopCloser{bytes.NewReader(data)})
+		},
+	}
+
+	for name, newSource := range sources {
+		newDumper := func(t *testing.T, data []byte, skip, limit int) (*BSONDump, *bytes.Buffer) {
+			out := &bytes.Buffer{}
+			return &BSONDump{
+				OutputOptions: &OutputOptions{
+					OutputMode: RelaxedOutputMode,
+					Skip:       skip,
+					Limit:      limit,
+				},
+				InputSource:  newSource(t, data),
+				OutputWriter: WriteNopCloser{out},
+			}, out
+		}
+
+		t.Run(name+": json dumps the requested range", func(t *testing.T) {
+			dumper, out := newDumper(t, input, 3, 4)
+			numFound, err := dumper.JSON()
+			require.NoError(t, err)
+			require.Equal(t, 4, numFound)
+			require.Equal(t, `{"i":3}`+"\n"+`{"i":4}`+"\n"+`{"i":5}`+"\n"+`{"i":6}`+"\n", out.String())
+		})
+
+		t.Run(name+": debug dumps the requested range", func(t *testing.T) {
+			dumper, out := newDumper(t, input, 8, 5)
+			numFound, err := dumper.Debug()
+			require.NoError(t, err)
+			require.Equal(t, 2, numFound)
+			require.Equal(t, 2, strings.Count(out.String(), "--- new object ---"))
+		})
+
+		t.Run(name+": skipping past the end dumps nothing", func(t *testing.T) {
+			dumper, out := newDumper(t, input, 20, 0)
+			numFound, err := dumper.JSON()
+			require.NoError(t, err)
+			require.Equal(t, 0, numFound)
+			require.Empty(t, out.String())
+		})
+
+		t.Run(name+": errors report positions in the whole input", func(t *testing.T) {
+			dumper, _ := newDumper(t, input[:5*docSize+3], 2, 0)
+			numFound, err := dumper.JSON()
+			require.Error(t, err)
+			require.Equal(t, 3, numFound)
+			require.Contains(
+				t,
+				err.Error(),
+				fmt.Sprintf("error reading document 6 at byte offset %v", 5*docSize),
+			)
+		})
+
+		t.Run(name+": a truncated skipped document is reported", func(t *testing.T) {
+			dumper, _ := newDumper(t, input[:2*docSize+6], 5, 0)
+			_, err := dumper.JSON()
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				fmt.Sprintf("error skipping document 3 at byte offset %v", 2*docSize),
+			)
+		})
+	}
+}
+
+func TestBsondumpDebugLimits(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	doc, err := bson.Marshal(bson.D{
+		{"a", bson.D{{"b", bson.D{{"c", bson.D{{"d", int32(1)}}}}}}},
+		{"arr", bson.A{int32(1), int32(2), int32(3), int32(4), int32(5)}},
+	})
+	require.NoError(t, err)
+
+	debug := func(t *testing.T, maxDepth, maxArrayLen int) string {
+		out := &bytes.Buffer{}
+		dumper := &BSONDump{
+			OutputOptions: &OutputOptions{
+				Type:        DebugOutputType,
+				MaxDepth:    maxDepth,
+				MaxArrayLen: maxArrayLen,
+			},
+			InputSource:  db.NewBSONSource(ReadNopCloser{bytes.NewReader(doc)}),
+			OutputWriter: WriteNopCloser{out},
+		}
+		numFound, err := dumper.Debug()
+		require.NoError(t, err)
+		require.Equal(t, 1, numFound)
+		return out.String()
+	}
+
+	t.Run("no limits prints everything", func(t *testing.T) {
+		out := debug(t, 0, 0)
+		require.Equal(t, 5, strings.Count(out, "--- new object ---"))
+		require.Contains(t, out, "\t\t\t\t\t\t\t\t\t\t\td\n")
+		require.NotContains(t, out, "more elements")
+	})
+
+	t.Run("depth is cut off at --maxDepth", func(t *testing.T) {
+		out := debug(t, 2, 0)
+		// the top-level document, a, and arr
+		require.Equal(t, 3, strings.Count(out, "--- new object ---"))
+		require.Contains(t, out, "\t\t\t\t\tb\n")
+		require.Contains(t, out, "\t\t\t\t\t\t... 1 more elements\n")
+		require.NotContains(t, out, "\tc\n")
+		require.Contains(t, out, "\t\t\t\t\t4\n")
+	})
+
+	t.Run("--maxDepth=1 prints only the top-level document", func(t *testing.T) {
+		out := debug(t, 1, 0)
+		aSize := len(bson.Raw(doc).Lookup("a").Value) + len("a") + 2
+		arrSize := len(bson.Raw(doc).Lookup("arr").Value) + len("arr") + 2
+		require.Equal(t, fmt.Sprintf("--- new object ---\n"+
+			"\tsize : %v\n"+
+			"\t\ta\n"+
+			"\t\t\ttype:    3 size: %v\n"+
+			"\t\t\t... 1 more elements\n"+
+			"\t\tarr\n"+
+			"\t\t\ttype:    4 size: %v\n"+
+			"\t\t\t... 5 more elements\n", len(doc), aSize, arrSize), out)
+	})
+
+	t.Run("arrays are cut off at --maxArrayLen", func(t *testing.T) {
+		out := debug(t, 0, 2)
+		require.Contains(t, out, "\t\t\t\t\t1\n")
+		require.NotContains(t, out, "\t\t\t\t\t2\n")
+		require.Contains(t, out, "\t\t\t\t\t... 3 more elements\n")
+		// documents aren't affected
+		require.Contains(t, out, "\td\n")
+	})
+
+	t.Run("limits are rejected for JSON output", func(t *testing.T) {
+		_, err := ParseOptions([]string{"--maxDepth=2"}, "", "")
+		require.Error(t, err)
+		_, err = ParseOptions([]string{"--type=debug", "--maxArrayLen=-1"}, "", "")
+		require.Error(t, err)
+		opts, err := ParseOptions([]string{"--type=debug", "--maxDepth=2", "--maxArrayLen=3"}, "", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, opts.MaxDepth)
+		require.Equal(t, 3, opts.MaxArrayLen)
+	})
+}
+
+func TestBsondumpValidate(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	// testdata/invalid_fields.bson holds 8 documents with _id 1 to 8, of
+	// which 1 and 8 are fine and 7 is not well-formed BSON.
+	input, err := os.ReadFile("testdata/invalid_fields.bson")
+	require.NoError(t, err)
+
+	newDumper := func(stopOnError bool) (*BSONDump, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &BSONDump{
+			OutputOptions: &OutputOptions{Validate: true, StopOnError: stopOnError},
+			InputSource:   db.NewBSONSource(io.NopCloser(bytes.NewReader(input))),
+			OutputWriter:  WriteNopCloser{out},
+		}, out
+	}
+
+	t.Run("reports every violation with its path", func(t *testing.T) {
+		dumper, out := newDumper(false)
+		numFound, err := dumper.Validate()
+		require.EqualError(t, err, "6 of 8 document(s) failed validation")
+		require.Equal(t, 8, numFound)
+		require.Equal(t, []string{
+			`document 2 at byte offset 27: "name": string is not valid UTF-8`,
+			`document 3 at byte offset 61: "k\xfe": field name is not valid UTF-8`,
+			`document 4 at byte offset 85: "a.b.c": field name contains '.'`,
+			`document 5 at byte offset 116: "$set": top-level field name starts with '$'`,
+			`document 6 at byte offset 174: "arr.1": string is not valid UTF-8`,
+			`document 6 at byte offset 174: "re": regular expression pattern is not valid UTF-8`,
+			`document 7 at byte offset 227: invalid BSON: too few bytes to read next component`,
+		}, strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"))
+	})
+
+	t.Run("stops at the first invalid document with --stopOnError", func(t *testing.T) {
+		dumper, out := newDumper(true)
+		numFound, err := dumper.Validate()
+		require.EqualError(t, err, "document 2 at byte offset 27 failed validation")
+		require.Equal(t, 2, numFound)
+		require.Equal(t, 1, strings.Count(out.String(), "\n"))
+	})
+
+	t.Run("valid documents pass", func(t *testing.T) {
+		sample, err := os.ReadFile("testdata/sample.bson")
+		require.NoError(t, err)
+		dumper, out := newDumper(false)
+		dumper.InputSource = db.NewBSONSource(io.NopCloser(bytes.NewReader(sample)))
+		_, err = dumper.Validate()
+		require.NoError(t, err)
+		require.Empty(t, out.String())
+	})
+
+	t.Run("checks code with scope and symbols", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{
+			{"code", primitive.CodeWithScope{
+				Code:  "f()",
+				Scope: bson.D{{"x.y", int32(1)}},
+			}},
+			{"sym", primitive.Symbol("\xff")},
+		})
+		require.NoError(t, err)
+		violations := findViolations(doc)
+		require.Equal(t, []violation{
+			{"code.$scope.x.y", "field name contains '.'"},
+			{"sym", "symbol is not valid UTF-8"},
+		}, violations)
+	})
+
+	t.Run("--stopOnError requires --validate", func(t *testing.T) {
+		_, err := ParseOptions([]string{"--stopOnError"}, "", "")
+		require.ErrorContains(t, err, "--stopOnError can only be used with --validate")
+	})
+}
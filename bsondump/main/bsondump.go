@@ -40,6 +40,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	signals.Handle()
 
 	dumper, err := bsondump.New(opts)
@@ -58,7 +63,9 @@ func main() {
 	log.Logvf(log.DebugLow, "running bsondump with --objcheck: %v", opts.ObjCheck)
 
 	var numFound int
-	if opts.Type == bsondump.DebugOutputType {
+	if opts.Validate {
+		numFound, err = dumper.Validate()
+	} else if opts.Type == bsondump.DebugOutputType {
 		numFound, err = dumper.Debug()
 	} else {
 		numFound, err = dumper.JSON()
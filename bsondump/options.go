@@ -31,16 +31,40 @@ const (
 	JSONOutputType  = "json"
 )
 
+// Extended JSON formats supported by the --outputMode option.
+const (
+	CanonicalOutputMode = "canonical"
+	RelaxedOutputMode   = "relaxed"
+	LegacyOutputMode    = "legacy"
+)
+
 type OutputOptions struct {
 	// Format to display the BSON data file
 	Type string `long:"type" value-name:"<type>" default:"json" default-mask:"-" description:"type of output: debug, json"`
 
 	// Validate each BSON document before displaying
-	ObjCheck bool `long:"objcheck" description:"validate BSON during processing"`
+	ObjCheck bool `long:"objcheck" description:"validate BSON during processing, stopping at the first invalid document and reporting its byte offset"`
+
+	// Check documents for problems drivers reject instead of displaying them
+	Validate    bool `long:"validate" description:"instead of dumping documents, report each string or field name that is not valid UTF-8, each field name containing '.', and each top-level field name starting with '$', with the path of the field; exits with an error if any are found"`
+	StopOnError bool `long:"stopOnError" description:"with --validate, stop at the first document with a problem"`
+
+	// Extended JSON format to use when the output type is JSON
+	OutputMode string `long:"outputMode" value-name:"<mode>" default:"canonical" default-mask:"-" description:"extended JSON format to output with --type=json: canonical, relaxed, or legacy (defaults to 'canonical')"`
 
 	// Display JSON data with indents
 	Pretty bool `long:"pretty" description:"output JSON formatted to be human-readable"`
 
+	// Number of documents to skip before displaying any
+	Skip int `long:"skip" value-name:"<count>" description:"number of documents to skip before dumping; skipped documents are not decoded or validated"`
+
+	// Maximum number of documents to display
+	Limit int `long:"limit" value-name:"<count>" description:"maximum number of documents to dump (0 for no limit)"`
+
+	// Nesting and array limits for the debug output
+	MaxDepth    int `long:"maxDepth" value-name:"<depth>" description:"with --type=debug, number of levels of nested documents and arrays to print, counting the top-level document (0 for no limit)"`
+	MaxArrayLen int `long:"maxArrayLen" value-name:"<count>" description:"with --type=debug, number of elements of each array to print (0 for no limit)"`
+
 	// Path to input BSON file
 	BSONFileName string `long:"bsonFile" description:"path to BSON file to dump to JSON; default is stdin"`
 
@@ -85,6 +109,38 @@ func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, erro
 		outputOpts.BSONFileName = args[0]
 	}
 
+	if outputOpts.Skip < 0 {
+		return Options{}, fmt.Errorf("--skip must not be negative")
+	}
+	if outputOpts.Limit < 0 {
+		return Options{}, fmt.Errorf("--limit must not be negative")
+	}
+	if outputOpts.MaxDepth < 0 {
+		return Options{}, fmt.Errorf("--maxDepth must not be negative")
+	}
+	if outputOpts.MaxArrayLen < 0 {
+		return Options{}, fmt.Errorf("--maxArrayLen must not be negative")
+	}
+	if outputOpts.StopOnError && !outputOpts.Validate {
+		return Options{}, fmt.Errorf("--stopOnError can only be used with --validate")
+	}
+	if (outputOpts.MaxDepth > 0 || outputOpts.MaxArrayLen > 0) &&
+		outputOpts.Type != DebugOutputType {
+		return Options{}, fmt.Errorf("--maxDepth and --maxArrayLen can only be used with --type=debug")
+	}
+
+	switch outputOpts.OutputMode {
+	case CanonicalOutputMode, RelaxedOutputMode, LegacyOutputMode:
+	default:
+		return Options{}, fmt.Errorf(
+			"unsupported output mode '%v'. Must be one of '%v', '%v', or '%v'",
+			outputOpts.OutputMode,
+			CanonicalOutputMode,
+			RelaxedOutputMode,
+			LegacyOutputMode,
+		)
+	}
+
 	switch outputOpts.Type {
 	case "", DebugOutputType, JSONOutputType:
 		return Options{toolOpts, outputOpts}, nil
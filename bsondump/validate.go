@@ -0,0 +1,180 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsondump
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// dbRefFields are the top-level field names starting with '$' that a
+// document may have, because it is a DBRef.
+var dbRefFields = []string{"$ref", "$id", "$db"}
+
+// A violation is a problem --validate found in a document. Its path is the
+// dotted path of the field with the problem, or empty if the problem is with
+// the document as a whole.
+type violation struct {
+	path    string
+	problem string
+}
+
+func (v violation) String() string {
+	if v.path == "" {
+		return v.problem
+	}
+	return fmt.Sprintf("%q: %v", v.path, v.problem)
+}
+
+// findViolations checks a document for problems that drivers reject even
+// though the document is well-formed BSON: strings and field names that
+// aren't valid UTF-8, field names containing '.', and top-level field names
+// starting with '$'. A document that isn't well-formed has a single violation
+// describing the structural problem.
+func findViolations(doc bson.Raw) []violation {
+	if err := doc.Validate(); err != nil {
+		return []violation{{problem: fmt.Sprintf("invalid BSON: %v", err)}}
+	}
+	return appendDocumentViolations(nil, doc, "", false)
+}
+
+func appendDocumentViolations(
+	violations []violation,
+	doc bson.Raw,
+	prefix string,
+	isArray bool,
+) []violation {
+	elements, err := doc.Elements()
+	if err != nil {
+		return append(violations, violation{prefix, fmt.Sprintf("invalid BSON: %v", err)})
+	}
+	for _, element := range elements {
+		key := element.Key()
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if !isArray {
+			switch {
+			case !utf8.ValidString(key):
+				violations = append(violations, violation{path, "field name is not valid UTF-8"})
+			case strings.Contains(key, "."):
+				violations = append(violations, violation{path, "field name contains '.'"})
+			case prefix == "" && strings.HasPrefix(key, "$") && !isDBRefField(key):
+				violations = append(
+					violations,
+					violation{path, "top-level field name starts with '$'"},
+				)
+			}
+		}
+
+		violations = appendValueViolations(violations, element.Value(), path)
+	}
+	return violations
+}
+
+func appendValueViolations(violations []violation, value bson.RawValue, path string) []violation {
+	checkString := func(what, s string) {
+		if !utf8.ValidString(s) {
+			violations = append(violations, violation{path, what + " is not valid UTF-8"})
+		}
+	}
+
+	switch value.Type {
+	case bsontype.String:
+		checkString("string", value.StringValue())
+	case bsontype.Symbol:
+		checkString("symbol", value.Symbol())
+	case bsontype.JavaScript:
+		checkString("JavaScript code", value.JavaScript())
+	case bsontype.CodeWithScope:
+		code, scope := value.CodeWithScope()
+		checkString("JavaScript code", code)
+		violations = appendDocumentViolations(violations, scope, path+".$scope", false)
+	case bsontype.Regex:
+		pattern, options := value.Regex()
+		checkString("regular expression pattern", pattern)
+		checkString("regular expression options", options)
+	case bsontype.DBPointer:
+		namespace, _ := value.DBPointer()
+		checkString("DBPointer namespace", namespace)
+	case bsontype.EmbeddedDocument:
+		violations = appendDocumentViolations(violations, value.Document(), path, false)
+	case bsontype.Array:
+		violations = appendDocumentViolations(violations, bson.Raw(value.Array()), path, true)
+	}
+	return violations
+}
+
+func isDBRefField(key string) bool {
+	for _, field := range dbRefFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate iterates through the BSON file and checks each document it finds
+// with findViolations, writing a line for each violation. It stops at the
+// first document with a violation if --stopOnError is set. It returns the
+// number of documents checked and a non-nil error if any document had a
+// violation or one is encountered before the end of the file is reached.
+func (bd *BSONDump) Validate() (int, error) {
+	numFound := 0
+	numInvalid := 0
+
+	if bd.InputSource == nil {
+		panic("Tried to call Validate() before opening file")
+	}
+
+	offset, err := bd.skipDocuments()
+	if err != nil {
+		return numFound, err
+	}
+	skipped := bd.OutputOptions.Skip
+	for !bd.limitReached(numFound) {
+		result := bson.Raw(bd.InputSource.LoadNext())
+		if result == nil {
+			break
+		}
+
+		violations := findViolations(result)
+		for _, v := range violations {
+			_, err := fmt.Fprintf(bd.OutputWriter, "document %v at byte offset %v: %v\n",
+				skipped+numFound+1, offset, v)
+			if err != nil {
+				return numFound, err
+			}
+		}
+		if len(violations) > 0 {
+			numInvalid++
+			if bd.OutputOptions.StopOnError {
+				return numFound + 1, fmt.Errorf(
+					"document %v at byte offset %v failed validation",
+					skipped+numFound+1, offset)
+			}
+		}
+		offset += int64(len(result))
+		numFound++
+	}
+	if err := bd.InputSource.Err(); err != nil {
+		return numFound, fmt.Errorf(
+			"error reading document %v at byte offset %v: %v", skipped+numFound+1, offset, err)
+	}
+
+	if numInvalid > 0 {
+		return numFound, fmt.Errorf(
+			"%v of %v document(s) failed validation", numInvalid, numFound)
+	}
+	return numFound, nil
+}
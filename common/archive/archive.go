@@ -45,6 +45,11 @@ const minBSONSize = 4 + 1 // an empty BSON document should be exactly five bytes
 var terminator int32 = -1
 var terminatorBytes = []byte{0xFF, 0xFF, 0xFF, 0xFF} // TODO, rectify this with terminator
 
+// ManifestCollection is the collection, in the empty database, that holds the
+// manifest written by mongodump --writeManifest. It is the last namespace of
+// the archive, and its single document holds the manifest as JSON.
+const ManifestCollection = "manifest"
+
 // MagicNumber is four bytes that are found at the beginning of the archive that indicate that
 // the byte stream is an archive, as opposed to anything else, including a stream of BSON documents.
 const MagicNumber uint32 = 0x8199e26d
@@ -13,6 +13,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"time"
@@ -248,6 +249,23 @@ func ParseSpecialKeys(special interface{}) (interface{}, error) {
 			return parseNumberLongField(jsonValue)
 		}
 
+		if jsonValue, ok := doc["$numberDouble"]; ok {
+			return parseNumberDoubleField(jsonValue)
+		}
+
+		if jsonValue, ok := doc["$uuid"]; ok {
+			switch v := jsonValue.(type) {
+			case string:
+				data, err := json.ParseUUID(v)
+				if err != nil {
+					return nil, err
+				}
+				return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: data}, nil
+			default:
+				return nil, errors.New("expected $uuid field to have string value")
+			}
+		}
+
 		if jsonValue, ok := doc["$numberInt"]; ok {
 			switch v := jsonValue.(type) {
 			case string:
@@ -274,20 +292,20 @@ func ParseSpecialKeys(special interface{}) (interface{}, error) {
 			}
 
 			if seconds, ok := tsDoc["t"]; ok {
-				if asUint32, err := util.ToUInt32(seconds); err == nil {
-					ts.Seconds = asUint32
-				} else {
-					return nil, errors.New("expected $timestamp 't' field to be a numeric type")
+				asUint32, err := parseTimestampField(seconds, "t")
+				if err != nil {
+					return nil, err
 				}
+				ts.Seconds = asUint32
 			} else {
 				return nil, errors.New("expected $timestamp to have 't' field")
 			}
 			if inc, ok := tsDoc["i"]; ok {
-				if asUint32, err := util.ToUInt32(inc); err == nil {
-					ts.Increment = asUint32
-				} else {
-					return nil, errors.New("expected $timestamp 'i' field to be  a numeric type")
+				asUint32, err := parseTimestampField(inc, "i")
+				if err != nil {
+					return nil, err
 				}
+				ts.Increment = asUint32
 			} else {
 				return nil, errors.New("expected $timestamp to have 'i' field")
 			}
@@ -308,11 +326,21 @@ func ParseSpecialKeys(special interface{}) (interface{}, error) {
 			return primitive.Undefined{}, nil
 		}
 
-		if _, ok := doc["$maxKey"]; ok {
+		if jsonValue, ok := doc["$regularExpression"]; ok {
+			return parseRegularExpressionField(jsonValue)
+		}
+
+		if jsonValue, ok := doc["$maxKey"]; ok {
+			if err := checkKeyBoundField(jsonValue, "$maxKey"); err != nil {
+				return nil, err
+			}
 			return primitive.MaxKey{}, nil
 		}
 
-		if _, ok := doc["$minKey"]; ok {
+		if jsonValue, ok := doc["$minKey"]; ok {
+			if err := checkKeyBoundField(jsonValue, "$minKey"); err != nil {
+				return nil, err
+			}
 			return primitive.MinKey{}, nil
 		}
 
@@ -446,6 +474,105 @@ func parseNumberLongField(jsonValue interface{}) (int64, error) {
 	}
 }
 
+// parseTimestampField converts the 't' or 'i' field of a $timestamp, which
+// must be an integer between 0 and math.MaxUint32.
+func parseTimestampField(jsonValue interface{}, key string) (uint32, error) {
+	asFloat, err := util.ToFloat64(jsonValue)
+	if err != nil {
+		return 0, fmt.Errorf("expected $timestamp '%v' field to be a numeric type", key)
+	}
+	if asFloat < 0 || asFloat > math.MaxUint32 || asFloat != math.Trunc(asFloat) {
+		return 0, fmt.Errorf(
+			"expected $timestamp '%v' field to be a non-negative 32-bit integer, got %v",
+			key,
+			jsonValue,
+		)
+	}
+	return uint32(asFloat), nil
+}
+
+// parseRegularExpressionField parses the value of a canonical Extended JSON
+// $regularExpression field, which is a document with string "pattern" and
+// "options" fields.
+func parseRegularExpressionField(jsonValue interface{}) (primitive.Regex, error) {
+	var regexDoc map[string]interface{}
+	switch internalDoc := jsonValue.(type) {
+	case map[string]interface{}:
+		regexDoc = internalDoc
+	case bson.D:
+		regexDoc = internalDoc.Map()
+	default:
+		return primitive.Regex{}, errors.New(
+			"expected $regularExpression key to have internal document")
+	}
+
+	regex := primitive.Regex{}
+	for key, value := range regexDoc {
+		s, ok := value.(string)
+		switch {
+		case key != "pattern" && key != "options":
+			return regex, fmt.Errorf("unexpected field '%v' in $regularExpression", key)
+		case !ok:
+			return regex, fmt.Errorf("expected $regularExpression '%v' field to be a string", key)
+		case key == "pattern":
+			regex.Pattern = s
+		default:
+			regex.Options = s
+		}
+	}
+	if _, ok := regexDoc["pattern"]; !ok {
+		return regex, errors.New("expected $regularExpression to have 'pattern' field")
+	}
+	if _, ok := regexDoc["options"]; !ok {
+		return regex, errors.New("expected $regularExpression to have 'options' field")
+	}
+
+	// the options allowed by the BSON specification
+	for i := range regex.Options {
+		switch o := regex.Options[i]; o {
+		case 'i', 'l', 'm', 's', 'u', 'x':
+		default:
+			return regex, fmt.Errorf("invalid regular expression option '%c'", o)
+		}
+	}
+	return regex, nil
+}
+
+// checkKeyBoundField checks that the value of a $minKey or $maxKey field is 1,
+// as Extended JSON requires.
+func checkKeyBoundField(jsonValue interface{}, key string) error {
+	if asFloat, err := util.ToFloat64(jsonValue); err != nil || asFloat != 1 {
+		return fmt.Errorf("expected %v field to have value 1, got %v", key, jsonValue)
+	}
+	return nil
+}
+
+// parseNumberDoubleField parses the string value of a $numberDouble field,
+// which is either a decimal number or one of "Infinity", "-Infinity" and "NaN".
+func parseNumberDoubleField(jsonValue interface{}) (float64, error) {
+	v, ok := jsonValue.(string)
+	if !ok {
+		return 0, errors.New("expected $numberDouble field to have string value")
+	}
+
+	switch v {
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+
+	// ParseFloat also accepts spellings like "inf" and "nan", which
+	// $numberDouble does not
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid $numberDouble value %q", v)
+	}
+	return f, nil
+}
+
 func Bson2Float64(data interface{}) (float64, bool) {
 	switch v := data.(type) {
 	case int32:
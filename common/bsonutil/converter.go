@@ -88,6 +88,13 @@ func ConvertLegacyExtJSONValueToBSON(x interface{}) (interface{}, error) {
 		}
 		return primitive.Binary{v.Type, data}, nil
 
+	case json.UUID: // UUID
+		data, err := json.ParseUUID(string(v))
+		if err != nil {
+			return nil, err
+		}
+		return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: data}, nil
+
 	case json.DBPointer: // DBPointer, for backwards compatibility
 		return primitive.DBPointer{v.Namespace, v.Id}, nil
 
@@ -99,7 +106,11 @@ func ConvertLegacyExtJSONValueToBSON(x interface{}) (interface{}, error) {
 
 	case json.JavaScript: // Javascript
 		if v.Scope != nil {
-			return primitive.CodeWithScope{Code: primitive.JavaScript(v.Code), Scope: v.Scope}, nil
+			scope, err := ConvertLegacyExtJSONValueToBSON(v.Scope)
+			if err != nil {
+				return nil, err
+			}
+			return primitive.CodeWithScope{Code: primitive.JavaScript(v.Code), Scope: scope}, nil
 		}
 		return primitive.JavaScript(v.Code), nil
 
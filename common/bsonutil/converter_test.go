@@ -137,6 +137,25 @@ func TestDateBSONToJSON(t *testing.T) {
 	})
 }
 
+func TestDateJSONToBSON(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Converting JSON dates to BSON should produce a BSON datetime", t, func() {
+		for _, millis := range []int64{1609459200000, -86400000, -1} {
+			value, err := ConvertLegacyExtJSONValueToBSON(json.Date(millis))
+			So(err, ShouldBeNil)
+
+			raw, err := bson.Marshal(bson.D{{"date", value}})
+			So(err, ShouldBeNil)
+			var doc struct {
+				Date primitive.DateTime `bson:"date"`
+			}
+			So(bson.Unmarshal(raw, &doc), ShouldBeNil)
+			So(doc.Date, ShouldEqual, primitive.DateTime(millis))
+		}
+	})
+}
+
 func TestMaxKeyBSONToJSON(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 
@@ -342,3 +361,37 @@ func TestJSCodeBSONToJSON(t *testing.T) {
 		})
 	})
 }
+
+func TestJSCodeConstructorRoundTrip(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("A Code constructor with a scope", t, func() {
+		var jsonMap map[string]interface{}
+		err := json.Unmarshal(
+			[]byte(`{"code":Code("function() { return x; }", {"x": "y"})}`),
+			&jsonMap,
+		)
+		So(err, ShouldBeNil)
+
+		Convey("converts to BSON code with scope", func() {
+			bsonValue, err := ConvertLegacyExtJSONValueToBSON(jsonMap["code"])
+			So(err, ShouldBeNil)
+			code, ok := bsonValue.(primitive.CodeWithScope)
+			So(ok, ShouldBeTrue)
+			So(code.Code, ShouldEqual, primitive.JavaScript("function() { return x; }"))
+
+			Convey("which converts back to the same JSON", func() {
+				jsonValue, err := ConvertBSONValueToLegacyExtJSON(code)
+				So(err, ShouldBeNil)
+				out, err := json.Marshal(jsonValue)
+				So(err, ShouldBeNil)
+
+				var roundTripped interface{}
+				So(json.Unmarshal(out, &roundTripped), ShouldBeNil)
+				roundTripped, err = ParseSpecialKeys(roundTripped)
+				So(err, ShouldBeNil)
+				So(roundTripped, ShouldResemble, code)
+			})
+		})
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -73,5 +74,29 @@ func TestDateValue(t *testing.T) {
 				So(jsonValue, ShouldEqual, date)
 			})
 		})
+
+		Convey("works for an ISODate with milliseconds and a timezone offset", func() {
+			key := "key"
+			jsonMap := map[string]interface{}{
+				key: json.ISODate("2021-01-01T00:00:00.123+05:30"),
+			}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(time.Time)
+			So(ok, ShouldBeTrue)
+			So(jsonValue, ShouldEqual, time.Date(2020, 12, 31, 18, 30, 0, 123e6, time.UTC))
+		})
+
+		Convey("reports an error for an out-of-range date", func() {
+			jsonMap := map[string]interface{}{
+				"key": map[string]interface{}{"$date": "2021-02-30T00:00:00Z"},
+			}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "day out of range")
+		})
 	})
 }
@@ -0,0 +1,126 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"sort"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FlattenDocument expands the subdocuments and arrays of doc into a single
+// level map keyed by dotted paths, e.g. {a: {b: [1, 2]}} becomes
+// {"a.b.0": 1, "a.b.1": 2}. Empty subdocuments and arrays are kept as values
+// so that their keys aren't lost.
+func FlattenDocument(doc bson.D) map[string]interface{} {
+	return FlattenDocumentFunc(doc, nil)
+}
+
+// FlattenDocumentFunc is like FlattenDocument, but passes every leaf value
+// through convert, if it is not nil, before storing it.
+func FlattenDocumentFunc(
+	doc bson.D,
+	convert func(value interface{}) interface{},
+) map[string]interface{} {
+	flat := map[string]interface{}{}
+	flattenValue(flat, "", doc, convert)
+	return flat
+}
+
+// flattenValue stores value in flat under key, first expanding it if it is
+// a subdocument or array.
+func flattenValue(
+	flat map[string]interface{},
+	key string,
+	value interface{},
+	convert func(interface{}) interface{},
+) {
+	switch v := value.(type) {
+	case *bson.D:
+		if v != nil {
+			flattenValue(flat, key, *v, convert)
+			return
+		}
+	case MarshalD:
+		if len(v) > 0 || key == "" {
+			flattenValue(flat, key, bson.D(v), convert)
+			return
+		}
+	case bson.D:
+		if len(v) > 0 || key == "" {
+			for _, elem := range v {
+				flattenValue(flat, joinKey(key, elem.Key), elem.Value, convert)
+			}
+			return
+		}
+	case bson.M:
+		if len(v) > 0 || key == "" {
+			flattenMap(flat, key, v, convert)
+			return
+		}
+	case map[string]interface{}:
+		if len(v) > 0 || key == "" {
+			flattenMap(flat, key, v, convert)
+			return
+		}
+	case bson.A:
+		if len(v) > 0 {
+			flattenSlice(flat, key, v, convert)
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			flattenSlice(flat, key, v, convert)
+			return
+		}
+	}
+
+	if convert != nil {
+		value = convert(value)
+	}
+	flat[key] = value
+}
+
+// flattenMap flattens each value of m under key, in sorted key order so that
+// convert is called in a stable order.
+func flattenMap(
+	flat map[string]interface{},
+	key string,
+	m map[string]interface{},
+	convert func(interface{}) interface{},
+) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		flattenValue(flat, joinKey(key, k), m[k], convert)
+	}
+}
+
+// flattenSlice flattens each element of a under key, using the element's
+// index as the last part of its key.
+func flattenSlice(
+	flat map[string]interface{},
+	key string,
+	a []interface{},
+	convert func(interface{}) interface{},
+) {
+	for i, elem := range a {
+		flattenValue(flat, joinKey(key, strconv.Itoa(i)), elem, convert)
+	}
+}
+
+// joinKey appends child to the dotted path key.
+func joinKey(key, child string) string {
+	if key == "" {
+		return child
+	}
+	return key + "." + child
+}
@@ -0,0 +1,72 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFlattenDocument(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When flattening a document", t, func() {
+		Convey("top-level values keep their keys", func() {
+			So(FlattenDocument(bson.D{{"a", 1}, {"b", "x"}}), ShouldResemble,
+				map[string]interface{}{"a": 1, "b": "x"})
+		})
+
+		Convey("deeply nested subdocuments are joined with dots", func() {
+			doc := bson.D{{"a", bson.D{{"b", &bson.D{{"c", bson.M{"d": int32(4)}}}}}}}
+			So(FlattenDocument(doc), ShouldResemble,
+				map[string]interface{}{"a.b.c.d": int32(4)})
+		})
+
+		Convey("arrays are expanded by index", func() {
+			doc := bson.D{
+				{"a", bson.A{1, bson.D{{"b", 2}}, []interface{}{3, 4}}},
+				{"c", bson.D{{"d", bson.A{bson.D{{"e", 5}}, bson.D{{"e", 6}}}}}},
+			}
+			So(FlattenDocument(doc), ShouldResemble, map[string]interface{}{
+				"a.0":     1,
+				"a.1.b":   2,
+				"a.2.0":   3,
+				"a.2.1":   4,
+				"c.d.0.e": 5,
+				"c.d.1.e": 6,
+			})
+		})
+
+		Convey("empty subdocuments and arrays are kept as values", func() {
+			doc := bson.D{{"a", bson.D{}}, {"b", bson.A{}}, {"c", bson.M{}}, {"d", MarshalD{}}}
+			So(FlattenDocument(doc), ShouldResemble, map[string]interface{}{
+				"a": bson.D{},
+				"b": bson.A{},
+				"c": bson.M{},
+				"d": MarshalD{},
+			})
+		})
+
+		Convey("leaf values are converted with the given function", func() {
+			oid := primitive.NewObjectID()
+			doc := bson.D{{"_id", oid}, {"a", bson.A{int64(1), true}}}
+			flat := FlattenDocumentFunc(doc, func(v interface{}) interface{} {
+				return fmt.Sprintf("%v", v)
+			})
+			So(flat, ShouldResemble, map[string]interface{}{
+				"_id": oid.String(),
+				"a.0": "1",
+				"a.1": "true",
+			})
+		})
+	})
+}
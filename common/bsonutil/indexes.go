@@ -157,6 +157,30 @@ func ConvertLegacyIndexOptions(indexOptions bson.M) {
 	}
 }
 
+// ConvertLegacyIndexVersion removes the index version from indexOptions if it
+// isn't one that current servers can build, so that the server uses its
+// default version instead. Index version 0 was last supported by MongoDB 3.0.
+//
+// This function logs the versions that are removed.
+func ConvertLegacyIndexVersion(indexOptions bson.M, ns string) {
+	v, ok := indexOptions["v"]
+	if !ok {
+		return
+	}
+	if version, ok := Bson2Float64(v); ok &&
+		(math.Abs(version-1) < epsilon || math.Abs(version-2) < epsilon) {
+		return
+	}
+	delete(indexOptions, "v")
+	log.Logvf(
+		log.Always,
+		"convertLegacyIndexes: removed unsupported index version '%v' from index '%v' on collection '%s'",
+		v,
+		indexOptions["name"],
+		ns,
+	)
+}
+
 // ConvertLegacyIndexOptionsFromOp removes options that don't match a known list of index options.
 // It is preferable to use the ignoreUnknownIndexOptions on the createIndex command to
 // force the server to do this task. But that option was only added in 4.1.9. So for
@@ -137,3 +137,29 @@ func TestConvertLegacyIndexOptionsFromOp(t *testing.T) {
 		So(indexoptionsMultipleInvalidOptions, ShouldResemble, convertedIndex)
 	})
 }
+
+func TestConvertLegacyIndexVersion(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Converting legacy index versions", t, func() {
+		Convey("removes version 0", func() {
+			options := bson.M{"name": "a_1", "v": int32(0)}
+			ConvertLegacyIndexVersion(options, "test.c")
+			So(options, ShouldResemble, bson.M{"name": "a_1"})
+		})
+
+		Convey("keeps versions 1 and 2", func() {
+			for _, v := range []interface{}{int32(1), int64(2), 2.0} {
+				options := bson.M{"name": "a_1", "v": v}
+				ConvertLegacyIndexVersion(options, "test.c")
+				So(options["v"], ShouldEqual, v)
+			}
+		})
+
+		Convey("leaves options without a version alone", func() {
+			options := bson.M{"name": "a_1"}
+			ConvertLegacyIndexVersion(options, "test.c")
+			So(options, ShouldResemble, bson.M{"name": "a_1"})
+		})
+	})
+}
@@ -23,6 +23,16 @@ type MarshalD bson.D
 // MarshalJSON makes the MarshalD type usable by
 // the encoding/json package.
 func (md MarshalD) MarshalJSON() ([]byte, error) {
+	return md.marshal(json.Marshal)
+}
+
+// MarshalCanonicalJSON marshals the document with its values in their
+// canonical Extended JSON form, for json.MarshalCanonical.
+func (md MarshalD) MarshalCanonicalJSON() ([]byte, error) {
+	return md.marshal(json.MarshalCanonical)
+}
+
+func (md MarshalD) marshal(marshal func(interface{}) ([]byte, error)) ([]byte, error) {
 	var buff bytes.Buffer
 	buff.WriteString("{")
 	for i, item := range md {
@@ -30,7 +40,7 @@ func (md MarshalD) MarshalJSON() ([]byte, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cannot marshal key %v: %v", item.Key, err)
 		}
-		val, err := json.Marshal(item.Value)
+		val, err := marshal(item.Value)
 		if err != nil {
 			return nil, fmt.Errorf("cannot marshal value %v: %v", item.Value, err)
 		}
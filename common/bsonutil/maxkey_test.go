@@ -43,5 +43,41 @@ func TestMaxKeyValue(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(jsonMap[key], ShouldResemble, primitive.MaxKey{})
 		})
+
+		Convey(`fails for a MaxKey document without the value 1`, func() {
+			for _, value := range []interface{}{0, 2, 1.5, "1", true} {
+				jsonMap := map[string]interface{}{
+					"key": map[string]interface{}{
+						"$maxKey": value,
+					},
+				}
+
+				err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "expected $maxKey field to have value 1")
+			}
+		})
+	})
+
+	Convey("MaxKey values survive a round trip through JSON", t, func() {
+		for _, data := range []string{
+			`{"key":MaxKey}`, `{"key":MaxKey()}`, `{"key":{"$maxKey":1}}`,
+		} {
+			var jsonMap map[string]interface{}
+			So(json.Unmarshal([]byte(data), &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, primitive.MaxKey{})
+
+			value, err := ConvertBSONValueToLegacyExtJSON(jsonMap["key"])
+			So(err, ShouldBeNil)
+			out, err := json.Marshal(map[string]interface{}{"key": value})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `{"key":{"$maxKey":1}}`)
+
+			jsonMap = nil
+			So(json.Unmarshal(out, &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, primitive.MaxKey{})
+		}
 	})
 }
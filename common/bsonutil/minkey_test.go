@@ -43,5 +43,41 @@ func TestMinKeyValue(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(jsonMap[key], ShouldResemble, primitive.MinKey{})
 		})
+
+		Convey(`fails for a MinKey document without the value 1`, func() {
+			for _, value := range []interface{}{0, 2, 1.5, "1", true} {
+				jsonMap := map[string]interface{}{
+					"key": map[string]interface{}{
+						"$minKey": value,
+					},
+				}
+
+				err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "expected $minKey field to have value 1")
+			}
+		})
+	})
+
+	Convey("MinKey values survive a round trip through JSON", t, func() {
+		for _, data := range []string{
+			`{"key":MinKey}`, `{"key":MinKey()}`, `{"key":{"$minKey":1}}`,
+		} {
+			var jsonMap map[string]interface{}
+			So(json.Unmarshal([]byte(data), &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, primitive.MinKey{})
+
+			value, err := ConvertBSONValueToLegacyExtJSON(jsonMap["key"])
+			So(err, ShouldBeNil)
+			out, err := json.Marshal(map[string]interface{}{"key": value})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `{"key":{"$minKey":1}}`)
+
+			jsonMap = nil
+			So(json.Unmarshal(out, &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, primitive.MinKey{})
+		}
 	})
 }
@@ -0,0 +1,93 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/json"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNumberDoubleValue(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	convert := func(value interface{}) (interface{}, error) {
+		jsonMap := map[string]interface{}{
+			"key": map[string]interface{}{
+				"$numberDouble": value,
+			},
+		}
+		err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+		return jsonMap["key"], err
+	}
+
+	Convey("When converting JSON with $numberDouble values", t, func() {
+
+		Convey("works for finite values", func() {
+			value, err := convert("1.5")
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, 1.5)
+		})
+
+		Convey("works for Infinity, -Infinity and NaN", func() {
+			value, err := convert("Infinity")
+			So(err, ShouldBeNil)
+			So(math.IsInf(value.(float64), 1), ShouldBeTrue)
+
+			value, err = convert("-Infinity")
+			So(err, ShouldBeNil)
+			So(math.IsInf(value.(float64), -1), ShouldBeTrue)
+
+			value, err = convert("NaN")
+			So(err, ShouldBeNil)
+			So(math.IsNaN(value.(float64)), ShouldBeTrue)
+		})
+
+		Convey("preserves subnormals and negative zero exactly", func() {
+			for _, f := range []float64{5e-324, math.Copysign(0, -1)} {
+				out, err := MarshalExtJSONReversible(bson.D{{"key", f}}, true, false)
+				So(err, ShouldBeNil)
+
+				var doc map[string]interface{}
+				So(json.Unmarshal(out, &doc), ShouldBeNil)
+				value, err := convert(doc["key"].(map[string]interface{})["$numberDouble"])
+				So(err, ShouldBeNil)
+				So(math.Float64bits(value.(float64)), ShouldEqual, math.Float64bits(f))
+			}
+		})
+
+		Convey("rejects invalid values", func() {
+			for _, v := range []interface{}{"inf", "nan", "1e400", "abc", 1.5} {
+				_, err := convert(v)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
+	Convey("When marshaling doubles to Extended JSON", t, func() {
+		doc := bson.D{{"inf", math.Inf(1)}, {"nan", math.NaN()}, {"one", 1.0}}
+
+		Convey("canonical mode wraps every double in $numberDouble", func() {
+			out, err := MarshalExtJSONReversible(doc, true, false)
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual,
+				`{"inf":{"$numberDouble":"Infinity"},"nan":{"$numberDouble":"NaN"},`+
+					`"one":{"$numberDouble":"1.0"}}`)
+		})
+
+		Convey("relaxed mode only wraps non-finite doubles", func() {
+			out, err := MarshalExtJSONReversible(doc, false, false)
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual,
+				`{"inf":{"$numberDouble":"Infinity"},"nan":{"$numberDouble":"NaN"},"one":1.0}`)
+		})
+	})
+}
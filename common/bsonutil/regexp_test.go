@@ -12,6 +12,7 @@ import (
 	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -71,5 +72,82 @@ func TestRegExpValue(t *testing.T) {
 			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
 			So(err, ShouldNotBeNil)
 		})
+
+		Convey(`works for a canonical $regularExpression document`, func() {
+			for options, expected := range map[string]primitive.Regex{
+				"ilmsux": {"foo", "ilmsux"},
+				"":       {"foo", ""},
+			} {
+				jsonMap := map[string]interface{}{
+					"key": map[string]interface{}{
+						"$regularExpression": map[string]interface{}{
+							"pattern": "foo",
+							"options": options,
+						},
+					},
+				}
+
+				err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+				So(err, ShouldBeNil)
+				So(jsonMap["key"], ShouldResemble, expected)
+			}
+		})
+
+		Convey(`fails for a malformed $regularExpression document`, func() {
+			for _, regexDoc := range []map[string]interface{}{
+				{"pattern": "foo", "options": "g"},
+				{"pattern": "foo"},
+				{"options": "i"},
+				{"pattern": "foo", "options": 1},
+				{"pattern": "foo", "options": "", "flags": ""},
+			} {
+				jsonMap := map[string]interface{}{
+					"key": map[string]interface{}{"$regularExpression": regexDoc},
+				}
+
+				err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
+	Convey("RegExp values survive a round trip through JSON", t, func() {
+		for _, data := range []string{
+			`{"key":{"$regularExpression":{"pattern":"a/c","options":""}}}`,
+			`{"key":{"$regex":"a/c","$options":""}}`,
+			`{"key":/a\/c/}`,
+		} {
+			var jsonMap map[string]interface{}
+			So(json.Unmarshal([]byte(data), &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, primitive.Regex{"a/c", ""})
+
+			value, err := ConvertBSONValueToLegacyExtJSON(jsonMap["key"])
+			So(err, ShouldBeNil)
+			canonical, err := json.MarshalCanonical(map[string]interface{}{"key": value})
+			So(err, ShouldBeNil)
+			So(string(canonical), ShouldEqual,
+				`{"key":{"$regularExpression":{"pattern":"a/c","options":""}}}`)
+			relaxed, err := json.Marshal(map[string]interface{}{"key": value})
+			So(err, ShouldBeNil)
+			So(string(relaxed), ShouldEqual, `{"key":{"$regex":"a/c","$options":""}}`)
+
+			for _, out := range [][]byte{canonical, relaxed} {
+				jsonMap = nil
+				So(json.Unmarshal(out, &jsonMap), ShouldBeNil)
+				So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+				So(jsonMap["key"], ShouldResemble, primitive.Regex{"a/c", ""})
+			}
+		}
+	})
+
+	Convey("RegExp values in converted documents marshal in canonical form", t, func() {
+		doc := bson.D{{"a", bson.D{{"b", primitive.Regex{"x", "i"}}}}}
+		value, err := ConvertBSONValueToLegacyExtJSON(doc)
+		So(err, ShouldBeNil)
+		out, err := json.MarshalCanonical(value)
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual,
+			`{"a":{"b":{"$regularExpression":{"pattern":"x","options":"i"}}}}`)
 	})
 }
@@ -0,0 +1,86 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// KeyOrder is the order in which MarshalWithKeyOrder writes the keys of a
+// document.
+type KeyOrder int
+
+const (
+	// KeyOrderOriginal writes keys in the order the document has them, as
+	// bson.Marshal does. It is the fastest, but the keys of maps are written
+	// in a random order.
+	KeyOrderOriginal KeyOrder = iota
+	// KeyOrderSorted sorts the keys of the document and of every subdocument
+	// lexicographically by their bytes.
+	KeyOrderSorted
+	// KeyOrderSortedIDFirst is like KeyOrderSorted, but keeps the top-level
+	// _id key first, as the server stores it.
+	KeyOrderSortedIDFirst
+)
+
+// MarshalWithKeyOrder marshals doc, which may be anything bson.Marshal
+// accepts, writing its keys in the given order. With a sorted order, the same
+// document always marshals to the same bytes, whatever order its keys were in,
+// so the output can be compared across runs.
+func MarshalWithKeyOrder(doc interface{}, order KeyOrder) ([]byte, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil || order == KeyOrderOriginal {
+		return raw, err
+	}
+
+	var d bson.D
+	if err := bson.Unmarshal(raw, &d); err != nil {
+		return nil, err
+	}
+	sortKeys(d)
+	if order == KeyOrderSortedIDFirst {
+		moveIDFirst(d)
+	}
+	return bson.Marshal(d)
+}
+
+// sortKeys sorts the keys of doc and of its subdocuments, including those in
+// arrays, in place.
+func sortKeys(doc bson.D) {
+	sort.SliceStable(doc, func(i, j int) bool {
+		return doc[i].Key < doc[j].Key
+	})
+	for _, elem := range doc {
+		sortValueKeys(elem.Value)
+	}
+}
+
+// sortValueKeys sorts the keys of value if it is a document, or of the
+// documents in it if it is an array.
+func sortValueKeys(value interface{}) {
+	switch v := value.(type) {
+	case bson.D:
+		sortKeys(v)
+	case bson.A:
+		for _, elem := range v {
+			sortValueKeys(elem)
+		}
+	}
+}
+
+// moveIDFirst moves the _id key of doc, if it has one, to the front.
+func moveIDFirst(doc bson.D) {
+	for i, elem := range doc {
+		if elem.Key == "_id" {
+			copy(doc[1:i+1], doc[:i])
+			doc[0] = elem
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMarshalWithKeyOrder(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	unmarshal := func(raw []byte) bson.D {
+		var d bson.D
+		So(bson.Unmarshal(raw, &d), ShouldBeNil)
+		return d
+	}
+
+	Convey("When marshalling with a key order", t, func() {
+		doc := bson.D{
+			{"b", 1},
+			{"_id", 2},
+			{"a", bson.D{{"z", 1}, {"y", bson.A{bson.D{{"d", 1}, {"c", 2}}, "x"}}}},
+		}
+
+		Convey("the original order is what bson.Marshal produces", func() {
+			raw, err := MarshalWithKeyOrder(doc, KeyOrderOriginal)
+			So(err, ShouldBeNil)
+			expected, err := bson.Marshal(doc)
+			So(err, ShouldBeNil)
+			So(raw, ShouldResemble, expected)
+		})
+
+		Convey("keys are sorted recursively, including in arrays", func() {
+			raw, err := MarshalWithKeyOrder(doc, KeyOrderSorted)
+			So(err, ShouldBeNil)
+			So(unmarshal(raw), ShouldResemble, bson.D{
+				{"_id", int32(2)},
+				{"a", bson.D{
+					{"y", bson.A{bson.D{{"c", int32(2)}, {"d", int32(1)}}, "x"}},
+					{"z", int32(1)},
+				}},
+				{"b", int32(1)},
+			})
+		})
+
+		Convey("the top-level _id can be kept first", func() {
+			doc := bson.D{{"b", 1}, {"A", 2}, {"_id", 3}}
+			raw, err := MarshalWithKeyOrder(doc, KeyOrderSortedIDFirst)
+			So(err, ShouldBeNil)
+			So(unmarshal(raw), ShouldResemble, bson.D{
+				{"_id", int32(3)}, {"A", int32(2)}, {"b", int32(1)},
+			})
+
+			raw, err = MarshalWithKeyOrder(bson.D{{"b", 1}, {"a", 2}}, KeyOrderSortedIDFirst)
+			So(err, ShouldBeNil)
+			So(unmarshal(raw), ShouldResemble, bson.D{{"a", int32(2)}, {"b", int32(1)}})
+		})
+
+		Convey("the output is stable across map iteration orders", func() {
+			m := bson.M{}
+			for i := 0; i < 50; i++ {
+				m[fmt.Sprintf("key%v", i)] = bson.M{"x": i, "w": bson.M{"v": i, "u": i}}
+			}
+			expected, err := MarshalWithKeyOrder(m, KeyOrderSorted)
+			So(err, ShouldBeNil)
+			for i := 0; i < 20; i++ {
+				raw, err := MarshalWithKeyOrder(m, KeyOrderSorted)
+				So(err, ShouldBeNil)
+				So(raw, ShouldResemble, expected)
+			}
+
+			d := MtoD(m)
+			raw, err := MarshalWithKeyOrder(d, KeyOrderSorted)
+			So(err, ShouldBeNil)
+			So(raw, ShouldResemble, expected)
+		})
+
+		Convey("values that are not documents are rejected", func() {
+			_, err := MarshalWithKeyOrder(42, KeyOrderSorted)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
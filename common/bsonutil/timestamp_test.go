@@ -7,6 +7,7 @@
 package bsonutil
 
 import (
+	"math"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/json"
@@ -48,5 +49,36 @@ func TestTimestampValue(t *testing.T) {
 				So(bsonMap.(map[string]interface{})["ts"], ShouldEqual, testTS)
 			})
 		})
+
+		Convey("the all-ones Timestamp survives a round trip", func() {
+			maxTS := primitive.Timestamp{T: math.MaxUint32, I: math.MaxUint32}
+
+			legacy, err := ConvertBSONValueToLegacyExtJSON(maxTS)
+			So(err, ShouldBeNil)
+			data, err := json.Marshal(legacy)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `{"$timestamp":{"t":4294967295,"i":4294967295}}`)
+
+			var jsonMap map[string]interface{}
+			So(json.Unmarshal([]byte(`{"ts":`+string(data)+`}`), &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+			So(jsonMap["ts"], ShouldEqual, maxTS)
+		})
+
+		Convey("values that aren't non-negative 32-bit integers are rejected", func() {
+			for _, tsDoc := range []map[string]interface{}{
+				{"t": -1.0, "i": 1.0},
+				{"t": 1.0, "i": int64(-1)},
+				{"t": 4294967296.0, "i": 1.0},
+				{"t": 1.0, "i": int64(math.MaxUint32 + 1)},
+				{"t": 1.5, "i": 1.0},
+				{"t": "1", "i": 1.0},
+			} {
+				_, err := ConvertLegacyExtJSONValueToBSON(map[string]interface{}{
+					"ts": map[string]interface{}{"$timestamp": tsDoc},
+				})
+				So(err, ShouldNotBeNil)
+			}
+		})
 	})
 }
@@ -0,0 +1,86 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package bsonutil
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/json"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUUIDValue(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	canonical := "73ffd264-44b3-4c69-90e8-e7d1dfc035d4"
+	expected := primitive.Binary{
+		Subtype: bson.TypeBinaryUUID,
+		Data: []byte{
+			0x73, 0xff, 0xd2, 0x64, 0x44, 0xb3, 0x4c, 0x69,
+			0x90, 0xe8, 0xe7, 0xd1, 0xdf, 0xc0, 0x35, 0xd4,
+		},
+	}
+
+	Convey("When converting JSON with UUID values", t, func() {
+		key := "key"
+
+		Convey(`works for UUID document ('{ "$uuid": "..." }')`, func() {
+			jsonMap := map[string]interface{}{
+				key: map[string]interface{}{"$uuid": canonical},
+			}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldBeNil)
+			So(jsonMap[key], ShouldResemble, expected)
+		})
+
+		Convey("works for json.UUID values", func() {
+			jsonMap := map[string]interface{}{key: json.UUID(canonical)}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldBeNil)
+			So(jsonMap[key], ShouldResemble, expected)
+		})
+
+		Convey("decoded UUIDs marshal back to the same $uuid document", func() {
+			input := `{"key":{"$uuid":"` + canonical + `"}}`
+			jsonMap := map[string]interface{}{}
+			So(json.Unmarshal([]byte(input), &jsonMap), ShouldBeNil)
+			So(ConvertLegacyExtJSONDocumentToBSON(jsonMap), ShouldBeNil)
+
+			binary, ok := jsonMap[key].(primitive.Binary)
+			So(ok, ShouldBeTrue)
+			uuid, err := json.FormatUUID(binary.Data)
+			So(err, ShouldBeNil)
+			output, err := json.Marshal(map[string]interface{}{key: uuid})
+			So(err, ShouldBeNil)
+			So(string(output), ShouldEqual, input)
+		})
+
+		Convey("fails for malformed UUIDs", func() {
+			jsonMap := map[string]interface{}{
+				key: map[string]interface{}{"$uuid": "not-a-uuid"},
+			}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid UUID")
+		})
+
+		Convey("fails for non-string $uuid values", func() {
+			jsonMap := map[string]interface{}{
+				key: map[string]interface{}{"$uuid": 12},
+			}
+
+			err := ConvertLegacyExtJSONDocumentToBSON(jsonMap)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/options"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+)
+
+// awsAuthMechanism is the authentication mechanism for AWS IAM credentials.
+const awsAuthMechanism = "MONGODB-AWS"
+
+// The standard AWS environment variables that credentials are read from.
+const (
+	awsAccessKeyIDEnv     = "AWS_ACCESS_KEY_ID"
+	awsSecretAccessKeyEnv = "AWS_SECRET_ACCESS_KEY"
+	awsSessionTokenEnv    = "AWS_SESSION_TOKEN"
+)
+
+// awsSessionTokenProperty is the auth mechanism property for the session token.
+const awsSessionTokenProperty = "AWS_SESSION_TOKEN"
+
+// isAWSAuth returns true if the MONGODB-AWS mechanism is used to authenticate.
+func isAWSAuth(mechanism string) bool {
+	return strings.EqualFold(mechanism, awsAuthMechanism)
+}
+
+// awsCredential fills in the access key ID, secret access key and session token
+// of a MONGODB-AWS credential. They are taken either from the options (the
+// username, the password and --awsSessionToken) or from the standard AWS
+// environment variables, but not from both. If neither has any credentials,
+// the credential is returned unchanged, leaving the driver to fetch them from
+// the ECS or EC2 metadata endpoints.
+func awsCredential(cred mopt.Credential, getenv func(string) string) (mopt.Credential, error) {
+	cred.AuthMechanism = awsAuthMechanism
+
+	token := cred.AuthMechanismProperties[awsSessionTokenProperty]
+	fromOptions := cred.Username != "" || cred.Password != ""
+	keyID, secretKey := getenv(awsAccessKeyIDEnv), getenv(awsSecretAccessKeyEnv)
+	fromEnv := keyID != "" || secretKey != ""
+
+	switch {
+	case fromOptions && fromEnv:
+		return cred, fmt.Errorf(
+			"AWS credentials were given both in the options and in the %v and %v "+
+				"environment variables; use only one of them",
+			awsAccessKeyIDEnv, awsSecretAccessKeyEnv,
+		)
+	case fromOptions:
+		if cred.Username == "" || cred.Password == "" {
+			return cred, fmt.Errorf(
+				"%v authentication requires both an access key ID (the username) "+
+					"and a secret access key (the password)",
+				awsAuthMechanism,
+			)
+		}
+	case fromEnv:
+		if token != "" {
+			return cred, fmt.Errorf(
+				"an AWS session token given in the options cannot be used with credentials "+
+					"from the %v and %v environment variables; set %v instead",
+				awsAccessKeyIDEnv, awsSecretAccessKeyEnv, awsSessionTokenEnv,
+			)
+		}
+		if keyID == "" || secretKey == "" {
+			return cred, fmt.Errorf(
+				"both %v and %v must be set to authenticate with %v",
+				awsAccessKeyIDEnv, awsSecretAccessKeyEnv, awsAuthMechanism,
+			)
+		}
+		cred.Username = keyID
+		cred.Password = secretKey
+		token = getenv(awsSessionTokenEnv)
+	case token != "":
+		return cred, errors.New(
+			"an AWS session token can only be used with an access key ID and a secret access key",
+		)
+	}
+
+	if token != "" {
+		props := map[string]string{awsSessionTokenProperty: token}
+		for k, v := range cred.AuthMechanismProperties {
+			if k != awsSessionTokenProperty {
+				props[k] = v
+			}
+		}
+		cred.AuthMechanismProperties = props
+	}
+	return cred, nil
+}
+
+// describeAWSAuthError adds a hint about where AWS credentials are looked for
+// to an authentication error, if the MONGODB-AWS mechanism is used and no
+// credentials were given. Other errors are returned unchanged.
+func describeAWSAuthError(err error, opts options.ToolOptions, getenv func(string) string) error {
+	var authErr *auth.Error
+	if opts.Auth == nil || !isAWSAuth(opts.Auth.Mechanism) || !errors.As(err, &authErr) {
+		return err
+	}
+	if opts.Auth.Username != "" || getenv(awsAccessKeyIDEnv) != "" {
+		return err
+	}
+	return fmt.Errorf(
+		"%v; no AWS credentials were found: give them with --username and --password, "+
+			"set the %v and %v environment variables, or run where they can be fetched "+
+			"from the ECS or EC2 metadata endpoints",
+		err, awsAccessKeyIDEnv, awsSecretAccessKeyEnv,
+	)
+}
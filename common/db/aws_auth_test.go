@@ -0,0 +1,122 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/options"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+)
+
+func fakeEnv(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestAWSCredential(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	envCreds := map[string]string{
+		awsAccessKeyIDEnv:     "envKey",
+		awsSecretAccessKeyEnv: "envSecret",
+	}
+
+	Convey("With MONGODB-AWS authentication", t, func() {
+		Convey("credentials from the options are kept", func() {
+			cred, err := awsCredential(mopt.Credential{
+				Username:                "key",
+				Password:                "secret",
+				AuthMechanismProperties: map[string]string{awsSessionTokenProperty: "token"},
+			}, fakeEnv(nil))
+			So(err, ShouldBeNil)
+			So(cred.Username, ShouldEqual, "key")
+			So(cred.Password, ShouldEqual, "secret")
+			So(cred.AuthMechanism, ShouldEqual, awsAuthMechanism)
+			So(cred.AuthMechanismProperties[awsSessionTokenProperty], ShouldEqual, "token")
+		})
+
+		Convey("credentials are read from the environment", func() {
+			env := map[string]string{awsSessionTokenEnv: "envToken"}
+			for k, v := range envCreds {
+				env[k] = v
+			}
+			cred, err := awsCredential(mopt.Credential{}, fakeEnv(env))
+			So(err, ShouldBeNil)
+			So(cred.Username, ShouldEqual, "envKey")
+			So(cred.Password, ShouldEqual, "envSecret")
+			So(cred.AuthMechanismProperties[awsSessionTokenProperty], ShouldEqual, "envToken")
+		})
+
+		Convey("no credentials are left to the driver", func() {
+			cred, err := awsCredential(mopt.Credential{}, fakeEnv(nil))
+			So(err, ShouldBeNil)
+			So(cred.Username, ShouldBeEmpty)
+			So(cred.AuthMechanismProperties, ShouldBeEmpty)
+		})
+
+		Convey("credentials cannot come from both the options and the environment", func() {
+			_, err := awsCredential(mopt.Credential{Username: "key", Password: "secret"},
+				fakeEnv(envCreds))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "use only one of them")
+		})
+
+		Convey("incomplete credentials are rejected", func() {
+			_, err := awsCredential(mopt.Credential{Password: "secret"}, fakeEnv(nil))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "access key ID")
+
+			_, err = awsCredential(mopt.Credential{},
+				fakeEnv(map[string]string{awsAccessKeyIDEnv: "envKey"}))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, awsSecretAccessKeyEnv)
+		})
+
+		Convey("a session token needs credentials from the same source", func() {
+			withToken := mopt.Credential{
+				AuthMechanismProperties: map[string]string{awsSessionTokenProperty: "token"},
+			}
+			_, err := awsCredential(withToken, fakeEnv(nil))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "session token")
+
+			_, err = awsCredential(withToken, fakeEnv(envCreds))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, awsSessionTokenEnv)
+		})
+	})
+}
+
+func TestDescribeAWSAuthError(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	authErr := fmt.Errorf("connection() error: %w", &auth.Error{})
+	opts := options.ToolOptions{Auth: &options.Auth{Mechanism: awsAuthMechanism}}
+
+	Convey("An authentication error says where AWS credentials are looked for", t, func() {
+		err := describeAWSAuthError(authErr, opts, fakeEnv(nil))
+		So(err.Error(), ShouldContainSubstring, "no AWS credentials were found")
+	})
+
+	Convey("Other errors are returned unchanged", t, func() {
+		other := errors.New("network error")
+		So(describeAWSAuthError(other, opts, fakeEnv(nil)), ShouldEqual, other)
+
+		withUser := options.ToolOptions{
+			Auth: &options.Auth{Mechanism: awsAuthMechanism, Username: "key"},
+		}
+		So(describeAWSAuthError(authErr, withUser, fakeEnv(nil)), ShouldEqual, authErr)
+
+		scram := options.ToolOptions{Auth: &options.Auth{Mechanism: "SCRAM-SHA-256"}}
+		So(describeAWSAuthError(authErr, scram, fakeEnv(nil)), ShouldEqual, authErr)
+	})
+}
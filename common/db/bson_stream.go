@@ -20,8 +20,18 @@ type BSONSource struct {
 	Stream      io.ReadCloser
 	err         error
 	MaxBSONSize int32
+
+	// streamEnd caches the size of a seekable Stream for SkipNext. It is
+	// streamEndUnknown before SkipNext is first called, and streamEndUnseekable
+	// if the Stream can't seek.
+	streamEnd int64
 }
 
+const (
+	streamEndUnknown    = -1
+	streamEndUnseekable = -2
+)
+
 // DecodedBSONSource reads documents from the underlying io.ReadCloser, Stream which
 // wraps a stream of BSON documents.
 type DecodedBSONSource struct {
@@ -38,12 +48,12 @@ type RawDocSource interface {
 
 // NewBSONSource creates a BSONSource with a reusable I/O buffer.
 func NewBSONSource(in io.ReadCloser) *BSONSource {
-	return &BSONSource{make([]byte, MaxBSONSize), in, nil, MaxBSONSize}
+	return &BSONSource{make([]byte, MaxBSONSize), in, nil, MaxBSONSize, streamEndUnknown}
 }
 
 // NewBufferlessBSONSource creates a BSONSource without a reusable I/O buffer.
 func NewBufferlessBSONSource(in io.ReadCloser) *BSONSource {
-	return &BSONSource{nil, in, nil, MaxBSONSize}
+	return &BSONSource{nil, in, nil, MaxBSONSize, streamEndUnknown}
 }
 
 // Close closes the BSONSource, rendering it unusable for I/O.
@@ -91,16 +101,50 @@ func (bs *BSONSource) LoadNext() []byte {
 	} else {
 		into = bs.reusableBuf
 	}
+	bsonSize := bs.readSize(into)
+	if bsonSize == 0 {
+		return nil
+	}
+
+	if int(bsonSize) > cap(into) {
+		bigInto := make([]byte, bsonSize)
+		copy(bigInto, into)
+		into = bigInto
+		if bs.reusableBuf != nil {
+			bs.reusableBuf = bigInto
+		}
+	}
+	into = into[:int(bsonSize)]
+	_, err := io.ReadAtLeast(bs.Stream, into[4:], int(bsonSize-4))
+	if err != nil {
+		if err != io.EOF {
+			bs.err = err
+			return nil
+		}
+		// this case means we hit EOF but read a partial document,
+		// so there's a broken doc in the stream. Treat this as error.
+		bs.err = fmt.Errorf("invalid bson: %v", err)
+		return nil
+	}
+
+	bs.err = nil
+	return into
+}
+
+// readSize reads the 4 byte size prefix of the next BSON document into
+// into[0:4] and returns the validated size. It returns 0 at the end of the
+// stream or if the prefix is invalid, setting bs.err in the latter case.
+func (bs *BSONSource) readSize(into []byte) int32 {
 	// read the bson object size (a 4 byte integer)
 	_, err := io.ReadAtLeast(bs.Stream, into[0:4], 4)
 	if err != nil {
 		if err != io.EOF {
 			bs.err = err
-			return nil
+			return 0
 		}
 		// we hit EOF right away, so we're at the end of the stream.
 		bs.err = nil
-		return nil
+		return 0
 	}
 
 	bsonSize := int32(
@@ -116,7 +160,7 @@ func (bs *BSONSource) LoadNext() []byte {
 	// Verify that we do not have an invalid BSON document with size < 5.
 	if bsonSize < 5 {
 		bs.err = fmt.Errorf("invalid BSONSize: %v bytes is less than 5 bytes", bsonSize)
-		return nil
+		return 0
 	}
 
 	if bsonSize > bs.MaxBSONSize {
@@ -125,32 +169,84 @@ func (bs *BSONSource) LoadNext() []byte {
 			bsonSize,
 			bs.MaxBSONSize,
 		)
-		return nil
+		return 0
 	}
+	return bsonSize
+}
 
-	if int(bsonSize) > cap(into) {
-		bigInto := make([]byte, bsonSize)
-		copy(bigInto, into)
-		into = bigInto
-		if bs.reusableBuf != nil {
-			bs.reusableBuf = bigInto
-		}
+// SkipNext advances past the next BSON document in the stream without reading
+// it into memory. Only the size prefix is read; the rest of the document is
+// skipped with Seek if the stream supports it, and read and discarded
+// otherwise. It returns the size of the skipped document, or 0 at the end of
+// the stream or if an error occurred, which is then reported by Err.
+func (bs *BSONSource) SkipNext() int32 {
+	var sizeBuf [4]byte
+	bsonSize := bs.readSize(sizeBuf[:])
+	if bsonSize == 0 {
+		return 0
 	}
-	into = into[:int(bsonSize)]
-	_, err = io.ReadAtLeast(bs.Stream, into[4:], int(bsonSize-4))
+
+	remaining := int64(bsonSize - 4)
+	seekable, err := bs.seekable()
+	switch {
+	case err != nil:
+		bs.err = err
+	case seekable:
+		bs.err = bs.seekPast(bs.Stream.(io.Seeker), remaining)
+	default:
+		_, bs.err = io.CopyN(io.Discard, bs.Stream, remaining)
+	}
+	if bs.err == io.EOF {
+		// this case means we hit EOF in the middle of a document,
+		// so there's a broken doc in the stream.
+		bs.err = fmt.Errorf("invalid bson: %v", io.ErrUnexpectedEOF)
+	}
+	if bs.err != nil {
+		return 0
+	}
+	return bsonSize
+}
+
+// seekable returns whether the Stream can seek, finding and caching its size
+// the first time it is called. Streams such as pipes implement io.Seeker but
+// fail to seek; they are treated as not seekable.
+func (bs *BSONSource) seekable() (bool, error) {
+	if bs.streamEnd != streamEndUnknown {
+		return bs.streamEnd != streamEndUnseekable, nil
+	}
+
+	seeker, ok := bs.Stream.(io.Seeker)
+	if !ok {
+		bs.streamEnd = streamEndUnseekable
+		return false, nil
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
 	if err != nil {
-		if err != io.EOF {
-			bs.err = err
-			return nil
-		}
-		// this case means we hit EOF but read a partial document,
-		// so there's a broken doc in the stream. Treat this as error.
-		bs.err = fmt.Errorf("invalid bson: %v", err)
-		return nil
+		bs.streamEnd = streamEndUnseekable
+		return false, nil
 	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	if _, err = seeker.Seek(current, io.SeekStart); err != nil {
+		return false, err
+	}
+	bs.streamEnd = end
+	return true, nil
+}
 
-	bs.err = nil
-	return into
+// seekPast moves the seeker forward by n bytes, returning io.EOF if that would
+// move past the end of the stream.
+func (bs *BSONSource) seekPast(seeker io.Seeker, n int64) error {
+	pos, err := seeker.Seek(n, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if pos > bs.streamEnd {
+		return io.EOF
+	}
+	return nil
 }
 
 func (bs *BSONSource) Err() error {
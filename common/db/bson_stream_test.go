@@ -9,6 +9,7 @@ package db
 import (
 	"bytes"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -50,3 +51,36 @@ func TestBufferlessBSONSource(t *testing.T) {
 		})
 	})
 }
+
+func TestSkipNextOnPipe(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("with a pipe containing several bson documents", t, func() {
+		var input []byte
+		for i := 0; i < 3; i++ {
+			doc, err := bson.Marshal(bson.D{{"i", int32(i)}})
+			So(err, ShouldBeNil)
+			input = append(input, doc...)
+		}
+		r, w, err := os.Pipe()
+		So(err, ShouldBeNil)
+		go func() {
+			_, _ = w.Write(input)
+			_ = w.Close()
+		}()
+		bsonSource := NewBSONSource(r)
+		defer bsonSource.Close()
+
+		Convey("documents can be skipped by reading them", func() {
+			So(bsonSource.SkipNext(), ShouldEqual, len(input)/3)
+			So(bsonSource.SkipNext(), ShouldEqual, len(input)/3)
+			So(bsonSource.Err(), ShouldBeNil)
+
+			var doc bson.D
+			So(NewDecodedBSONSource(bsonSource).Next(&doc), ShouldBeTrue)
+			So(doc, ShouldResemble, bson.D{{"i", int32(2)}})
+			So(bsonSource.SkipNext(), ShouldEqual, 0)
+			So(bsonSource.Err(), ShouldBeNil)
+		})
+	})
+}
@@ -9,6 +9,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -32,6 +33,15 @@ type BufferedBulkInserter struct {
 	byteLimit     int
 	bulkWriteOpts *options.BulkWriteOptions
 	upsert        bool
+	inFlight      *int64
+
+	// continueOnError makes ordered bulk writes resume after ignorable write errors
+	continueOnError bool
+
+	// tags holds the tag of each buffered write model, see TagNext
+	tags         []interface{}
+	nextTag      interface{}
+	onWriteError func(tag interface{}, writeErr mongo.BulkWriteError)
 }
 
 func newBufferedBulkInserter(
@@ -73,6 +83,15 @@ func (bb *BufferedBulkInserter) SetOrdered(ordered bool) *BufferedBulkInserter {
 	return bb
 }
 
+// SetContinueOnError sets whether an ordered bulk write that fails with an
+// ignorable write error, such as a duplicate key, goes on with the write models
+// after the one that failed. The models are still written in order. Unordered
+// bulk writes always attempt every write model, so they are not affected.
+func (bb *BufferedBulkInserter) SetContinueOnError(continueOnError bool) *BufferedBulkInserter {
+	bb.continueOnError = continueOnError
+	return bb
+}
+
 func (bb *BufferedBulkInserter) SetBypassDocumentValidation(bypass bool) *BufferedBulkInserter {
 	bb.bulkWriteOpts.SetBypassDocumentValidation(bypass)
 	return bb
@@ -86,6 +105,7 @@ func (bb *BufferedBulkInserter) SetUpsert(upsert bool) *BufferedBulkInserter {
 // throw away the old bulk and init a new one.
 func (bb *BufferedBulkInserter) ResetBulk() {
 	bb.writeModels = bb.writeModels[:0]
+	bb.tags = bb.tags[:0]
 	bb.docCount = 0
 	bb.byteCount = 0
 }
@@ -108,9 +128,8 @@ func (bb *BufferedBulkInserter) Update(selector, update bson.D) (*mongo.BulkWrit
 	if err != nil {
 		return nil, err
 	}
-	bb.byteCount += len(rawBytes)
-
 	return bb.addModel(
+		len(rawBytes),
 		mongo.NewUpdateOneModel().SetFilter(selector).SetUpdate(rawBytes).SetUpsert(bb.upsert),
 	)
 }
@@ -124,9 +143,8 @@ func (bb *BufferedBulkInserter) Replace(
 	if err != nil {
 		return nil, err
 	}
-	bb.byteCount += len(rawBytes)
-
 	return bb.addModel(
+		len(rawBytes),
 		mongo.NewReplaceOneModel().
 			SetFilter(selector).
 			SetReplacement(rawBytes).
@@ -137,9 +155,7 @@ func (bb *BufferedBulkInserter) Replace(
 // InsertRaw adds a document, represented as raw bson bytes, to the buffer for bulk insertion. If the buffer becomes full,
 // the bulk write is performed, returning any error that occurs.
 func (bb *BufferedBulkInserter) InsertRaw(rawBytes []byte) (*mongo.BulkWriteResult, error) {
-	bb.byteCount += len(rawBytes)
-
-	return bb.addModel(mongo.NewInsertOneModel().SetDocument(rawBytes))
+	return bb.addModel(len(rawBytes), mongo.NewInsertOneModel().SetDocument(rawBytes))
 }
 
 // Delete adds a document to the buffer for bulk removal. If the buffer becomes full, the bulk delete is performed, returning
@@ -147,20 +163,98 @@ func (bb *BufferedBulkInserter) InsertRaw(rawBytes []byte) (*mongo.BulkWriteResu
 func (bb *BufferedBulkInserter) Delete(
 	selector, replacement bson.D,
 ) (*mongo.BulkWriteResult, error) {
-	return bb.addModel(mongo.NewDeleteOneModel().SetFilter(selector))
+	return bb.addModel(0, mongo.NewDeleteOneModel().SetFilter(selector))
 }
 
-// addModel adds a WriteModel to the buffer. If the buffer becomes full, the bulk write is performed, returning any error
-// that occurs.
-func (bb *BufferedBulkInserter) addModel(model mongo.WriteModel) (*mongo.BulkWriteResult, error) {
+// fits reports whether a document of the given size can be added to the
+// buffer without taking it over the byte limit. An empty buffer fits any
+// document, so that one larger than the limit is sent on its own.
+func (bb *BufferedBulkInserter) fits(size int) bool {
+	return bb.docCount == 0 || bb.byteCount+size <= bb.byteLimit
+}
+
+// addModel adds a WriteModel of the given size in bytes to the buffer. The buffered documents are written first if the
+// new one would take them over the byte limit, and the buffer is written once it becomes full. It returns the combined
+// result and any error of those bulk writes.
+func (bb *BufferedBulkInserter) addModel(
+	size int,
+	model mongo.WriteModel,
+) (*mongo.BulkWriteResult, error) {
+	var result *mongo.BulkWriteResult
+	var err error
+	var flushed int
+	if !bb.fits(size) {
+		flushed = bb.docCount
+		result, err = bb.Flush()
+	}
+
 	bb.docCount++
+	bb.byteCount += size
 	bb.writeModels = append(bb.writeModels, model)
+	bb.tags = append(bb.tags, bb.nextTag)
+	bb.nextTag = nil
+
+	// the new model stays buffered if writing the earlier ones failed
+	if err != nil {
+		return result, err
+	}
 
 	if bb.docCount >= bb.docLimit || bb.byteCount >= bb.byteLimit {
-		return bb.Flush()
+		flushResult, err := bb.Flush()
+		return combineBulkWriteResults(result, flushResult, flushed), err
+	}
+
+	return result, nil
+}
+
+// combineBulkWriteResults adds up the counts of two consecutive bulk write
+// results, either of which may be nil. aModels is the number of write models
+// in the first bulk write, which offsets the indexes of b's upserted IDs.
+func combineBulkWriteResults(a, b *mongo.BulkWriteResult, aModels int) *mongo.BulkWriteResult {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
 	}
+	combined := &mongo.BulkWriteResult{
+		InsertedCount: a.InsertedCount + b.InsertedCount,
+		MatchedCount:  a.MatchedCount + b.MatchedCount,
+		ModifiedCount: a.ModifiedCount + b.ModifiedCount,
+		DeletedCount:  a.DeletedCount + b.DeletedCount,
+		UpsertedCount: a.UpsertedCount + b.UpsertedCount,
+		UpsertedIDs:   map[int64]interface{}{},
+	}
+	for index, id := range a.UpsertedIDs {
+		combined.UpsertedIDs[index] = id
+	}
+	for index, id := range b.UpsertedIDs {
+		combined.UpsertedIDs[index+int64(aModels)] = id
+	}
+	return combined
+}
+
+// TagNext sets a value to keep with the next write model added to the buffer,
+// such as where the document came from. It is passed to the write error
+// handler if that write fails.
+func (bb *BufferedBulkInserter) TagNext(tag interface{}) {
+	bb.nextTag = tag
+}
 
-	return nil, nil
+// SetWriteErrorHandler sets a function that is called with each write error of
+// the bulk writes, along with the tag of the write model that failed.
+func (bb *BufferedBulkInserter) SetWriteErrorHandler(
+	handler func(tag interface{}, writeErr mongo.BulkWriteError),
+) *BufferedBulkInserter {
+	bb.onWriteError = handler
+	return bb
+}
+
+// SetInFlightCounter sets a counter that is atomically incremented while each
+// bulk write is in progress. The counter may be shared between inserters.
+func (bb *BufferedBulkInserter) SetInFlightCounter(counter *int64) *BufferedBulkInserter {
+	bb.inFlight = counter
+	return bb
 }
 
 // Flush writes all buffered documents in one bulk write and then resets the buffer.
@@ -179,5 +273,64 @@ func (bb *BufferedBulkInserter) flush() (*mongo.BulkWriteResult, error) {
 		return nil, nil
 	}
 
-	return bb.collection.BulkWrite(context.Background(), bb.writeModels, bb.bulkWriteOpts)
+	if bb.inFlight != nil {
+		atomic.AddInt64(bb.inFlight, 1)
+		defer atomic.AddInt64(bb.inFlight, -1)
+	}
+
+	var result *mongo.BulkWriteResult
+	var writeErrors []mongo.BulkWriteError
+	// offset is the index of the first write model of the current bulk write
+	offset := 0
+	for {
+		batchResult, err := bb.collection.BulkWrite(
+			context.Background(),
+			bb.writeModels[offset:],
+			bb.bulkWriteOpts,
+		)
+		result = combineBulkWriteResults(result, batchResult, offset)
+
+		bwe, ok := err.(mongo.BulkWriteException)
+		if !ok {
+			if err == nil && len(writeErrors) > 0 {
+				err = mongo.BulkWriteException{WriteErrors: writeErrors}
+			}
+			return result, err
+		}
+
+		for i := range bwe.WriteErrors {
+			bwe.WriteErrors[i].Index += offset
+			writeErr := bwe.WriteErrors[i]
+			if bb.onWriteError != nil {
+				var tag interface{}
+				if writeErr.Index >= 0 && writeErr.Index < len(bb.tags) {
+					tag = bb.tags[writeErr.Index]
+				}
+				bb.onWriteError(tag, writeErr)
+			}
+		}
+		writeErrors = append(writeErrors, bwe.WriteErrors...)
+
+		next, ok := bb.resumeIndex(bwe)
+		if !ok {
+			bwe.WriteErrors = writeErrors
+			return result, bwe
+		}
+		offset = next
+	}
+}
+
+// resumeIndex returns the index of the write model to resume an ordered bulk
+// write from after it failed with bwe, whose write error indexes are relative
+// to the whole buffer. It returns false if the bulk write should not resume.
+func (bb *BufferedBulkInserter) resumeIndex(bwe mongo.BulkWriteException) (int, bool) {
+	ordered := bb.bulkWriteOpts.Ordered == nil || *bb.bulkWriteOpts.Ordered
+	if !bb.continueOnError || !ordered || len(bwe.WriteErrors) == 0 {
+		return 0, false
+	}
+	if bwe.WriteConcernError != nil || !CanIgnoreError(bwe) {
+		return 0, false
+	}
+	next := bwe.WriteErrors[len(bwe.WriteErrors)-1].Index + 1
+	return next, next < len(bb.writeModels)
 }
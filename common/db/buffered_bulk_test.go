@@ -8,12 +8,15 @@ package db
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func TestBufferedBulkInserterInserts(t *testing.T) {
@@ -154,6 +157,145 @@ func TestBufferedBulkInserterInserts(t *testing.T) {
 			})
 		})
 
+		Convey("using a test collection, a byte limit of 1000 and mixed document sizes", func() {
+			testCol := session.Database("tools-test").Collection("bulk5")
+			bufBulk = NewUnorderedBufferedBulkInserter(testCol, 1000)
+			So(bufBulk, ShouldNotBeNil)
+			bufBulk.byteLimit = 1000
+			tiny := bson.D{{"foo", "bar"}}
+			medium := bson.D{{"foo", strings.Repeat("m", 600)}}
+			huge := bson.D{{"foo", strings.Repeat("h", 2000)}}
+
+			Convey("tiny documents are buffered until one that doesn't fit", func() {
+				for i := 0; i < 3; i++ {
+					result, err := bufBulk.Insert(tiny)
+					So(err, ShouldBeNil)
+					So(result, ShouldBeNil)
+				}
+				result, err := bufBulk.Insert(medium)
+				So(err, ShouldBeNil)
+				So(result, ShouldBeNil)
+				So(bufBulk.docCount, ShouldEqual, 4)
+
+				result, err = bufBulk.Insert(medium)
+				So(err, ShouldBeNil)
+				So(result, ShouldNotBeNil)
+				So(result.InsertedCount, ShouldEqual, 4)
+				So(bufBulk.docCount, ShouldEqual, 1)
+			})
+
+			Convey("a document over the limit is sent alone", func() {
+				for i := 0; i < 3; i++ {
+					_, err := bufBulk.Insert(tiny)
+					So(err, ShouldBeNil)
+				}
+				result, err := bufBulk.Insert(huge)
+				So(err, ShouldBeNil)
+				So(result, ShouldNotBeNil)
+				So(result.InsertedCount, ShouldEqual, 4)
+				So(bufBulk.docCount, ShouldEqual, 0)
+
+				result, err = bufBulk.Insert(huge)
+				So(err, ShouldBeNil)
+				So(result, ShouldNotBeNil)
+				So(result.InsertedCount, ShouldEqual, 1)
+
+				_, err = bufBulk.Insert(tiny)
+				So(err, ShouldBeNil)
+				result, err = bufBulk.Flush()
+				So(err, ShouldBeNil)
+				So(result.InsertedCount, ShouldEqual, 1)
+
+				count, err := testCol.CountDocuments(context.Background(), bson.D{})
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 6)
+			})
+		})
+
+		Convey("using a test collection with a write error handler", func() {
+			testCol := session.Database("tools-test").Collection("bulk5")
+			var failed []interface{}
+			bufBulk = NewUnorderedBufferedBulkInserter(testCol, 3).
+				SetWriteErrorHandler(func(tag interface{}, writeErr mongo.BulkWriteError) {
+					So(writeErr.Code, ShouldEqual, ErrDuplicateKeyCode)
+					failed = append(failed, tag)
+				})
+
+			Convey("the tags of failed writes are passed to the handler", func() {
+				for i, id := range []int{1, 2, 1, 3, 2} {
+					bufBulk.TagNext(i)
+					_, err := bufBulk.Insert(bson.D{{"_id", id}})
+					if i == 2 {
+						So(err, ShouldNotBeNil)
+					}
+				}
+				_, err := bufBulk.Flush()
+				So(err, ShouldNotBeNil)
+				So(failed, ShouldResemble, []interface{}{2, 4})
+
+				count, err := testCol.CountDocuments(context.Background(), bson.D{})
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 3)
+			})
+		})
+
+		Convey("using a test collection with ordered writes that continue on error", func() {
+			testCol := session.Database("tools-test").Collection("bulk6")
+			var failed []interface{}
+			bufBulk = NewOrderedBufferedBulkInserter(testCol, 10).
+				SetContinueOnError(true).
+				SetWriteErrorHandler(func(tag interface{}, writeErr mongo.BulkWriteError) {
+					failed = append(failed, tag)
+				})
+
+			Convey("documents after duplicate keys are inserted in order", func() {
+				for i, id := range []int{1, 2, 1, 3, 2, 4} {
+					bufBulk.TagNext(i)
+					_, err := bufBulk.Insert(bson.D{{"_id", id}})
+					So(err, ShouldBeNil)
+				}
+				result, err := bufBulk.Flush()
+				So(err, ShouldNotBeNil)
+				So(CanIgnoreError(err), ShouldBeTrue)
+				So(result.InsertedCount, ShouldEqual, 4)
+				So(failed, ShouldResemble, []interface{}{2, 4})
+
+				bwe, ok := err.(mongo.BulkWriteException)
+				So(ok, ShouldBeTrue)
+				So(bwe.WriteErrors, ShouldHaveLength, 2)
+				So(bwe.WriteErrors[0].Index, ShouldEqual, 2)
+				So(bwe.WriteErrors[1].Index, ShouldEqual, 4)
+
+				cursor, err := testCol.Find(
+					context.Background(),
+					bson.D{},
+					mopt.Find().SetSort(bson.D{{"$natural", 1}}),
+				)
+				So(err, ShouldBeNil)
+				var docs []bson.D
+				So(cursor.All(context.Background(), &docs), ShouldBeNil)
+				So(docs, ShouldResemble, []bson.D{
+					{{"_id", int32(1)}},
+					{{"_id", int32(2)}},
+					{{"_id", int32(3)}},
+					{{"_id", int32(4)}},
+				})
+			})
+
+			Convey("without it an ordered write stops at the first duplicate key", func() {
+				bufBulk.SetContinueOnError(false)
+				for i, id := range []int{1, 2, 1, 3} {
+					bufBulk.TagNext(i)
+					_, err := bufBulk.Insert(bson.D{{"_id", id}})
+					So(err, ShouldBeNil)
+				}
+				result, err := bufBulk.Flush()
+				So(err, ShouldNotBeNil)
+				So(result.InsertedCount, ShouldEqual, 2)
+				So(failed, ShouldResemble, []interface{}{2})
+			})
+		})
+
 		Reset(func() {
 			So(provider.DropDatabase("tools-test"), ShouldBeNil)
 			provider.Close()
@@ -161,3 +303,54 @@ func TestBufferedBulkInserterInserts(t *testing.T) {
 	})
 
 }
+
+func TestBufferedBulkInserterFits(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a BufferedBulkInserter with a byte limit of 100", t, func() {
+		bufBulk := NewUnorderedBufferedBulkInserter(nil, 1000)
+		bufBulk.byteLimit = 100
+
+		Convey("an empty buffer fits any document", func() {
+			So(bufBulk.fits(10), ShouldBeTrue)
+			So(bufBulk.fits(1000), ShouldBeTrue)
+		})
+
+		Convey("a partly full buffer only fits documents up to the limit", func() {
+			bufBulk.docCount = 3
+			bufBulk.byteCount = 60
+			So(bufBulk.fits(40), ShouldBeTrue)
+			So(bufBulk.fits(41), ShouldBeFalse)
+			So(bufBulk.fits(1000), ShouldBeFalse)
+		})
+	})
+}
+
+func TestCombineBulkWriteResults(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Combining bulk write results", t, func() {
+		a := &mongo.BulkWriteResult{
+			InsertedCount: 2,
+			UpsertedCount: 1,
+			UpsertedIDs:   map[int64]interface{}{2: "x"},
+		}
+		b := &mongo.BulkWriteResult{
+			InsertedCount: 1,
+			UpsertedCount: 1,
+			UpsertedIDs:   map[int64]interface{}{0: "y"},
+		}
+
+		Convey("adds up the counts and offsets the second result's upserted IDs", func() {
+			combined := combineBulkWriteResults(a, b, 3)
+			So(combined.InsertedCount, ShouldEqual, 3)
+			So(combined.UpsertedCount, ShouldEqual, 2)
+			So(combined.UpsertedIDs, ShouldResemble, map[int64]interface{}{2: "x", 3: "y"})
+		})
+
+		Convey("returns the other result when one is nil", func() {
+			So(combineBulkWriteResults(nil, b, 0), ShouldEqual, b)
+			So(combineBulkWriteResults(a, nil, 3), ShouldEqual, a)
+		})
+	})
+}
@@ -9,10 +9,12 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	mopt "go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -61,15 +63,24 @@ type CommandRunner interface {
 // }
 //
 // Run issues the provided command on the db database and unmarshals its result
-// into out.
+// into out. Read-only commands are retried on transient errors for up to the
+// --retryTimeout; others are sent once, since they may have been applied.
 
 func (sp *SessionProvider) Run(command interface{}, out interface{}, name string) error {
 	db := sp.DB(name)
-	result := db.RunCommand(context.Background(), command)
-	if result.Err() != nil {
+	var retryTimeout time.Duration
+	if isRetryableCommand(command) {
+		retryTimeout = sp.retryTimeout
+	}
+	var result *mongo.SingleResult
+	err := RetryOnTransientError(retryTimeout, func() error {
+		result = db.RunCommand(context.Background(), command)
 		return result.Err()
+	})
+	if err != nil {
+		return err
 	}
-	err := result.Decode(out)
+	err = result.Decode(out)
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -25,12 +26,17 @@ import (
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/youmark/pkcs8"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
 	mopt "go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/tag"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
 type (
@@ -79,6 +85,9 @@ type SessionProvider struct {
 
 	// the master client used for operations
 	client *mongo.Client
+
+	// how long to keep retrying commands that fail with transient errors
+	retryTimeout time.Duration
 }
 
 // Returns a mongo.Client connected to the database server for which the
@@ -119,13 +128,22 @@ func NewSessionProvider(opts options.ToolOptions) (*SessionProvider, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = client.Ping(context.Background(), nil)
+
+	var retryTimeout time.Duration
+	if opts.Connection != nil {
+		retryTimeout = time.Duration(opts.Connection.RetryTimeout) * time.Second
+	}
+	err = RetryOnTransientError(retryTimeout, func() error {
+		return client.Ping(context.Background(), nil)
+	})
 	if err != nil {
+		err = describeServerSelectionError(err, serverSelectionTimeout(opts), readPrefMode(opts))
+		err = describeAWSAuthError(err, opts, os.Getenv)
 		return nil, fmt.Errorf("failed to connect to %s: %v", opts.URI.ParsedConnString(), err)
 	}
 
 	// create the provider
-	return &SessionProvider{client: client}, nil
+	return &SessionProvider{client: client, retryTimeout: retryTimeout}, nil
 }
 
 // addClientCertFromFile adds a client certificate to the configuration given a path to the
@@ -286,6 +304,46 @@ func addCACertsFromFile(cfg *tls.Config, file string) error {
 	return nil
 }
 
+// addCACertsFromDir adds every PEM certificate found directly in dir to the
+// root pool. Files that don't contain a PEM certificate are skipped.
+func addCACertsFromDir(cfg *tls.Config, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	if cfg.RootCAs == nil {
+		cfg.RootCAs = x509.NewCertPool()
+	}
+
+	log.Logvf(log.Info, "loading trusted server certificates from directory `%v`", dir)
+	loaded := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if !cfg.RootCAs.AppendCertsFromPEM(data) {
+			log.Logvf(log.DebugLow, "skipping `%v`: no PEM certificates found", file)
+			continue
+		}
+		loaded++
+	}
+
+	if loaded == 0 {
+		return fmt.Errorf(
+			"SSL trusted server certificates directory does not contain any valid certificates. Directory: `%v`",
+			dir,
+		)
+	}
+	log.Logvf(log.DebugLow, "loaded certificates from %v file(s) in `%v`", loaded, dir)
+	return nil
+}
+
 // configure the client according to the options set in the uri and in the provided ToolOptions, with ToolOptions having precedence.
 func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 	if opts.URI == nil || opts.URI.ConnectionString == "" {
@@ -310,9 +368,7 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 	clientopt.SetConnectTimeout(time.Duration(opts.Timeout) * time.Second)
 	clientopt.SetSocketTimeout(time.Duration(opts.SocketTimeout) * time.Second)
 	if opts.Connection.ServerSelectionTimeout > 0 {
-		clientopt.SetServerSelectionTimeout(
-			time.Duration(opts.Connection.ServerSelectionTimeout) * time.Second,
-		)
+		clientopt.SetServerSelectionTimeout(serverSelectionTimeout(opts))
 	}
 	if opts.ReplicaSetName != "" {
 		clientopt.SetReplicaSet(opts.ReplicaSetName)
@@ -330,6 +386,8 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 	}
 	if opts.WriteConcern != nil {
 		clientopt.SetWriteConcern(opts.WriteConcern)
+	} else if cs.JSet || cs.WString != "" || cs.WNumberSet || cs.WTimeoutSet {
+		clientopt.SetWriteConcern(writeConcernFromConnString(cs))
 	} else {
 		// If no write concern was specified, default to majority
 		clientopt.SetWriteConcern(writeconcern.Majority())
@@ -337,6 +395,7 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 
 	if opts.Compressors != "" && opts.Compressors != "none" {
 		clientopt.SetCompressors(strings.Split(opts.Compressors, ","))
+		clientopt.SetServerMonitor(newCompressionMonitor())
 	}
 
 	if cs.ZlibLevelSet {
@@ -404,26 +463,6 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 		clientopt.SetRetryReads(cs.RetryReads)
 	}
 
-	if cs.JSet || cs.WString != "" || cs.WNumberSet || cs.WTimeoutSet {
-		opts := make([]writeconcern.Option, 0, 1)
-
-		if len(cs.WString) > 0 {
-			opts = append(opts, writeconcern.WTagSet(cs.WString))
-		} else if cs.WNumberSet {
-			opts = append(opts, writeconcern.W(cs.WNumber))
-		}
-
-		if cs.JSet {
-			opts = append(opts, writeconcern.J(cs.J))
-		}
-
-		if cs.WTimeoutSet {
-			opts = append(opts, writeconcern.WTimeout(cs.WTimeout))
-		}
-
-		clientopt.SetWriteConcern(writeconcern.New(opts...))
-	}
-
 	if opts.Auth != nil && opts.Auth.IsSet() {
 		cred := mopt.Credential{
 			Username:      opts.Auth.Username,
@@ -431,12 +470,16 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 			AuthSource:    opts.GetAuthenticationDatabase(),
 			AuthMechanism: opts.Auth.Mechanism,
 		}
-		if cs.AuthMechanism == "MONGODB-AWS" {
+		if isAWSAuth(cs.AuthMechanism) {
 			cred.Username = cs.Username
 			cred.Password = cs.Password
 			cred.AuthSource = cs.AuthSource
-			cred.AuthMechanism = cs.AuthMechanism
 			cred.AuthMechanismProperties = cs.AuthMechanismProperties
+			var err error
+			cred, err = awsCredential(cred, os.Getenv)
+			if err != nil {
+				return nil, err
+			}
 		}
 		// Technically, an empty password is possible, but the tools don't have the
 		// means to easily distinguish and so require a non-empty password.
@@ -448,7 +491,9 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 			if opts.Kerberos.Service != "" {
 				props["SERVICE_NAME"] = opts.Kerberos.Service
 			}
-			// XXX How do we use opts.Kerberos.ServiceHost if at all?
+			if opts.Kerberos.ServiceHost != "" {
+				props["SERVICE_HOST"] = opts.Kerberos.ServiceHost
+			}
 			cred.AuthMechanismProperties = props
 		}
 		clientopt.SetAuth(cred)
@@ -495,6 +540,11 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 				return nil, fmt.Errorf("error configuring client, can't load CA file: %v", err)
 			}
 		}
+		if opts.SSLCAPath != "" {
+			if err := addCACertsFromDir(tlsConfig, opts.SSLCAPath); err != nil {
+				return nil, fmt.Errorf("error configuring client, can't load CA path: %v", err)
+			}
+		}
 
 		// If a username wasn't specified for x509, add one from the certificate.
 		if clientopt.Auth != nil &&
@@ -514,6 +564,119 @@ func configureClient(opts options.ToolOptions) (*mongo.Client, error) {
 	return mongo.NewClient(clientopt)
 }
 
+// newCompressionMonitor returns a server monitor that logs, once per server,
+// which compressor was negotiated with it. Servers that support none of the
+// requested compressors are communicated with uncompressed.
+func newCompressionMonitor() *event.ServerMonitor {
+	var mutex sync.Mutex
+	logged := map[address.Address]bool{}
+	return &event.ServerMonitor{
+		ServerDescriptionChanged: func(e *event.ServerDescriptionChangedEvent) {
+			if e.NewDescription.Kind == description.Unknown {
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if logged[e.Address] {
+				return
+			}
+			logged[e.Address] = true
+			log.Logv(log.Info, compressionMessage(e.Address, e.NewDescription.Compression))
+		},
+	}
+}
+
+// compressionMessage describes the outcome of compression negotiation with a
+// server, given the compressors it agreed to.
+func compressionMessage(addr address.Address, compression []string) string {
+	if len(compression) == 0 {
+		return fmt.Sprintf(
+			"server %v supports none of the requested compressors, communicating uncompressed",
+			addr,
+		)
+	}
+	return fmt.Sprintf("negotiated %v compression with server %v", compression[0], addr)
+}
+
+// writeConcernFromConnString returns the write concern given in the URI, for
+// tools that don't build one with NewMongoWriteConcern. A write concern built
+// from --writeConcern and the URI is used as is instead, so that none of its
+// options are replaced.
+func writeConcernFromConnString(cs *connstring.ConnString) *writeconcern.WriteConcern {
+	opts := make([]writeconcern.Option, 0, 1)
+
+	if len(cs.WString) > 0 {
+		opts = append(opts, writeconcern.WTagSet(cs.WString))
+	} else if cs.WNumberSet {
+		opts = append(opts, writeconcern.W(cs.WNumber))
+	}
+
+	if cs.JSet {
+		opts = append(opts, writeconcern.J(cs.J))
+	}
+
+	if cs.WTimeoutSet {
+		opts = append(opts, writeconcern.WTimeout(cs.WTimeout))
+	}
+
+	return writeconcern.New(opts...)
+}
+
+// defaultServerSelectionTimeout is the driver's server selection timeout, used
+// when --serverSelectionTimeout isn't set.
+const defaultServerSelectionTimeout = 30 * time.Second
+
+// serverSelectionTimeout returns how long the driver waits for a suitable
+// server before failing an operation.
+func serverSelectionTimeout(opts options.ToolOptions) time.Duration {
+	if opts.Connection != nil && opts.Connection.ServerSelectionTimeout > 0 {
+		return time.Duration(opts.Connection.ServerSelectionTimeout) * time.Second
+	}
+	return defaultServerSelectionTimeout
+}
+
+// readPrefMode returns the name of the read preference servers are selected
+// with.
+func readPrefMode(opts options.ToolOptions) string {
+	if opts.ReadPreference != nil {
+		return opts.ReadPreference.Mode().String()
+	}
+	if opts.URI != nil {
+		if cs := opts.URI.ParsedConnString(); cs != nil && cs.ReadPreference != "" {
+			return cs.ReadPreference
+		}
+	}
+	return readpref.PrimaryMode.String()
+}
+
+// describeServerSelectionError rewrites a server selection error to say that
+// no suitable server was found, followed by the state of each server the
+// driver knew about when it gave up. Other errors are returned unchanged.
+func describeServerSelectionError(err error, timeout time.Duration, readPref string) error {
+	var selectionErr topology.ServerSelectionError
+	if !errors.As(err, &selectionErr) {
+		return err
+	}
+
+	var servers []string
+	for _, server := range selectionErr.Desc.Servers {
+		state := server.Kind.String()
+		if server.LastError != nil {
+			state += ": " + server.LastError.Error()
+		}
+		servers = append(servers, fmt.Sprintf("%v (%v)", server.Addr, state))
+	}
+	known := "none"
+	if len(servers) > 0 {
+		known = strings.Join(servers, ", ")
+	}
+	return fmt.Errorf(
+		"no suitable server found within %v for read preference '%v'; "+
+			"topology type %v, known servers: %v",
+		timeout, readPref, selectionErr.Desc.Kind, known,
+	)
+}
+
 // FilterError determines whether an error needs to be propagated back to the user or can be continued through. If an
 // error cannot be ignored, a non-nil error is returned. If an error can be continued through, it is logged and nil is
 // returned.
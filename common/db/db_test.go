@@ -8,14 +8,21 @@ package db
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/description"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
 // var block and functions copied from testutil to avoid import cycle.
@@ -452,3 +459,140 @@ func TestConfigureClientMultipleHosts(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 }
+
+func TestAddCACertsFromDir(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a directory of CA certificates", t, func() {
+		dir := t.TempDir()
+		copyFile := func(src, name string) {
+			data, err := os.ReadFile(src)
+			So(err, ShouldBeNil)
+			So(os.WriteFile(filepath.Join(dir, name), data, 0o644), ShouldBeNil)
+		}
+
+		Convey("every PEM file is added and other files are skipped", func() {
+			copyFile("testdata/ca.pem", "ca.pem")
+			copyFile("testdata/ia.pem", "ia.pem")
+			So(os.WriteFile(filepath.Join(dir, "README"), []byte("not a cert"), 0o644), ShouldBeNil)
+			So(os.Mkdir(filepath.Join(dir, "nested"), 0o755), ShouldBeNil)
+
+			cfg := &tls.Config{}
+			So(addCACertsFromDir(cfg, dir), ShouldBeNil)
+			So(cfg.RootCAs.Subjects(), ShouldHaveLength, 2)
+		})
+
+		Convey("certificates are added alongside those from a CA file", func() {
+			copyFile("testdata/ia.pem", "ia.pem")
+
+			cfg := &tls.Config{}
+			So(addCACertsFromFile(cfg, "testdata/ca.pem"), ShouldBeNil)
+			So(addCACertsFromDir(cfg, dir), ShouldBeNil)
+			So(cfg.RootCAs.Subjects(), ShouldHaveLength, 2)
+		})
+
+		Convey("a directory without certificates is an error", func() {
+			So(os.WriteFile(filepath.Join(dir, "README"), []byte("not a cert"), 0o644), ShouldBeNil)
+
+			err := addCACertsFromDir(&tls.Config{}, dir)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "does not contain any valid certificates")
+		})
+
+		Convey("a missing directory is an error", func() {
+			So(addCACertsFromDir(&tls.Config{}, filepath.Join(dir, "missing")), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestConfigureClientCompressors(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With compressors set", t, func() {
+		enabled := options.EnabledOptions{Connection: true, URI: true}
+		toolOptions := options.New("test", "", "", "", true, enabled)
+		_, err := toolOptions.ParseArgs([]string{"--compressors", "snappy,zstd"})
+		So(err, ShouldBeNil)
+
+		Convey("they are passed to the client with a monitor that logs the negotiation", func() {
+			client, err := configureClient(*toolOptions)
+			So(err, ShouldBeNil)
+			So(client, ShouldNotBeNil)
+		})
+
+		Convey("the negotiated compressor is the first the server agreed to", func() {
+			So(
+				compressionMessage("foo:27017", []string{"zstd", "snappy"}),
+				ShouldEqual,
+				"negotiated zstd compression with server foo:27017",
+			)
+			So(
+				compressionMessage("foo:27017", nil),
+				ShouldContainSubstring,
+				"supports none of the requested compressors, communicating uncompressed",
+			)
+		})
+	})
+}
+
+func TestServerSelectionTimeout(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	enabled := options.EnabledOptions{Connection: true, URI: true}
+
+	Convey("The server selection timeout defaults to the driver's", t, func() {
+		toolOptions := options.New("test", "", "", "", true, enabled)
+		_, err := toolOptions.ParseArgs([]string{})
+		So(err, ShouldBeNil)
+		So(serverSelectionTimeout(*toolOptions), ShouldEqual, 30*time.Second)
+		So(readPrefMode(*toolOptions), ShouldEqual, "primary")
+
+		_, err = toolOptions.ParseArgs([]string{"--serverSelectionTimeout", "2"})
+		So(err, ShouldBeNil)
+		So(serverSelectionTimeout(*toolOptions), ShouldEqual, 2*time.Second)
+	})
+
+	Convey("Server selection errors describe the known topology", t, func() {
+		selectionErr := topology.ServerSelectionError{
+			Wrapped: context.DeadlineExceeded,
+			Desc: description.Topology{
+				Kind: description.ReplicaSetNoPrimary,
+				Servers: []description.Server{
+					{Addr: "a:27017", Kind: description.RSSecondary},
+					{
+						Addr:      "b:27017",
+						Kind:      description.Unknown,
+						LastError: errors.New("connection refused"),
+					},
+				},
+			},
+		}
+		err := describeServerSelectionError(
+			fmt.Errorf("ping: %w", selectionErr), 5*time.Second, "primary")
+		So(err.Error(), ShouldEqual,
+			"no suitable server found within 5s for read preference 'primary'; "+
+				"topology type ReplicaSetNoPrimary, known servers: "+
+				"a:27017 (RSSecondary), b:27017 (Unknown: connection refused)")
+
+		selectionErr.Desc.Servers = nil
+		err = describeServerSelectionError(selectionErr, time.Second, "secondary")
+		So(err.Error(), ShouldEndWith, "known servers: none")
+
+		other := errors.New("auth failed")
+		So(describeServerSelectionError(other, time.Second, "primary"), ShouldEqual, other)
+	})
+
+	Convey("Connecting to an unreachable server fails after the timeout", t, func() {
+		toolOptions := options.New("test", "", "", "", true, enabled)
+		_, err := toolOptions.ParseArgs(
+			[]string{"--host", "127.0.0.1", "--port", "1", "--serverSelectionTimeout", "1"})
+		So(err, ShouldBeNil)
+
+		start := time.Now()
+		_, err = NewSessionProvider(*toolOptions)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "no suitable server found within 1s")
+		So(err.Error(), ShouldContainSubstring, "127.0.0.1:1 (Unknown")
+		So(time.Since(start), ShouldBeLessThan, 10*time.Second)
+	})
+}
@@ -14,6 +14,8 @@ type DeferredQuery struct {
 	Filter    interface{}
 	Hint      interface{}
 	LogReplay bool
+	// Session, if set, is the session that Iter runs the query in.
+	Session mongo.Session
 }
 
 // Count issues a EstimatedDocumentCount command when there is no Filter in the query and a CountDocuments command otherwise.
@@ -54,5 +56,9 @@ func (q *DeferredQuery) Iter() (*mongo.Cursor, error) {
 	if filter == nil {
 		filter = bson.D{}
 	}
-	return q.Coll.Find(context.TODO(), filter, opts)
+	ctx := context.TODO()
+	if q.Session != nil {
+		ctx = mongo.NewSessionContext(ctx, q.Session)
+	}
+	return q.Coll.Find(ctx, filter, opts)
 }
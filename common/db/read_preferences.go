@@ -8,6 +8,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/json"
@@ -23,6 +24,9 @@ type readPrefDoc struct {
 	MaxStalenessSeconds *int
 }
 
+// readPrefDocFields are the fields allowed in a --readPreference json object.
+var readPrefDocFields = []string{"mode", "tagSets", "maxStalenessSeconds"}
+
 const (
 	WarningNonPrimaryMongosConnection = "Warning: using a non-primary readPreference with a " +
 		"connection to mongos may produce inconsistent duplicates or miss some documents."
@@ -45,8 +49,18 @@ func NewReadPreference(rp string, cs *connstring.ConnString) (*readpref.ReadPref
 	if rp[0] != '{' {
 		mode = rp
 	} else {
+		var raw map[string]interface{}
+		err := json.Unmarshal([]byte(rp), &raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json object: %v", err)
+		}
+		err = validateReadPrefDoc(raw)
+		if err != nil {
+			return nil, err
+		}
+
 		var doc readPrefDoc
-		err := json.Unmarshal([]byte(rp), &doc)
+		err = json.Unmarshal([]byte(rp), &doc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid json object: %v", err)
 		}
@@ -67,12 +81,74 @@ func NewReadPreference(rp string, cs *connstring.ConnString) (*readpref.ReadPref
 
 	rpMode, err := readpref.ModeFromString(mode)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf(
+			"unknown read preference mode '%v', must be one of: %v",
+			mode,
+			strings.Join(readPrefModes(), ", "),
+		)
 	}
 
 	return readpref.New(rpMode, options...)
 }
 
+// readPrefModes returns the names of all valid read preference modes.
+func readPrefModes() []string {
+	return []string{
+		readpref.PrimaryMode.String(),
+		readpref.PrimaryPreferredMode.String(),
+		readpref.SecondaryMode.String(),
+		readpref.SecondaryPreferredMode.String(),
+		readpref.NearestMode.String(),
+	}
+}
+
+// validateReadPrefDoc checks a --readPreference json object for unknown fields
+// and malformed tag sets, so that mistakes are reported instead of ignored.
+func validateReadPrefDoc(raw map[string]interface{}) error {
+	for field := range raw {
+		known := false
+		for _, name := range readPrefDocFields {
+			if strings.EqualFold(field, name) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf(
+				"unknown field '%v' in read preference, must be one of: %v",
+				field,
+				strings.Join(readPrefDocFields, ", "),
+			)
+		}
+	}
+
+	for field, value := range raw {
+		if !strings.EqualFold(field, "tagSets") {
+			continue
+		}
+		tagSets, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid tagSets: expected an array of documents")
+		}
+		for i, tagSet := range tagSets {
+			tags, ok := tagSet.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid tag set #%v: expected a document", i)
+			}
+			for name, tagValue := range tags {
+				if _, ok := tagValue.(string); !ok {
+					return fmt.Errorf(
+						"invalid tag set #%v: value of tag '%v' must be a string",
+						i,
+						name,
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func readPrefFromConnString(cs *connstring.ConnString) (*readpref.ReadPref, error) {
 	var opts []readpref.Option
 
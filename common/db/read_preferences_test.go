@@ -103,5 +103,28 @@ func TestNewReadPreference(t *testing.T) {
 				So(maxStaleness, ShouldEqual, 123*time.Second)
 			},
 		)
+
+		Convey("An unknown mode should be rejected with the valid modes", func() {
+			for _, rp := range []string{"secondry", `{mode: "secondry"}`} {
+				_, err := NewReadPreference(rp, nil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unknown read preference mode 'secondry'")
+				So(err.Error(), ShouldContainSubstring, "secondaryPreferred")
+			}
+		})
+
+		Convey("Malformed json objects should be rejected", func() {
+			for rp, msg := range map[string]string{
+				`{mode: "secondary", tagSet: [{dc: "east"}]}`: "unknown field 'tagSet'",
+				`{mode: "secondary", tagSets: {dc: "east"}}`:  "expected an array of documents",
+				`{mode: "secondary", tagSets: ["east"]}`:      "invalid tag set #0: expected a document",
+				`{mode: "secondary", tagSets: [{}, {dc: 1}]}`: "invalid tag set #1: value of tag 'dc'",
+				`{tagSets: [{dc: "east"}]}`:                   "no 'mode' specified",
+			} {
+				_, err := NewReadPreference(rp, nil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, msg)
+			}
+		})
 	})
 }
@@ -0,0 +1,138 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package db
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// Bounds on the delay between attempts in RetryOnTransientError.
+const (
+	minRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff = 5 * time.Second
+)
+
+// transientErrorCodes are server error codes returned while a replica set
+// changes state, e.g. during an election. A command failing with one of
+// these codes was not executed, so it is safe to send it again.
+var transientErrorCodes = map[int]bool{
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	10107: true, // NotWritablePrimary
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotPrimaryNoSecondaryOk
+	13436: true, // NotPrimaryOrSecondary
+}
+
+// retryableCommands are the commands, in lower case, that SessionProvider.Run
+// retries on transient errors. They only read, so running one twice is
+// harmless. Other commands, such as insert or applyOps, may have been applied
+// before the error was returned, and are not retried.
+var retryableCommands = map[string]bool{
+	"buildinfo":        true,
+	"collstats":        true,
+	"connectionstatus": true,
+	"count":            true,
+	"dbstats":          true,
+	"distinct":         true,
+	"find":             true,
+	"getcmdlineopts":   true,
+	"getparameter":     true,
+	"hello":            true,
+	"hostinfo":         true,
+	"ismaster":         true,
+	"listcollections":  true,
+	"listdatabases":    true,
+	"listindexes":      true,
+	"ping":             true,
+	"replsetgetconfig": true,
+	"replsetgetstatus": true,
+	"rolesinfo":        true,
+	"serverstatus":     true,
+	"usersinfo":        true,
+}
+
+// isRetryableCommand returns true if command is one of the retryableCommands.
+// The name of a command is its first key.
+func isRetryableCommand(command interface{}) bool {
+	raw, err := bson.Marshal(command)
+	if err != nil {
+		return false
+	}
+	elem, err := bson.Raw(raw).IndexErr(0)
+	if err != nil {
+		return false
+	}
+	return retryableCommands[strings.ToLower(elem.Key())]
+}
+
+// retryNow and retrySleep are swapped out in tests.
+var (
+	retryNow   = time.Now
+	retrySleep = time.Sleep
+)
+
+// IsTransientError returns true if err is caused by a temporary change in
+// the cluster topology, such as a replica set election, rather than by the
+// operation itself.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for code := range transientErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RetryOnTransientError calls fn until it succeeds, fails with an error that
+// is not transient, or timeout has elapsed. Attempts are spaced with
+// exponential backoff and jitter. A timeout of zero calls fn exactly once.
+func RetryOnTransientError(timeout time.Duration, fn func() error) error {
+	deadline := retryNow().Add(timeout)
+	backoff := minRetryBackoff
+	for {
+		err := fn()
+		if err == nil || timeout <= 0 || !IsTransientError(err) {
+			return err
+		}
+
+		// sleep for between half and all of the current backoff
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if remaining := deadline.Sub(retryNow()); delay > remaining {
+			if remaining <= 0 {
+				return err
+			}
+			delay = remaining
+		}
+		log.Logvf(log.Info, "retrying after transient error in %v: %v", delay, err)
+		retrySleep(delay)
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+func TestRetryOnTransientError(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	notPrimary := mongo.CommandError{Code: 10107, Name: "NotWritablePrimary"}
+
+	Convey("With a fake clock", t, func() {
+		now := time.Unix(0, 0)
+		var delays []time.Duration
+		origNow, origSleep := retryNow, retrySleep
+		retryNow = func() time.Time { return now }
+		retrySleep = func(d time.Duration) {
+			delays = append(delays, d)
+			now = now.Add(d)
+		}
+		Reset(func() {
+			retryNow, retrySleep = origNow, origSleep
+		})
+
+		Convey("transient errors are retried until the call succeeds", func() {
+			calls := 0
+			err := RetryOnTransientError(time.Minute, func() error {
+				calls++
+				if calls < 4 {
+					return notPrimary
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 4)
+			So(len(delays), ShouldEqual, 3)
+			for i, delay := range delays {
+				backoff := minRetryBackoff << i
+				So(delay, ShouldBeBetweenOrEqual, backoff/2, backoff)
+			}
+		})
+
+		Convey("retries stop once the timeout has elapsed", func() {
+			calls := 0
+			err := RetryOnTransientError(2*time.Second, func() error {
+				calls++
+				return notPrimary
+			})
+			So(err, ShouldResemble, notPrimary)
+			So(calls, ShouldBeGreaterThan, 1)
+			So(now.Sub(time.Unix(0, 0)), ShouldEqual, 2*time.Second)
+		})
+
+		Convey("non-transient errors are returned immediately", func() {
+			calls := 0
+			boom := errors.New("boom")
+			err := RetryOnTransientError(time.Minute, func() error {
+				calls++
+				return boom
+			})
+			So(err, ShouldEqual, boom)
+			So(calls, ShouldEqual, 1)
+			So(delays, ShouldBeEmpty)
+		})
+
+		Convey("a zero timeout disables retries", func() {
+			calls := 0
+			err := RetryOnTransientError(0, func() error {
+				calls++
+				return notPrimary
+			})
+			So(err, ShouldResemble, notPrimary)
+			So(calls, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestIsTransientError(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("IsTransientError", t, func() {
+		So(IsTransientError(nil), ShouldBeFalse)
+		So(IsTransientError(errors.New("boom")), ShouldBeFalse)
+		So(IsTransientError(mongo.CommandError{Code: 11000}), ShouldBeFalse)
+		So(IsTransientError(mongo.CommandError{Code: 11602}), ShouldBeTrue)
+		So(IsTransientError(mongo.CommandError{Code: 189}), ShouldBeTrue)
+		So(IsTransientError(topology.ServerSelectionError{}), ShouldBeTrue)
+		So(
+			IsTransientError(fmt.Errorf("wrapped: %w", mongo.CommandError{Code: 13435})),
+			ShouldBeTrue,
+		)
+	})
+}
+
+func TestIsRetryableCommand(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When checking whether a command is retried", t, func() {
+		Convey("read-only commands are retried whatever their case", func() {
+			So(isRetryableCommand(&bson.M{"buildInfo": 1}), ShouldBeTrue)
+			So(isRetryableCommand(bson.D{{"isMaster", 1}}), ShouldBeTrue)
+			So(isRetryableCommand(bson.D{{"listIndexes", "c"}, {"cursor", bson.D{}}}), ShouldBeTrue)
+		})
+
+		Convey("commands that write are not retried", func() {
+			So(isRetryableCommand(bson.D{{"insert", "c"}, {"documents", bson.A{}}}), ShouldBeFalse)
+			So(isRetryableCommand(bson.D{{"applyOps", bson.A{}}}), ShouldBeFalse)
+		})
+
+		Convey("commands that can't be marshaled are not retried", func() {
+			So(isRetryableCommand(bson.D{}), ShouldBeFalse)
+			So(isRetryableCommand(42), ShouldBeFalse)
+		})
+	})
+}
@@ -7,6 +7,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/util"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
@@ -23,9 +25,14 @@ const (
 	j         = "j"
 	w         = "w"
 	wTimeout  = "wtimeout"
+	fsync     = "fsync"
 	majString = "majority"
 )
 
+// writeConcernFailedErrorCode is the code of the write concern error the
+// server returns when wtimeout expires before the write concern is satisfied.
+const writeConcernFailedErrorCode = 64
+
 // NewMongoWriteConcern takes a string (from the command line writeConcern option) and a ConnString object
 // (from the command line uri option) and returns a WriteConcern. If both are provided, preference is given to
 // the command line writeConcern option. If neither is provided, the default 'majority' write concern is constructed.
@@ -111,6 +118,17 @@ func parseJSONWriteConcern(
 ) (*writeconcern.WriteConcern, error) {
 	var opts []writeconcern.Option
 
+	for field := range jsonWriteConcern {
+		switch field {
+		case w, j, wTimeout, fsync:
+		default:
+			return nil, fmt.Errorf(
+				"unknown write concern field '%v', must be one of: w, j, wtimeout, fsync",
+				field,
+			)
+		}
+	}
+
 	// Construct new options from 'w', if it exists; otherwise default to 'majority'
 	if wVal, ok := jsonWriteConcern[w]; ok {
 		opt, err := parseWField(wVal)
@@ -123,15 +141,18 @@ func parseJSONWriteConcern(
 		opts = append(opts, writeconcern.WMajority())
 	}
 
-	// Journal option
-	if jVal, ok := jsonWriteConcern[j]; ok && util.IsTruthy(jVal) {
-		opts = append(opts, writeconcern.J(true))
+	// Journal option. The server no longer supports fsync in a write concern;
+	// waiting for the journal gives the same guarantee.
+	jVal, jSet := jsonWriteConcern[j]
+	fsyncVal, fsyncSet := jsonWriteConcern[fsync]
+	if jSet || fsyncSet {
+		opts = append(opts, writeconcern.J(util.IsTruthy(jVal) || util.IsTruthy(fsyncVal)))
 	}
 
 	// Wtimeout option
 	if wtimeout, ok := jsonWriteConcern[wTimeout]; ok {
 		timeoutVal, err := util.ToInt(wtimeout)
-		if err != nil {
+		if err != nil || timeoutVal < 0 {
 			return nil, fmt.Errorf("invalid '%v' argument: %v", wTimeout, wtimeout)
 		}
 		// Previous implementation assumed passed in string was milliseconds
@@ -141,6 +162,22 @@ func parseJSONWriteConcern(
 	return writeconcern.New(opts...), nil
 }
 
+// IsWriteConcernTimeout returns true if err includes a write concern error
+// caused by wtimeout expiring. The writes it reports were applied on the
+// server they were sent to, but may not have been replicated as requested.
+func IsWriteConcernTimeout(err error) bool {
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) {
+		return bwe.WriteConcernError != nil &&
+			bwe.WriteConcernError.Code == writeConcernFailedErrorCode
+	}
+	var wce mongo.WriteConcernError
+	if errors.As(err, &wce) {
+		return wce.Code == writeConcernFailedErrorCode
+	}
+	return false
+}
+
 func parseWField(wValue interface{}) (writeconcern.Option, error) {
 	// Try parsing as int
 	if wNumber, err := util.ToInt(wValue); err == nil {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
@@ -63,6 +64,30 @@ func TestNewMongoWriteConcern(t *testing.T) {
 					So(writeConcern.GetJ(), ShouldBeTrue)
 				},
 			)
+			Convey("j, wtimeout and a tag set w should all be honored", func() {
+				writeConcern, err := NewMongoWriteConcern(`{w:"dc1", j:true, wtimeout:500}`, nil)
+				So(err, ShouldBeNil)
+				So(writeConcern.GetW(), ShouldEqual, "dc1")
+				So(writeConcern.GetJ(), ShouldBeTrue)
+				So(writeConcern.GetWTimeout(), ShouldEqual, 500*time.Millisecond)
+			})
+			Convey("fsync should require the journal", func() {
+				writeConcern, err := NewMongoWriteConcern(`{w:2, fsync:true}`, nil)
+				So(err, ShouldBeNil)
+				So(writeConcern.GetJ(), ShouldBeTrue)
+				writeConcern, err = NewMongoWriteConcern(`{w:2, j:false}`, nil)
+				So(err, ShouldBeNil)
+				So(writeConcern.GetJ(), ShouldBeFalse)
+			})
+			Convey("with a negative wtimeout, an error should be returned", func() {
+				_, err := NewMongoWriteConcern(`{w:1, wtimeout:-1}`, nil)
+				So(err, ShouldNotBeNil)
+			})
+			Convey("with an unknown field, an error should be returned", func() {
+				_, err := NewMongoWriteConcern(`{w:1, wtimout:500}`, nil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unknown write concern field 'wtimout'")
+			})
 			// Regression test for TOOLS-1741
 			Convey("When passing an empty writeConcern and empty URI"+
 				"then write concern should default to being majority", func() {
@@ -161,3 +186,23 @@ func TestConstructWCFromConnString(t *testing.T) {
 		})
 	})
 }
+
+func TestIsWriteConcernTimeout(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Only write concern errors with the WriteConcernFailed code are timeouts", t, func() {
+		So(IsWriteConcernTimeout(nil), ShouldBeFalse)
+		So(IsWriteConcernTimeout(mongo.BulkWriteException{
+			WriteConcernError: &mongo.WriteConcernError{Code: writeConcernFailedErrorCode},
+		}), ShouldBeTrue)
+		So(IsWriteConcernTimeout(mongo.BulkWriteException{
+			WriteConcernError: &mongo.WriteConcernError{Code: 100},
+		}), ShouldBeFalse)
+		So(IsWriteConcernTimeout(mongo.BulkWriteException{
+			WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}},
+		}), ShouldBeFalse)
+		So(IsWriteConcernTimeout(
+			mongo.WriteConcernError{Code: writeConcernFailedErrorCode},
+		), ShouldBeTrue)
+	})
+}
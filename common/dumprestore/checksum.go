@@ -0,0 +1,68 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package dumprestore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumSuffix is appended to the path of a dumped BSON file to get the
+// path of the sidecar file that records its SHA-256 checksum.
+const ChecksumSuffix = ".sha256"
+
+// WriteChecksumFile records sum as the SHA-256 checksum of the file at path.
+// The sidecar uses the same format as sha256sum, so it can also be checked
+// with "sha256sum -c".
+func WriteChecksumFile(path string, sum []byte) error {
+	line := fmt.Sprintf("%x  %s\n", sum, filepath.Base(path))
+	return os.WriteFile(path+ChecksumSuffix, []byte(line), 0o644)
+}
+
+// ReadChecksumFile returns the SHA-256 checksum recorded for the file at path.
+func ReadChecksumFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path + ChecksumSuffix)
+	if err != nil {
+		return nil, err
+	}
+	field, _, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
+	sum, err := hex.DecodeString(field)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("invalid checksum file %v", path+ChecksumSuffix)
+	}
+	return sum, nil
+}
+
+// VerifyChecksum hashes the file at path and returns an error if the result
+// doesn't match the checksum recorded in its sidecar file.
+func VerifyChecksum(path string) error {
+	expected, err := ReadChecksumFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading checksum for %v: %v", path, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("error reading %v: %v", path, err)
+	}
+	if actual := hash.Sum(nil); !bytes.Equal(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %v: expected %x but found %x", path, expected, actual)
+	}
+	return nil
+}
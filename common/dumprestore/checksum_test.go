@@ -0,0 +1,57 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package dumprestore
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumFile(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "coll.bson")
+	data := []byte("some bson bytes")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	sum := sha256.Sum256(data)
+	require.NoError(t, WriteChecksumFile(path, sum[:]))
+
+	sidecar, err := os.ReadFile(path + ChecksumSuffix)
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f]{64}  coll\.bson\n$`, string(sidecar))
+
+	read, err := ReadChecksumFile(path)
+	require.NoError(t, err)
+	require.Equal(t, sum[:], read)
+	require.NoError(t, VerifyChecksum(path))
+
+	t.Run("mismatch", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("corrupted bytes"), 0o644))
+		err := VerifyChecksum(path)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("missing sidecar", func(t *testing.T) {
+		other := filepath.Join(dir, "other.bson")
+		require.NoError(t, os.WriteFile(other, data, 0o644))
+		require.Error(t, VerifyChecksum(other))
+	})
+
+	t.Run("invalid sidecar", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path+ChecksumSuffix, []byte("xyz  coll.bson\n"), 0o644))
+		_, err := ReadChecksumFile(path)
+		require.Error(t, err)
+	})
+}
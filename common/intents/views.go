@@ -0,0 +1,95 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package intents
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ViewOn returns the name of the collection or view that a view intent is
+// defined on, or the empty string if the intent isn't a view.
+func (it *Intent) ViewOn() string {
+	for _, opt := range it.Options {
+		if opt.Key == "viewOn" {
+			viewOn, _ := opt.Value.(string)
+			return viewOn
+		}
+	}
+	return ""
+}
+
+// ViewCycleError is returned by SortViewsByDependency when views are defined
+// on each other in a cycle.
+type ViewCycleError struct {
+	// Cycle lists the namespaces of the views in the cycle, starting and
+	// ending with the same view.
+	Cycle []string
+}
+
+func (e ViewCycleError) Error() string {
+	return fmt.Sprintf("cyclic view definitions: %v", strings.Join(e.Cycle, " -> "))
+}
+
+// SortViewsByDependency returns the views among the given intents, ordered so
+// that every view comes after the view it is defined on. Views defined on a
+// collection, or on a view that isn't among the intents, keep the order of
+// their namespaces. It returns a ViewCycleError if the views form a cycle.
+func SortViewsByDependency(intents []*Intent) ([]*Intent, error) {
+	views := map[string]*Intent{}
+	var namespaces []string
+	for _, intent := range intents {
+		if intent.IsView() {
+			views[intent.Namespace()] = intent
+			namespaces = append(namespaces, intent.Namespace())
+		}
+	}
+	sort.Strings(namespaces)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	sorted := make([]*Intent, 0, len(namespaces))
+
+	// visit walks down the chain of views that ns is defined on. path holds
+	// the views currently being visited, to report a cycle if one is found.
+	var visit func(ns string, path []string) error
+	visit = func(ns string, path []string) error {
+		switch state[ns] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == ns {
+					return ViewCycleError{Cycle: append(path[i:], ns)}
+				}
+			}
+		}
+		state[ns] = visiting
+		view := views[ns]
+		dependency := view.DB + "." + view.ViewOn()
+		if _, ok := views[dependency]; ok {
+			if err := visit(dependency, append(path, ns)); err != nil {
+				return err
+			}
+		}
+		state[ns] = visited
+		sorted = append(sorted, view)
+		return nil
+	}
+
+	for _, ns := range namespaces {
+		if err := visit(ns, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package intents
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func viewIntent(db, c, viewOn string) *Intent {
+	return &Intent{
+		DB:      db,
+		C:       c,
+		Type:    "view",
+		Options: bson.D{{"viewOn", viewOn}, {"pipeline", bson.A{}}},
+	}
+}
+
+func namespaces(intents []*Intent) []string {
+	var out []string
+	for _, intent := range intents {
+		out = append(out, intent.Namespace())
+	}
+	return out
+}
+
+func TestSortViewsByDependency(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	t.Run("orders views after the views they are defined on", func(t *testing.T) {
+		sorted, err := SortViewsByDependency([]*Intent{
+			viewIntent("db", "a", "b"),
+			{DB: "db", C: "coll"},
+			viewIntent("db", "b", "c"),
+			viewIntent("db", "c", "coll"),
+			viewIntent("db", "d", "coll"),
+			viewIntent("other", "a", "missing"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db.c", "db.b", "db.a", "db.d", "other.a"}, namespaces(sorted))
+	})
+
+	t.Run("only follows dependencies within a database", func(t *testing.T) {
+		sorted, err := SortViewsByDependency([]*Intent{
+			viewIntent("db1", "a", "b"),
+			viewIntent("db2", "b", "a"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db1.a", "db2.b"}, namespaces(sorted))
+	})
+
+	t.Run("reports cycles", func(t *testing.T) {
+		_, err := SortViewsByDependency([]*Intent{
+			viewIntent("db", "x", "coll"),
+			viewIntent("db", "a", "b"),
+			viewIntent("db", "b", "c"),
+			viewIntent("db", "c", "b"),
+		})
+		var cycleErr ViewCycleError
+		require.ErrorAs(t, err, &cycleErr)
+		assert.Equal(t, []string{"db.b", "db.c", "db.b"}, cycleErr.Cycle)
+		assert.EqualError(t, err, "cyclic view definitions: db.b -> db.c -> db.b")
+	})
+
+	t.Run("reports views defined on themselves", func(t *testing.T) {
+		_, err := SortViewsByDependency([]*Intent{viewIntent("db", "a", "a")})
+		assert.EqualError(t, err, "cyclic view definitions: db.a -> db.a")
+	})
+}
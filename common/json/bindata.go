@@ -7,6 +7,7 @@
 package json
 
 import (
+	"encoding/base64"
 	"fmt"
 	"reflect"
 )
@@ -38,6 +39,9 @@ func (d *decodeState) storeBinData(v reflect.Value) {
 	case reflect.Interface:
 		arg0 := byte(args[0].Uint())
 		arg1 := args[1].String()
+		if err := checkBinDataBase64(arg1, d.off); err != nil {
+			d.error(err)
+		}
 		v.Set(reflect.ValueOf(BinData{arg0, arg1}))
 	default:
 		d.error(fmt.Errorf("cannot store %v value into %v type", binDataType, kind))
@@ -59,15 +63,37 @@ func (d *decodeState) getBinData() interface{} {
 	if err := ctorNumArgsMismatch("BinData", 2, len(args)); err != nil {
 		d.error(err)
 	}
-	arg0, err := args[0].(Number).Uint8()
-	if err != nil {
+	arg0, ok := args[0].(Number)
+	if !ok {
 		d.error(fmt.Errorf("expected byte for first argument of BinData constructor"))
 	}
+	subtype, err := arg0.Uint8()
+	if err != nil {
+		d.error(fmt.Errorf(
+			"expected subtype between 0 and 255 for first argument of BinData constructor, "+
+				"found %v", arg0))
+	}
 	arg1, ok := args[1].(string)
 	if !ok {
 		d.error(fmt.Errorf("expected string for second argument of BinData constructor"))
 	}
+	if err := checkBinDataBase64(arg1, d.off); err != nil {
+		d.error(err)
+	}
 
 	d.useNumber = useNumber
-	return BinData{arg0, arg1}
+	return BinData{subtype, arg1}
+}
+
+// checkBinDataBase64 returns a SyntaxError if payload, the second argument of
+// the BinData constructor ending at offset off, isn't valid base64.
+func checkBinDataBase64(payload string, off int) error {
+	if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+		return &SyntaxError{
+			msg: fmt.Sprintf(
+				"invalid base64 in BinData constructor ending at offset %v: %v", off, err),
+			Offset: int64(off),
+		}
+	}
+	return nil
 }
@@ -23,7 +23,7 @@ func TestBinDataValue(t *testing.T) {
 			var jsonMap map[string]interface{}
 
 			key := "key"
-			value := `BinData(1, "xyz")`
+			value := `BinData(1, "eHl6")`
 			data := fmt.Sprintf(`{"%v":%v}`, key, value)
 
 			err := Unmarshal([]byte(data), &jsonMap)
@@ -31,15 +31,15 @@ func TestBinDataValue(t *testing.T) {
 
 			jsonValue, ok := jsonMap[key].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue, ShouldResemble, BinData{1, "xyz"})
+			So(jsonValue, ShouldResemble, BinData{1, "eHl6"})
 		})
 
 		Convey("works for multiple keys", func() {
 			var jsonMap map[string]interface{}
 
 			key1, key2, key3 := "key1", "key2", "key3"
-			value1, value2, value3 := `BinData(1, "abc")`,
-				`BinData(2, "def")`, `BinData(3, "ghi")`
+			value1, value2, value3 := `BinData(1, "YWJj")`,
+				`BinData(2, "ZGVm")`, `BinData(3, "Z2hp")`
 			data := fmt.Sprintf(`{"%v":%v,"%v":%v,"%v":%v}`,
 				key1, value1, key2, value2, key3, value3)
 
@@ -48,22 +48,22 @@ func TestBinDataValue(t *testing.T) {
 
 			jsonValue1, ok := jsonMap[key1].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue1, ShouldResemble, BinData{1, "abc"})
+			So(jsonValue1, ShouldResemble, BinData{1, "YWJj"})
 
 			jsonValue2, ok := jsonMap[key2].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue2, ShouldResemble, BinData{2, "def"})
+			So(jsonValue2, ShouldResemble, BinData{2, "ZGVm"})
 
 			jsonValue3, ok := jsonMap[key3].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue3, ShouldResemble, BinData{3, "ghi"})
+			So(jsonValue3, ShouldResemble, BinData{3, "Z2hp"})
 		})
 
 		Convey("works in an array", func() {
 			var jsonMap map[string]interface{}
 
 			key := "key"
-			value := `BinData(42, "10")`
+			value := `BinData(42, "MTA=")`
 			data := fmt.Sprintf(`{"%v":[%v,%v,%v]}`,
 				key, value, value, value)
 
@@ -76,7 +76,7 @@ func TestBinDataValue(t *testing.T) {
 			for _, _jsonValue := range jsonArray {
 				jsonValue, ok := _jsonValue.(BinData)
 				So(ok, ShouldBeTrue)
-				So(jsonValue, ShouldResemble, BinData{42, "10"})
+				So(jsonValue, ShouldResemble, BinData{42, "MTA="})
 			}
 		})
 
@@ -84,7 +84,7 @@ func TestBinDataValue(t *testing.T) {
 			var jsonMap map[string]interface{}
 
 			key := "key"
-			value := `BinData(0x5f, "xyz")`
+			value := `BinData(0x5f, "eHl6")`
 			data := fmt.Sprintf(`{"%v":%v}`, key, value)
 
 			err := Unmarshal([]byte(data), &jsonMap)
@@ -92,7 +92,52 @@ func TestBinDataValue(t *testing.T) {
 
 			jsonValue, ok := jsonMap[key].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue, ShouldResemble, BinData{0x5f, "xyz"})
+			So(jsonValue, ShouldResemble, BinData{0x5f, "eHl6"})
+		})
+
+		Convey("rejects invalid base64 with the position of the constructor", func() {
+			var jsonMap map[string]interface{}
+
+			data := `{"key":BinData(1, "x!z=")}`
+
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid base64 in BinData constructor")
+			syntaxErr, ok := err.(*SyntaxError)
+			So(ok, ShouldBeTrue)
+			So(syntaxErr.Offset, ShouldEqual, len(data)-1)
+		})
+
+		Convey("rejects subtypes outside 0-255", func() {
+			var jsonMap map[string]interface{}
+
+			for _, value := range []string{`BinData(256, "eHl6")`, `BinData(-1, "eHl6")`} {
+				err := Unmarshal([]byte(fmt.Sprintf(`{"key":%v}`, value)), &jsonMap)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestBinDataMarshal(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When marshalling BinData values", t, func() {
+		data, err := Marshal(map[string]interface{}{"key": BinData{4, "c//SZESzTGmQ6OfR38A11A=="}})
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"key":{"$binary":"c//SZESzTGmQ6OfR38A11A==","$type":"04"}}`)
+	})
+
+	Convey("When marshalling BinDataConstructor values", t, func() {
+		value := BinDataConstructor{4, "c//SZESzTGmQ6OfR38A11A=="}
+		data, err := Marshal(map[string]interface{}{"key": value})
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"key":BinData(4,"c//SZESzTGmQ6OfR38A11A==")}`)
+
+		Convey("the output can be read back", func() {
+			var jsonMap map[string]interface{}
+			So(Unmarshal(data, &jsonMap), ShouldBeNil)
+			So(jsonMap["key"], ShouldResemble, BinData{4, "c//SZESzTGmQ6OfR38A11A=="})
 		})
 	})
 }
@@ -0,0 +1,66 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Transition functions for recognizing Code.
+// Adapted from encoding/json/scanner.go.
+
+// stateC is the state after reading `C`.
+func stateC(s *scanner, c int) int {
+	if c == 'o' {
+		s.step = generateState("Code", []byte("de"), stateConstructor)
+		return scanContinue
+	}
+	return s.error(c, "in literal Code (expecting 'o')")
+}
+
+// Decodes a Code literal stored in the underlying byte data into v.
+func (d *decodeState) storeCode(v reflect.Value) {
+	code := d.getCode()
+	switch kind := v.Kind(); kind {
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(code))
+	default:
+		d.error(fmt.Errorf("cannot store %v value into %v type", javaScriptType, kind))
+	}
+}
+
+// Returns a Code literal from the underlying byte data. The optional second
+// argument is the scope, which is parsed like any other object.
+func (d *decodeState) getCode() JavaScript {
+	op := d.scanWhile(scanSkipSpace)
+	if op != scanBeginCtor {
+		d.error(fmt.Errorf("expected beginning of constructor"))
+	}
+
+	args := d.ctorInterface()
+	if len(args) != 1 && len(args) != 2 {
+		d.error(fmt.Errorf("expected 1 or 2 arguments to Code constructor, but %v received",
+			len(args)))
+	}
+	code, ok := args[0].(string)
+	if !ok {
+		d.error(fmt.Errorf("expected string for first argument of Code constructor"))
+	}
+	if len(args) == 1 {
+		return JavaScript{Code: code}
+	}
+	switch scope := args[1].(type) {
+	case map[string]interface{}, bson.D:
+		return JavaScript{Code: code, Scope: scope}
+	default:
+		d.error(fmt.Errorf("expected object for second argument of Code constructor"))
+	}
+	return JavaScript{}
+}
@@ -0,0 +1,89 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCodeValue(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Unmarshalling JSON with Code values", t, func() {
+		key := "key"
+
+		Convey("works without a scope", func() {
+			var jsonMap map[string]interface{}
+
+			data := fmt.Sprintf(`{"%v":Code("function() { return 1; }")}`, key)
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(JavaScript)
+			So(ok, ShouldBeTrue)
+			So(jsonValue, ShouldResemble, JavaScript{Code: "function() { return 1; }"})
+		})
+
+		Convey("works with a scope", func() {
+			var jsonMap map[string]interface{}
+
+			data := fmt.Sprintf(`{"%v":Code("function() { return x; }", {"x": "y"})}`, key)
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(JavaScript)
+			So(ok, ShouldBeTrue)
+			So(jsonValue.Code, ShouldEqual, "function() { return x; }")
+			So(jsonValue.Scope, ShouldResemble, map[string]interface{}{"x": "y"})
+		})
+
+		Convey("works with an empty scope", func() {
+			var jsonMap map[string]interface{}
+
+			data := fmt.Sprintf(`{"%v":Code("f", {})}`, key)
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(JavaScript)
+			So(ok, ShouldBeTrue)
+			So(jsonValue.Code, ShouldEqual, "f")
+			So(jsonValue.Scope, ShouldResemble, map[string]interface{}{})
+		})
+
+		Convey("works in an array", func() {
+			var jsonMap map[string]interface{}
+
+			data := fmt.Sprintf(`{"%v":[Code("a"),Code("b", {"c": "d"})]}`, key)
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonArray, ok := jsonMap[key].([]interface{})
+			So(ok, ShouldBeTrue)
+			So(jsonArray[0], ShouldResemble, JavaScript{Code: "a"})
+			So(jsonArray[1], ShouldResemble,
+				JavaScript{Code: "b", Scope: map[string]interface{}{"c": "d"}})
+		})
+
+		Convey("fails for invalid arguments", func() {
+			for _, value := range []string{
+				`Code()`,
+				`Code(1)`,
+				`Code("a", 1)`,
+				`Code("a", {}, {})`,
+				`Cod("a")`,
+			} {
+				var jsonMap map[string]interface{}
+				data := fmt.Sprintf(`{"%v":%v}`, key, value)
+				So(Unmarshal([]byte(data), &jsonMap), ShouldNotBeNil)
+			}
+		})
+	})
+}
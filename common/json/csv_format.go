@@ -23,6 +23,10 @@ func (b BinData) String() string {
 	return fmt.Sprintf("%X", data) // use uppercase hexadecimal
 }
 
+func (u UUID) String() string {
+	return string(u)
+}
+
 func (js JavaScript) String() string {
 	return js.Code
 }
@@ -72,14 +72,15 @@ func (d *decodeState) getDate() interface{} {
 		// validate the date format of the string
 		_, err := util.FormatDate(args[0].(string))
 		if err != nil {
-			d.error(fmt.Errorf("unexpected ISODate format"))
+			d.error(fmt.Errorf("unexpected ISODate format: %v", err))
 		}
 		d.useNumber = useNumber
 		return ISODate(args[0].(string))
 	}
 	arg0, err := arg0num.Int64()
 	if err != nil {
-		d.error(fmt.Errorf("expected int64 for first argument of Date constructor"))
+		d.error(fmt.Errorf(
+			"expected int64 for first argument of Date constructor, got %v", arg0num))
 	}
 
 	d.useNumber = useNumber
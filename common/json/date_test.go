@@ -12,6 +12,7 @@ import (
 
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestDateValue(t *testing.T) {
@@ -90,6 +91,52 @@ func TestDateValue(t *testing.T) {
 			So(err, ShouldNotBeNil)
 		})
 
+		Convey("works for milliseconds before the epoch", func() {
+			var jsonMap map[string]interface{}
+
+			key := "key"
+			value := "Date(-86400000)"
+			data := fmt.Sprintf(`{"%v":%v}`, key, value)
+
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(Date)
+			So(ok, ShouldBeTrue)
+			So(jsonValue, ShouldEqual, Date(-86400000))
+		})
+
+		Convey("works the same with and without the new keyword", func() {
+			for _, value := range []string{"Date(1609459200000)", "new Date(1609459200000)"} {
+				doc, err := UnmarshalBsonD([]byte(fmt.Sprintf(`{"key":%v}`, value)))
+				So(err, ShouldBeNil)
+				So(doc, ShouldResemble, bson.D{{"key", Date(1609459200000)}})
+			}
+			for _, value := range []string{"Date(-1)", "new Date(-1)"} {
+				doc, err := UnmarshalBsonD([]byte(fmt.Sprintf(`{"key":%v}`, value)))
+				So(err, ShouldBeNil)
+				So(doc, ShouldResemble, bson.D{{"key", Date(-1)}})
+			}
+		})
+
+		Convey("cannot use milliseconds that overflow an int64", func() {
+			for _, value := range []string{
+				"Date(9223372036854775808)",
+				"new Date(9223372036854775808)",
+				"Date(-9223372036854775809)",
+			} {
+				data := fmt.Sprintf(`{"key":%v}`, value)
+
+				var jsonMap map[string]interface{}
+				So(Unmarshal([]byte(data), &jsonMap), ShouldNotBeNil)
+
+				_, err := UnmarshalBsonD([]byte(data))
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring,
+					"expected int64 for first argument of Date constructor")
+			}
+		})
+
 		Convey("can specify argument in hexadecimal", func() {
 			var jsonMap map[string]interface{}
 
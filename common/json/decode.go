@@ -22,11 +22,13 @@ import (
 	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Unmarshal parses the JSON-encoded data and stores the result
@@ -100,6 +102,27 @@ func UnmarshalMap(data []byte) (map[string]interface{}, error) {
 }
 
 func UnmarshalBsonD(data []byte) (bson.D, error) {
+	return UnmarshalBsonDWithOptions(data, UnmarshalOptions{})
+}
+
+// UnmarshalBsonDStrict is like UnmarshalBsonD, but returns a
+// DuplicateKeyError if any object in data has the same key more than once.
+func UnmarshalBsonDStrict(data []byte) (bson.D, error) {
+	return UnmarshalBsonDWithOptions(data, UnmarshalOptions{StrictKeys: true})
+}
+
+// UnmarshalOptions configures UnmarshalBsonDWithOptions.
+type UnmarshalOptions struct {
+	// StrictKeys returns a DuplicateKeyError if any object has the same key
+	// more than once.
+	StrictKeys bool
+	// PreserveLargeIntegers decodes integers too large for an int64 as
+	// Decimal128 rather than as a float64, which can't hold all their digits.
+	PreserveLargeIntegers bool
+}
+
+// UnmarshalBsonDWithOptions is like UnmarshalBsonD, configured by opts.
+func UnmarshalBsonDWithOptions(data []byte, opts UnmarshalOptions) (bson.D, error) {
 	// Check for well-formedness.
 	// Avoids filling out half a data structure
 	// before discovering a JSON syntax error.
@@ -110,6 +133,8 @@ func UnmarshalBsonD(data []byte) (bson.D, error) {
 	}
 
 	d.init(data)
+	d.strictKeys = opts.StrictKeys
+	d.largeIntegers = opts.PreserveLargeIntegers
 	return d.unmarshalBsonD()
 }
 
@@ -133,6 +158,17 @@ func (e *UnmarshalTypeError) Error() string {
 	return "json: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
 }
 
+// A DuplicateKeyError describes a key that appears more than once in the
+// same object, which is only an error in strict mode.
+type DuplicateKeyError struct {
+	Key    string
+	Offset int // byte offset of the repeated key in the input
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key '%v' at offset %v", e.Key, e.Offset)
+}
+
 // An UnmarshalFieldError describes a JSON object key that
 // led to an unexported (and therefore unwritable) struct field.
 // (No longer used; kept for compatibility.)
@@ -250,15 +286,25 @@ func (n Number) Int64() (int64, error) {
 	return strconv.ParseInt(string(n), base, 64)
 }
 
-// Uint8 returns the number as an uint8.
+// Uint8 returns the number as an uint8. It fails if the number is negative
+// or greater than 255.
 func (n Number) Uint8() (uint8, error) {
-	x, err := n.Uint64()
+	base := 10
+	if isHexPrefix(string(n)) {
+		base = 0 // strconv.ParseUint will infer base 16
+	}
+	x, err := strconv.ParseUint(string(n), base, 8)
 	return uint8(x), err
 }
 
-// Uint32 returns the number as an uint32.
+// Uint32 returns the number as an uint32. It fails if the number is negative
+// or doesn't fit in 32 bits.
 func (n Number) Uint32() (uint32, error) {
-	x, err := n.Uint64()
+	base := 10
+	if isHexPrefix(string(n)) {
+		base = 0 // strconv.ParseUint will infer base 16
+	}
+	x, err := strconv.ParseUint(string(n), base, 32)
 	return uint32(x), err
 }
 
@@ -280,6 +326,10 @@ type decodeState struct {
 	savedError error
 	tempstr    string // scratch space to avoid some allocations
 	useNumber  bool
+	strictKeys bool // whether a key repeated in an object is an error
+
+	// whether integers too large for an int64 are decoded as Decimal128
+	largeIntegers bool
 }
 
 // errPhase is used for errors that should not happen unless
@@ -307,6 +357,28 @@ func (d *decodeState) saveError(err error) {
 	}
 }
 
+// keySet returns the set to pass to checkKey for a new object, which is nil
+// unless strictKeys is set.
+func (d *decodeState) keySet() map[string]struct{} {
+	if !d.strictKeys {
+		return nil
+	}
+	return map[string]struct{}{}
+}
+
+// checkKey records the key of an object that starts at offset start, and
+// aborts the decoding with a DuplicateKeyError if it was already in seen. It
+// does nothing unless strictKeys is set.
+func (d *decodeState) checkKey(seen map[string]struct{}, key string, start int) {
+	if !d.strictKeys {
+		return
+	}
+	if _, ok := seen[key]; ok {
+		d.error(&DuplicateKeyError{Key: key, Offset: start})
+	}
+	seen[key] = struct{}{}
+}
+
 // next cuts off and returns the next full JSON value in d.data[d.off:].
 // The next value is known to be an object or array, not a literal.
 func (d *decodeState) next() []byte {
@@ -634,6 +706,7 @@ func (d *decodeState) object(v reflect.Value) {
 	}
 
 	var mapElem reflect.Value
+	seen := d.keySet()
 
 	for {
 		// Read opening " of string key or closing }.
@@ -654,6 +727,7 @@ func (d *decodeState) object(v reflect.Value) {
 		if !ok {
 			d.error(errPhase)
 		}
+		d.checkKey(seen, string(key), start)
 
 		// Figure out field corresponding to key.
 		var subv reflect.Value
@@ -748,13 +822,21 @@ func (d *decodeState) literal(v reflect.Value) {
 // or a Number depending on the setting of d.useNumber and whether the
 // string is specified in hexadecimal. It does this by parsing the string to see if it
 // can an integer, if not it is treated as a float. If the integer is within the bounds of an int32 it
-// is returned as an int32.
+// is returned as an int32. If d.largeIntegers is set, an integer too large for
+// an int64 is returned as a Decimal128 instead of a float64.
 func (d *decodeState) convertNumber(s string) (interface{}, error) {
 	if d.useNumber {
 		return Number(s), nil
 	}
 	parsedInteger, err := strconv.ParseInt(s, 0, 64)
 	if err != nil {
+		if d.largeIntegers && isLargeInteger(s) {
+			parsedDecimal, err := primitive.ParseDecimal128(s)
+			if err != nil {
+				return nil, &UnmarshalTypeError{"number " + s, decimal128Type}
+			}
+			return Decimal128{parsedDecimal}, nil
+		}
 		parsedFloat, err := strconv.ParseFloat(s, 64)
 		if err != nil {
 			return nil, &UnmarshalTypeError{"number " + s, reflect.TypeOf(0.0)}
@@ -769,7 +851,26 @@ func (d *decodeState) convertNumber(s string) (interface{}, error) {
 
 }
 
-var numberType = reflect.TypeOf(Number(""))
+// isLargeInteger reports whether s is a decimal integer literal that is too
+// large for an int64.
+func isLargeInteger(s string) bool {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	_, err := strconv.ParseInt(s, 10, 64)
+	return errors.Is(err, strconv.ErrRange)
+}
+
+var (
+	numberType     = reflect.TypeOf(Number(""))
+	decimal128Type = reflect.TypeOf(Decimal128{})
+)
 
 // literalStore decodes a literal stored in item into v.
 //
@@ -1055,6 +1156,7 @@ func (d *decodeState) arrayInterface(insideBSOND bool) []interface{} {
 // bsonDInterface is like object but returns bson.D{}.
 func (d *decodeState) bsonDInterface() bson.D {
 	m := bson.D{}
+	seen := d.keySet()
 	for {
 		// Read opening " of string key or closing }.
 		op := d.scanWhile(scanSkipSpace)
@@ -1074,6 +1176,7 @@ func (d *decodeState) bsonDInterface() bson.D {
 		if !ok {
 			d.error(errPhase)
 		}
+		d.checkKey(seen, key, start)
 
 		// Read : before value.
 		if op == scanSkipSpace {
@@ -1101,6 +1204,7 @@ func (d *decodeState) bsonDInterface() bson.D {
 // objectInterface is like object but returns map[string]interface{}.
 func (d *decodeState) objectInterface() map[string]interface{} {
 	m := make(map[string]interface{})
+	seen := d.keySet()
 	for {
 		// Read opening " of string key or closing }.
 		op := d.scanWhile(scanSkipSpace)
@@ -1120,6 +1224,7 @@ func (d *decodeState) objectInterface() map[string]interface{} {
 		if !ok {
 			d.error(errPhase)
 		}
+		d.checkKey(seen, key, start)
 
 		// Read : before value.
 		if op == scanSkipSpace {
@@ -8,6 +8,7 @@ package json
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -131,3 +132,42 @@ func TestDecodeBsonD(t *testing.T) {
 		So(err, ShouldNotBeNil)
 	})
 }
+
+func TestDecodeBsonDDuplicateKeys(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When unmarshalling JSON with a repeated key", t, func() {
+		data := `{"a": 1, "b": {"c": 2, "c": 3}, "a": 4}`
+
+		Convey("by default the last value is kept", func() {
+			out, err := UnmarshalBsonD([]byte(data))
+			So(err, ShouldBeNil)
+			So(out, ShouldResemble, bson.D{
+				{"a", int32(1)},
+				{"b", bson.D{{"c", int32(2)}, {"c", int32(3)}}},
+				{"a", int32(4)},
+			})
+		})
+
+		Convey("in strict mode the first repeated key is reported", func() {
+			_, err := UnmarshalBsonDStrict([]byte(data))
+			So(err, ShouldResemble, &DuplicateKeyError{Key: "c", Offset: 23})
+			So(err.Error(), ShouldEqual, "duplicate key 'c' at offset 23")
+		})
+
+		Convey("in strict mode the same key in different objects is allowed", func() {
+			out, err := UnmarshalBsonDStrict([]byte(`{"a": {"a": 1}, "b": [{"a": 2}, {"a": 3}]}`))
+			So(err, ShouldBeNil)
+			So(len(out), ShouldEqual, 2)
+		})
+
+		Convey("a Decoder that disallows duplicate keys reports them", func() {
+			dec := NewDecoder(strings.NewReader(`{"x": 1} {"y": 1, 'y': 2}`))
+			dec.DisallowDuplicateKeys()
+			out := map[string]interface{}{}
+			So(dec.Decode(&out), ShouldBeNil)
+			err := dec.Decode(&out)
+			So(err, ShouldResemble, &DuplicateKeyError{Key: "y", Offset: 10})
+		})
+	})
+}
@@ -142,6 +142,17 @@ func Marshal(v interface{}) ([]byte, error) {
 	return e.Bytes(), nil
 }
 
+// MarshalCanonical is like Marshal, but values that have a canonical Extended
+// JSON form, such as RegExp, are encoded in that form instead of the legacy one.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	e := &encodeState{canonical: true}
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
 // MarshalIndent is like Marshal but applies Indent to format the output.
 func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	b, err := Marshal(v)
@@ -197,6 +208,12 @@ type Marshaler interface {
 	MarshalJSON() ([]byte, error)
 }
 
+// canonicalMarshaler is implemented by Marshalers whose canonical Extended JSON
+// form differs from the legacy one they marshal to.
+type canonicalMarshaler interface {
+	MarshalCanonicalJSON() ([]byte, error)
+}
+
 // An UnsupportedTypeError is returned by Marshal when attempting
 // to encode an unsupported value type.
 type UnsupportedTypeError struct {
@@ -248,6 +265,9 @@ var hex = "0123456789abcdef"
 type encodeState struct {
 	bytes.Buffer // accumulated output
 	scratch      [64]byte
+
+	// canonical is set to encode values in their canonical Extended JSON form
+	canonical bool
 }
 
 var encodeStatePool sync.Pool
@@ -432,7 +452,7 @@ func marshalerEncoder(e *encodeState, v reflect.Value, quoted bool) {
 	}
 	//nolint:errcheck
 	m := v.Interface().(Marshaler)
-	b, err := m.MarshalJSON()
+	b, err := e.marshalJSON(m)
 	if err == nil {
 		// copy JSON into buffer, checking validity.
 		err = compact(&e.Buffer, b, true)
@@ -442,6 +462,14 @@ func marshalerEncoder(e *encodeState, v reflect.Value, quoted bool) {
 	}
 }
 
+// marshalJSON marshals m, in its canonical form if one is being encoded.
+func (e *encodeState) marshalJSON(m Marshaler) ([]byte, error) {
+	if cm, ok := m.(canonicalMarshaler); ok && e.canonical {
+		return cm.MarshalCanonicalJSON()
+	}
+	return m.MarshalJSON()
+}
+
 func addrMarshalerEncoder(e *encodeState, v reflect.Value, quoted bool) {
 	va := v.Addr()
 	if va.IsNil() {
@@ -450,7 +478,7 @@ func addrMarshalerEncoder(e *encodeState, v reflect.Value, quoted bool) {
 	}
 	//nolint:errcheck
 	m := va.Interface().(Marshaler)
-	b, err := m.MarshalJSON()
+	b, err := e.marshalJSON(m)
 	if err == nil {
 		// copy JSON into buffer, checking validity.
 		err = compact(&e.Buffer, b, true)
@@ -23,6 +23,16 @@ func (b BinData) MarshalJSON() ([]byte, error) {
 	return []byte(data), nil
 }
 
+// MarshalJSON renders b as a shell BinData constructor, e.g.
+// BinData(4, "c//SZESzTGmQ6OfR38A11A=="), which the scanner reads back.
+func (b BinDataConstructor) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`BinData(%v, %q)`, b.Type, b.Base64)), nil
+}
+
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{ "$uuid": "%v" }`, string(u))), nil
+}
+
 func (d128 Decimal128) MarshalJSON() ([]byte, error) {
 	s := d128.Decimal128.String()
 	return []byte(fmt.Sprintf(`{ "$numberDecimal" : "%s" }`, s)), nil
@@ -157,6 +167,22 @@ func (r RegExp) MarshalJSON() ([]byte, error) {
 	return []byte(data), nil
 }
 
+// MarshalCanonicalJSON marshals the RegExp as an Extended JSON v2
+// $regularExpression document.
+func (r RegExp) MarshalCanonicalJSON() ([]byte, error) {
+	pattern, err := Marshal(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	options, err := Marshal(r.Options)
+	if err != nil {
+		return nil, err
+	}
+	data := fmt.Sprintf(`{ "$regularExpression": { "pattern": %v, "options": %v } }`,
+		string(pattern), string(options))
+	return []byte(data), nil
+}
+
 func (t Timestamp) MarshalJSON() ([]byte, error) {
 	data := fmt.Sprintf(`{ "$timestamp": { "t": %v, "i": %v } }`,
 		t.Seconds, t.Increment)
@@ -0,0 +1,107 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+// minLargeIntegerDigits is the fewest digits an integer too large for an int64
+// can have.
+const minLargeIntegerDigits = 19
+
+// LargeIntegersToDecimal128 returns data with each integer value that is too
+// large for an int64 replaced by an Extended JSON {"$numberDecimal": "..."}
+// object, so that Extended JSON parsers keep all of its digits instead of
+// decoding it as a float64. Data that has no such integers, or that is not
+// valid JSON, is returned unchanged.
+func LargeIntegersToDecimal128(data []byte) []byte {
+	var out []byte
+	// end of the data copied to out so far
+	copied := 0
+	ok := scanLargeIntegers(data, func(start, end int) {
+		out = append(out, data[copied:start]...)
+		out = append(out, `{"$numberDecimal":"`...)
+		out = append(out, data[start:end]...)
+		out = append(out, `"}`...)
+		copied = end
+	})
+	if !ok || out == nil {
+		return data
+	}
+	return append(out, data[copied:]...)
+}
+
+// FirstLargeInteger returns the first integer value in data that is too large
+// for an int64, or "" if there is none or data is not valid JSON.
+func FirstLargeInteger(data []byte) string {
+	first := ""
+	ok := scanLargeIntegers(data, func(start, end int) {
+		if first == "" {
+			first = string(data[start:end])
+		}
+	})
+	if !ok {
+		return ""
+	}
+	return first
+}
+
+// scanLargeIntegers calls found with the bounds of each integer value in data
+// that is too large for an int64, in order. It returns false if data is not
+// valid JSON.
+func scanLargeIntegers(data []byte, found func(start, end int)) bool {
+	if !hasDigitRun(data, minLargeIntegerDigits) {
+		// skip scanning data that can't have any large integers
+		return true
+	}
+
+	var scan scanner
+	scan.reset()
+
+	// start of the number literal being scanned, or -1
+	start := -1
+	check := func(end int) {
+		if start >= 0 && isLargeInteger(string(data[start:end])) {
+			found(start, end)
+		}
+		start = -1
+	}
+
+	for i, c := range data {
+		inKey := len(scan.parseState) > 0 &&
+			scan.parseState[len(scan.parseState)-1] == parseObjectKey
+		op := scan.step(&scan, int(c))
+		if op == scanError {
+			return false
+		}
+		if op == scanContinue {
+			continue
+		}
+		check(i)
+		if op == scanBeginLiteral && !inKey && (c == '-' || '0' <= c && c <= '9') {
+			start = i
+		}
+	}
+	if scan.eof() == scanError {
+		return false
+	}
+	check(len(data))
+	return true
+}
+
+// hasDigitRun returns true if data has at least n consecutive decimal digits.
+func hasDigitRun(data []byte, n int) bool {
+	run := 0
+	for _, c := range data {
+		if '0' <= c && c <= '9' {
+			run++
+			if run >= n {
+				return true
+			}
+		} else {
+			run = 0
+		}
+	}
+	return false
+}
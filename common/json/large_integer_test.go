@@ -0,0 +1,149 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mustParseDecimal128(s string) Decimal128 {
+	d, err := primitive.ParseDecimal128(s)
+	if err != nil {
+		panic(err)
+	}
+	return Decimal128{d}
+}
+
+func TestPreserveLargeIntegers(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	data := []byte(`{"big": 12345678901234567890, "neg": -98765432109876543210,` +
+		` "max": 9223372036854775807, "small": 42, "float": 12345678901234567890.5,` +
+		` "arr": [18446744073709551616]}`)
+
+	Convey("When unmarshalling integers too large for an int64", t, func() {
+		Convey("they are decoded as float64 by default", func() {
+			doc, err := UnmarshalBsonD(data)
+			So(err, ShouldBeNil)
+			So(doc[0].Value, ShouldEqual, float64(12345678901234567890))
+			So(doc[1].Value, ShouldEqual, float64(-98765432109876543210))
+		})
+
+		Convey("they keep all their digits with PreserveLargeIntegers", func() {
+			doc, err := UnmarshalBsonDWithOptions(
+				data, UnmarshalOptions{PreserveLargeIntegers: true})
+			So(err, ShouldBeNil)
+			So(doc[0].Value, ShouldResemble, mustParseDecimal128("12345678901234567890"))
+			So(doc[0].Value.(Decimal128).String(), ShouldEqual, "12345678901234567890")
+			So(doc[1].Value, ShouldResemble, mustParseDecimal128("-98765432109876543210"))
+			So(doc[2].Value, ShouldEqual, int64(9223372036854775807))
+			So(doc[3].Value, ShouldEqual, int32(42))
+			So(doc[4].Value, ShouldEqual, 12345678901234567890.5)
+			So(doc[5].Value, ShouldResemble,
+				[]interface{}{mustParseDecimal128("18446744073709551616")})
+		})
+
+		Convey("integers with more digits than a Decimal128 holds are an error", func() {
+			_, err := UnmarshalBsonDWithOptions(
+				[]byte(`{"a": 12345678901234567890123456789012345678901}`),
+				UnmarshalOptions{PreserveLargeIntegers: true},
+			)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("a Decoder keeps them with PreserveLargeIntegers", func() {
+			dec := NewDecoder(bytes.NewReader([]byte(`[12345678901234567890, 7]`)))
+			dec.PreserveLargeIntegers()
+			var v interface{}
+			So(dec.Decode(&v), ShouldBeNil)
+			So(v, ShouldResemble,
+				[]interface{}{mustParseDecimal128("12345678901234567890"), int32(7)})
+		})
+
+		Convey("UseNumber takes precedence", func() {
+			dec := NewDecoder(bytes.NewReader([]byte(`12345678901234567890`)))
+			dec.UseNumber()
+			dec.PreserveLargeIntegers()
+			var v interface{}
+			So(dec.Decode(&v), ShouldBeNil)
+			So(v, ShouldEqual, Number("12345678901234567890"))
+		})
+	})
+}
+
+func TestIsLargeInteger(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Only decimal integers outside the int64 range are large", t, func() {
+		So(isLargeInteger("12345678901234567890"), ShouldBeTrue)
+		So(isLargeInteger("-12345678901234567890"), ShouldBeTrue)
+		So(isLargeInteger("9223372036854775808"), ShouldBeTrue)
+		So(isLargeInteger("-9223372036854775809"), ShouldBeTrue)
+		So(isLargeInteger("9223372036854775807"), ShouldBeFalse)
+		So(isLargeInteger("-9223372036854775808"), ShouldBeFalse)
+		So(isLargeInteger("12345678901234567890.0"), ShouldBeFalse)
+		So(isLargeInteger("1e30"), ShouldBeFalse)
+		So(isLargeInteger("0x1234567890abcdef12"), ShouldBeFalse)
+		So(isLargeInteger("-"), ShouldBeFalse)
+	})
+}
+
+func TestLargeIntegersToDecimal128(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When rewriting large integers as $numberDecimal", t, func() {
+		Convey("values too large for an int64 are wrapped", func() {
+			out := LargeIntegersToDecimal128([]byte(
+				`{"a": 12345678901234567890, "b": [1, -98765432109876543210], "c": 2}`))
+			So(string(out), ShouldEqual,
+				`{"a": {"$numberDecimal":"12345678901234567890"}, `+
+					`"b": [1, {"$numberDecimal":"-98765432109876543210"}], "c": 2}`)
+		})
+
+		Convey("a large integer at the end of the data is wrapped", func() {
+			out := LargeIntegersToDecimal128([]byte(`12345678901234567890`))
+			So(string(out), ShouldEqual, `{"$numberDecimal":"12345678901234567890"}`)
+		})
+
+		Convey("strings, floats and small integers are unchanged", func() {
+			data := []byte(`{"12345678901234567890": "12345678901234567890",` +
+				` "f": 12345678901234567890.5, "e": 1e30, "i": 9223372036854775807}`)
+			So(string(LargeIntegersToDecimal128(data)), ShouldEqual, string(data))
+		})
+
+		Convey("invalid JSON is unchanged", func() {
+			data := []byte(`{"a": 12345678901234567890,`)
+			So(string(LargeIntegersToDecimal128(data)), ShouldEqual, string(data))
+		})
+	})
+}
+
+func TestFirstLargeInteger(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When looking for large integers", t, func() {
+		Convey("the first value too large for an int64 is returned", func() {
+			data := []byte(`{"a": 1, "b": [-98765432109876543210, 12345678901234567890]}`)
+			So(FirstLargeInteger(data), ShouldEqual, "-98765432109876543210")
+		})
+
+		Convey("nothing is returned without large integer values", func() {
+			for _, data := range []string{
+				`{"a": 9223372036854775807, "12345678901234567890": "12345678901234567890"}`,
+				`{"f": 12345678901234567890.5}`,
+				`{"a": 12345678901234567890,`,
+			} {
+				So(FirstLargeInteger([]byte(data)), ShouldEqual, "")
+			}
+		})
+	})
+}
@@ -20,6 +20,10 @@ type BinData struct {
 	Base64 string
 }
 
+// BinDataConstructor is BinData that is marshaled in the shell's
+// BinData(subtype, "base64") form instead of as a $binary object.
+type BinDataConstructor BinData
+
 // Represents the number of milliseconds since the Unix epoch.
 type Date int64
 
@@ -82,6 +86,9 @@ type Float float64
 // Represents the literal undefined.
 type Undefined struct{}
 
+// Represents a UUID (binary subtype 4) in canonical hyphenated form.
+type UUID string
+
 var (
 	// primitive types.
 	byteType   = reflect.TypeOf(byte(0))
@@ -90,6 +97,7 @@ var (
 
 	// object types.
 	binDataType     = reflect.TypeOf(BinData{})
+	javaScriptType  = reflect.TypeOf(JavaScript{})
 	dateType        = reflect.TypeOf(Date(0))
 	isoDateType     = reflect.TypeOf(ISODate(""))
 	dbRefType       = reflect.TypeOf(DBRef{})
@@ -105,16 +113,30 @@ var (
 	orderedBSONType = reflect.TypeOf(bson.D{})
 )
 
+// isFormatable reports whether d falls within the years 0001 through 2999,
+// which is the range that can be written as an ISO-8601 date string.
 func (d Date) isFormatable() bool {
-	return int64(d) < int64(32535215999000)
+	return int64(d) >= int64(-62135596800000) && int64(d) < int64(32535215999000)
 }
 
+// shellValuesContext explains a syntax error caused by a mongo shell value
+// when they have been disallowed.
+const shellValuesContext = "mongo shell values such as ObjectId(...), ISODate(...) and " +
+	"NumberLong(...) are only accepted in legacy extended JSON"
+
+// stateBeginExtendedValue is the state at the beginning of a value that isn't
+// JSON, such as ObjectId(...) or /regexp/.
 func stateBeginExtendedValue(s *scanner, c int) int {
+	if s.noShellValues {
+		return s.error(c, "looking for beginning of value; "+shellValuesContext)
+	}
 	switch c {
 	case 'u': // beginning of undefined
 		s.step = stateU
 	case 'B': // beginning of BinData or Boolean
 		s.step = stateB
+	case 'C': // beginning of Code
+		s.step = stateC
 	case 'D': // beginning of Date
 		s.step = stateD
 	case 'I': // beginning of Infinity or ISODate
@@ -239,6 +261,8 @@ func (d *decodeState) storeExtendedLiteral(item []byte, v reflect.Value, fromQuo
 		case 'o': // Boolean
 			d.storeBoolean(v)
 		}
+	case 'C': // Code
+		d.storeCode(v)
 	case 'D': // Date, DBRef, DBPointer, Dbpointer,or Dbref
 		switch item[1] {
 		case 'a': // Date
@@ -337,6 +361,9 @@ func (d *decodeState) getExtendedLiteral(item []byte) (interface{}, bool) {
 			return d.getBoolean(), true
 		}
 
+	case 'C': // Code
+		return d.getCode(), true
+
 	case 'D': // Date, DBRef, or Dbref
 		switch item[1] {
 		case 'a': // Date
@@ -23,7 +23,7 @@ func TestNewKeyword(t *testing.T) {
 			var jsonMap map[string]interface{}
 
 			key := "key"
-			value := `new BinData(1, "xyz")`
+			value := `new BinData(1, "eHl6")`
 			data := fmt.Sprintf(`{"%v":%v}`, key, value)
 
 			err := Unmarshal([]byte(data), &jsonMap)
@@ -31,7 +31,7 @@ func TestNewKeyword(t *testing.T) {
 
 			jsonValue, ok := jsonMap[key].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue, ShouldResemble, BinData{1, "xyz"})
+			So(jsonValue, ShouldResemble, BinData{1, "eHl6"})
 		})
 
 		Convey("can be used with Boolean constructor", func() {
@@ -79,7 +79,7 @@ func TestNewKeyword(t *testing.T) {
 			var jsonMap map[string]interface{}
 
 			key := "key"
-			value := `new BinData(1, "xyz")`
+			value := `new BinData(1, "eHl6")`
 			data := fmt.Sprintf(`{"%v":%v}`, key, value)
 
 			err := Unmarshal([]byte(data), &jsonMap)
@@ -87,7 +87,7 @@ func TestNewKeyword(t *testing.T) {
 
 			jsonValue, ok := jsonMap[key].(BinData)
 			So(ok, ShouldBeTrue)
-			So(jsonValue, ShouldResemble, BinData{1, "xyz"})
+			So(jsonValue, ShouldResemble, BinData{1, "eHl6"})
 		})
 
 		Convey("can be used with NumberInt constructor", func() {
@@ -102,6 +102,9 @@ func stateInRegexpOptions(s *scanner, c int) int {
 	case 'g', 'i', 'm', 's':
 		return scanRegexpOptions
 	}
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		return s.error(c, "in regular expression options (expecting 'g', 'i', 'm', or 's')")
+	}
 	return stateEndValue(s, c)
 }
 
@@ -249,5 +249,68 @@ func TestRegexpLiteral(t *testing.T) {
 			err := Unmarshal([]byte(data), &jsonMap)
 			So(err, ShouldNotBeNil)
 		})
+
+		Convey("can contain escaped forward slashes ('\\/')", func() {
+			var jsonMap map[string]interface{}
+
+			key := "key"
+			value := `/a\/b\/c/i`
+			data := fmt.Sprintf(`{"%v":%v}`, key, value)
+
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+
+			jsonValue, ok := jsonMap[key].(RegExp)
+			So(ok, ShouldBeTrue)
+			So(jsonValue, ShouldResemble, RegExp{"a/b/c", "i"})
+		})
+
+		Convey("cannot be unterminated", func() {
+			var jsonMap map[string]interface{}
+
+			for _, data := range []string{`{"key":/foo}`, `{"key":/foo`, `/foo`} {
+				err := Unmarshal([]byte(data), &jsonMap)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unterminated regular expression literal")
+			}
+		})
+
+		Convey("cannot use unknown options", func() {
+			var jsonMap map[string]interface{}
+
+			data := `{"key":/foo/ix}`
+
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "in regular expression options")
+		})
+	})
+}
+
+func TestRegExpMarshal(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When marshalling RegExp values", t, func() {
+		value := map[string]interface{}{"key": RegExp{"a.c", "i"}}
+
+		Convey("the relaxed form uses $regex and $options", func() {
+			data, err := Marshal(value)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `{"key":{"$regex":"a.c","$options":"i"}}`)
+		})
+
+		Convey("the canonical form uses $regularExpression", func() {
+			data, err := MarshalCanonical(value)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual,
+				`{"key":{"$regularExpression":{"pattern":"a.c","options":"i"}}}`)
+		})
+
+		Convey("empty options are kept in the canonical form", func() {
+			data, err := MarshalCanonical([]interface{}{RegExp{"a.c", ""}})
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual,
+				`[{"$regularExpression":{"pattern":"a.c","options":""}}]`)
+		})
 	})
 }
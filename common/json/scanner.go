@@ -101,6 +101,10 @@ type scanner struct {
 
 	// total bytes consumed, updated by decoder.Decode
 	bytes int64
+
+	// noShellValues makes mongo shell values, such as ObjectId(...) and
+	// new Date(...), a syntax error.
+	noShellValues bool
 }
 
 // These values are returned by the state transition functions
@@ -163,12 +167,16 @@ func (s *scanner) eof() int {
 	if s.endTop {
 		return scanEnd
 	}
-	s.step(s, ' ')
+	op := s.step(s, ' ')
 	if s.endTop {
 		return scanEnd
 	}
 	if s.err == nil {
-		s.err = &SyntaxError{"unexpected end of JSON input", s.bytes}
+		if op == scanRegexpPattern {
+			s.err = &SyntaxError{"unterminated regular expression literal", s.bytes}
+		} else {
+			s.err = &SyntaxError{"unexpected end of JSON input", s.bytes}
+		}
 	}
 	return scanError
 }
@@ -597,6 +605,9 @@ func stateFals(s *scanner, c int) int {
 
 // stateN is the state after reading `n`.
 func stateN(s *scanner, c int) int {
+	if c == 'e' && s.noShellValues {
+		return s.error(c, "in literal null (expecting 'u'); "+shellValuesContext)
+	}
 	if c == 'e' {
 		s.step = stateNe
 		return scanContinue
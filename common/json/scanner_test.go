@@ -11,9 +11,11 @@ package json
 
 import (
 	"bytes"
+	"io"
 	"math"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -340,3 +342,49 @@ func genMap(n int) map[string]interface{} {
 	}
 	return x
 }
+
+func TestScannerNoShellValues(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	decodeAll := func(input string, noShellValues bool) error {
+		dec := NewDecoder(strings.NewReader(input))
+		if noShellValues {
+			dec.DisallowShellValues()
+		}
+		for {
+			_, err := dec.ScanObject()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	plain := `{"a": 1, "b": {"$oid": "5a934e000102030405000000"}, "c": [true, null, "x"]}`
+	if err := decodeAll(plain, true); err != nil {
+		t.Fatalf("JSON should be accepted without shell values: %v", err)
+	}
+
+	for _, shell := range []string{
+		`{"_id": ObjectId("5a934e000102030405000000")}`,
+		`{"d": ISODate("2020-01-01T00:00:00Z")}`,
+		`{"n": NumberLong(5)}`,
+		`{"d": new Date(0)}`,
+		`{"r": /abc/i}`,
+		`{"u": undefined}`,
+	} {
+		if err := decodeAll(shell, false); err != nil {
+			t.Errorf("%v should be accepted by default: %v", shell, err)
+		}
+		err := decodeAll(shell, true)
+		if err == nil {
+			t.Errorf("%v should be rejected without shell values", shell)
+			continue
+		}
+		if !strings.Contains(err.Error(), "only accepted in legacy extended JSON") {
+			t.Errorf("unexpected error for %v: %v", shell, err)
+		}
+	}
+}
@@ -88,7 +88,7 @@ func TestSingleQuotedValues(t *testing.T) {
 			var jsonMap map[string]interface{}
 
 			key := "bindata"
-			value := "BinData(1, 'xyz')"
+			value := "BinData(1, 'eHl6')"
 			data := fmt.Sprintf(`{"%v":%v}`, key, value)
 
 			err := Unmarshal([]byte(data), &jsonMap)
@@ -97,7 +97,7 @@ func TestSingleQuotedValues(t *testing.T) {
 			jsonValue, ok := jsonMap[key].(BinData)
 			So(ok, ShouldBeTrue)
 			So(jsonValue.Type, ShouldEqual, 1)
-			So(jsonValue.Base64, ShouldEqual, "xyz")
+			So(jsonValue.Base64, ShouldEqual, "eHl6")
 		})
 
 		Convey("can be used within Boolean constructor", func() {
@@ -36,6 +36,20 @@ func NewDecoder(r io.Reader) *Decoder {
 // Number instead of as a float64.
 func (dec *Decoder) UseNumber() { dec.d.useNumber = true }
 
+// PreserveLargeIntegers causes the Decoder to unmarshal an integer too large
+// for an int64 into an interface{} as a Decimal128 instead of as a float64,
+// which would lose some of its digits. UseNumber takes precedence over it.
+func (dec *Decoder) PreserveLargeIntegers() { dec.d.largeIntegers = true }
+
+// DisallowShellValues causes the Decoder to return a SyntaxError for mongo
+// shell values, such as ObjectId(...), ISODate(...) and /regexp/, so that only
+// JSON is accepted.
+func (dec *Decoder) DisallowShellValues() { dec.scan.noShellValues = true }
+
+// DisallowDuplicateKeys causes the Decoder to return a DuplicateKeyError when
+// an object has the same key more than once, instead of keeping the last value.
+func (dec *Decoder) DisallowDuplicateKeys() { dec.d.strictKeys = true }
+
 // Decode reads the next JSON-encoded value from its
 // input and stores it in the value pointed to by v.
 //
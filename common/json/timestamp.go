@@ -61,11 +61,17 @@ func (d *decodeState) getTimestamp() interface{} {
 	}
 	arg0, err := args[0].(Number).Uint32()
 	if err != nil {
-		d.error(fmt.Errorf("expected uint32 for first argument of Timestamp constructor"))
+		d.error(fmt.Errorf(
+			"expected non-negative 32-bit integer for first argument of Timestamp constructor, got %v",
+			args[0],
+		))
 	}
 	arg1, err := args[1].(Number).Uint32()
 	if err != nil {
-		d.error(fmt.Errorf("expected uint32 for second argument of Timestamp constructor"))
+		d.error(fmt.Errorf(
+			"expected non-negative 32-bit integer for second argument of Timestamp constructor, got %v",
+			args[1],
+		))
 	}
 
 	d.useNumber = useNumber
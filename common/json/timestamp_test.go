@@ -8,6 +8,7 @@ package json
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -80,6 +81,34 @@ func TestTimestampValue(t *testing.T) {
 			}
 		})
 
+		Convey("works for the all-ones Timestamp and round trips", func() {
+			var jsonMap map[string]interface{}
+
+			data := `{"ts":Timestamp(4294967295, 4294967295)}`
+			err := Unmarshal([]byte(data), &jsonMap)
+			So(err, ShouldBeNil)
+			So(jsonMap["ts"], ShouldResemble, Timestamp{math.MaxUint32, math.MaxUint32})
+
+			marshaled, err := Marshal(jsonMap)
+			So(err, ShouldBeNil)
+			So(string(marshaled), ShouldEqual,
+				`{"ts":{"$timestamp":{"t":4294967295,"i":4294967295}}}`)
+		})
+
+		Convey("cannot use negative or out of range arguments", func() {
+			for _, value := range []string{
+				"Timestamp(-1, 1)",
+				"Timestamp(1, -1)",
+				"Timestamp(4294967296, 1)",
+				"Timestamp(1, 4294967296)",
+				"Timestamp(1.5, 1)",
+			} {
+				var jsonMap map[string]interface{}
+				err := Unmarshal([]byte(fmt.Sprintf(`{"key":%v}`, value)), &jsonMap)
+				So(err, ShouldNotBeNil)
+			}
+		})
+
 		Convey("cannot use string as argument", func() {
 			var jsonMap map[string]interface{}
 
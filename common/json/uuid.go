@@ -0,0 +1,67 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+import "fmt"
+
+// uuidHyphens holds the offsets of the hyphens in a canonical UUID string.
+var uuidHyphens = [...]int{8, 13, 18, 23}
+
+// ParseUUID decodes a UUID in canonical hyphenated form
+// (e.g. "73ffd264-44b3-4c69-90e8-e7d1dfc035d4") into its 16 raw bytes.
+func ParseUUID(s string) ([]byte, error) {
+	if len(s) != 36 {
+		return nil, fmt.Errorf(
+			"invalid UUID %q: expected 36 characters but found %v", s, len(s))
+	}
+
+	data := make([]byte, 0, 16)
+	next := 0
+	for i := 0; i < len(s); i += 2 {
+		if next < len(uuidHyphens) && i == uuidHyphens[next] {
+			if s[i] != '-' {
+				return nil, fmt.Errorf(
+					"invalid UUID %q: expected '-' at position %v", s, i)
+			}
+			next++
+			i--
+			continue
+		}
+		hi, lo := unhex(s[i]), unhex(s[i+1])
+		if hi < 0 || lo < 0 {
+			return nil, fmt.Errorf("invalid UUID %q: invalid hex digit near position %v", s, i)
+		}
+		data = append(data, byte(hi<<4|lo))
+	}
+	return data, nil
+}
+
+// unhex returns the value of a hex digit, or -1 if c is not one.
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
+
+// FormatUUID returns the canonical hyphenated form of 16 raw UUID bytes.
+func FormatUUID(data []byte) (UUID, error) {
+	if len(data) != 16 {
+		return "", fmt.Errorf("invalid UUID: expected 16 bytes but found %v", len(data))
+	}
+	buf := make([]byte, 0, 32)
+	for _, b := range data {
+		buf = append(buf, hex[b>>4], hex[b&0xF])
+	}
+	s := string(buf)
+	return UUID(s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]), nil
+}
@@ -0,0 +1,62 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package json
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUUIDValue(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When parsing and formatting UUIDs", t, func() {
+		canonical := "73ffd264-44b3-4c69-90e8-e7d1dfc035d4"
+		raw := []byte{
+			0x73, 0xff, 0xd2, 0x64, 0x44, 0xb3, 0x4c, 0x69,
+			0x90, 0xe8, 0xe7, 0xd1, 0xdf, 0xc0, 0x35, 0xd4,
+		}
+
+		Convey("a canonical UUID string round-trips", func() {
+			data, err := ParseUUID(canonical)
+			So(err, ShouldBeNil)
+			So(data, ShouldResemble, raw)
+
+			uuid, err := FormatUUID(data)
+			So(err, ShouldBeNil)
+			So(uuid, ShouldEqual, UUID(canonical))
+		})
+
+		Convey("uppercase hex digits are accepted", func() {
+			data, err := ParseUUID("73FFD264-44B3-4C69-90E8-E7D1DFC035D4")
+			So(err, ShouldBeNil)
+			So(data, ShouldResemble, raw)
+		})
+
+		Convey("malformed UUIDs are rejected", func() {
+			for _, bad := range []string{
+				"",
+				"73ffd26444b34c6990e8e7d1dfc035d4",
+				"73ffd264-44b3-4c69-90e8-e7d1dfc035d",
+				"73ffd264_44b3-4c69-90e8-e7d1dfc035d4",
+				"73ffd264-44b3-4c69-90e8-e7d1dfc035zz",
+			} {
+				_, err := ParseUUID(bad)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid UUID")
+			}
+		})
+
+		Convey("marshals as a $uuid document", func() {
+			data, err := Marshal(UUID(canonical))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `{"$uuid":"`+canonical+`"}`)
+		})
+	})
+}
@@ -179,13 +179,17 @@ func processReplacement(from, to string) (re *regexp.Regexp, replacer string, er
 }
 
 // NewRenamer creates a Renamer that will use the given from and to slices to
-// map namespaces.
+// map namespaces. When several patterns match a namespace, the last one given
+// takes precedence. Patterns that match exactly the same namespaces but rename
+// them differently are ambiguous and rejected.
 func NewRenamer(fromSlice, toSlice []string) (r *Renamer, err error) {
 	if len(fromSlice) != len(toSlice) {
 		err = fmt.Errorf("Different number of froms and tos")
 		return
 	}
 	r = new(Renamer)
+	// index into fromSlice of the rule already seen for each generated regexp
+	seen := make(map[string]int)
 	for i := len(fromSlice) - 1; i >= 0; i-- {
 		// reversed for replacement precedence
 		from := fromSlice[i]
@@ -199,6 +203,17 @@ func NewRenamer(fromSlice, toSlice []string) (r *Renamer, err error) {
 			err = fmt.Errorf("Invalid replacement from '%s' to '%s': %s", from, to, e)
 			return
 		}
+		if j, ok := seen[matcher.String()]; ok {
+			// rules are added in reverse, so rule j is at len(fromSlice)-1-j
+			if r.replacers[len(fromSlice)-1-j] != replacer {
+				err = fmt.Errorf(
+					"Ambiguous replacements from '%s' to '%s' and from '%s' to '%s'",
+					from, to, fromSlice[j], toSlice[j],
+				)
+				return
+			}
+		}
+		seen[matcher.String()] = i
 		r.matchers = append(r.matchers, matcher)
 		r.replacers = append(r.replacers, replacer)
 	}
@@ -88,6 +88,52 @@ func TestReplacer(t *testing.T) {
 			So(r.Get("ÿœz.tāx"), ShouldEqual, "yes.tax")
 			So(r.Get("normal.characters"), ShouldEqual, "special.charâctęrs")
 		})
+		Convey("'prod.*' -> 'staging.*' with dotted collection names", func() {
+			r, err := NewRenamer([]string{"prod.*"}, []string{"staging.*"})
+			So(err, ShouldBeNil)
+			So(r.Get("prod.users"), ShouldEqual, "staging.users")
+			So(r.Get("prod.system.profile"), ShouldEqual, "staging.system.profile")
+			So(r.Get("prod.a.b.c"), ShouldEqual, "staging.a.b.c")
+			So(r.Get("prod..leading"), ShouldEqual, "staging..leading")
+			So(r.Get("production.users"), ShouldEqual, "production.users")
+			So(r.Get("other.prod.users"), ShouldEqual, "other.prod.users")
+		})
+		Convey("the last matching rule takes precedence", func() {
+			r, err := NewRenamer(
+				[]string{"prod.*", "prod.audit.*"},
+				[]string{"staging.*", "archive.audit.*"},
+			)
+			So(err, ShouldBeNil)
+			So(r.Get("prod.audit.2024.q1"), ShouldEqual, "archive.audit.2024.q1")
+			So(r.Get("prod.auditors"), ShouldEqual, "staging.auditors")
+
+			r, err = NewRenamer(
+				[]string{"prod.audit.*", "prod.*"},
+				[]string{"archive.audit.*", "staging.*"},
+			)
+			So(err, ShouldBeNil)
+			So(r.Get("prod.audit.2024.q1"), ShouldEqual, "staging.audit.2024.q1")
+		})
+		Convey("repeating an identical rule is allowed", func() {
+			r, err := NewRenamer(
+				[]string{"prod.*", "prod.$coll$"},
+				[]string{"staging.*", "staging.$coll$"},
+			)
+			So(err, ShouldBeNil)
+			So(r.Get("prod.a.b"), ShouldEqual, "staging.a.b")
+		})
+	})
+	Convey("with ambiguous replacements", t, func() {
+		Convey("the same pattern renamed to different namespaces is rejected", func() {
+			_, err := NewRenamer(
+				[]string{"prod.*", "test.*", "prod.$coll$"},
+				[]string{"staging.*", "qa.*", "backup.$coll$"},
+			)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Ambiguous")
+			So(err.Error(), ShouldContainSubstring, "'prod.*' to 'staging.*'")
+			So(err.Error(), ShouldContainSubstring, "'prod.$coll$' to 'backup.$coll$'")
+		})
 	})
 	Convey("with invalid replacements", t, func() {
 		Convey("'$db$.user$db$' -> 'test.user-$db$'", func() {
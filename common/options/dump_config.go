@@ -0,0 +1,159 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/util"
+)
+
+// Sources of option values, as printed by --dumpConfig.
+const (
+	sourceDefault          = "default"
+	sourceCommandLine      = "command line"
+	sourceConfigFile       = "config file"
+	sourceConnectionString = "connection string"
+	// sourceDerived is for values the tool worked out from other options.
+	sourceDerived = "derived"
+)
+
+// configValue is the resolved value of an option and where it came from.
+type configValue struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// forEachOption calls fn for every option of the parser, in every group.
+func (opts *ToolOptions) forEachOption(fn func(*flags.Option)) {
+	var walk func(groups []*flags.Group)
+	walk = func(groups []*flags.Group) {
+		for _, group := range groups {
+			for _, option := range group.Options() {
+				fn(option)
+			}
+			walk(group.Groups())
+		}
+	}
+	walk(opts.parser.Groups())
+}
+
+// recordSource records where the value of the option with the given long name
+// came from, unless that is already known.
+func (opts *ToolOptions) recordSource(name, source string) {
+	if opts.optionSources == nil {
+		opts.optionSources = map[string]string{}
+	}
+	if _, ok := opts.optionSources[name]; !ok {
+		opts.optionSources[name] = source
+	}
+}
+
+// recordCommandLineSources records the options set by the command line. It
+// must be called right after the command line is parsed, before the options
+// in a config file are applied.
+func (opts *ToolOptions) recordCommandLineSources() {
+	opts.forEachOption(func(option *flags.Option) {
+		if option.IsSet() && !option.IsSetDefault() {
+			opts.recordSource(option.LongName, sourceCommandLine)
+		}
+	})
+}
+
+// optionValues returns the current value of every option, formatted so that
+// changes to them can be found.
+func (opts *ToolOptions) optionValues() map[string]string {
+	values := map[string]string{}
+	opts.forEachOption(func(option *flags.Option) {
+		if value, ok := opts.optionValue(option); ok {
+			values[option.LongName] = fmt.Sprintf("%#v", value)
+		}
+	})
+	return values
+}
+
+// recordChangedSources records the given source for every option whose value
+// changed since the given values were taken, and isn't known to come from
+// anywhere else.
+func (opts *ToolOptions) recordChangedSources(values map[string]string, source string) {
+	for name, value := range opts.optionValues() {
+		if value != values[name] {
+			opts.recordSource(name, source)
+		}
+	}
+}
+
+// optionValue returns the value of an option. The verbosity option sets the
+// verbosity level through a function, so the level is returned for it.
+func (opts *ToolOptions) optionValue(option *flags.Option) (interface{}, bool) {
+	if option.LongName == "verbose" && opts.Verbosity != nil {
+		return opts.VLevel, true
+	}
+	if option.Field().Type.Kind() == reflect.Func {
+		return nil, false
+	}
+	return option.Value(), true
+}
+
+// resolvedConfig returns the value of each visible option, keyed by its long
+// name, along with where it came from: the command line, a config file, the
+// connection string, the tool itself or the default. Passwords are masked.
+func (opts *ToolOptions) resolvedConfig() map[string]configValue {
+	config := map[string]configValue{}
+	opts.forEachOption(func(option *flags.Option) {
+		if option.Hidden || option.LongName == "" {
+			return
+		}
+		value, ok := opts.optionValue(option)
+		if !ok {
+			return
+		}
+
+		source, ok := opts.optionSources[option.LongName]
+		if !ok {
+			source = sourceDefault
+			parsed, ok := opts.parsedValues[option.LongName]
+			if ok && parsed != fmt.Sprintf("%#v", value) {
+				source = sourceDerived
+			}
+		}
+
+		if s, ok := value.(string); ok && s != "" {
+			if option.LongName == "uri" {
+				value = util.SanitizeURI(s)
+			} else if sensitiveConfigKeys[option.LongName] {
+				value = "<redacted>"
+			}
+		}
+		config[option.LongName] = configValue{Value: value, Source: source}
+	})
+	return config
+}
+
+// PrintConfig prints the resolved option values to stdout as JSON, if the
+// dumpConfig flag is specified. Returns whether or not it is specified.
+func (opts *ToolOptions) PrintConfig() bool {
+	if !opts.dumpingConfig() {
+		return false
+	}
+	data, err := json.MarshalIndent(opts.resolvedConfig(), "", "  ")
+	if err != nil {
+		log.Logvf(log.Always, "error printing the configuration: %v", err)
+		return true
+	}
+	fmt.Println(string(data))
+	return true
+}
+
+// dumpingConfig returns true if the dumpConfig flag is specified.
+func (opts *ToolOptions) dumpingConfig() bool {
+	return opts.General != nil && opts.DumpConfig
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v2"
 )
 
@@ -100,6 +102,12 @@ type ToolOptions struct {
 
 	// Will attempt to parse positional arguments as connection strings if true
 	parsePositionalArgsAsURI bool
+
+	// where the value of each option set by the user came from, by long name
+	optionSources map[string]string
+	// the value of each option once the args are parsed, for --dumpConfig to
+	// find the values a tool changed afterwards
+	parsedValues map[string]string
 }
 
 type Namespace struct {
@@ -116,7 +124,9 @@ func (ns Namespace) String() string {
 type General struct {
 	Help       bool   `long:"help" description:"print usage"`
 	Version    bool   `long:"version" description:"print the tool version and exit"`
-	ConfigPath string `long:"config" description:"path to a configuration file"`
+	ConfigPath string `long:"config" value-name:"<file-path>" description:"path to a YAML or JSON configuration file of option values, keyed by their long names; options on the command line override the file"`
+
+	DumpConfig bool `long:"dumpConfig" description:"print the resolved value of each option as JSON, with where it came from (command line, config file, connection string, derived or default) and passwords masked, and exit"`
 
 	MaxProcs   int    `long:"numThreads" hidden:"true"`
 	Failpoints string `long:"failpoints" hidden:"true"`
@@ -154,14 +164,16 @@ type Connection struct {
 	Timeout                int    `long:"dialTimeout" default:"3" hidden:"true" description:"dial timeout in seconds"`
 	SocketTimeout          int    `long:"socketTimeout" default:"0" hidden:"true" description:"socket timeout in seconds (0 for no timeout)"`
 	TCPKeepAliveSeconds    int    `long:"TCPKeepAliveSeconds" default:"30" hidden:"true" description:"seconds between TCP keep alives"`
-	ServerSelectionTimeout int    `long:"serverSelectionTimeout" hidden:"true" description:"seconds to wait for server selection; 0 means driver default"`
-	Compressors            string `long:"compressors" default:"none" hidden:"true" value-name:"<snappy,...>" description:"comma-separated list of compressors to enable. Use 'none' to disable."`
+	ServerSelectionTimeout int    `long:"serverSelectionTimeout" value-name:"<seconds>" description:"seconds to wait for a server matching the read preference to be available before failing, separate from the timeout of each connection attempt; on failure, the servers found so far and their state are reported (0 for the default of 30 seconds)"`
+	Compressors            string `long:"compressors" default:"none" value-name:"<snappy,...>" description:"comma-separated list of wire compressors to negotiate with the server, in order of preference: snappy, zlib or zstd. Use 'none' to disable."`
+	RetryTimeout           int    `long:"retryTimeout" value-name:"<seconds>" default:"0" description:"seconds to keep retrying the connection and read-only commands that fail with transient errors, e.g. during a replica set election (0 disables retries)"`
 }
 
 // Struct holding ssl-related options.
 type SSL struct {
 	UseSSL              bool   `long:"ssl" description:"connect to a mongod or mongos that has ssl enabled"`
 	SSLCAFile           string `long:"sslCAFile" value-name:"<filename>" description:"the .pem file containing the root certificate chain from the certificate authority"`
+	SSLCAPath           string `long:"sslCAPath" value-name:"<directory>" description:"a directory of .pem files containing root certificates from the certificate authority; may be combined with --sslCAFile"`
 	SSLPEMKeyFile       string `long:"sslPEMKeyFile" value-name:"<filename>" description:"the .pem file containing the certificate and key"`
 	SSLPEMKeyPassword   string `long:"sslPEMKeyPassword" value-name:"<password>" description:"the password to decrypt the sslPEMKeyFile, if necessary"`
 	SSLCRLFile          string `long:"sslCRLFile" value-name:"<filename>" description:"the .pem file containing the certificate revocation list"`
@@ -372,7 +384,7 @@ type URISetter interface {
 
 func (auth *Auth) RequiresExternalDB() bool {
 	return auth.Mechanism == "GSSAPI" || auth.Mechanism == "PLAIN" ||
-		auth.Mechanism == "MONGODB-X509"
+		auth.Mechanism == "MONGODB-X509" || auth.Mechanism == "MONGODB-AWS"
 }
 
 func (auth *Auth) IsSet() bool {
@@ -518,13 +530,22 @@ func (opts *ToolOptions) ParseArgs(args []string) ([]string, error) {
 			return []string{}, err
 		}
 	}
+	// a connection string given as a positional argument is on the command line too
+	uriSource := sourceDerived
+	if opts.URI != nil && opts.URI.ConnectionString != "" {
+		opts.recordSource("uri", sourceCommandLine)
+		uriSource = sourceConnectionString
+	}
 
 	failpoint.ParseFailpoints(opts.Failpoints)
 
+	values := opts.optionValues()
 	err = opts.NormalizeOptionsAndURI()
 	if err != nil {
 		return []string{}, err
 	}
+	opts.recordChangedSources(values, uriSource)
+	opts.parsedValues = opts.optionValues()
 
 	return args, err
 }
@@ -579,10 +600,22 @@ func LogSensitiveOptionWarnings(args []string) {
 	}
 }
 
+// sensitiveConfigKeys are the config file keys whose values are never logged.
+var sensitiveConfigKeys = map[string]bool{
+	"password":            true,
+	"uri":                 true,
+	"sslPEMKeyPassword":   true,
+	"destinationPassword": true,
+	"awsSessionToken":     true,
+}
+
 // ParseConfigFile iterates over args to find a --config option. If not found, we return.
-// If found, we read the contents of the specified config file in YAML format. We parse
-// any values corresponding to --password, --uri and --sslPEMKeyPassword, and store them
-// in the opts.
+// If found, we read the contents of the specified config file in YAML format, which
+// also accepts JSON. Each key in the file is the long name of an option, e.g.
+// "password" or "numParallelCollections", and its value is applied as if it had been
+// given on the command line. Unknown keys are ignored with a warning, and so are
+// keys for options that are set on the command line, which override any values
+// from the file. The command line args are parsed again afterwards by ParseArgs.
 // This also applies to --destinationPassword for mongomirror only.
 func (opts *ToolOptions) ParseConfigFile(args []string) error {
 	// Get config file path from the arguments, if specified.
@@ -590,6 +623,7 @@ func (opts *ToolOptions) ParseConfigFile(args []string) error {
 	if err != nil {
 		return err
 	}
+	opts.recordCommandLineSources()
 
 	// No --config option was specified.
 	if opts.General.ConfigPath == "" {
@@ -597,39 +631,138 @@ func (opts *ToolOptions) ParseConfigFile(args []string) error {
 	}
 
 	// --config option specifies a file path.
-	configBytes, err := os.ReadFile(opts.General.ConfigPath)
+	configPath := opts.General.ConfigPath
+	configBytes, err := os.ReadFile(configPath)
 	if err != nil {
 		return errors.Wrapf(err, "error opening file with --config")
 	}
 
-	// Unmarshal the config file as a top-level YAML file.
-	var config struct {
-		Password            string `yaml:"password"`
-		ConnectionString    string `yaml:"uri"`
-		SSLPEMKeyPassword   string `yaml:"sslPEMKeyPassword"`
-		DestinationPassword string `yaml:"destinationPassword"`
-	}
+	// Unmarshal the config file as a top-level YAML file, keeping the order of its keys.
+	var config yaml.MapSlice
 	err = yaml.UnmarshalStrict(configBytes, &config)
 	if err != nil {
-		return errors.Wrapf(err, "error parsing config file %s", opts.General.ConfigPath)
+		return errors.Wrapf(err, "error parsing config file %s", configPath)
 	}
 
-	// Assign each parsed value to its respective ToolOptions field.
-	opts.Auth.Password = config.Password
-	opts.URI.ConnectionString = config.ConnectionString
-	opts.SSL.SSLPEMKeyPassword = config.SSLPEMKeyPassword
+	var configArgs []string
+	seen := map[string]bool{}
+	for _, item := range config {
+		key, ok := item.Key.(string)
+		if !ok {
+			return fmt.Errorf("error parsing config file %s: key %v is not a string",
+				configPath, item.Key)
+		}
+		if seen[key] {
+			return fmt.Errorf("error parsing config file %s: %v is set more than once",
+				configPath, key)
+		}
+		seen[key] = true
+		logValue := item.Value
+		if sensitiveConfigKeys[key] {
+			logValue = "<redacted>"
+		}
+
+		// Mongomirror has an extra option to set.
+		if key == "destinationPassword" {
+			value, ok := item.Value.(string)
+			if !ok {
+				return fmt.Errorf("error parsing config file %s: %v must be a string",
+					configPath, key)
+			}
+			for _, extraOpt := range opts.URI.extraOptionsRegistry {
+				if destinationAuth, ok := extraOpt.(DestinationAuthOptions); ok {
+					destinationAuth.SetDestinationPassword(value)
+					break
+				}
+			}
+			continue
+		}
 
-	// Mongomirror has an extra option to set.
-	for _, extraOpt := range opts.URI.extraOptionsRegistry {
-		if destinationAuth, ok := extraOpt.(DestinationAuthOptions); ok {
-			destinationAuth.SetDestinationPassword(config.DestinationPassword)
-			break
+		option := opts.parser.FindOptionByLongName(key)
+		if option == nil {
+			log.Logvf(log.Always, "WARNING: ignoring unknown option '%v' in config file %v",
+				key, configPath)
+			continue
+		}
+		if key == "config" {
+			return fmt.Errorf("error parsing config file %s: config cannot be set in a config file",
+				configPath)
 		}
+		if opts.optionSources[option.LongName] == sourceCommandLine {
+			// applying the file's value too would add to lists and counters
+			// rather than replace it
+			log.Logvf(log.DebugLow, "not setting %v from config file, it is set on the command line",
+				key)
+			continue
+		}
+		optionArgs, err := configOptionArgs(option, item.Value)
+		if err != nil {
+			return fmt.Errorf("error parsing config file %s: %v", configPath, err)
+		}
+		log.Logvf(log.DebugLow, "setting %v from config file to %v", key, logValue)
+		configArgs = append(configArgs, optionArgs...)
+		opts.recordSource(option.LongName, sourceConfigFile)
 	}
 
+	_, err = opts.CallArgParser(configArgs)
+	if err != nil {
+		return errors.Wrapf(err, "error applying config file %s", configPath)
+	}
 	return nil
 }
 
+// configOptionArgs returns the command line args that set option to a value
+// read from a config file. A list sets the option once for each element, and
+// a boolean option is only set by the value true.
+func configOptionArgs(option *flags.Option, value interface{}) ([]string, error) {
+	flag := "--" + option.LongName
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("%v has no value", option.LongName)
+	case []interface{}:
+		var args []string
+		for _, elem := range v {
+			elemArgs, err := configOptionArgs(option, elem)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, elemArgs...)
+		}
+		return args, nil
+	case yaml.MapSlice, map[interface{}]interface{}:
+		return nil, fmt.Errorf("%v must be a single value or a list", option.LongName)
+	}
+
+	if isBoolOption(option) {
+		enabled, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v must be true or false", option.LongName)
+		}
+		if !enabled {
+			return nil, nil
+		}
+		return []string{flag}, nil
+	}
+	return []string{fmt.Sprintf("%v=%v", flag, value)}, nil
+}
+
+// isBoolOption returns true if option is a switch that doesn't take a value.
+func isBoolOption(option *flags.Option) bool {
+	tp := option.Field().Type
+	for {
+		switch tp.Kind() {
+		case reflect.Slice, reflect.Ptr:
+			tp = tp.Elem()
+		case reflect.Bool:
+			return true
+		case reflect.Func:
+			return tp.NumIn() == 0
+		default:
+			return false
+		}
+	}
+}
+
 func (opts *ToolOptions) setURIFromPositionalArg(args []string) ([]string, error) {
 	newArgs := []string{}
 	var foundURI bool
@@ -693,8 +826,9 @@ func (opts *ToolOptions) NormalizeOptionsAndURI() error {
 		return err
 	}
 
-	// finalize auth options, filling in missing passwords
-	if opts.Auth.ShouldAskForPassword() {
+	// finalize auth options, filling in missing passwords, which --dumpConfig
+	// doesn't need
+	if !opts.dumpingConfig() && opts.Auth.ShouldAskForPassword() {
 		pass, err := password.Prompt("mongo user")
 		if err != nil {
 			return fmt.Errorf("error reading password: %v", err)
@@ -707,7 +841,7 @@ func (opts *ToolOptions) NormalizeOptionsAndURI() error {
 	if err != nil {
 		return fmt.Errorf("error determining whether client cert needs password: %v", err)
 	}
-	if shouldAskForSSLPassword {
+	if !opts.dumpingConfig() && shouldAskForSSLPassword {
 		pass, err := password.Prompt("client certificate")
 		if err != nil {
 			return fmt.Errorf("error reading password: %v", err)
@@ -730,6 +864,32 @@ func (opts *ToolOptions) NormalizeOptionsAndURI() error {
 	return nil
 }
 
+// SupportedCompressors are the wire compressors that can be negotiated with the
+// server, in the order they are listed in errors.
+var SupportedCompressors = []string{"snappy", "zlib", "zstd"}
+
+// validateCompressors returns an error if a compressor is not supported, or if
+// "none" is combined with any other compressor. An empty list disables
+// compression just as "none" does.
+func validateCompressors(compressors []string) error {
+	if len(compressors) == 1 && (compressors[0] == "none" || compressors[0] == "") {
+		return nil
+	}
+	for _, compressor := range compressors {
+		if compressor == "none" {
+			return fmt.Errorf("compressor 'none' cannot be combined with other compressors")
+		}
+		if !slices.Contains(SupportedCompressors, compressor) {
+			return fmt.Errorf(
+				"unsupported compressor '%v', must be one of: %v",
+				compressor,
+				strings.Join(SupportedCompressors, ", "),
+			)
+		}
+	}
+	return nil
+}
+
 func (opts *ToolOptions) handleUnknownOption(
 	option string,
 	arg flags.SplitArgument,
@@ -928,9 +1088,13 @@ func (opts *ToolOptions) setOptionsFromURI(cs *connstring.ConnString) error {
 					"--compressors",
 				)
 			}
+			opts.Connection.Compressors = strings.Join(cs.Compressors, ",")
 		} else {
 			cs.Compressors = strings.Split(opts.Connection.Compressors, ",")
 		}
+		if err := validateCompressors(cs.Compressors); err != nil {
+			return err
+		}
 	}
 
 	if opts.enabledOptions.Auth {
@@ -1199,6 +1363,44 @@ func (opts *ToolOptions) setOptionsFromURI(cs *connstring.ConnString) error {
 		if opts.Kerberos.Service == "" && cs.AuthMechanismPropertiesSet {
 			opts.Kerberos.Service = gssapiServiceName
 		}
+
+		gssapiHostName, hostNameSet := cs.AuthMechanismProperties["SERVICE_HOST"]
+
+		if opts.Kerberos.ServiceHost != "" && hostNameSet {
+			if opts.Kerberos.ServiceHost != gssapiHostName {
+				return ConflictingArgsErrorFormat(
+					"Kerberos host name",
+					gssapiHostName,
+					opts.Kerberos.ServiceHost,
+					"--gssapiHostName",
+				)
+			}
+		}
+		if opts.Kerberos.ServiceHost != "" && !hostNameSet {
+			if cs.AuthMechanismProperties == nil {
+				cs.AuthMechanismProperties = make(map[string]string)
+			}
+			cs.AuthMechanismProperties["SERVICE_HOST"] = opts.Kerberos.ServiceHost
+			cs.AuthMechanismPropertiesSet = true
+		}
+		if opts.Kerberos.ServiceHost == "" && hostNameSet {
+			if gssapiHostName == "" {
+				return fmt.Errorf("Kerberos host name (SERVICE_HOST) cannot be empty")
+			}
+			opts.Kerberos.ServiceHost = gssapiHostName
+		}
+
+		if opts.Kerberos.Service != "" && strings.TrimSpace(opts.Kerberos.Service) == "" {
+			return fmt.Errorf("--gssapiServiceName cannot be empty")
+		}
+		if opts.Kerberos.ServiceHost != "" && strings.TrimSpace(opts.Kerberos.ServiceHost) == "" {
+			return fmt.Errorf("--gssapiHostName cannot be empty")
+		}
+	} else if opts.Kerberos != nil && (opts.Kerberos.Service != "" || opts.Kerberos.ServiceHost != "") {
+		return fmt.Errorf(
+			"--gssapiServiceName and --gssapiHostName can only be used with " +
+				"--authenticationMechanism=GSSAPI",
+		)
 	}
 
 	if strings.ToLower(cs.AuthMechanism) == "mongodb-aws" {
@@ -520,6 +520,176 @@ func TestParseAndSetOptions(t *testing.T) {
 			},
 			ShouldError: false,
 		},
+		{
+			Name: "gssapi host name from the command line",
+			CS: &connstring.ConnString{
+				AuthMechanism: "GSSAPI",
+			},
+			WithGSSAPI: true,
+			OptsIn: &ToolOptions{
+				General:    &General{},
+				Verbosity:  &Verbosity{},
+				Connection: &Connection{},
+				URI:        &URI{},
+				SSL:        &SSL{},
+				Auth:       &Auth{},
+				Namespace:  &Namespace{},
+				Kerberos: &Kerberos{
+					Service:     "service",
+					ServiceHost: "principal.example.com",
+				},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			OptsExpected: &ToolOptions{
+				General:    &General{},
+				Verbosity:  &Verbosity{},
+				Connection: &Connection{},
+				URI:        &URI{},
+				SSL:        &SSL{},
+				Auth:       &Auth{Mechanism: "GSSAPI"},
+				Namespace:  &Namespace{},
+				Kerberos: &Kerberos{
+					Service:     "service",
+					ServiceHost: "principal.example.com",
+				},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			ShouldError: false,
+		},
+		{
+			Name: "gssapi host name from the URI",
+			CS: &connstring.ConnString{
+				AuthMechanism: "GSSAPI",
+				AuthMechanismProperties: map[string]string{
+					"SERVICE_HOST": "principal.example.com",
+				},
+				AuthMechanismPropertiesSet: true,
+			},
+			WithGSSAPI: true,
+			OptsIn:     New("", "", "", "", true, enabledURIOnly),
+			OptsExpected: &ToolOptions{
+				General:    &General{},
+				Verbosity:  &Verbosity{},
+				Connection: &Connection{},
+				URI:        &URI{},
+				SSL:        &SSL{},
+				Auth:       &Auth{},
+				Namespace:  &Namespace{},
+				Kerberos: &Kerberos{
+					ServiceHost: "principal.example.com",
+				},
+				enabledOptions: enabledURIOnly,
+			},
+			ShouldError: false,
+		},
+		{
+			Name: "conflicting gssapi host names",
+			CS: &connstring.ConnString{
+				AuthMechanism: "GSSAPI",
+				AuthMechanismProperties: map[string]string{
+					"SERVICE_HOST": "principal.example.com",
+				},
+				AuthMechanismPropertiesSet: true,
+			},
+			WithGSSAPI: true,
+			OptsIn: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{ServiceHost: "other.example.com"},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			OptsExpected: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{Mechanism: "GSSAPI"},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{ServiceHost: "other.example.com"},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "empty gssapi host name",
+			CS: &connstring.ConnString{
+				AuthMechanism: "GSSAPI",
+				AuthMechanismProperties: map[string]string{
+					"SERVICE_HOST": "",
+				},
+				AuthMechanismPropertiesSet: true,
+			},
+			WithGSSAPI:   true,
+			OptsIn:       New("", "", "", "", true, enabledURIOnly),
+			OptsExpected: New("", "", "", "", true, enabledURIOnly),
+			ShouldError:  true,
+		},
+		{
+			Name: "blank gssapi service name",
+			CS: &connstring.ConnString{
+				AuthMechanism: "GSSAPI",
+			},
+			WithGSSAPI: true,
+			OptsIn: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{Service: " "},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			OptsExpected: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{Mechanism: "GSSAPI"},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{Service: " "},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "gssapi options without the GSSAPI mechanism",
+			CS: &connstring.ConnString{
+				AuthMechanism: "SCRAM-SHA-256",
+			},
+			WithGSSAPI: true,
+			OptsIn: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{ServiceHost: "principal.example.com"},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			OptsExpected: &ToolOptions{
+				General:        &General{},
+				Verbosity:      &Verbosity{},
+				Connection:     &Connection{},
+				URI:            &URI{},
+				SSL:            &SSL{},
+				Auth:           &Auth{Mechanism: "SCRAM-SHA-256"},
+				Namespace:      &Namespace{},
+				Kerberos:       &Kerberos{ServiceHost: "principal.example.com"},
+				enabledOptions: EnabledOptions{Auth: true, URI: true},
+			},
+			ShouldError: true,
+		},
 		{
 			Name: "Direct is false when loadbalanced == true",
 			CS: &connstring.ConnString{
@@ -635,6 +805,15 @@ func TestParseAndSetOptions(t *testing.T) {
 	}
 }
 
+// listOptions is an option group with a list option.
+type listOptions struct {
+	NSInclude []string `long:"nsInclude"`
+}
+
+func (*listOptions) Name() string {
+	return "list"
+}
+
 type configTester struct {
 	description  string
 	yamlBytes    []byte
@@ -759,12 +938,57 @@ func TestParseConfigFile(t *testing.T) {
 			{
 				"containing an unsupported or misspelled field",
 				[]byte("pasword: abc123"),
+				createExpectedOpts("", "", ""),
+				ShouldSucceed,
+			},
+			{
+				"containing the same fields as JSON",
+				[]byte(`{"password": "abc123", "uri": "def456", "sslPEMKeyPassword": "ghi789"}`),
+				createExpectedOpts("abc123", "def456", "ghi789"),
+				ShouldSucceed,
+			},
+			{
+				"containing a nested document",
+				[]byte("password:\n  value: abc123"),
+				nil,
+				ShouldFail,
+			},
+			{
+				"containing a --config field",
+				[]byte("config: other.yaml"),
 				nil,
 				ShouldFail,
 			},
 		})
 	})
 
+	t.Run("with other options in the config file", func(t *testing.T) {
+		configFilePath := "./test-config.yaml"
+		defer os.Remove(configFilePath)
+		config := []byte(`db: myDB
+collection: myColl
+quiet: true
+trace: false
+verbose: 2
+port: 12345
+`)
+		require.NoError(t, os.WriteFile(configFilePath, config, 0644))
+
+		opts := New("test", "", "", "", false, EnabledOptions{true, true, true, true})
+		_, err := opts.ParseArgs([]string{"--config", configFilePath, "--collection", "other"})
+		require.NoError(t, err)
+		require.Equal(t, "myDB", opts.Namespace.DB)
+		require.Equal(t, "other", opts.Namespace.Collection)
+		require.True(t, opts.Quiet)
+		require.False(t, opts.Trace)
+		require.Equal(t, 2, opts.VLevel)
+		require.Equal(t, "12345", opts.Connection.Port)
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte("quiet: yes please"), 0644))
+		opts = New("test", "", "", "", false, EnabledOptions{true, true, true, true})
+		require.Error(t, opts.ParseConfigFile([]string{"--config", configFilePath}))
+	})
+
 	t.Run("with command line args that override config file values", func(t *testing.T) {
 		configFilePath := "./test-config.yaml"
 		defer os.Remove(configFilePath)
@@ -787,6 +1011,28 @@ func TestParseConfigFile(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, "ghi789", opts.Auth.Password)
 		})
+
+		t.Run("with list and counting options", func(t *testing.T) {
+			config := []byte("verbose: 3\nnsInclude: [a.*, b.*]\n")
+			require.NoError(t, os.WriteFile(configFilePath, config, 0644))
+
+			args := []string{"--config=" + configFilePath, "-v", "--nsInclude=c.*"}
+			opts := New("test", "", "", "", false, EnabledOptions{})
+			extra := &listOptions{}
+			opts.AddOptions(extra)
+			_, err := opts.ParseArgs(args)
+			require.NoError(t, err)
+			require.Equal(t, 1, opts.VLevel)
+			require.Equal(t, []string{"c.*"}, extra.NSInclude)
+
+			opts = New("test", "", "", "", false, EnabledOptions{})
+			extra = &listOptions{}
+			opts.AddOptions(extra)
+			_, err = opts.ParseArgs([]string{"--config=" + configFilePath})
+			require.NoError(t, err)
+			require.Equal(t, 3, opts.VLevel)
+			require.Equal(t, []string{"a.*", "b.*"}, extra.NSInclude)
+		})
 	})
 }
 
@@ -897,6 +1143,10 @@ func TestOptionsParsing(t *testing.T) {
 		{"--compressors snappy", "mongodb://foo/?compressors=zlib", ShouldFail},
 		// {"--compressors none", "mongodb://foo/?compressors=snappy", ShouldFail}, // Note: zero value problem
 		{"--compressors snappy", "mongodb://foo/?compressors=none", ShouldFail},
+		{"--compressors snappy,zstd", "mongodb://foo/", ShouldSucceed},
+		{"--compressors lz4", "mongodb://foo/", ShouldFail},
+		{"--compressors none,snappy", "mongodb://foo/", ShouldFail},
+		{"", "mongodb://foo/?compressors=zlib,lz4", ShouldFail},
 
 		// Auth
 		{"--username alice", "mongodb://alice@foo", ShouldSucceed},
@@ -1138,6 +1388,16 @@ func TestHiddenOptionsDefaults(t *testing.T) {
 	})
 }
 
+func TestCompressorsFromURI(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	enabled := EnabledOptions{Connection: true, URI: true}
+	opts := New("", "", "", "", true, enabled)
+	_, err := opts.ParseArgs([]string{"--uri", "mongodb://foo/?compressors=zstd,snappy"})
+	require.NoError(t, err)
+	require.Equal(t, "zstd,snappy", opts.Connection.Compressors)
+}
+
 func TestNamespace_String(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 
@@ -0,0 +1,41 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package progress_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/progress"
+)
+
+// This example shows how a program embedding a tool can receive its progress
+// through an Observer rather than terminal progress bars. The Tracker would
+// normally be set as the tool's ProgressManager and started with Start.
+func ExampleTracker() {
+	observer := progress.ObserverFunc(func(updates []progress.Update) {
+		for _, update := range updates {
+			if update.Done {
+				fmt.Printf("%v: finished with %v documents\n", update.Name, update.Current)
+				continue
+			}
+			fmt.Printf("%v: %v of %v documents\n", update.Name, update.Current, update.Max)
+		}
+	})
+	tracker := progress.NewTracker(time.Second, false, observer)
+
+	counter := progress.NewCounter(100)
+	tracker.Attach("test.people", counter)
+	counter.Set(40)
+	tracker.Notify()
+	counter.Set(100)
+	tracker.Detach("test.people")
+
+	// Output:
+	// test.people: 40 of 100 documents
+	// test.people: finished with 100 documents
+}
@@ -166,3 +166,59 @@ func (manager *BarWriter) start() {
 func (manager *BarWriter) Stop() {
 	manager.stopChan <- struct{}{}
 }
+
+// staticProgressor reports the values of the last Update that a BarWriter
+// observed for a bar.
+type staticProgressor struct {
+	current, max int64
+}
+
+func (p *staticProgressor) Progress() (int64, int64) {
+	return p.current, p.max
+}
+
+// Observe implements Observer, so that a BarWriter can render the progress
+// sent by a Tracker as terminal progress bars. A bar is added for each new
+// name and removed after its Done update. A BarWriter used this way should
+// not be started or attached to directly, since the Tracker decides what it
+// renders and when.
+func (manager *BarWriter) Observe(updates []Update) {
+	render := false
+	for _, update := range updates {
+		manager.Lock()
+		var progressor *staticProgressor
+		for _, bar := range manager.bars {
+			if bar.Name == update.Name {
+				progressor, _ = bar.Watching.(*staticProgressor)
+				break
+			}
+		}
+		manager.Unlock()
+
+		if progressor == nil {
+			progressor = &staticProgressor{}
+			manager.Attach(update.Name, progressor)
+		}
+		manager.Lock()
+		progressor.current, progressor.max = update.Current, update.Max
+		if update.Done {
+			// always show the final progress, even if the bar was
+			// never rendered by a regular update
+			for _, bar := range manager.bars {
+				if bar.Name == update.Name {
+					bar.hasRendered = true
+				}
+			}
+		}
+		manager.Unlock()
+
+		if update.Done {
+			manager.Detach(update.Name)
+		} else {
+			render = true
+		}
+	}
+	if render {
+		manager.renderAllBars()
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package progress
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Update is the progress of one progressor attached to a Tracker at the time
+// its observers were notified.
+type Update struct {
+	// Name is the name the progressor was attached with, usually the
+	// namespace being processed.
+	Name string
+	// Current and Max are the values returned by the progressor. Max is 0
+	// if the total isn't known.
+	Current int64
+	Max     int64
+	// IsBytes is true if the values are byte counts rather than document
+	// counts.
+	IsBytes bool
+	// Done is true for the final update sent when the progressor is
+	// detached.
+	Done bool
+}
+
+// Observer receives progress from a Tracker, so that it can be shown by
+// something other than the terminal progress bars, e.g. when a tool is
+// embedded in another program.
+type Observer interface {
+	// Observe is called with the progress of every attached progressor, in
+	// the order they were attached, each time the Tracker polls them. It is
+	// also called with a single Done update when a progressor is detached.
+	// Calls are never concurrent.
+	Observe(updates []Update)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(updates []Update)
+
+// Observe calls f(updates).
+func (f ObserverFunc) Observe(updates []Update) {
+	f(updates)
+}
+
+type trackedProgressor struct {
+	name       string
+	progressor Progressor
+}
+
+// Tracker implements Manager. It polls its progressors at a regular interval
+// and sends their progress to its observers, leaving it to them to render it.
+type Tracker struct {
+	sync.Mutex
+
+	waitTime    time.Duration
+	isBytes     bool
+	observers   []Observer
+	progressors []trackedProgressor
+	stopChan    chan struct{}
+}
+
+// NewTracker returns a Tracker that notifies the given observers every
+// waitTime, with updates whose IsBytes field is set to isBytes.
+func NewTracker(waitTime time.Duration, isBytes bool, observers ...Observer) *Tracker {
+	return &Tracker{
+		waitTime:  waitTime,
+		isBytes:   isBytes,
+		observers: observers,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// AddObserver registers another observer with the tracker.
+func (tracker *Tracker) AddObserver(observer Observer) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.observers = append(tracker.observers, observer)
+}
+
+// Attach registers the given progressor with the tracker. It panics if a
+// progressor with the same name is already attached.
+func (tracker *Tracker) Attach(name string, progressor Progressor) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	for _, tracked := range tracker.progressors {
+		if tracked.name == name {
+			panic(fmt.Sprintf("progressor with name '%s' already exists in tracker", name))
+		}
+	}
+	tracker.progressors = append(tracker.progressors, trackedProgressor{name, progressor})
+}
+
+// Detach removes the progressor with the given name from the tracker, after
+// sending its final progress to the observers as a Done update.
+func (tracker *Tracker) Detach(name string) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	for i, tracked := range tracker.progressors {
+		if tracked.name == name {
+			update := tracker.update(tracked)
+			update.Done = true
+			tracker.notify([]Update{update})
+			tracker.progressors = append(tracker.progressors[:i], tracker.progressors[i+1:]...)
+			return
+		}
+	}
+	panic("could not find progressor")
+}
+
+// Notify sends the current progress of every attached progressor to the
+// observers right away. If nothing is attached, the observers aren't called.
+func (tracker *Tracker) Notify() {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if len(tracker.progressors) == 0 {
+		return
+	}
+	updates := make([]Update, 0, len(tracker.progressors))
+	for _, tracked := range tracker.progressors {
+		updates = append(updates, tracker.update(tracked))
+	}
+	tracker.notify(updates)
+}
+
+func (tracker *Tracker) update(tracked trackedProgressor) Update {
+	current, max := tracked.progressor.Progress()
+	return Update{
+		Name:    tracked.name,
+		Current: current,
+		Max:     max,
+		IsBytes: tracker.isBytes,
+	}
+}
+
+// notify must be called with the tracker locked.
+func (tracker *Tracker) notify(updates []Update) {
+	for _, observer := range tracker.observers {
+		observer.Observe(updates)
+	}
+}
+
+// Start begins notifying the observers every waitTime.
+func (tracker *Tracker) Start() {
+	go tracker.start()
+}
+
+func (tracker *Tracker) start() {
+	if tracker.waitTime <= 0 {
+		tracker.waitTime = DefaultWaitTime
+	}
+	ticker := time.NewTicker(tracker.waitTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tracker.stopChan:
+			return
+		case <-ticker.C:
+			tracker.Notify()
+		}
+	}
+}
+
+// Stop ends the tracker's goroutine, so that observers are only notified
+// when progressors are detached.
+func (tracker *Tracker) Stop() {
+	tracker.stopChan <- struct{}{}
+}
@@ -0,0 +1,104 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package progress
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTracker(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a Tracker and a recording observer", t, func() {
+		var observed [][]Update
+		tracker := NewTracker(time.Hour, true, ObserverFunc(func(updates []Update) {
+			observed = append(observed, updates)
+		}))
+
+		Convey("observers should not be notified while nothing is attached", func() {
+			tracker.Notify()
+			So(observed, ShouldBeEmpty)
+		})
+
+		Convey("attaching two progressors and notifying", func() {
+			first := NewCounter(10)
+			second := NewCounter(0)
+			tracker.Attach("db.first", first)
+			tracker.Attach("db.second", second)
+			first.Set(4)
+			second.Set(7)
+			tracker.Notify()
+
+			Convey("should send both in attach order", func() {
+				So(observed, ShouldResemble, [][]Update{{
+					{Name: "db.first", Current: 4, Max: 10, IsBytes: true},
+					{Name: "db.second", Current: 7, Max: 0, IsBytes: true},
+				}})
+			})
+
+			Convey("detaching one should send a Done update and stop tracking it", func() {
+				first.Set(10)
+				tracker.Detach("db.first")
+				So(observed[1], ShouldResemble, []Update{
+					{Name: "db.first", Current: 10, Max: 10, IsBytes: true, Done: true},
+				})
+
+				tracker.Notify()
+				So(observed[2], ShouldResemble, []Update{
+					{Name: "db.second", Current: 7, Max: 0, IsBytes: true},
+				})
+			})
+
+			Convey("added observers should get later updates", func() {
+				var added []Update
+				tracker.AddObserver(ObserverFunc(func(updates []Update) {
+					added = append(added, updates...)
+				}))
+				tracker.Notify()
+				So(len(added), ShouldEqual, 2)
+				So(len(observed), ShouldEqual, 2)
+			})
+
+			Convey("attaching a duplicate name should panic", func() {
+				So(func() { tracker.Attach("db.first", first) }, ShouldPanic)
+			})
+		})
+
+		Convey("detaching an unknown name should panic", func() {
+			So(func() { tracker.Detach("db.missing") }, ShouldPanic)
+		})
+	})
+
+	Convey("With a BarWriter observing a Tracker", t, func() {
+		writeBuffer := new(safeBuffer)
+		bars := NewBarWriter(writeBuffer, time.Hour, 10, false)
+		tracker := NewTracker(time.Hour, false, bars)
+		counter := NewCounter(10)
+		tracker.Attach("db.coll", counter)
+
+		Convey("each notification should render the bars", func() {
+			counter.Set(5)
+			tracker.Notify()
+			So(writeBuffer.String(), ShouldContainSubstring, "db.coll")
+			So(writeBuffer.String(), ShouldContainSubstring, "5/10")
+			So(len(bars.bars), ShouldEqual, 1)
+		})
+
+		Convey("detaching should render the final progress and remove the bar", func() {
+			counter.Set(10)
+			tracker.Detach("db.coll")
+			So(writeBuffer.String(), ShouldContainSubstring, "10/10")
+			So(bars.bars, ShouldBeEmpty)
+			So(strings.Count(writeBuffer.String(), "db.coll"), ShouldEqual, 1)
+		})
+	})
+}
@@ -24,6 +24,19 @@ const (
 	BarRight        = "]"
 )
 
+// rateWindow is the number of most recent renders that the throughput shown
+// with ShowRate is averaged over.
+const rateWindow = 5
+
+// barNow is swapped out in tests.
+var barNow = time.Now
+
+// progressSample is the count of a Bar's Progressor at the time of a render.
+type progressSample struct {
+	at    time.Time
+	count int64
+}
+
 // Bar is a tool for concurrently monitoring the progress
 // of a task with a simple linear ASCII visualization.
 type Bar struct {
@@ -40,6 +53,15 @@ type Bar struct {
 	// values necessary for calculation
 	Watching Progressor
 
+	// Status, if set, is called each time the bar is rendered and its
+	// result is printed after the counts, e.g. to report work in flight
+	Status func() string
+
+	// ShowRate adds the throughput over the last few renders and the
+	// estimated time remaining to the output, or "stalled" if there was no
+	// progress, and the total elapsed time once the bar is complete
+	ShowRate bool
+
 	// Writer is where the Bar is written out to
 	Writer io.Writer
 	// WaitTime is the time to wait between writing the bar
@@ -51,6 +73,10 @@ type Bar struct {
 	// hasRendered indicates that the bar has been rendered at least once
 	// and implies that when detaching should be rendered one more time
 	hasRendered bool
+
+	// startTime and samples track progress over time for ShowRate
+	startTime time.Time
+	samples   []progressSample
 }
 
 // Start starts the Bar goroutine. Once Start is called, a bar will
@@ -110,17 +136,73 @@ func (pb *Bar) renderToWriter() {
 	if maxCount == 0 {
 		// if we have no max amount, just print a count
 		fmt.Fprintf(pb.Writer, "%v\t%v", pb.Name, currentStr)
-		return
-	}
-	// otherwise, print a bar and percents
-	percent := float64(currentCount) / float64(maxCount)
-	fmt.Fprintf(pb.Writer, "%v %v\t%s/%s (%2.1f%%)",
-		drawBar(pb.BarLength, percent),
-		pb.Name,
-		currentStr,
-		maxStr,
-		percent*100,
-	)
+	} else {
+		// otherwise, print a bar and percents
+		percent := float64(currentCount) / float64(maxCount)
+		fmt.Fprintf(pb.Writer, "%v %v\t%s/%s (%2.1f%%)",
+			drawBar(pb.BarLength, percent),
+			pb.Name,
+			currentStr,
+			maxStr,
+			percent*100,
+		)
+	}
+	if rate := pb.rate(currentCount, maxCount); rate != "" {
+		fmt.Fprintf(pb.Writer, " %v", rate)
+	}
+	if status := pb.status(); status != "" {
+		fmt.Fprintf(pb.Writer, " %v", status)
+	}
+}
+
+// rate records the current count and, if ShowRate is set, returns the
+// throughput and estimated time remaining, "stalled" if nothing has progressed
+// over the last few renders, or the elapsed time if the bar is complete.
+func (pb *Bar) rate(currentCount, maxCount int64) string {
+	if !pb.ShowRate {
+		return ""
+	}
+	now := barNow()
+	if pb.startTime.IsZero() {
+		pb.startTime = now
+	}
+	pb.samples = append(pb.samples, progressSample{at: now, count: currentCount})
+	if len(pb.samples) > rateWindow {
+		pb.samples = pb.samples[len(pb.samples)-rateWindow:]
+	}
+
+	if maxCount > 0 && currentCount >= maxCount {
+		return fmt.Sprintf("done in %v", now.Sub(pb.startTime).Round(time.Second))
+	}
+
+	oldest := pb.samples[0]
+	seconds := now.Sub(oldest.at).Seconds()
+	if seconds <= 0 {
+		return ""
+	}
+	perSecond := float64(currentCount-oldest.count) / seconds
+	if perSecond <= 0 {
+		return "stalled"
+	}
+
+	var rateStr string
+	if pb.IsBytes {
+		rateStr = text.FormatByteAmount(int64(perSecond)) + "/s"
+	} else {
+		rateStr = fmt.Sprintf("%.0f/s", perSecond)
+	}
+	if maxCount == 0 {
+		return rateStr
+	}
+	remaining := time.Duration(float64(maxCount-currentCount) / perSecond * float64(time.Second))
+	return fmt.Sprintf("%v, ETA %v", rateStr, remaining.Round(time.Second))
+}
+
+func (pb *Bar) status() string {
+	if pb.Status == nil {
+		return ""
+	}
+	return pb.Status()
 }
 
 func (pb *Bar) renderToGridRow(grid *text.GridWriter) {
@@ -139,6 +221,12 @@ func (pb *Bar) renderToGridRow(grid *text.GridWriter) {
 			fmt.Sprintf("(%2.1f%%)", percent*100),
 		)
 	}
+	if rate := pb.rate(currentCount, maxCount); rate != "" {
+		grid.WriteCell(rate)
+	}
+	if status := pb.status(); status != "" {
+		grid.WriteCell(status)
+	}
 	grid.EndRow()
 }
 
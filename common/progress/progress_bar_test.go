@@ -12,11 +12,13 @@ package progress
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/text"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -92,6 +94,125 @@ func TestProgressBarWithNoMax(t *testing.T) {
 	})
 }
 
+func TestProgressBarStatus(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a ProgressBar that reports a status", t, func() {
+		writeBuffer := &bytes.Buffer{}
+		inFlight := 0
+		pbar := &Bar{
+			Name:      "test",
+			Watching:  NewCounter(10),
+			Writer:    writeBuffer,
+			BarLength: 10,
+			Status: func() string {
+				if inFlight == 0 {
+					return ""
+				}
+				return fmt.Sprintf("(%v in flight)", inFlight)
+			},
+		}
+
+		Convey("the status should follow the counts", func() {
+			inFlight = 2
+			pbar.renderToWriter()
+			So(writeBuffer.String(), ShouldEndWith, "0.0%) (2 in flight)")
+
+			grid := &text.GridWriter{}
+			pbar.renderToGridRow(grid)
+			gridBuffer := &bytes.Buffer{}
+			grid.Flush(gridBuffer)
+			So(gridBuffer.String(), ShouldContainSubstring, "(2 in flight)")
+		})
+
+		Convey("an empty status should print nothing extra", func() {
+			pbar.renderToWriter()
+			So(writeBuffer.String(), ShouldEndWith, "(0.0%)")
+		})
+	})
+}
+
+func TestProgressBarRate(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a ProgressBar that shows its rate", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		barNow = func() time.Time { return now }
+		defer func() { barNow = time.Now }()
+
+		watching := NewCounter(1000)
+		writeBuffer := &bytes.Buffer{}
+		pbar := &Bar{
+			Name:      "test",
+			Watching:  watching,
+			Writer:    writeBuffer,
+			BarLength: 10,
+			ShowRate:  true,
+		}
+		render := func() string {
+			writeBuffer.Reset()
+			pbar.renderToWriter()
+			return writeBuffer.String()
+		}
+
+		Convey("the first render has nothing to compare against", func() {
+			So(render(), ShouldEndWith, "(0.0%)")
+		})
+
+		Convey("the throughput and ETA follow the counts", func() {
+			render()
+			now = now.Add(2 * time.Second)
+			watching.Inc(100)
+			So(render(), ShouldEndWith, "(10.0%) 50/s, ETA 18s")
+		})
+
+		Convey("the throughput is averaged over the most recent renders", func() {
+			render()
+			for i := 0; i < rateWindow; i++ {
+				now = now.Add(time.Second)
+				watching.Inc(10)
+				render()
+			}
+			// the initial idle period has dropped out of the window
+			now = now.Add(time.Second)
+			watching.Inc(10)
+			So(render(), ShouldContainSubstring, " 10/s, ETA ")
+		})
+
+		Convey("no progress is reported as stalled", func() {
+			render()
+			now = now.Add(time.Second)
+			So(render(), ShouldEndWith, "(0.0%) stalled")
+		})
+
+		Convey("a complete bar shows the elapsed time", func() {
+			render()
+			now = now.Add(90 * time.Second)
+			watching.Set(1000)
+			So(render(), ShouldEndWith, "(100.0%) done in 1m30s")
+		})
+
+		Convey("byte amounts are formatted as such", func() {
+			pbar.IsBytes = true
+			render()
+			now = now.Add(time.Second)
+			watching.Inc(512)
+			So(render(), ShouldContainSubstring, "512B/s, ETA 1s")
+		})
+
+		Convey("the rate is also added to grid rows", func() {
+			grid := &text.GridWriter{}
+			pbar.renderToGridRow(grid)
+			now = now.Add(time.Second)
+			watching.Inc(500)
+			pbar.renderToGridRow(grid)
+			gridBuffer := &bytes.Buffer{}
+			grid.Flush(gridBuffer)
+			So(gridBuffer.String(), ShouldContainSubstring, "500/s, ETA 1s")
+		})
+	})
+}
+
 func TestBarConcurrency(t *testing.T) {
 	// TOOLS-2715: Disable flaky test
 	t.SkipNow()
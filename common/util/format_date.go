@@ -7,10 +7,15 @@
 package util
 
 import (
+	"fmt"
 	"time"
 )
 
 var (
+	// acceptedDateFormats are tried in order. Fractional seconds are accepted
+	// after the seconds field of any format, and formats without a zone are
+	// parsed as UTC. The most general format goes last so that its error is
+	// the one reported for invalid dates.
 	acceptedDateFormats = []string{
 		"2006-01-02T15:04:05.000Z",
 		"2006-01-02T15:04:05Z",
@@ -18,19 +23,24 @@ var (
 		"2006-01-02T15:04:05.000-0700",
 		"2006-01-02T15:04:05-0700",
 		"2006-01-02T15:04-0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02T15:04Z07:00",
 		"2006-01-02T15:04:05Z07:00",
 	}
 )
 
+// FormatDate parses an ISO-8601 date string, as found in ISODate and $date
+// values, into a time.Time normalized to UTC. It returns an error if v is not
+// in one of the accepted formats or has an out-of-range component.
 func FormatDate(v string) (interface{}, error) {
-	var date interface{}
 	var err error
-
 	for _, format := range acceptedDateFormats {
+		var date time.Time
 		date, err = time.Parse(format, v)
 		if err == nil {
-			return date, nil
+			return date.UTC(), nil
 		}
 	}
-	return v, nil
+	return nil, fmt.Errorf("invalid ISO-8601 date: %v", err)
 }
@@ -8,6 +8,7 @@ package util
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
@@ -51,4 +52,27 @@ func TestFormatDate(t *testing.T) {
 		So(err, ShouldNotBeNil)
 	})
 
+	Convey("will take fractional seconds with a numeric offset and normalize to UTC", t, func() {
+		date, err := FormatDate("2021-01-01T00:00:00.123+05:30")
+		So(err, ShouldBeNil)
+		So(date, ShouldEqual, time.Date(2020, 12, 31, 18, 30, 0, 123e6, time.UTC))
+		So(date.(time.Time).Location(), ShouldEqual, time.UTC)
+	})
+
+	Convey("will treat a date without a zone as UTC", t, func() {
+		date, err := FormatDate("2021-01-01T10:20:30.5")
+		So(err, ShouldBeNil)
+		So(date, ShouldEqual, time.Date(2021, 1, 1, 10, 20, 30, 5e8, time.UTC))
+	})
+
+	Convey("will describe an out-of-range component", t, func() {
+		_, err := FormatDate("2021-13-01T00:00:00Z")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "month out of range")
+
+		_, err = FormatDate("2021-01-01T25:00:00+05:30")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "hour out of range")
+	})
+
 }
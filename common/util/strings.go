@@ -22,6 +22,13 @@ func Pluralize(amount int, singular, plural string) string {
 
 var uriRedactionRE = regexp.MustCompile(`^([^:]+)://[^/?]*@`)
 
+// awsSessionTokenRedactionRE matches the AWS session token given in the
+// authMechanismProperties of a connection string.
+var awsSessionTokenRedactionRE = regexp.MustCompile(`(?i)(AWS_SESSION_TOKEN(?::|%3A))[^,&]*`)
+
+// SanitizeURI redacts the credentials in a connection string: the user info
+// and any AWS session token.
 func SanitizeURI(u string) string {
-	return uriRedactionRE.ReplaceAllString(u, "$1://[**REDACTED**]@")
+	u = uriRedactionRE.ReplaceAllString(u, "$1://[**REDACTED**]@")
+	return awsSessionTokenRedactionRE.ReplaceAllString(u, "$1[**REDACTED**]")
 }
@@ -0,0 +1,126 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/util"
+)
+
+// collectionFileEntry is a namespace listed in a --collectionFile.
+type collectionFileEntry struct {
+	line int
+	db   string
+	coll string
+}
+
+// readCollectionFile reads the namespaces listed in a --collectionFile.
+func readCollectionFile(path string) ([]collectionFileEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening collectionFile: %v", err)
+	}
+	defer file.Close()
+	entries, err := parseCollectionFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading collectionFile %v: %v", path, err)
+	}
+	return entries, nil
+}
+
+// parseCollectionFile parses one "db.collection" namespace per line. Blank
+// lines and lines starting with '#' are ignored, as are repeated namespaces.
+func parseCollectionFile(r io.Reader) ([]collectionFileEntry, error) {
+	var entries []collectionFileEntry
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		ns := strings.TrimSpace(scanner.Text())
+		if ns == "" || strings.HasPrefix(ns, "#") {
+			continue
+		}
+
+		dbName, collName, ok := strings.Cut(ns, ".")
+		if !ok || collName == "" {
+			return nil, fmt.Errorf("line %v: '%v' is not of the form <db>.<collection>", line, ns)
+		}
+		if err := util.ValidateDBName(dbName); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		if err := util.ValidateCollectionGrammar(collName); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		if strings.HasPrefix(collName, "system.buckets.") {
+			return nil, fmt.Errorf("line %v: cannot list a system.buckets collection; "+
+				"list the timeseries collection to dump its system.buckets collection", line)
+		}
+
+		if seen[ns] {
+			log.Logvf(log.DebugLow, "line %v: ignoring repeated namespace %v", line, ns)
+			continue
+		}
+		seen[ns] = true
+		entries = append(entries, collectionFileEntry{line: line, db: dbName, coll: collName})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CreateIntentsFromCollectionFile builds dump intents for exactly the
+// collections listed in --collectionFile. A listed collection that does not
+// exist is reported and skipped, or is an error with --strict.
+func (dump *MongoDump) CreateIntentsFromCollectionFile() error {
+	path := dump.OutputOptions.CollectionFile
+	entries, err := readCollectionFile(path)
+	if err != nil {
+		return err
+	}
+
+	session, err := dump.SessionProvider.GetSession()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		collInfo, err := db.GetCollectionInfo(session.Database(entry.db).Collection(entry.coll))
+		if err != nil {
+			return fmt.Errorf("error getting collection options for %v.%v: %v",
+				entry.db, entry.coll, err)
+		}
+		if collInfo == nil {
+			if dump.OutputOptions.Strict {
+				return fmt.Errorf("%v line %v: collection %v.%v does not exist",
+					path, entry.line, entry.db, entry.coll)
+			}
+			log.Logvf(log.Always,
+				"warning: %v line %v: collection %v.%v does not exist, skipping it",
+				path, entry.line, entry.db, entry.coll)
+			continue
+		}
+
+		if dump.OutputOptions.ViewsAsCollections && !collInfo.IsView() {
+			log.Logvf(log.DebugLow, "skipping dump of %v.%v because it is not a view",
+				entry.db, entry.coll)
+			continue
+		}
+		intent, err := dump.NewIntentFromOptions(entry.db, collInfo)
+		if err != nil {
+			return err
+		}
+		dump.manager.Put(intent)
+	}
+	return nil
+}
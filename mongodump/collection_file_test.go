@@ -0,0 +1,90 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCollectionFile(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	t.Run("namespaces are read one per line", func(t *testing.T) {
+		entries, err := parseCollectionFile(strings.NewReader(
+			"# collections to back up\ndb1.a\n\n  db2.b.c  \ndb1.a\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []collectionFileEntry{
+			{line: 2, db: "db1", coll: "a"},
+			{line: 4, db: "db2", coll: "b.c"},
+		}, entries)
+	})
+
+	t.Run("malformed lines are rejected", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"db1.a\nnodot\n":               "line 2: 'nodot' is not of the form <db>.<collection>",
+			"db1.\n":                       "line 1: 'db1.' is not of the form",
+			"bad/db.a\n":                   "line 1:",
+			"db1.system.buckets.weather\n": "cannot list a system.buckets collection",
+		} {
+			_, err := parseCollectionFile(strings.NewReader(input))
+			assert.ErrorContains(t, err, expected, input)
+		}
+	})
+
+	t.Run("a missing file is an error", func(t *testing.T) {
+		_, err := readCollectionFile(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorContains(t, err, "error opening collectionFile")
+	})
+}
+
+func TestMongoDumpCollectionFile(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+
+	require.NoError(t, setUpMongoDumpTestData())
+	defer func() {
+		require.NoError(t, tearDownMongoDumpTestData())
+	}()
+
+	dir := t.TempDir()
+	collectionFile := filepath.Join(dir, "collections")
+	require.NoError(t, os.WriteFile(collectionFile, []byte(
+		testDB+".coll1\n"+testDB+".missing\n"+testDB+".coll/three\n"), 0o644))
+
+	dump := func(strict bool) error {
+		md := simpleMongoDumpInstance()
+		md.ToolOptions.Namespace.DB = ""
+		md.OutputOptions.Out = filepath.Join(dir, "dump")
+		md.OutputOptions.CollectionFile = collectionFile
+		md.OutputOptions.Strict = strict
+		require.NoError(t, md.Init())
+		return md.Dump()
+	}
+
+	t.Run("only the listed collections are dumped", func(t *testing.T) {
+		require.NoError(t, dump(false))
+
+		dumped, err := listNonIndexBSONFiles(filepath.Join(dir, "dump", testDB))
+		require.NoError(t, err)
+		assert.ElementsMatch(t,
+			[]string{"coll1.bson", util.EscapeCollectionName("coll/three") + ".bson"}, dumped)
+		_, err = os.Stat(filepath.Join(dir, "dump", "admin"))
+		assert.True(t, os.IsNotExist(err), "users and roles should not be dumped")
+	})
+
+	t.Run("a missing collection is an error with --strict", func(t *testing.T) {
+		err := dump(true)
+		assert.ErrorContains(t, err, "line 2: collection "+testDB+".missing does not exist")
+	})
+}
@@ -47,6 +47,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	// init logger
 	log.SetVerbosity(opts.Verbosity)
 
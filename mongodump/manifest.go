@@ -0,0 +1,171 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mongodb/mongo-tools/common/archive"
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ManifestFileName is the name of the file written to the output directory
+// by --writeManifest.
+const ManifestFileName = "manifest.json"
+
+// Manifest summarizes a completed dump, so that it can be checked for
+// completeness without reading the BSON files.
+type Manifest struct {
+	ToolVersion string              `json:"toolVersion"`
+	Namespaces  []ManifestNamespace `json:"namespaces"`
+	Oplog       *ManifestOplog      `json:"oplog,omitempty"`
+}
+
+// ManifestNamespace describes a single dumped collection. Bytes is the size
+// of its BSON file as written, so it is the compressed size with --gzip. File
+// and Bytes are not set for archives.
+type ManifestNamespace struct {
+	DB         string `json:"db"`
+	Collection string `json:"collection"`
+	File       string `json:"file,omitempty"`
+	Documents  int64  `json:"documents"`
+	Bytes      int64  `json:"bytes"`
+	Indexes    int    `json:"indexes"`
+}
+
+// ManifestOplog describes the oplog entries captured with --oplog.
+type ManifestOplog struct {
+	Start     ManifestTimestamp `json:"start"`
+	End       ManifestTimestamp `json:"end"`
+	Documents int64             `json:"documents"`
+}
+
+// ManifestTimestamp is a BSON timestamp in plain JSON.
+type ManifestTimestamp struct {
+	T uint32 `json:"t"`
+	I uint32 `json:"i"`
+}
+
+func newManifestTimestamp(ts primitive.Timestamp) ManifestTimestamp {
+	return ManifestTimestamp{T: ts.T, I: ts.I}
+}
+
+// recordManifest applies update to the manifest entry for intent. It does
+// nothing unless --writeManifest is set, and is safe for concurrent use.
+func (dump *MongoDump) recordManifest(intent *intents.Intent, update func(*ManifestNamespace)) {
+	if !dump.OutputOptions.WriteManifest {
+		return
+	}
+	dump.manifestLock.Lock()
+	defer dump.manifestLock.Unlock()
+	if dump.manifestEntries == nil {
+		dump.manifestEntries = map[string]*ManifestNamespace{}
+	}
+	entry, ok := dump.manifestEntries[intent.Namespace()]
+	if !ok {
+		entry = &ManifestNamespace{DB: intent.DB, Collection: intent.C}
+		dump.manifestEntries[intent.Namespace()] = entry
+	}
+	update(entry)
+}
+
+// buildManifest assembles the Manifest from the entries recorded during
+// the dump, reading the size of each BSON file from disk.
+func (dump *MongoDump) buildManifest() (*Manifest, error) {
+	manifest := &Manifest{
+		ToolVersion: dump.ToolOptions.VersionStr,
+		Namespaces:  []ManifestNamespace{},
+	}
+
+	dump.manifestLock.Lock()
+	defer dump.manifestLock.Unlock()
+	root := dump.outputRoot()
+	for _, entry := range dump.manifestEntries {
+		ns := *entry
+		if ns.File != "" {
+			info, err := os.Stat(ns.File)
+			if err != nil {
+				return nil, fmt.Errorf("error reading size of %v: %v", ns.File, err)
+			}
+			ns.Bytes = info.Size()
+			if rel, err := filepath.Rel(root, ns.File); err == nil {
+				ns.File = filepath.ToSlash(rel)
+			}
+		}
+		manifest.Namespaces = append(manifest.Namespaces, ns)
+	}
+	sort.Slice(manifest.Namespaces, func(i, j int) bool {
+		a, b := manifest.Namespaces[i], manifest.Namespaces[j]
+		if a.DB != b.DB {
+			return a.DB < b.DB
+		}
+		return a.Collection < b.Collection
+	})
+
+	if dump.OutputOptions.Oplog {
+		manifest.Oplog = &ManifestOplog{
+			Start:     newManifestTimestamp(dump.oplogStart),
+			End:       newManifestTimestamp(dump.oplogEnd),
+			Documents: dump.oplogCount,
+		}
+	}
+	return manifest, nil
+}
+
+// writeManifest writes the manifest of a successful dump to the output
+// directory, or to the end of the archive.
+func (dump *MongoDump) writeManifest() error {
+	manifest, err := dump.buildManifest()
+	if err != nil {
+		return err
+	}
+	jsonBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling manifest: %v", err)
+	}
+	if dump.OutputOptions.Archive != "" {
+		return dump.writeArchiveManifest(jsonBytes)
+	}
+	path := filepath.Join(dump.outputRoot(), ManifestFileName)
+	log.Logvf(log.Always, "writing manifest to %v", path)
+	if err := os.WriteFile(path, append(jsonBytes, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	return nil
+}
+
+// writeArchiveManifest writes the manifest as the single document of the
+// manifest namespace of the archive, which is listed in the prelude.
+func (dump *MongoDump) writeArchiveManifest(jsonBytes []byte) error {
+	log.Logvf(log.Always, "writing manifest to the archive")
+	doc, err := bson.Marshal(bson.D{{"manifest", string(jsonBytes)}})
+	if err != nil {
+		return fmt.Errorf("error marshaling manifest: %v", err)
+	}
+	muxIn := &archive.MuxIn{
+		Mux:    dump.archive.Mux,
+		Intent: &intents.Intent{C: archive.ManifestCollection},
+	}
+	if err := muxIn.Open(); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	if _, err := muxIn.Write(doc); err != nil {
+		_ = muxIn.Close()
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	if err := muxIn.Close(); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/archive"
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWriteManifest(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0o755))
+	bsonPath := filepath.Join(dir, "db", "a.bson")
+	require.NoError(t, os.WriteFile(bsonPath, make([]byte, 42), 0o644))
+
+	md := simpleMongoDumpInstance()
+	md.ToolOptions.VersionStr = "100.0.0"
+	md.OutputOptions.Out = dir
+	md.OutputOptions.WriteManifest = true
+	md.OutputOptions.Oplog = true
+	md.oplogStart = primitive.Timestamp{T: 10, I: 1}
+	md.oplogEnd = primitive.Timestamp{T: 12, I: 3}
+	md.oplogCount = 7
+
+	a := &intents.Intent{DB: "db", C: "a"}
+	md.recordManifest(a, func(entry *ManifestNamespace) { entry.Indexes = 2 })
+	md.recordManifest(a, func(entry *ManifestNamespace) {
+		entry.File = bsonPath
+		entry.Documents = 3
+	})
+	md.recordManifest(&intents.Intent{DB: "db", C: "view"}, func(entry *ManifestNamespace) {})
+
+	require.NoError(t, md.writeManifest())
+
+	jsonBytes, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(jsonBytes, &manifest))
+
+	assert.Equal(t, Manifest{
+		ToolVersion: "100.0.0",
+		Namespaces: []ManifestNamespace{
+			{DB: "db", Collection: "a", File: "db/a.bson", Documents: 3, Bytes: 42, Indexes: 2},
+			{DB: "db", Collection: "view"},
+		},
+		Oplog: &ManifestOplog{
+			Start:     ManifestTimestamp{T: 10, I: 1},
+			End:       ManifestTimestamp{T: 12, I: 3},
+			Documents: 7,
+		},
+	}, manifest)
+}
+
+func TestRecordManifestDisabled(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	md := simpleMongoDumpInstance()
+	md.recordManifest(&intents.Intent{DB: "db", C: "a"}, func(entry *ManifestNamespace) {})
+	assert.Empty(t, md.manifestEntries)
+}
+
+type closingBuffer struct {
+	bytes.Buffer
+}
+
+func (*closingBuffer) Close() error {
+	return nil
+}
+
+func TestWriteArchiveManifest(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	md := simpleMongoDumpInstance()
+	md.ToolOptions.VersionStr = "100.0.0"
+	md.OutputOptions.Archive = "dump.archive"
+	md.OutputOptions.WriteManifest = true
+	md.recordManifest(&intents.Intent{DB: "db", C: "a"}, func(entry *ManifestNamespace) {
+		entry.Documents = 3
+	})
+
+	out := &closingBuffer{}
+	prelude, err := archive.NewPrelude(intents.NewIntentManager(), 1, "7.0.0", "100.0.0")
+	require.NoError(t, err)
+	prelude.AddMetadata(&archive.CollectionMetadata{Collection: archive.ManifestCollection})
+	require.NoError(t, prelude.Write(out))
+
+	md.archive = &archive.Writer{Out: out, Mux: archive.NewMultiplexer(out, newNotifier())}
+	go md.archive.Mux.Run()
+	require.NoError(t, md.writeManifest())
+	close(md.archive.Mux.Control)
+	require.NoError(t, <-md.archive.Mux.Completed)
+
+	// read the manifest back out of the archive
+	readPrelude := &archive.Prelude{}
+	require.NoError(t, readPrelude.Read(out))
+	demux := archive.CreateDemux(readPrelude.NamespaceMetadatas, out, false)
+	manifestIntent := &intents.Intent{C: archive.ManifestCollection}
+	cache := archive.NewSpecialCollectionCache(manifestIntent, demux)
+	demux.Open(manifestIntent.Namespace(), cache)
+	require.NoError(t, demux.Run())
+
+	var doc struct {
+		Manifest string `bson:"manifest"`
+	}
+	raw, err := bson.ReadDocument(cache)
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal([]byte(doc.Manifest), &manifest))
+
+	assert.Equal(t, Manifest{
+		ToolVersion: "100.0.0",
+		Namespaces:  []ManifestNamespace{{DB: "db", Collection: "a", Documents: 3}},
+	}, manifest)
+}
@@ -108,6 +108,10 @@ func (dump *MongoDump) dumpMetadata(
 		}
 	}
 
+	dump.recordManifest(intent, func(entry *ManifestNamespace) {
+		entry.Indexes = len(meta.Indexes)
+	})
+
 	// Finally, we send the results to the writer as JSON bytes
 	jsonBytes, err := bsonutil.MarshalExtJSONReversible(meta, true, false)
 	if err != nil {
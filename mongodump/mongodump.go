@@ -33,6 +33,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
@@ -64,11 +65,31 @@ type MongoDump struct {
 	oplogCollection string
 	oplogStart      primitive.Timestamp
 	oplogEnd        primitive.Timestamp
-	isMongos        bool
-	isAtlasProxy    bool
-	storageEngine   storageEngineType
-	authVersion     int
-	archive         *archive.Writer
+	// oplogWindowStart and oplogWindowEnd are set by --oplogStart and
+	// --oplogEnd, and are zero when not given.
+	oplogWindowStart primitive.Timestamp
+	oplogWindowEnd   primitive.Timestamp
+	isMongos         bool
+	isAtlasProxy     bool
+	storageEngine    storageEngineType
+	authVersion      int
+	archive          *archive.Writer
+	oplogCount       int64
+
+	// readConcern is the read concern collections are read with, from
+	// --readConcern. snapshotTime is the cluster time that every collection
+	// is read at when --readConcern is snapshot.
+	readConcern  *readconcern.ReadConcern
+	snapshotTime *primitive.Timestamp
+
+	// shardProviders are the connections to each shard for --splitChunks,
+	// keyed by shard name
+	shardLock      sync.Mutex
+	shardProviders map[string]*db.SessionProvider
+
+	// manifestEntries collects the per-namespace results for --writeManifest
+	manifestLock    sync.Mutex
+	manifestEntries map[string]*ManifestNamespace
 	// shutdownIntentsNotifier is provided to the multiplexer
 	// as well as the signal handler, and allows them to notify
 	// the intent dumpers that they should shutdown
@@ -106,6 +127,13 @@ func (dump *MongoDump) ValidateOptions() error {
 		return fmt.Errorf("either query or queryFile can be specified as a query option, not both")
 	case dump.InputOptions.Query != "" && dump.InputOptions.TableScan:
 		return fmt.Errorf("cannot use --forceTableScan when specifying --query")
+	case dump.InputOptions.ReadConcern != "" &&
+		!util.StringSliceContains(readConcernLevels, dump.InputOptions.ReadConcern):
+		return fmt.Errorf(
+			"invalid --readConcern '%v', choose one of: %v",
+			dump.InputOptions.ReadConcern,
+			strings.Join(readConcernLevels, ", "),
+		)
 	case dump.OutputOptions.DumpDBUsersAndRoles && dump.ToolOptions.Namespace.DB == "":
 		return fmt.Errorf("must specify a database when running with dumpDbUsersAndRoles")
 	case dump.OutputOptions.DumpDBUsersAndRoles && dump.ToolOptions.Namespace.Collection != "":
@@ -115,6 +143,17 @@ func (dump *MongoDump) ValidateOptions() error {
 			"Specifying the timeseries collection will dump the system.buckets collection")
 	case dump.OutputOptions.Oplog && dump.ToolOptions.Namespace.DB != "":
 		return fmt.Errorf("--oplog mode only supported on full dumps")
+	case dump.OutputOptions.OplogStart != "" && !dump.OutputOptions.Oplog:
+		return fmt.Errorf("cannot use --oplogStart without --oplog enabled")
+	case dump.OutputOptions.OplogEnd != "" && !dump.OutputOptions.Oplog:
+		return fmt.Errorf("cannot use --oplogEnd without --oplog enabled")
+	case dump.OutputOptions.ContinuousOplog && !dump.OutputOptions.Oplog:
+		return fmt.Errorf("cannot use --continuousOplog without --oplog enabled")
+	case dump.OutputOptions.ContinuousOplog && dump.OutputOptions.OplogEnd != "":
+		return fmt.Errorf("cannot use --continuousOplog with --oplogEnd")
+	case dump.OutputOptions.ContinuousOplog &&
+		(dump.OutputOptions.Archive != "" || dump.OutputOptions.Out == "-"):
+		return fmt.Errorf("--continuousOplog can only be used when dumping to a directory")
 	case len(dump.OutputOptions.ExcludedCollections) > 0 && dump.ToolOptions.Namespace.Collection != "":
 		return fmt.Errorf("--collection is not allowed when --excludeCollection is specified")
 	case len(dump.OutputOptions.ExcludedCollectionPrefixes) > 0 && dump.ToolOptions.Namespace.Collection != "":
@@ -131,6 +170,22 @@ func (dump *MongoDump) ValidateOptions() error {
 		return fmt.Errorf(
 			"compression can't be used when dumping a single collection to standard output",
 		)
+	case dump.OutputOptions.WriteManifest && dump.OutputOptions.Out == "-":
+		return fmt.Errorf("--writeManifest cannot be used when dumping to standard output")
+	case dump.OutputOptions.WriteChecksums &&
+		(dump.OutputOptions.Archive != "" || dump.OutputOptions.Out == "-"):
+		return fmt.Errorf("--writeChecksums can only be used when dumping to a directory")
+	case dump.InputOptions.SplitChunks &&
+		(dump.OutputOptions.Archive != "" || dump.OutputOptions.Out == "-"):
+		return fmt.Errorf("--splitChunks can only be used when dumping to a directory")
+	case dump.OutputOptions.Strict && dump.OutputOptions.CollectionFile == "":
+		return fmt.Errorf("cannot use --strict without --collectionFile")
+	case dump.OutputOptions.CollectionFile != "" &&
+		(dump.ToolOptions.Namespace.DB != "" || dump.ToolOptions.Namespace.Collection != ""):
+		return fmt.Errorf("cannot use --db or --collection with --collectionFile; " +
+			"list the namespaces to dump in the file instead")
+	case dump.OutputOptions.CollectionFile != "" && dump.OutputOptions.Oplog:
+		return fmt.Errorf("--oplog mode only supported on full dumps")
 	case dump.OutputOptions.NumParallelCollections <= 0:
 		return fmt.Errorf("numParallelCollections must be positive")
 	case dump.isAtlasProxy && (dump.OutputOptions.DumpDBUsersAndRoles || dump.ToolOptions.DB == "admin"):
@@ -177,6 +232,10 @@ func (dump *MongoDump) Init() error {
 	if err != nil {
 		return fmt.Errorf("bad option: %v", err)
 	}
+	err = dump.parseOplogWindow()
+	if err != nil {
+		return fmt.Errorf("bad option: %v", err)
+	}
 	if dump.OutputWriter == nil {
 		dump.OutputWriter = os.Stdout
 	}
@@ -184,6 +243,9 @@ func (dump *MongoDump) Init() error {
 	if dump.isMongos && dump.OutputOptions.Oplog {
 		return fmt.Errorf("can't use --oplog option when dumping from a mongos")
 	}
+	if !dump.isMongos && dump.InputOptions.SplitChunks {
+		return fmt.Errorf("can only use --splitChunks when dumping from a mongos")
+	}
 
 	// warn if we are trying to dump from a secondary in a sharded cluster
 	if dump.isMongos && pref != readpref.Primary() {
@@ -215,6 +277,7 @@ func (dump *MongoDump) verifyCollectionExists() (bool, error) {
 // Dump handles some final options checking and executes MongoDump.
 func (dump *MongoDump) Dump() (err error) {
 	defer dump.SessionProvider.Close()
+	defer dump.closeShardSessions()
 
 	exists, err := dump.verifyCollectionExists()
 	if err != nil {
@@ -231,15 +294,10 @@ func (dump *MongoDump) Dump() (err error) {
 	dump.shutdownIntentsNotifier = newNotifier()
 
 	if dump.InputOptions.HasQuery() {
-		content, err := dump.InputOptions.GetQuery()
+		query, err := dump.InputOptions.ParseQuery()
 		if err != nil {
 			return err
 		}
-		var query bson.D
-		err = bson.UnmarshalExtJSON(content, false, &query)
-		if err != nil {
-			return fmt.Errorf("error parsing query as Extended JSON: %v", err)
-		}
 		dump.query = query
 	}
 
@@ -312,10 +370,29 @@ func (dump *MongoDump) Dump() (err error) {
 		if err != nil {
 			return fmt.Errorf("error finding oplog: %v", err)
 		}
-		log.Logvf(log.Info, "getting most recent oplog timestamp")
-		dump.oplogStart, err = dump.getOplogCopyStartTime()
-		if err != nil {
-			return fmt.Errorf("error getting oplog start: %v", err)
+		if dump.oplogWindowStart.IsZero() {
+			log.Logvf(log.Info, "getting most recent oplog timestamp")
+			dump.oplogStart, err = dump.getOplogCopyStartTime()
+			if err != nil {
+				return fmt.Errorf("error getting oplog start: %v", err)
+			}
+		} else {
+			dump.oplogStart = dump.oplogWindowStart
+			exists, err := dump.checkOplogTimestampExists(dump.oplogStart)
+			if err != nil {
+				return fmt.Errorf("unable to check oplog for --oplogStart: %v", err)
+			}
+			if !exists {
+				oldest, err := dump.getOldestOplogTime()
+				if err != nil {
+					return fmt.Errorf("unable to check oplog for --oplogStart: %v", err)
+				}
+				log.Logvf(log.Always,
+					"warning: --oplogStart %v has already rolled off the oplog; "+
+						"starting from the oldest oplog entry %v instead",
+					dump.oplogStart, oldest)
+				dump.oplogStart = oldest
+			}
 		}
 	}
 
@@ -326,6 +403,8 @@ func (dump *MongoDump) Dump() (err error) {
 
 	// switch on what kind of execution to do
 	switch {
+	case dump.OutputOptions.CollectionFile != "":
+		err = dump.CreateIntentsFromCollectionFile()
 	case dump.ToolOptions.DB == "" && dump.ToolOptions.Collection == "":
 		err = dump.CreateAllIntents()
 	case dump.ToolOptions.DB != "" && dump.ToolOptions.Collection == "":
@@ -380,6 +459,11 @@ func (dump *MongoDump) Dump() (err error) {
 		if err != nil {
 			return fmt.Errorf("creating archive prelude: %v", err)
 		}
+		if dump.OutputOptions.WriteManifest {
+			dump.archive.Prelude.AddMetadata(
+				&archive.CollectionMetadata{Collection: archive.ManifestCollection},
+			)
+		}
 		err = dump.archive.Prelude.Write(dump.archive.Out)
 		if err != nil {
 			return fmt.Errorf("error writing metadata into archive: %v", err)
@@ -389,7 +473,9 @@ func (dump *MongoDump) Dump() (err error) {
 	// Dump users and roles only if these settings are not configured to be skipped,
 	// and mongodump isn't connected to an atlas proxy.
 	if !dump.SkipUsersAndRoles && !dump.isAtlasProxy {
-		if dump.ToolOptions.DB == "admin" || dump.ToolOptions.DB == "" {
+		// Only the listed collections are dumped with --collectionFile.
+		if (dump.ToolOptions.DB == "admin" || dump.ToolOptions.DB == "") &&
+			dump.OutputOptions.CollectionFile == "" {
 			err = dump.DumpUsersAndRoles()
 			if err != nil {
 				return fmt.Errorf("error dumping users and roles: %v", err)
@@ -415,6 +501,9 @@ func (dump *MongoDump) Dump() (err error) {
 	log.Logvf(log.DebugHigh, "dump phase II: regular collections")
 
 	// begin dumping intents
+	if err := dump.setUpReadConcern(); err != nil {
+		return fmt.Errorf("error setting up --readConcern: %v", err)
+	}
 	if err := dump.DumpIntents(); err != nil {
 		return err
 	}
@@ -434,6 +523,19 @@ func (dump *MongoDump) Dump() (err error) {
 		if err != nil {
 			return fmt.Errorf("error getting oplog end: %v", err)
 		}
+		if !dump.oplogWindowEnd.IsZero() {
+			if util.TimestampGreaterThan(dump.oplogWindowEnd, dump.oplogEnd) {
+				log.Logvf(log.Always,
+					"warning: --oplogEnd %v is after the most recent oplog entry %v",
+					dump.oplogWindowEnd, dump.oplogEnd)
+			} else {
+				dump.oplogEnd = dump.oplogWindowEnd
+			}
+			if util.TimestampGreaterThan(dump.oplogStart, dump.oplogEnd) {
+				return fmt.Errorf("--oplogEnd %v is before the oplog start %v",
+					dump.oplogEnd, dump.oplogStart)
+			}
+		}
 
 		log.Logvf(log.DebugLow, "checking if oplog entry %v still exists", dump.oplogStart)
 		exists, err := dump.checkOplogTimestampExists(dump.oplogStart)
@@ -468,6 +570,19 @@ func (dump *MongoDump) Dump() (err error) {
 			return fmt.Errorf("unable to check oplog for overflow: %v", err)
 		}
 		log.Logvf(log.DebugHigh, "oplog entry %v still exists", dump.oplogStart)
+
+		if dump.OutputOptions.ContinuousOplog {
+			log.Logvf(log.Always, "tailing the oplog after %v until interrupted", dump.oplogEnd)
+			if err := dump.TailOplog(); err != nil {
+				return fmt.Errorf("error tailing oplog: %v", err)
+			}
+		}
+	}
+
+	if dump.OutputOptions.WriteManifest {
+		if err := dump.writeManifest(); err != nil {
+			return err
+		}
 	}
 
 	log.Logvf(log.DebugLow, "finishing dump")
@@ -501,13 +616,15 @@ func (dump *MongoDump) getResettableOutputBuffer() resettableOutputBuffer {
 // DumpIntents iterates through the previously-created intents and
 // dumps all of the found collections.
 func (dump *MongoDump) DumpIntents() error {
-	resultChan := make(chan error)
-
 	jobs := dump.OutputOptions.NumParallelCollections
 	if numIntents := len(dump.manager.Intents()); jobs > numIntents {
 		jobs = numIntents
 	}
 
+	// buffered so that the remaining workers can still report and exit after
+	// we return on the first error
+	resultChan := make(chan error, jobs)
+
 	if jobs > 1 {
 		dump.manager.Finalize(intents.LongestTaskFirst)
 	} else {
@@ -535,7 +652,7 @@ func (dump *MongoDump) DumpIntents() error {
 				if intent.BSONFile != nil {
 					err := dump.DumpIntent(intent, buffer)
 					if err != nil {
-						resultChan <- err
+						resultChan <- fmt.Errorf("error dumping %v: %w", intent.Namespace(), err)
 						return
 					}
 				}
@@ -561,12 +678,7 @@ func (dump *MongoDump) DumpIntent(intent *intents.Intent, buffer resettableOutpu
 		return err
 	}
 	intendedDB := session.Database(intent.DB)
-	var coll *mongo.Collection
-	if intent.IsTimeseries() {
-		coll = intendedDB.Collection("system.buckets." + intent.C)
-	} else {
-		coll = intendedDB.Collection(intent.C)
-	}
+	coll := dump.collectionForIntent(session, intent)
 
 	// it is safer to assume that a collection is a view, if we cannot determine that it is not.
 	isView := true
@@ -603,6 +715,14 @@ func (dump *MongoDump) DumpIntent(intent *intents.Intent, buffer resettableOutpu
 	}
 
 	findQuery := &db.DeferredQuery{Coll: coll}
+	snapshotSession, err := dump.startSnapshotSession(session)
+	if err != nil {
+		return err
+	}
+	if snapshotSession != nil {
+		defer snapshotSession.EndSession(context.Background())
+		findQuery.Session = snapshotSession
+	}
 	switch {
 	case len(dump.query) > 0:
 		if intent.IsTimeseries() {
@@ -649,6 +769,22 @@ func (dump *MongoDump) DumpIntent(intent *intents.Intent, buffer resettableOutpu
 
 	var dumpCount int64
 
+	if dump.InputOptions.SplitChunks && !isView && !intent.IsTimeseries() &&
+		!intent.IsSpecialCollection() {
+		sharded, err := readShardedCollection(session, intent.Namespace())
+		if err != nil {
+			return err
+		}
+		if sharded != nil {
+			log.Logvf(log.Always, "writing %v to %v from its shards",
+				intent.DataNamespace(), intent.Location)
+			if dumpCount, err = dump.dumpShardRangesToIntent(intent, sharded, buffer); err != nil {
+				return err
+			}
+			return dump.finishIntent(intent, dumpCount)
+		}
+	}
+
 	if dump.OutputOptions.Out == "-" {
 		log.Logvf(log.Always, "writing %v to stdout", intent.DataNamespace())
 		dumpCount, err = dump.dumpQueryToIntent(findQuery, intent, buffer)
@@ -663,6 +799,17 @@ func (dump *MongoDump) DumpIntent(intent *intents.Intent, buffer resettableOutpu
 	if dumpCount, err = dump.dumpQueryToIntent(findQuery, intent, buffer); err != nil {
 		return err
 	}
+	return dump.finishIntent(intent, dumpCount)
+}
+
+// finishIntent records and logs the number of documents dumped for an intent.
+func (dump *MongoDump) finishIntent(intent *intents.Intent, dumpCount int64) error {
+	dump.recordManifest(intent, func(entry *ManifestNamespace) {
+		if dump.OutputOptions.Archive == "" {
+			entry.File = intent.Location
+		}
+		entry.Documents = dumpCount
+	})
 
 	log.Logvf(
 		log.Always,
@@ -703,9 +703,167 @@ func TestMongoDumpValidateOptions(t *testing.T) {
 			)
 		})
 
+		Convey("we cannot specify both a query and a queryFile", func() {
+			md.ToolOptions.Namespace.Collection = "some_collection"
+			md.InputOptions.Query = "{}"
+			md.InputOptions.QueryFile = "query.json"
+
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(
+				err.Error(),
+				ShouldContainSubstring,
+				"either query or queryFile can be specified as a query option, not both",
+			)
+		})
+
+		Convey("we cannot write a manifest when dumping to stdout", func() {
+			md.OutputOptions.WriteManifest = true
+			md.OutputOptions.Archive = "dump.archive"
+			So(md.ValidateOptions(), ShouldBeNil)
+
+			md.OutputOptions.Archive = ""
+			md.OutputOptions.Out = "-"
+			md.ToolOptions.Namespace.Collection = "some_collection"
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(
+				err.Error(),
+				ShouldContainSubstring,
+				"--writeManifest cannot be used when dumping to standard output",
+			)
+		})
+
+		Convey("we can only split chunks when dumping to a directory", func() {
+			md.InputOptions.SplitChunks = true
+			md.OutputOptions.Out = ""
+			md.OutputOptions.Archive = "dump.archive"
+
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(
+				err.Error(),
+				ShouldContainSubstring,
+				"--splitChunks can only be used when dumping to a directory",
+			)
+		})
+
+		Convey("we cannot bound the oplog without --oplog", func() {
+			md.ToolOptions.Namespace.DB = ""
+			md.ToolOptions.Namespace.Collection = ""
+			md.OutputOptions.OplogStart = "100:1"
+
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "cannot use --oplogStart without --oplog")
+
+			md.OutputOptions.Oplog = true
+			So(md.ValidateOptions(), ShouldBeNil)
+		})
+
+		Convey("--continuousOplog needs --oplog and an output directory", func() {
+			md.ToolOptions.Namespace.DB = ""
+			md.ToolOptions.Namespace.Collection = ""
+			md.OutputOptions.ContinuousOplog = true
+
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "cannot use --continuousOplog without --oplog")
+
+			md.OutputOptions.Oplog = true
+			So(md.ValidateOptions(), ShouldBeNil)
+
+			md.OutputOptions.OplogEnd = "100:1"
+			err = md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "cannot use --continuousOplog with --oplogEnd")
+
+			md.OutputOptions.OplogEnd = ""
+			md.OutputOptions.Archive = "dump.archive"
+			err = md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(
+				err.Error(),
+				ShouldContainSubstring,
+				"--continuousOplog can only be used when dumping to a directory",
+			)
+		})
+
+		Convey("--collectionFile replaces --db and --collection", func() {
+			md.OutputOptions.CollectionFile = "collections.txt"
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(
+				err.Error(),
+				ShouldContainSubstring,
+				"cannot use --db or --collection with --collectionFile",
+			)
+
+			md.ToolOptions.Namespace.DB = ""
+			So(md.ValidateOptions(), ShouldBeNil)
+
+			md.OutputOptions.Oplog = true
+			err = md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--oplog mode only supported on full dumps")
+		})
+
+		Convey("--strict requires --collectionFile", func() {
+			md.OutputOptions.Strict = true
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "cannot use --strict without --collectionFile")
+		})
+
+		Convey("--readConcern must be a known level", func() {
+			md.InputOptions.ReadConcern = "snapshot"
+			So(md.ValidateOptions(), ShouldBeNil)
+
+			md.InputOptions.ReadConcern = "linearizable"
+			err := md.ValidateOptions()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid --readConcern")
+		})
+
 	})
 }
 
+func TestMongoDumpSnapshotReadConcern(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	require.NoError(t, err)
+	defer sessionProvider.Close()
+
+	require.NoError(t, setUpMongoDumpTestData())
+	defer func() {
+		require.NoError(t, tearDownMongoDumpTestData())
+	}()
+
+	dumpDir := t.TempDir()
+	md := simpleMongoDumpInstance()
+	md.OutputOptions.Out = dumpDir
+	md.InputOptions.ReadConcern = "snapshot"
+	require.NoError(t, md.Init())
+	require.NoError(t, md.Dump())
+
+	unsupported, err := md.snapshotReadsUnsupported()
+	require.NoError(t, err)
+	if unsupported != "" {
+		require.Nil(t, md.snapshotTime, "no cluster time is pinned when falling back")
+		require.NotNil(t, md.readConcern)
+		return
+	}
+	require.NotNil(t, md.snapshotTime, "a cluster time is pinned for snapshot reads")
+	for _, collName := range testCollectionNames {
+		require.FileExists(
+			t,
+			filepath.Join(dumpDir, testDB, util.EscapeCollectionName(collName)+".bson"),
+		)
+	}
+}
+
 func TestMongoDumpConnectedToAtlasProxy(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
 	log.SetWriter(io.Discard)
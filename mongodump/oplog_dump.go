@@ -8,8 +8,11 @@ package mongodump
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/log"
@@ -111,11 +114,49 @@ func (dump *MongoDump) getOplogCopyStartTime() (primitive.Timestamp, error) {
 	return primitive.Timestamp{T: t, I: i}, nil
 }
 
+// parseOplogWindow parses the timestamps given to --oplogStart and --oplogEnd.
+func (dump *MongoDump) parseOplogWindow() error {
+	var err error
+	if dump.OutputOptions.OplogStart != "" {
+		dump.oplogWindowStart, err = util.ParseTimestampFlag(dump.OutputOptions.OplogStart)
+		if err != nil {
+			return fmt.Errorf("error parsing timestamp argument to --oplogStart: %v", err)
+		}
+	}
+	if dump.OutputOptions.OplogEnd != "" {
+		dump.oplogWindowEnd, err = util.ParseTimestampFlag(dump.OutputOptions.OplogEnd)
+		if err != nil {
+			return fmt.Errorf("error parsing timestamp argument to --oplogEnd: %v", err)
+		}
+	}
+	if !dump.oplogWindowStart.IsZero() && !dump.oplogWindowEnd.IsZero() &&
+		util.TimestampGreaterThan(dump.oplogWindowStart, dump.oplogWindowEnd) {
+		return fmt.Errorf("--oplogStart %v is after --oplogEnd %v",
+			dump.oplogWindowStart, dump.oplogWindowEnd)
+	}
+	return nil
+}
+
 // checkOplogTimestampExists checks to make sure the oplog hasn't rolled over
 // since mongodump started. It does this by checking the oldest oplog entry
 // still in the database and making sure it happened at or before the timestamp
 // captured at the start of the dump.
 func (dump *MongoDump) checkOplogTimestampExists(ts primitive.Timestamp) (bool, error) {
+	oldest, err := dump.getOldestOplogTime()
+	if err != nil {
+		return false, err
+	}
+
+	log.Logvf(log.DebugHigh, "oldest oplog entry has timestamp %v", oldest)
+	if util.TimestampGreaterThan(oldest, ts) {
+		log.Logvf(log.Info, "oldest oplog entry of timestamp %v is newer than %v", oldest, ts)
+		return false, nil
+	}
+	return true, nil
+}
+
+// getOldestOplogTime returns the timestamp of the oldest entry in the oplog.
+func (dump *MongoDump) getOldestOplogTime() (primitive.Timestamp, error) {
 	oldestOplogEntry := db.Oplog{}
 	var tempBSON bson.Raw
 
@@ -129,20 +170,13 @@ func (dump *MongoDump) checkOplogTimestampExists(ts primitive.Timestamp) (bool,
 		0,
 	)
 	if err != nil {
-		return false, fmt.Errorf("unable to read entry from oplog: %v", err)
+		return primitive.Timestamp{}, fmt.Errorf("unable to read entry from oplog: %w", err)
 	}
 	err = bson.Unmarshal(tempBSON, &oldestOplogEntry)
 	if err != nil {
-		return false, err
-	}
-
-	log.Logvf(log.DebugHigh, "oldest oplog entry has timestamp %v", oldestOplogEntry.Timestamp)
-	if util.TimestampGreaterThan(oldestOplogEntry.Timestamp, ts) {
-		log.Logvf(log.Info, "oldest oplog entry of timestamp %v is newer than %v",
-			oldestOplogEntry.Timestamp, ts)
-		return false, nil
+		return primitive.Timestamp{}, err
 	}
-	return true, nil
+	return oldestOplogEntry.Timestamp, nil
 }
 
 func oplogDocumentValidator(in []byte) error {
@@ -208,8 +242,162 @@ func (dump *MongoDump) DumpOplogBetweenTimestamps(start, end primitive.Timestamp
 		oplogDocumentValidator,
 	)
 	if err == nil {
+		dump.oplogCount = oplogCount
 		log.Logvf(log.Always, "\tdumped %v oplog %v",
 			oplogCount, util.Pluralize(int(oplogCount), "entry", "entries"))
 	}
 	return err
 }
+
+// Bounds on the delay before the oplog is tailed again after an error, with
+// --continuousOplog.
+const (
+	minOplogTailBackoff = time.Second
+	maxOplogTailBackoff = 30 * time.Second
+)
+
+// oplogTailAwaitTime is how long the server waits for new oplog entries before
+// answering a getMore on the tailing cursor.
+const oplogTailAwaitTime = time.Second
+
+// TailOplog appends the oplog entries written after the end of the captured
+// oplog to the oplog file as they arrive, until mongodump is interrupted. If
+// the cursor is lost to a transient or network error, the oplog is tailed
+// again from the last entry written. On return, the end of the captured
+// oplog is the last entry written.
+func (dump *MongoDump) TailOplog() (err error) {
+	file := dump.newBSONFile(dump.outputPath("oplog.bson", ""), dump.manager.Oplog())
+	file.appending = true
+	if err = file.Open(); err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("error writing oplog to disk: %v", closeErr)
+		}
+	}()
+
+	var w io.Writer = file
+	if buffer := dump.getResettableOutputBuffer(); buffer != nil {
+		buffer.Reset(file)
+		w = buffer
+		defer func() {
+			closeErr := buffer.Close()
+			if err == nil && closeErr != nil {
+				err = fmt.Errorf("error writing oplog to disk: %v", closeErr)
+			}
+		}()
+	}
+
+	// the shutdown notifier cancels the context, which ends the tailing cleanly
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-dump.shutdownIntentsNotifier.notified:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := minOplogTailBackoff
+	for {
+		count, err := dump.tailOplogFrom(ctx, w)
+		dump.oplogCount += count
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil && !isResumableOplogError(err) {
+			return err
+		}
+		if count > 0 {
+			backoff = minOplogTailBackoff
+		}
+		if err != nil {
+			log.Logvf(log.Always, "lost the oplog after %v, resuming in %v: %v",
+				dump.oplogEnd, backoff, err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxOplogTailBackoff {
+			backoff = maxOplogTailBackoff
+		}
+	}
+
+	log.Logvf(log.Always, "stopped tailing the oplog; the last entry written has timestamp %v",
+		dump.oplogEnd)
+	return nil
+}
+
+// tailOplogFrom opens a tailable cursor on the oplog entries after the end of
+// the captured oplog, and writes them to w as they arrive, moving the end
+// along. Each batch is flushed once written. Returns the number of entries
+// written when the cursor is lost or ctx is cancelled.
+func (dump *MongoDump) tailOplogFrom(ctx context.Context, w io.Writer) (int64, error) {
+	exists, err := dump.checkOplogTimestampExists(dump.oplogEnd)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf(
+			"oplog overflow: the entries after %v have rolled off the oplog", dump.oplogEnd)
+	}
+
+	session, err := dump.SessionProvider.GetSession()
+	if err != nil {
+		return 0, err
+	}
+	coll := session.Database("local").Collection(dump.oplogCollection)
+	findOpts := mopt.Find().
+		SetCursorType(mopt.TailableAwait).
+		SetMaxAwaitTime(oplogTailAwaitTime)
+	cursor, err := coll.Find(ctx, bson.D{{"ts", bson.D{{"$gt", dump.oplogEnd}}}}, findOpts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	var count int64
+	for cursor.Next(ctx) {
+		t, i, ok := cursor.Current.Lookup("ts").TimestampOK()
+		if !ok {
+			return count, fmt.Errorf("oplog entry has no timestamp: %v", cursor.Current)
+		}
+		if _, err := w.Write(cursor.Current); err != nil {
+			return count, fmt.Errorf("error writing oplog to disk: %v", err)
+		}
+		dump.oplogEnd = primitive.Timestamp{T: t, I: i}
+		count++
+
+		if cursor.RemainingBatchLength() == 0 {
+			if err := flushWriter(w); err != nil {
+				return count, fmt.Errorf("error writing oplog to disk: %v", err)
+			}
+			log.Logvf(log.DebugLow, "tailed oplog up to %v", dump.oplogEnd)
+		}
+	}
+	if err := flushWriter(w); err != nil {
+		return count, fmt.Errorf("error writing oplog to disk: %v", err)
+	}
+	return count, cursor.Err()
+}
+
+// flushWriter flushes w, if it buffers what is written to it.
+func flushWriter(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// isResumableOplogError returns true if tailing the oplog can be resumed after
+// err, as the connection or the cursor was lost rather than the oplog itself.
+func isResumableOplogError(err error) bool {
+	var serverErr mongo.ServerError
+	return db.IsTransientError(err) || mongo.IsNetworkError(err) || mongo.IsTimeout(err) ||
+		(errors.As(err, &serverErr) && serverErr.HasErrorCode(43)) // CursorNotFound
+}
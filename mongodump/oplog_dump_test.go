@@ -7,12 +7,16 @@
 package mongodump
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/failpoint"
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -21,6 +25,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -37,6 +42,40 @@ func TestErrorOnImportCollection(t *testing.T) {
 	})
 }
 
+func TestParseOplogWindow(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	parse := func(start, end string) (*MongoDump, error) {
+		dump := &MongoDump{
+			OutputOptions: &OutputOptions{Oplog: true, OplogStart: start, OplogEnd: end},
+		}
+		return dump, dump.parseOplogWindow()
+	}
+
+	dump, err := parse("", "")
+	require.NoError(t, err)
+	require.True(t, dump.oplogWindowStart.IsZero())
+	require.True(t, dump.oplogWindowEnd.IsZero())
+
+	dump, err = parse("100:2", "200")
+	require.NoError(t, err)
+	require.Equal(t, primitive.Timestamp{T: 100, I: 2}, dump.oplogWindowStart)
+	require.Equal(t, primitive.Timestamp{T: 200}, dump.oplogWindowEnd)
+
+	dump, err = parse("100:2", "100:2")
+	require.NoError(t, err)
+	require.Equal(t, dump.oplogWindowStart, dump.oplogWindowEnd)
+
+	_, err = parse("200", "100")
+	require.ErrorContains(t, err, "is after --oplogEnd")
+
+	_, err = parse("cats", "")
+	require.ErrorContains(t, err, "--oplogStart")
+
+	_, err = parse("", "1:2:3")
+	require.ErrorContains(t, err, "--oplogEnd")
+}
+
 // TestOplogDumpVectoredInsertsOplog tests dumping oplogs that are from vectored inserts.
 // They have a special oplog format.
 func TestOplogDumpVectoredInsertsOplog(t *testing.T) {
@@ -130,3 +169,103 @@ func vectoredInsert(ctx context.Context) error {
 
 	return nil
 }
+
+func TestOplogStartRolledOff(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	// Oplog is not available in a standalone topology.
+	testtype.SkipUnlessTestType(t, testtype.ReplSetTestType)
+
+	log.SetWriter(io.Discard)
+
+	md := simpleMongoDumpInstance()
+	md.ToolOptions.Namespace.DB = ""
+	md.OutputOptions.Oplog = true
+	// no oplog keeps entries from the first second of 1970
+	md.OutputOptions.OplogStart = "1:1"
+	md.OutputOptions.Out = t.TempDir()
+	require.NoError(t, md.Init())
+	require.NoError(t, md.determineOplogCollectionName())
+
+	oldest, err := md.getOldestOplogTime()
+	require.NoError(t, err)
+	require.NoError(t, md.Dump())
+	require.False(t, util.TimestampGreaterThan(oldest, md.oplogStart))
+	require.True(t, util.TimestampGreaterThan(md.oplogStart, primitive.Timestamp{T: 1, I: 1}))
+}
+
+func TestIsResumableOplogError(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	require.True(t, isResumableOplogError(mongo.CommandError{Code: 43, Name: "CursorNotFound"}))
+	require.True(t, isResumableOplogError(mongo.CommandError{Code: 189}))
+	require.True(t, isResumableOplogError(
+		mongo.CommandError{Labels: []string{"NetworkError"}},
+	))
+	require.False(t, isResumableOplogError(
+		mongo.CommandError{Code: 136, Name: "CappedPositionLost"},
+	))
+	require.False(t, isResumableOplogError(errors.New("error writing oplog to disk")))
+}
+
+func TestTailOplog(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	// Oplog is not available in a standalone topology.
+	testtype.SkipUnlessTestType(t, testtype.ReplSetTestType)
+
+	log.SetWriter(io.Discard)
+
+	session, err := testutil.GetBareSession()
+	require.NoError(t, err)
+	coll := session.Database(testDB).Collection("tail_oplog")
+	//nolint:errcheck
+	defer tearDownMongoDumpTestData()
+
+	dumpDir := t.TempDir()
+	md := simpleMongoDumpInstance()
+	md.ToolOptions.Namespace.DB = ""
+	md.OutputOptions.Oplog = true
+	md.OutputOptions.ContinuousOplog = true
+	md.OutputOptions.Out = dumpDir
+	require.NoError(t, md.Init())
+	md.shutdownIntentsNotifier = newNotifier()
+	require.NoError(t, md.CreateOplogIntents())
+	md.oplogEnd, err = md.getCurrentOplogTime()
+	require.NoError(t, err)
+	start := md.oplogEnd
+
+	tailErr := make(chan error, 1)
+	go func() {
+		tailErr <- md.TailOplog()
+	}()
+
+	// insert documents until one of them shows up in the tailed oplog
+	oplogPath := filepath.Join(dumpDir, "oplog.bson")
+	tailed := func() bool {
+		contents, err := os.ReadFile(oplogPath)
+		return err == nil && bytes.Contains(contents, []byte("tail_oplog"))
+	}
+	for i := 0; !tailed(); i++ {
+		require.Less(t, i, 300, "inserts were not appended to the oplog")
+		_, err := coll.InsertOne(context.Background(), bson.D{{"_id", i}})
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	md.HandleInterrupt()
+	require.NoError(t, <-tailErr)
+	require.Positive(t, md.oplogCount)
+	require.True(t, util.TimestampGreaterThan(md.oplogEnd, start))
+
+	oplogFile, err := os.Open(oplogPath)
+	require.NoError(t, err)
+	defer oplogFile.Close()
+	var last db.Oplog
+	iter := db.NewDecodedBSONSource(db.NewBSONSource(oplogFile))
+	var count int64
+	for iter.Next(&last) {
+		count++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, md.oplogCount, count)
+	require.Equal(t, md.oplogEnd, last.Timestamp)
+}
@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/options"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 var Usage = `<options> <connection-string>
@@ -25,10 +28,13 @@ See http://docs.mongodb.com/database-tools/mongodump/ for more information.`
 
 // InputOptions defines the set of options to use in retrieving data from the server.
 type InputOptions struct {
-	Query          string `long:"query" short:"q" description:"query filter, as a v2 Extended JSON string, e.g., '{\"x\":{\"$gt\":1}}'"`
-	QueryFile      string `long:"queryFile" description:"path to a file containing a query filter (v2 Extended JSON)"`
+	Query          string `long:"query" short:"q" description:"query filter, as an Extended JSON string, e.g., '{\"x\":{\"$gt\":1}}'; shell literals such as NumberLong(5) and ISODate(\"...\") are also accepted"`
+	QueryFile      string `long:"queryFile" description:"path to a file containing a query filter, in the same Extended JSON as --query"`
 	ReadPreference string `long:"readPreference" value-name:"<string>|<json>" description:"specify either a preference mode (e.g. 'nearest') or a preference json object (e.g. '{mode: \"nearest\", tagSets: [{a: \"b\"}], maxStalenessSeconds: 123}')"`
 	TableScan      bool   `long:"forceTableScan" description:"force a table scan (do not use $snapshot or hint _id). Deprecated since this is default behavior on WiredTiger"`
+	ReadConcern    string `long:"readConcern" value-name:"<level>" description:"read concern level for reading collections: local, available, majority or snapshot. With snapshot, every collection is read at the same cluster time, which needs a replica set or sharded cluster running MongoDB 5.0 or later and a dump that finishes within the server's snapshot history window; other deployments fall back to majority with a warning"`
+
+	SplitChunks bool `long:"splitChunks" description:"when connected to a mongos, read each range of chunks of a sharded collection directly from the shard that owns it, reading from all shards in parallel; the credentials used must be valid on every shard. A collection is dumped again if chunks migrate while it is read"`
 }
 
 // Name returns a human-readable group name for input options.
@@ -53,17 +59,51 @@ func (inputOptions *InputOptions) GetQuery() ([]byte, error) {
 	panic("GetQuery can return valid values only for query or queryFile input")
 }
 
+// ParseQuery parses the query or queryFile input as Extended JSON with the
+// common/json parser, which also accepts shell literals such as NumberLong(5)
+// and ISODate("...").
+func (inputOptions *InputOptions) ParseQuery() (bson.D, error) {
+	content, err := inputOptions.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	query, err := json.UnmarshalBsonD(content)
+	if err == nil {
+		query, err = bsonutil.GetExtendedBsonD(query)
+	}
+	if err != nil {
+		if inputOptions.QueryFile != "" {
+			return nil, fmt.Errorf(
+				"error parsing queryFile %v as Extended JSON: %v",
+				inputOptions.QueryFile,
+				err,
+			)
+		}
+		return nil, fmt.Errorf("error parsing query as Extended JSON: %v", err)
+	}
+	return query, nil
+}
+
 // OutputOptions defines the set of options for writing dump data.
 type OutputOptions struct {
 	Out                        string   `long:"out" value-name:"<directory-path>" short:"o" description:"output directory, or '-' for stdout (default: 'dump')"`
 	Gzip                       bool     `long:"gzip" description:"compress archive or collection output with Gzip"`
 	Oplog                      bool     `long:"oplog" description:"for taking a point-in-time snapshot on a replica set that is not part of a sharded cluster."`
+	OplogStart                 string   `long:"oplogStart" value-name:"<seconds>[:ordinal]" description:"with --oplog, capture oplog entries starting at the provided Timestamp instead of the start of the dump; if the Timestamp is no longer in the oplog, the oldest entry still in the oplog is used instead"`
+	OplogEnd                   string   `long:"oplogEnd" value-name:"<seconds>[:ordinal]" description:"with --oplog, capture oplog entries up to and including the provided Timestamp instead of the end of the dump"`
 	Archive                    string   `long:"archive" value-name:"<file-path>" optional:"true" optional-value:"-" description:"dump as an archive to the specified path. If flag is specified without a value, archive is written to stdout"`
 	DumpDBUsersAndRoles        bool     `long:"dumpDbUsersAndRoles" description:"dump user and role definitions for the specified database"`
 	ExcludedCollections        []string `long:"excludeCollection" value-name:"<collection-name>" description:"collection to exclude from the dump (may be specified multiple times to exclude additional collections)"`
-	ExcludedCollectionPrefixes []string `long:"excludeCollectionsWithPrefix" value-name:"<collection-prefix>" description:"exclude all collections from the dump that have the given prefix (may be specified multiple times to exclude additional prefixes)"`
+	ExcludedCollectionPrefixes []string `long:"excludeCollectionsWithPrefix" value-name:"<collection-prefix>" description:"exclude all collections from the dump that have the given prefix, or that match the given pattern if it contains '*' wildcards, e.g. '*_archive' (may be specified multiple times to exclude additional prefixes)"`
 	NumParallelCollections     int      `long:"numParallelCollections" short:"j" description:"number of collections to dump in parallel" default:"4" default-mask:"-"`
 	ViewsAsCollections         bool     `long:"viewsAsCollections" description:"dump views as normal collections with their produced data, omitting standard collections"`
+	WriteManifest              bool     `long:"writeManifest" description:"after a successful dump, write manifest.json to the output directory listing the document count, file size and index count of each collection, and the oplog timestamps with --oplog; with --archive, the manifest is written at the end of the archive instead"`
+	WriteChecksums             bool     `long:"writeChecksums" description:"write a SHA-256 checksum of each .bson file to a .sha256 file next to it, for mongorestore --verifyChecksums; archives always include a checksum of each collection"`
+
+	CollectionFile string `long:"collectionFile" value-name:"<filename>" description:"file listing the collections to dump, one <db>.<collection> namespace per line; only those collections are dumped. Blank lines and lines starting with '#' are ignored"`
+	Strict         bool   `long:"strict" description:"with --collectionFile, fail if a listed collection does not exist instead of warning and skipping it"`
+
+	ContinuousOplog bool `long:"continuousOplog" description:"with --oplog, keep tailing the oplog after the dump, appending new entries to oplog.bson until interrupted; the timestamp of the last entry written is logged, and recorded in manifest.json with --writeManifest"`
 }
 
 // Name returns a human-readable group name for output options.
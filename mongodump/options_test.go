@@ -7,11 +7,16 @@
 package mongodump
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
@@ -138,3 +143,61 @@ func TestPositionalArgumentParsing(t *testing.T) {
 		}
 	})
 }
+
+func TestParseQuery(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a queryFile", t, func() {
+		queryFile := filepath.Join(t.TempDir(), "query.json")
+		inputOptions := &InputOptions{QueryFile: queryFile}
+
+		Convey("Extended JSON literals are parsed like an inline query", func() {
+			content := `{"count": {"$numberLong": "5"}, ` +
+				`"ts": {"$gte": {"$date": "2021-03-01T10:00:00Z"}}}`
+			So(os.WriteFile(queryFile, []byte(content), 0o644), ShouldBeNil)
+
+			query, err := inputOptions.ParseQuery()
+			So(err, ShouldBeNil)
+
+			inline, err := (&InputOptions{Query: content}).ParseQuery()
+			So(err, ShouldBeNil)
+			So(query, ShouldResemble, inline)
+			So(query, ShouldResemble, bson.D{
+				{"count", int64(5)},
+				{"ts", bson.D{{"$gte", time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)}}},
+			})
+		})
+
+		Convey("shell literals are parsed like their Extended JSON forms", func() {
+			content := `{"count": NumberLong(5), ` +
+				`"ts": {"$gte": ISODate("2021-03-01T10:00:00Z")}, ` +
+				`"_id": ObjectId("5f0c8c3e2f8fb814b56fa181")}`
+			So(os.WriteFile(queryFile, []byte(content), 0o644), ShouldBeNil)
+
+			query, err := inputOptions.ParseQuery()
+			So(err, ShouldBeNil)
+
+			oid, err := primitive.ObjectIDFromHex("5f0c8c3e2f8fb814b56fa181")
+			So(err, ShouldBeNil)
+			So(query, ShouldResemble, bson.D{
+				{"count", int64(5)},
+				{"ts", bson.D{{"$gte", time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)}}},
+				{"_id", oid},
+			})
+		})
+
+		Convey("a parse error names the file", func() {
+			So(os.WriteFile(queryFile, []byte(`{"a": `), 0o644), ShouldBeNil)
+
+			_, err := inputOptions.ParseQuery()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "error parsing queryFile "+queryFile)
+		})
+
+		Convey("a missing file is reported", func() {
+			_, err := inputOptions.ParseQuery()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "error reading queryFile")
+		})
+	})
+}
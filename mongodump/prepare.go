@@ -10,8 +10,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
@@ -51,6 +53,12 @@ type realBSONFile struct {
 	errorReader
 	intent *intents.Intent
 	NilPos
+	// checksum, if set, records the SHA-256 of the file in a sidecar on Close
+	checksum bool
+	hash     hash.Hash
+	// appending, if set, makes Open add to an existing file instead of
+	// replacing it
+	appending bool
 }
 
 // Open is part of the intents.file interface. realBSONFiles need to have Open called before
@@ -67,6 +75,13 @@ func (f *realBSONFile) Open() (err error) {
 			filepath.Dir(f.path), err)
 	}
 
+	if f.checksum {
+		f.hash = sha256.New()
+	}
+	if f.appending {
+		return f.openForAppend()
+	}
+
 	f.WriteCloser, err = os.Create(f.path)
 	if err != nil {
 		return fmt.Errorf("error creating BSON file %v: %v", f.path, err)
@@ -75,6 +90,48 @@ func (f *realBSONFile) Open() (err error) {
 	return nil
 }
 
+// openForAppend opens the BSON file to add to it, feeding what is already in
+// it to the checksum, if one is being recorded.
+func (f *realBSONFile) openForAppend() error {
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("error opening BSON file %v: %v", f.path, err)
+	}
+	if f.hash != nil {
+		if _, err := io.Copy(f.hash, file); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("error reading BSON file %v: %v", f.path, err)
+		}
+	}
+	f.WriteCloser = file
+	return nil
+}
+
+// Write is part of the intents.file interface. It also feeds the checksum,
+// if one is being recorded.
+func (f *realBSONFile) Write(p []byte) (int, error) {
+	n, err := f.WriteCloser.Write(p)
+	if f.hash != nil {
+		f.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// Close is part of the intents.file interface. It writes the checksum
+// sidecar once the file is complete.
+func (f *realBSONFile) Close() error {
+	if err := f.WriteCloser.Close(); err != nil {
+		return err
+	}
+	if f.hash == nil {
+		return nil
+	}
+	if err := dumprestore.WriteChecksumFile(f.path, f.hash.Sum(nil)); err != nil {
+		return fmt.Errorf("error writing checksum for BSON file %v: %v", f.path, err)
+	}
+	return nil
+}
+
 // realMetadataFile implements intent.file, and corresponds to a Metadata file on disk.
 type realMetadataFile struct {
 	io.WriteCloser
@@ -184,21 +241,56 @@ func (dump *MongoDump) shouldSkipCollection(colName string) bool {
 		}
 	}
 	for _, excludedCollectionPrefix := range dump.OutputOptions.ExcludedCollectionPrefixes {
-		if strings.HasPrefix(colName, excludedCollectionPrefix) {
+		if strings.Contains(excludedCollectionPrefix, "*") {
+			// system collections are left to their own rules rather than
+			// being caught by a wildcard
+			if !strings.HasPrefix(colName, "system.") &&
+				matchesCollectionGlob(excludedCollectionPrefix, colName) {
+				return true
+			}
+		} else if strings.HasPrefix(colName, excludedCollectionPrefix) {
 			return true
 		}
 	}
 	return false
 }
 
-// outputPath creates a path for the collection to be written to (sans file extension).
-func (dump *MongoDump) outputPath(dbName, colName string) string {
-	var root string
+// matchesCollectionGlob reports whether the whole of colName matches pattern,
+// in which each '*' stands for any sequence of characters.
+func matchesCollectionGlob(pattern, colName string) bool {
+	parts := strings.Split(pattern, "*")
+	first, last := parts[0], parts[len(parts)-1]
+	if !strings.HasPrefix(colName, first) {
+		return false
+	}
+	rest := colName[len(first):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, last)
+}
+
+// newBSONFile returns a realBSONFile for intent at path, recording its
+// checksum when --writeChecksums is set.
+func (dump *MongoDump) newBSONFile(path string, intent *intents.Intent) *realBSONFile {
+	return &realBSONFile{path: path, intent: intent, checksum: dump.OutputOptions.WriteChecksums}
+}
+
+// outputRoot returns the directory that the dump is written to.
+func (dump *MongoDump) outputRoot() string {
 	if dump.OutputOptions.Out == "" {
-		root = "dump"
-	} else {
-		root = dump.OutputOptions.Out
+		return "dump"
 	}
+	return dump.OutputOptions.Out
+}
+
+// outputPath creates a path for the collection to be written to (sans file extension).
+func (dump *MongoDump) outputPath(dbName, colName string) string {
+	root := dump.outputRoot()
 
 	// Encode a new output path for collection names that would result in a file name greater
 	// than 255 bytes long. This includes the longest possible file extension: .metadata.json.gz
@@ -231,7 +323,7 @@ func (dump *MongoDump) CreateOplogIntents() error {
 	if dump.OutputOptions.Archive != "" {
 		oplogIntent.BSONFile = &archive.MuxIn{Mux: dump.archive.Mux, Intent: oplogIntent}
 	} else {
-		oplogIntent.BSONFile = &realBSONFile{path: dump.outputPath("oplog.bson", ""), intent: oplogIntent}
+		oplogIntent.BSONFile = dump.newBSONFile(dump.outputPath("oplog.bson", ""), oplogIntent)
 	}
 	dump.manager.Put(oplogIntent)
 	return nil
@@ -261,9 +353,9 @@ func (dump *MongoDump) CreateUsersRolesVersionIntentsForDB(db string) error {
 		rolesIntent.BSONFile = &archive.MuxIn{Intent: rolesIntent, Mux: dump.archive.Mux}
 		versionIntent.BSONFile = &archive.MuxIn{Intent: versionIntent, Mux: dump.archive.Mux}
 	} else {
-		usersIntent.BSONFile = &realBSONFile{path: filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.users.bson")), intent: usersIntent}
-		rolesIntent.BSONFile = &realBSONFile{path: filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.roles.bson")), intent: rolesIntent}
-		versionIntent.BSONFile = &realBSONFile{path: filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.version.bson")), intent: versionIntent}
+		usersIntent.BSONFile = dump.newBSONFile(filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.users.bson")), usersIntent)
+		rolesIntent.BSONFile = dump.newBSONFile(filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.roles.bson")), rolesIntent)
+		versionIntent.BSONFile = dump.newBSONFile(filepath.Join(outDir, nameGz(dump.OutputOptions.Gzip, "$admin.system.version.bson")), versionIntent)
 	}
 	dump.manager.Put(usersIntent)
 	dump.manager.Put(rolesIntent)
@@ -330,7 +422,7 @@ func (dump *MongoDump) NewIntentFromOptions(
 			}
 		} else if ci.IsTimeseries() {
 			path := nameGz(dump.OutputOptions.Gzip, dump.outputPath(dbName, "system.buckets."+ci.Name)+".bson")
-			intent.BSONFile = &realBSONFile{path: path, intent: intent}
+			intent.BSONFile = dump.newBSONFile(path, intent)
 			intent.Location = path
 		} else if ci.IsView() && !dump.OutputOptions.ViewsAsCollections {
 			log.Logvf(log.DebugLow, "not dumping data for %v.%v because it is a view", dbName, ci.Name)
@@ -338,7 +430,7 @@ func (dump *MongoDump) NewIntentFromOptions(
 			// otherwise, if it's either not a view or we're treating views as collections
 			// then create a standard filesystem path for this collection.
 			path := nameGz(dump.OutputOptions.Gzip, dump.outputPath(dbName, ci.Name)+".bson")
-			intent.BSONFile = &realBSONFile{path: path, intent: intent}
+			intent.BSONFile = dump.newBSONFile(path, intent)
 			intent.Location = path
 		}
 
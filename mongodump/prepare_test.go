@@ -7,11 +7,17 @@
 package mongodump
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/dumprestore"
+	"github.com/mongodb/mongo-tools/common/intents"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestSkipCollection(t *testing.T) {
@@ -56,6 +62,41 @@ func TestSkipCollection(t *testing.T) {
 		})
 	})
 
+	Convey("With a mongodump that excludes the patterns 'tmp_*', '*_archive' and 'a*b*c'",
+		t, func() {
+			md := &MongoDump{
+				OutputOptions: &OutputOptions{
+					ExcludedCollectionPrefixes: []string{"tmp_*", "*_archive", "a*b*c"},
+				},
+			}
+
+			Convey("collections matching a pattern should be skipped", func() {
+				So(md.shouldSkipCollection("tmp_"), ShouldBeTrue)
+				So(md.shouldSkipCollection("tmp_2024.jan"), ShouldBeTrue)
+				So(md.shouldSkipCollection("orders_archive"), ShouldBeTrue)
+				So(md.shouldSkipCollection("_archive"), ShouldBeTrue)
+				So(md.shouldSkipCollection("abc"), ShouldBeTrue)
+				So(md.shouldSkipCollection("a.b.b.c"), ShouldBeTrue)
+			})
+
+			Convey("collections only partly matching a pattern should not be skipped", func() {
+				So(md.shouldSkipCollection("my_tmp_data"), ShouldBeFalse)
+				So(md.shouldSkipCollection("orders_archive_2"), ShouldBeFalse)
+				So(md.shouldSkipCollection("acb"), ShouldBeFalse)
+				So(md.shouldSkipCollection("abcd"), ShouldBeFalse)
+			})
+
+			Convey("system collections should not be matched by a pattern", func() {
+				So(md.shouldSkipCollection("system.views_archive"), ShouldBeFalse)
+				So(md.shouldSkipCollection("system.buckets.a_b_c"), ShouldBeFalse)
+			})
+
+			Convey("literal prefixes should still apply to system collections", func() {
+				md.OutputOptions.ExcludedCollectionPrefixes = []string{"system.buckets."}
+				So(md.shouldSkipCollection("system.buckets.weather"), ShouldBeTrue)
+			})
+		})
+
 }
 
 type testTable struct {
@@ -215,3 +256,99 @@ func TestShouldSkipSystemNamespace(t *testing.T) {
 		}
 	}
 }
+
+func TestBSONFileChecksum(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a mongodump writing checksums", t, func() {
+		md := &MongoDump{OutputOptions: &OutputOptions{WriteChecksums: true}}
+		path := filepath.Join(t.TempDir(), "test", "coll.bson")
+		intent := &intents.Intent{DB: "test", C: "coll"}
+
+		Convey("closing a BSON file records a matching checksum", func() {
+			file := md.newBSONFile(path, intent)
+			So(file.Open(), ShouldBeNil)
+			_, err := file.Write([]byte("some bson bytes"))
+			So(err, ShouldBeNil)
+			So(file.Close(), ShouldBeNil)
+
+			So(dumprestore.VerifyChecksum(path), ShouldBeNil)
+		})
+
+		Convey("appending to a BSON file keeps its contents in the checksum", func() {
+			file := md.newBSONFile(path, intent)
+			So(file.Open(), ShouldBeNil)
+			_, err := file.Write([]byte("some bson bytes"))
+			So(err, ShouldBeNil)
+			So(file.Close(), ShouldBeNil)
+
+			file = md.newBSONFile(path, intent)
+			file.appending = true
+			So(file.Open(), ShouldBeNil)
+			_, err = file.Write([]byte(" and some more"))
+			So(err, ShouldBeNil)
+			So(file.Close(), ShouldBeNil)
+
+			contents, err := os.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(contents), ShouldEqual, "some bson bytes and some more")
+			So(dumprestore.VerifyChecksum(path), ShouldBeNil)
+		})
+
+		Convey("no checksum is recorded without the option", func() {
+			md.OutputOptions.WriteChecksums = false
+			file := md.newBSONFile(path, intent)
+			So(file.Open(), ShouldBeNil)
+			So(file.Close(), ShouldBeNil)
+
+			_, err := os.Stat(path + dumprestore.ChecksumSuffix)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+}
+
+func TestNewIntentFromOptionsForViews(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	viewInfo := func() *db.CollectionInfo {
+		return &db.CollectionInfo{
+			Name: "myView",
+			Type: "view",
+			Options: bson.D{
+				{"viewOn", "coll"},
+				{"pipeline", bson.A{bson.D{{"$match", bson.D{{"a", 1}}}}}},
+			},
+		}
+	}
+
+	Convey("With a view from listCollections", t, func() {
+		dump := &MongoDump{OutputOptions: &OutputOptions{Out: "dump"}}
+
+		Convey("it is dumped as its definition by default", func() {
+			intent, err := dump.NewIntentFromOptions("db", viewInfo())
+			So(err, ShouldBeNil)
+			So(intent.IsView(), ShouldBeTrue)
+			So(intent.BSONFile, ShouldBeNil)
+			So(intent.MetadataFile, ShouldNotBeNil)
+
+			viewOn, err := bsonutil.FindValueByKey("viewOn", &intent.Options)
+			So(err, ShouldBeNil)
+			So(viewOn, ShouldEqual, "coll")
+			_, err = bsonutil.FindValueByKey("pipeline", &intent.Options)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("it is dumped as a collection with --viewsAsCollections", func() {
+			dump.OutputOptions.ViewsAsCollections = true
+			intent, err := dump.NewIntentFromOptions("db", viewInfo())
+			So(err, ShouldBeNil)
+			So(intent.BSONFile, ShouldNotBeNil)
+			So(intent.Location, ShouldEqual, filepath.Join("dump", "db", "myView.bson"))
+
+			_, err = bsonutil.FindValueByKey("viewOn", &intent.Options)
+			So(err, ShouldNotBeNil)
+			_, err = bsonutil.FindValueByKey("pipeline", &intent.Options)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+)
+
+const snapshotReadConcern = "snapshot"
+
+// readConcernLevels are the levels accepted by --readConcern.
+var readConcernLevels = []string{"local", "available", "majority", snapshotReadConcern}
+
+// minSnapshotReadVersion is the first server version that supports snapshot
+// reads outside of transactions.
+var minSnapshotReadVersion = db.Version{5, 0, 0}
+
+// setUpReadConcern prepares the read concern from --readConcern for reading
+// collections. For snapshot, it pins the cluster time of a first snapshot read
+// so that every collection is read at that same time. If the deployment can't
+// do snapshot reads, it warns and falls back to majority.
+func (dump *MongoDump) setUpReadConcern() error {
+	level := dump.InputOptions.ReadConcern
+	if level == "" {
+		return nil
+	}
+	if level == snapshotReadConcern {
+		reason, err := dump.snapshotReadsUnsupported()
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			return dump.pinSnapshotTime()
+		}
+		log.Logvf(log.Always,
+			"warning: --readConcern snapshot is not supported because %v; "+
+				"reading each collection with majority read concern instead", reason)
+		level = "majority"
+	}
+	dump.readConcern = readconcern.New(readconcern.Level(level))
+	return nil
+}
+
+// snapshotReadsUnsupported returns why the deployment can't do snapshot reads,
+// or the empty string if it can.
+func (dump *MongoDump) snapshotReadsUnsupported() (string, error) {
+	nodeType, err := dump.SessionProvider.GetNodeType()
+	if err != nil {
+		return "", fmt.Errorf("error determining the type of node: %v", err)
+	}
+	if nodeType != db.ReplSet && nodeType != db.Mongos {
+		return "it requires a replica set or sharded cluster", nil
+	}
+	version, err := dump.SessionProvider.ServerVersionArray()
+	if err != nil {
+		return "", fmt.Errorf("error getting the server version: %v", err)
+	}
+	if !version.GTE(minSnapshotReadVersion) {
+		return fmt.Sprintf("it requires MongoDB %v or later", minSnapshotReadVersion), nil
+	}
+	return "", nil
+}
+
+// pinSnapshotTime runs a snapshot read on the first collection to dump, and
+// keeps the cluster time the server chose for it. Every collection is then
+// read in a session that uses that time.
+func (dump *MongoDump) pinSnapshotTime() error {
+	var first *intents.Intent
+	for _, intent := range dump.manager.NormalIntents() {
+		if !intent.IsView() {
+			first = intent
+			break
+		}
+	}
+	if first == nil {
+		return nil
+	}
+
+	client, err := dump.SessionProvider.GetSession()
+	if err != nil {
+		return err
+	}
+	session, err := client.StartSession(mopt.Session().SetSnapshot(true))
+	if err != nil {
+		return fmt.Errorf("error starting a snapshot session: %v", err)
+	}
+	defer session.EndSession(context.Background())
+
+	coll := dump.collectionForIntent(client, first)
+	ctx := mongo.NewSessionContext(context.Background(), session)
+	cursor, err := coll.Find(ctx, bson.D{}, mopt.Find().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("error running snapshot read on %v: %v", first.Namespace(), err)
+	}
+	if err := cursor.Close(ctx); err != nil {
+		return err
+	}
+
+	snapshotTime := sessionSnapshotTime(session)
+	if snapshotTime == nil {
+		return fmt.Errorf("the server did not return a cluster time for the snapshot read")
+	}
+	log.Logvf(log.Always, "reading all collections at cluster time %v", *snapshotTime)
+	dump.snapshotTime = snapshotTime
+	return nil
+}
+
+// startSnapshotSession starts a snapshot session that reads at the pinned
+// cluster time. It returns nil if no time is pinned.
+func (dump *MongoDump) startSnapshotSession(client *mongo.Client) (mongo.Session, error) {
+	if dump.snapshotTime == nil {
+		return nil, nil
+	}
+	session, err := client.StartSession(mopt.Session().SetSnapshot(true))
+	if err != nil {
+		return nil, fmt.Errorf("error starting a snapshot session: %v", err)
+	}
+	if xSession, ok := session.(mongo.XSession); ok {
+		snapshotTime := *dump.snapshotTime
+		xSession.ClientSession().SnapshotTime = &snapshotTime
+	}
+	return session, nil
+}
+
+// sessionSnapshotTime returns the cluster time a snapshot session reads at,
+// or nil if it hasn't done a read yet.
+func sessionSnapshotTime(session mongo.Session) *primitive.Timestamp {
+	xSession, ok := session.(mongo.XSession)
+	if !ok {
+		return nil
+	}
+	return xSession.ClientSession().SnapshotTime
+}
+
+// collectionForIntent returns the collection to read the documents of an
+// intent from, with the --readConcern level if one was given.
+func (dump *MongoDump) collectionForIntent(
+	client *mongo.Client,
+	intent *intents.Intent,
+) *mongo.Collection {
+	collOpts := mopt.Collection()
+	if dump.readConcern != nil {
+		collOpts.SetReadConcern(dump.readConcern)
+	}
+	name := intent.C
+	if intent.IsTimeseries() {
+		name = "system.buckets." + intent.C
+	}
+	return client.Database(intent.DB).Collection(name, collOpts)
+}
@@ -0,0 +1,419 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/options"
+	"github.com/mongodb/mongo-tools/common/progress"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/exp/slices"
+)
+
+// maxSplitChunksAttempts is the number of times --splitChunks reads a
+// collection, or its chunks, before giving up because chunks keep migrating.
+const maxSplitChunksAttempts = 3
+
+// shardedCollection is a sharded collection, as recorded in config.collections.
+type shardedCollection struct {
+	ns  string
+	key bson.D
+	// chunkFilter selects the chunks of the collection in config.chunks.
+	chunkFilter bson.D
+}
+
+// shardRange is a range of shard key values, from Min up to but not including
+// Max, that is owned by a single shard.
+type shardRange struct {
+	Min   bson.Raw `bson:"min"`
+	Max   bson.Raw `bson:"max"`
+	Shard string   `bson:"shard"`
+}
+
+func (r shardRange) equal(other shardRange) bool {
+	return bytes.Equal(r.Min, other.Min) && bytes.Equal(r.Max, other.Max) &&
+		r.Shard == other.Shard
+}
+
+// readShardedCollection returns the sharded collection with the given
+// namespace, or nil if the collection isn't sharded.
+func readShardedCollection(client *mongo.Client, ns string) (*shardedCollection, error) {
+	var doc struct {
+		Key     bson.D            `bson:"key"`
+		UUID    *primitive.Binary `bson:"uuid"`
+		Dropped bool              `bson:"dropped"`
+	}
+	err := client.Database("config").Collection("collections").
+		FindOne(context.Background(), bson.D{{"_id", ns}}).Decode(&doc)
+	if err == mongo.ErrNoDocuments || err == nil && doc.Dropped {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config.collections: %v", err)
+	}
+
+	// Chunks are recorded by the UUID of their collection since MongoDB 5.0,
+	// and by its namespace before.
+	chunkFilter := bson.D{{"ns", ns}}
+	if doc.UUID != nil {
+		chunkFilter = bson.D{{"$or", bson.A{bson.D{{"uuid", *doc.UUID}}, chunkFilter}}}
+	}
+	return &shardedCollection{ns: ns, key: doc.Key, chunkFilter: chunkFilter}, nil
+}
+
+// readShardRanges returns the ranges of shard key values owned by each shard,
+// from the chunks of the collection. A migration that commits while the
+// chunks are read can make them overlap; they are read again if so.
+func readShardRanges(client *mongo.Client, coll *shardedCollection) ([]shardRange, error) {
+	for attempt := 1; ; attempt++ {
+		cursor, err := client.Database("config").Collection("chunks").Find(
+			context.Background(),
+			coll.chunkFilter,
+			mopt.Find().SetSort(bson.D{{"min", 1}}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config.chunks: %v", err)
+		}
+		var chunks []shardRange
+		if err := cursor.All(context.Background(), &chunks); err != nil {
+			return nil, fmt.Errorf("error reading config.chunks: %v", err)
+		}
+
+		ranges, err := mergeShardRanges(chunks)
+		if err == nil {
+			return ranges, nil
+		}
+		if attempt == maxSplitChunksAttempts {
+			return nil, fmt.Errorf("error reading the chunks of %v: %v", coll.ns, err)
+		}
+		log.Logvf(log.Always, "chunks of %v are inconsistent, reading them again: %v", coll.ns, err)
+		time.Sleep(time.Second)
+	}
+}
+
+// mergeShardRanges checks that sorted chunks cover contiguous ranges, and
+// merges adjacent chunks owned by the same shard into a single range.
+func mergeShardRanges(chunks []shardRange) ([]shardRange, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no chunks found")
+	}
+	var ranges []shardRange
+	for i, chunk := range chunks {
+		if i > 0 && !bytes.Equal(chunks[i-1].Max, chunk.Min) {
+			return nil, fmt.Errorf("chunk ending at %v and chunk starting at %v overlap "+
+				"or leave a gap", chunks[i-1].Max, chunk.Min)
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].Shard == chunk.Shard {
+			ranges[n-1].Max = chunk.Max
+			continue
+		}
+		ranges = append(ranges, chunk)
+	}
+	return ranges, nil
+}
+
+// shardSessionProvider returns a connection to the given shard, reusing the
+// credentials and options of the connection to the mongos.
+func (dump *MongoDump) shardSessionProvider(shard string) (*db.SessionProvider, error) {
+	dump.shardLock.Lock()
+	defer dump.shardLock.Unlock()
+	if provider, ok := dump.shardProviders[shard]; ok {
+		return provider, nil
+	}
+
+	var doc struct {
+		Host string `bson:"host"`
+	}
+	err := dump.SessionProvider.DB("config").Collection("shards").
+		FindOne(context.Background(), bson.D{{"_id", shard}}).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading shard %v from config.shards: %v", shard, err)
+	}
+
+	provider, err := db.NewSessionProvider(shardToolOptions(*dump.ToolOptions, doc.Host))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to shard %v at %v: %v", shard, doc.Host, err)
+	}
+	if dump.shardProviders == nil {
+		dump.shardProviders = map[string]*db.SessionProvider{}
+	}
+	dump.shardProviders[shard] = provider
+	return provider, nil
+}
+
+// shardToolOptions returns a copy of opts that connects to the shard with the
+// given host string from config.shards, which is either "<set>/<host>,..."
+// for a replica set or a single "<host>".
+func shardToolOptions(opts options.ToolOptions, host string) options.ToolOptions {
+	setName, hosts, ok := strings.Cut(host, "/")
+	if !ok {
+		setName, hosts = "", host
+	}
+
+	uri := *opts.URI
+	cs := *uri.ConnString
+	cs.Hosts = strings.Split(hosts, ",")
+	cs.ReplicaSet = setName
+	cs.LoadBalanced, cs.LoadBalancedSet = false, false
+	uri.ConnString = &cs
+	opts.URI = &uri
+
+	opts.ReplicaSetName = setName
+	opts.Direct = setName == ""
+	return opts
+}
+
+// closeShardSessions closes the connections to the shards.
+func (dump *MongoDump) closeShardSessions() {
+	dump.shardLock.Lock()
+	defer dump.shardLock.Unlock()
+	for _, provider := range dump.shardProviders {
+		provider.Close()
+	}
+	dump.shardProviders = nil
+}
+
+// dumpShardRangesToIntent dumps a sharded collection by reading the range of
+// each shard directly from it, one range after another per shard and all
+// shards in parallel. Each range is written to its own file, and the files
+// are then joined into the collection's BSON file. If chunks migrated while
+// the ranges were read, the collection is dumped again.
+func (dump *MongoDump) dumpShardRangesToIntent(
+	intent *intents.Intent,
+	coll *shardedCollection,
+	buffer resettableOutputBuffer,
+) (int64, error) {
+	client, err := dump.SessionProvider.GetSession()
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := dump.getCount(&db.DeferredQuery{Coll: client.Database(intent.DB).
+		Collection(intent.C)}, intent)
+	if err != nil {
+		return 0, err
+	}
+	dumpProgressor := progress.NewCounter(total)
+	if dump.ProgressManager != nil {
+		dump.ProgressManager.Attach(intent.Namespace(), dumpProgressor)
+		defer dump.ProgressManager.Detach(intent.Namespace())
+	}
+
+	for attempt := 1; ; attempt++ {
+		ranges, err := readShardRanges(client, coll)
+		if err != nil {
+			return 0, err
+		}
+		log.Logvf(log.Info, "dumping %v in %v ranges", intent.Namespace(), len(ranges))
+
+		if err := os.MkdirAll(filepath.Dir(intent.Location), 0755); err != nil {
+			return 0, err
+		}
+		dir, err := os.MkdirTemp(filepath.Dir(intent.Location), "."+intent.C+".ranges-")
+		if err != nil {
+			return 0, fmt.Errorf("error creating directory for range files: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		dumpProgressor.Set(0)
+		if err := dump.dumpShardRanges(intent, coll, ranges, dir, dumpProgressor); err != nil {
+			return 0, err
+		}
+
+		after, err := readShardRanges(client, coll)
+		if err != nil {
+			return 0, err
+		}
+		if slices.EqualFunc(ranges, after, shardRange.equal) {
+			count, _ := dumpProgressor.Progress()
+			return count, dump.joinRangeFiles(intent, dir, len(ranges), buffer)
+		}
+		if attempt == maxSplitChunksAttempts {
+			return 0, fmt.Errorf("chunks of %v kept migrating while it was dumped; "+
+				"stop the balancer or dump it without --splitChunks", intent.Namespace())
+		}
+		log.Logvf(log.Always, "chunks of %v migrated while it was dumped, dumping it again",
+			intent.Namespace())
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// dumpShardRanges writes each range to its own file in dir, reading the
+// ranges of different shards in parallel.
+func (dump *MongoDump) dumpShardRanges(
+	intent *intents.Intent,
+	coll *shardedCollection,
+	ranges []shardRange,
+	dir string,
+	progressor progress.Updateable,
+) error {
+	byShard := map[string][]int{}
+	for i, r := range ranges {
+		byShard[r.Shard] = append(byShard[r.Shard], i)
+	}
+
+	// wait for every shard, so that no range file is written after dir is
+	// removed
+	errChan := make(chan error, len(byShard))
+	for shard, indexes := range byShard {
+		go func(shard string, indexes []int) {
+			for _, i := range indexes {
+				err := dump.dumpShardRange(intent, coll, ranges[i], rangeFile(dir, i), progressor)
+				if err != nil {
+					errChan <- fmt.Errorf("error dumping range %v to %v from shard %v: %v",
+						ranges[i].Min, ranges[i].Max, shard, err)
+					return
+				}
+			}
+			errChan <- nil
+		}(shard, indexes)
+	}
+
+	var firstErr error
+	for range byShard {
+		if err := <-errChan; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// dumpShardRange writes the documents of a range, read from the shard that
+// owns it, to the file at path.
+func (dump *MongoDump) dumpShardRange(
+	intent *intents.Intent,
+	coll *shardedCollection,
+	r shardRange,
+	path string,
+	progressor progress.Updateable,
+) (err error) {
+	provider, err := dump.shardSessionProvider(r.Shard)
+	if err != nil {
+		return err
+	}
+	client, err := provider.GetSession()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	snapshotSession, err := dump.startSnapshotSession(client)
+	if err != nil {
+		return err
+	}
+	if snapshotSession != nil {
+		defer snapshotSession.EndSession(context.Background())
+		ctx = mongo.NewSessionContext(ctx, snapshotSession)
+	}
+
+	filter := dump.query
+	if filter == nil {
+		filter = bson.D{}
+	}
+	// min and max bound the range by the shard key index, which also works
+	// for compound and hashed shard keys
+	cursor, err := dump.collectionForIntent(client, intent).Find(ctx, filter,
+		mopt.Find().SetMin(r.Min).SetMax(r.Max).SetHint(coll.key))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		_ = cursor.Close(ctx)
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	writer := bufio.NewWriter(file)
+	if err := dump.dumpValidatedIterToWriter(cursor, writer, progressor, nil); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// joinRangeFiles writes the range files, in order, to the intent's BSON file.
+func (dump *MongoDump) joinRangeFiles(
+	intent *intents.Intent,
+	dir string,
+	count int,
+	buffer resettableOutputBuffer,
+) (err error) {
+	if err = intent.BSONFile.Open(); err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := intent.BSONFile.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf(
+				"error writing data for collection `%v` to disk: %v",
+				intent.Namespace(),
+				closeErr,
+			)
+		}
+	}()
+
+	var out io.Writer = intent.BSONFile
+	if buffer != nil {
+		buffer.Reset(out)
+		out = buffer
+		defer func() {
+			closeErr := buffer.Close()
+			if err == nil && closeErr != nil {
+				err = fmt.Errorf(
+					"error writing data for collection `%v` to disk: %v",
+					intent.Namespace(),
+					closeErr,
+				)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		if err := copyFile(out, rangeFile(dir, i)); err != nil {
+			return fmt.Errorf("error writing data for collection `%v` to disk: %v",
+				intent.Namespace(), err)
+		}
+	}
+	return nil
+}
+
+func copyFile(out io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(out, file)
+	return err
+}
+
+// rangeFile returns the path of the file the range with the given index is
+// written to.
+func rangeFile(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d.bson", index))
+}
@@ -0,0 +1,87 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongodump
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/options"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+func shardKeyBound(t *testing.T, value interface{}) bson.Raw {
+	raw, err := bson.Marshal(bson.D{{"x", value}})
+	require.NoError(t, err)
+	return raw
+}
+
+func TestMergeShardRanges(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	minKey := shardKeyBound(t, primitive.MinKey{})
+	ten := shardKeyBound(t, 10)
+	twenty := shardKeyBound(t, 20)
+	thirty := shardKeyBound(t, 30)
+	maxKey := shardKeyBound(t, primitive.MaxKey{})
+
+	t.Run("adjacent chunks of a shard are merged", func(t *testing.T) {
+		ranges, err := mergeShardRanges([]shardRange{
+			{Min: minKey, Max: ten, Shard: "a"},
+			{Min: ten, Max: twenty, Shard: "a"},
+			{Min: twenty, Max: thirty, Shard: "b"},
+			{Min: thirty, Max: maxKey, Shard: "a"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []shardRange{
+			{Min: minKey, Max: twenty, Shard: "a"},
+			{Min: twenty, Max: thirty, Shard: "b"},
+			{Min: thirty, Max: maxKey, Shard: "a"},
+		}, ranges)
+	})
+
+	t.Run("overlapping chunks are rejected", func(t *testing.T) {
+		_, err := mergeShardRanges([]shardRange{
+			{Min: minKey, Max: twenty, Shard: "a"},
+			{Min: ten, Max: maxKey, Shard: "b"},
+		})
+		assert.ErrorContains(t, err, "overlap or leave a gap")
+	})
+
+	t.Run("a collection without chunks is rejected", func(t *testing.T) {
+		_, err := mergeShardRanges(nil)
+		assert.ErrorContains(t, err, "no chunks found")
+	})
+}
+
+func TestShardToolOptions(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	cs := &connstring.ConnString{Hosts: []string{"mongos:27017"}, LoadBalanced: true}
+	opts := options.ToolOptions{
+		URI: &options.URI{ConnectionString: "mongodb://mongos", ConnString: cs},
+	}
+
+	shardOpts := shardToolOptions(opts, "rs1/host1:27018,host2:27018")
+	assert.Equal(t, []string{"host1:27018", "host2:27018"}, shardOpts.URI.ConnString.Hosts)
+	assert.Equal(t, "rs1", shardOpts.ReplicaSetName)
+	assert.False(t, shardOpts.URI.ConnString.LoadBalanced)
+	assert.False(t, shardOpts.Direct)
+
+	shardOpts = shardToolOptions(opts, "host3:27018")
+	assert.Equal(t, []string{"host3:27018"}, shardOpts.URI.ConnString.Hosts)
+	assert.Equal(t, "", shardOpts.ReplicaSetName)
+	assert.True(t, shardOpts.Direct)
+
+	// the options of the connection to the mongos are left unchanged
+	assert.Equal(t, []string{"mongos:27017"}, cs.Hosts)
+	assert.True(t, cs.LoadBalanced)
+}
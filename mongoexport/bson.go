@@ -0,0 +1,67 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoexport
+
+import (
+	"bufio"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// rawExportOutput is implemented by ExportOutputs that can write the raw
+// bytes of a document returned by the server without decoding it first.
+type rawExportOutput interface {
+	ExportRawDocument(bson.Raw) error
+}
+
+// BSONExportOutput is an implementation of ExportOutput that writes documents
+// to the output as concatenated BSON, the same format as the .bson files
+// written by mongodump.
+type BSONExportOutput struct {
+	Out         *bufio.Writer
+	NumExported int64
+}
+
+// NewBSONExportOutput creates a new BSONExportOutput that writes to out.
+func NewBSONExportOutput(out io.Writer) *BSONExportOutput {
+	return &BSONExportOutput{Out: bufio.NewWriter(out)}
+}
+
+// WriteHeader is a no-op for BSON export.
+func (bsonExporter *BSONExportOutput) WriteHeader() error {
+	return nil
+}
+
+// WriteFooter is a no-op for BSON export.
+func (bsonExporter *BSONExportOutput) WriteFooter() error {
+	return nil
+}
+
+// Flush writes any buffered documents to the output.
+func (bsonExporter *BSONExportOutput) Flush() error {
+	return bsonExporter.Out.Flush()
+}
+
+// ExportDocument marshals the document to BSON and writes it to the output.
+func (bsonExporter *BSONExportOutput) ExportDocument(document bson.D) error {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return bsonExporter.ExportRawDocument(raw)
+}
+
+// ExportRawDocument writes a document to the output exactly as it was
+// returned by the server.
+func (bsonExporter *BSONExportOutput) ExportRawDocument(document bson.Raw) error {
+	if _, err := bsonExporter.Out.Write(document); err != nil {
+		return err
+	}
+	bsonExporter.NumExported++
+	return nil
+}
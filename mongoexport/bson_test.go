@@ -0,0 +1,53 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoexport
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestWriteBSON(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a BSON export output", t, func() {
+		out := &bytes.Buffer{}
+		bsonExporter := NewBSONExportOutput(out)
+
+		Convey("documents are written as concatenated BSON", func() {
+			first := bson.D{{"_id", int32(1)}, {"a", bson.D{{"b", "c"}}}}
+			second, err := bson.Marshal(bson.D{{"_id", int32(2)}})
+			So(err, ShouldBeNil)
+
+			So(bsonExporter.WriteHeader(), ShouldBeNil)
+			So(bsonExporter.ExportDocument(first), ShouldBeNil)
+			So(bsonExporter.ExportRawDocument(second), ShouldBeNil)
+			So(bsonExporter.WriteFooter(), ShouldBeNil)
+			So(bsonExporter.Flush(), ShouldBeNil)
+			So(bsonExporter.NumExported, ShouldEqual, 2)
+
+			source := db.NewDecodedBSONSource(db.NewBufferlessBSONSource(
+				io.NopCloser(bytes.NewReader(out.Bytes()))))
+			var docs []bson.D
+			var doc bson.D
+			for source.Next(&doc) {
+				docs = append(docs, doc)
+				doc = nil
+			}
+			So(source.Err(), ShouldBeNil)
+			So(docs, ShouldHaveLength, 2)
+			So(docs[0], ShouldResemble, first)
+			So(docs[1], ShouldResemble, bson.D{{"_id", int32(2)}})
+		})
+	})
+}
@@ -7,16 +7,21 @@
 package mongoexport
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/json"
+	"github.com/mongodb/mongo-tools/common/log"
 	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/exp/slices"
 )
 
 // type for reflect code.
@@ -35,59 +40,293 @@ type CSVExportOutput struct {
 	// NoHeaderLine, if set, will export CSV data without a list of field names at the first line
 	NoHeaderLine bool
 
-	csvWriter *csv.Writer
+	// Headers, if set, are the column names written in place of Fields in the header line.
+	Headers []string
+
+	// Strict, if set, writes output that follows RFC 4180 exactly: records end
+	// with CRLF, and every field is written byte for byte, quoted if needed.
+	// Arrays and subdocuments are always quoted.
+	Strict bool
+
+	// DeriveFields, if set and Fields is empty, takes Fields from the top-level
+	// fields of the first document, in order. NewFields decides what happens
+	// to fields of later documents that aren't among them.
+	DeriveFields bool
+
+	// NewFields is NewFieldsReport or NewFieldsExtend. It defaults to
+	// NewFieldsReport.
+	NewFields string
+
+	// ReportNewFields, if set, logs each top-level field of an exported
+	// document that isn't in Fields once. It is used when Fields were
+	// collected from a sample of the documents.
+	ReportNewFields bool
+
+	// reportedFields are the new fields that have already been logged.
+	reportedFields map[string]bool
+
+	// heldFile holds the documents exported with NewFieldsExtend as BSON, so
+	// that they aren't kept in memory. They are written by WriteFooter, once
+	// every column is known.
+	heldFile   *os.File
+	heldWriter *bufio.Writer
+
+	csvWriter    *csv.Writer
+	strictWriter *bufio.Writer
 }
 
 // NewCSVExportOutput returns a CSVExportOutput configured to write output to the
 // given io.Writer, extracting the specified fields only.
 func NewCSVExportOutput(fields []string, noHeaderLine bool, out io.Writer) *CSVExportOutput {
 	return &CSVExportOutput{
-		fields,
-		0,
-		noHeaderLine,
-		csv.NewWriter(out),
+		Fields:       fields,
+		NoHeaderLine: noHeaderLine,
+		csvWriter:    csv.NewWriter(out),
+		strictWriter: bufio.NewWriter(out),
+	}
+}
+
+// writeRecord writes a single record, forcing quotes around the fields
+// marked in forceQuote in strict mode.
+func (csvExporter *CSVExportOutput) writeRecord(record []string, forceQuote []bool) error {
+	if !csvExporter.Strict {
+		if err := csvExporter.csvWriter.Write(record); err != nil {
+			return err
+		}
+		return csvExporter.csvWriter.Error()
+	}
+	for i, field := range record {
+		if i > 0 {
+			if err := csvExporter.strictWriter.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		quote := i < len(forceQuote) && forceQuote[i]
+		if err := writeStrictField(csvExporter.strictWriter, field, quote); err != nil {
+			return err
+		}
+	}
+	_, err := csvExporter.strictWriter.WriteString("\r\n")
+	return err
+}
+
+// writeStrictField writes field as defined by RFC 4180. The field is quoted
+// if quote is set or if it contains a comma, quote, CR or LF, and quotes
+// inside it are doubled. Fields with leading or trailing whitespace are
+// quoted too, so that readers that trim unquoted fields keep it.
+func writeStrictField(w *bufio.Writer, field string, quote bool) error {
+	if !quote && !strings.ContainsAny(field, ",\"\r\n") &&
+		strings.TrimSpace(field) == field {
+		_, err := w.WriteString(field)
+		return err
 	}
+	_, err := w.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`)
+	return err
 }
 
 // WriteHeader writes a comma-delimited list of fields as the output header row.
+// When the fields are derived from the documents, the header is written with
+// the first document instead.
 func (csvExporter *CSVExportOutput) WriteHeader() error {
+	if csvExporter.deriving() {
+		return nil
+	}
+	return csvExporter.writeHeader()
+}
+
+func (csvExporter *CSVExportOutput) writeHeader() error {
 	if !csvExporter.NoHeaderLine {
-		if err := csvExporter.csvWriter.Write(csvExporter.Fields); err != nil {
+		headers := csvExporter.Headers
+		if headers == nil {
+			headers = csvExporter.Fields
+		}
+		return csvExporter.writeRecord(headers, nil)
+	}
+	return nil
+}
+
+// WriteFooter writes the documents held back to extend the header, if any.
+// CSV export formats have no footer.
+func (csvExporter *CSVExportOutput) WriteFooter() error {
+	if !csvExporter.extending() || len(csvExporter.Fields) == 0 {
+		return nil
+	}
+	defer csvExporter.Close()
+	if err := csvExporter.writeHeader(); err != nil {
+		return err
+	}
+	if csvExporter.heldFile == nil {
+		return nil
+	}
+	if err := csvExporter.heldWriter.Flush(); err != nil {
+		return fmt.Errorf("error writing held documents: %v", err)
+	}
+	if _, err := csvExporter.heldFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading held documents: %v", err)
+	}
+	source := db.NewDecodedBSONSource(db.NewBSONSource(io.NopCloser(csvExporter.heldFile)))
+	for {
+		var document bson.D
+		if !source.Next(&document) {
+			break
+		}
+		if err := csvExporter.writeDocument(document); err != nil {
 			return err
 		}
-		return csvExporter.csvWriter.Error()
+	}
+	if err := source.Err(); err != nil {
+		return fmt.Errorf("error reading held documents: %v", err)
+	}
+	return nil
+}
+
+// holdDocument appends document to the temporary file of documents held back
+// until every column is known, creating the file for the first document.
+func (csvExporter *CSVExportOutput) holdDocument(document bson.D) error {
+	if csvExporter.heldFile == nil {
+		file, err := os.CreateTemp("", "mongoexport-*.bson")
+		if err != nil {
+			return fmt.Errorf("error creating a temporary file for --newFields=extend: %v", err)
+		}
+		csvExporter.heldFile = file
+		csvExporter.heldWriter = bufio.NewWriter(file)
+	}
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if _, err := csvExporter.heldWriter.Write(raw); err != nil {
+		return fmt.Errorf("error writing held documents: %v", err)
 	}
 	return nil
 }
 
-// WriteFooter is a no-op for CSV export formats.
-func (_ *CSVExportOutput) WriteFooter() error {
-	// no CSV footer
+// Close removes the temporary file of held documents, if any.
+func (csvExporter *CSVExportOutput) Close() error {
+	if csvExporter.heldFile == nil {
+		return nil
+	}
+	name := csvExporter.heldFile.Name()
+	closeErr := csvExporter.heldFile.Close()
+	csvExporter.heldFile = nil
+	csvExporter.heldWriter = nil
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// deriving returns true if Fields are taken from the documents.
+func (csvExporter *CSVExportOutput) deriving() bool {
+	return csvExporter.DeriveFields
+}
+
+// extending returns true if new fields are added as columns.
+func (csvExporter *CSVExportOutput) extending() bool {
+	return csvExporter.deriving() && csvExporter.NewFields == NewFieldsExtend
+}
+
+// deriveFields adds the top-level fields of document to Fields. The first
+// document sets the columns; the fields of later documents that aren't among
+// them are added or reported, depending on NewFields. It writes the header
+// after the first document unless the header is extended.
+func (csvExporter *CSVExportOutput) deriveFields(document bson.D) error {
+	first := len(csvExporter.Fields) == 0
+	known := make(map[string]bool, len(csvExporter.Fields))
+	for _, field := range csvExporter.Fields {
+		known[field] = true
+	}
+	for _, elem := range document {
+		if known[elem.Key] {
+			continue
+		}
+		known[elem.Key] = true
+		if first || csvExporter.extending() {
+			csvExporter.Fields = append(csvExporter.Fields, elem.Key)
+			continue
+		}
+		if !csvExporter.reportedFields[elem.Key] {
+			if csvExporter.reportedFields == nil {
+				csvExporter.reportedFields = map[string]bool{}
+			}
+			csvExporter.reportedFields[elem.Key] = true
+			log.Logvf(log.Always,
+				"field '%v' is not in the first document and will not be exported; "+
+					"use --newFields=extend to add it as a column", elem.Key)
+		}
+	}
+	if first && !csvExporter.extending() {
+		return csvExporter.writeHeader()
+	}
 	return nil
 }
 
+// reportNewFields logs the top-level fields of document that aren't in Fields
+// and haven't been logged yet.
+func (csvExporter *CSVExportOutput) reportNewFields(document bson.D) {
+	for _, elem := range document {
+		if csvExporter.reportedFields[elem.Key] || slices.Contains(csvExporter.Fields, elem.Key) {
+			continue
+		}
+		if csvExporter.reportedFields == nil {
+			csvExporter.reportedFields = map[string]bool{}
+		}
+		csvExporter.reportedFields[elem.Key] = true
+		log.Logvf(log.Always,
+			"field '%v' was not in the documents read by --fieldsAll and will not be exported; "+
+				"use --fieldsAllSample=0 to read every document", elem.Key)
+	}
+}
+
 // Flush writes any pending data to the underlying I/O stream.
 func (csvExporter *CSVExportOutput) Flush() error {
+	if csvExporter.Strict {
+		return csvExporter.strictWriter.Flush()
+	}
 	csvExporter.csvWriter.Flush()
 	return csvExporter.csvWriter.Error()
 }
 
 // ExportDocument writes a line to output with the CSV representation of a document.
 func (csvExporter *CSVExportOutput) ExportDocument(document bson.D) error {
+	if csvExporter.deriving() {
+		if err := csvExporter.deriveFields(document); err != nil {
+			return err
+		}
+		if csvExporter.extending() {
+			return csvExporter.holdDocument(document)
+		}
+	}
+	if csvExporter.ReportNewFields {
+		csvExporter.reportNewFields(document)
+	}
+	return csvExporter.writeDocument(document)
+}
+
+// writeDocument writes the CSV representation of a document.
+func (csvExporter *CSVExportOutput) writeDocument(document bson.D) error {
 	rowOut := make([]string, 0, len(csvExporter.Fields))
+	quoted := make([]bool, 0, len(csvExporter.Fields))
 	extendedDoc, err := bsonutil.ConvertBSONValueToLegacyExtJSON(document)
 	if err != nil {
 		return err
 	}
+	flatDoc := bsonutil.FlattenDocument(bson.D(extendedDoc.(bsonutil.MarshalD)))
 
 	for _, fieldName := range csvExporter.Fields {
-		fieldVal := extractFieldByName(fieldName, extendedDoc)
+		fieldVal, ok := flatDoc[fieldName]
+		if !ok {
+			// the field is a whole subdocument or array, or is missing
+			fieldVal = extractFieldByName(fieldName, extendedDoc)
+		}
+		isCompound := false
 		if fieldVal == nil {
 			rowOut = append(rowOut, "")
 		} else if reflect.TypeOf(fieldVal) == reflect.TypeOf(bson.M{}) ||
 			reflect.TypeOf(fieldVal) == reflect.TypeOf(bson.D{}) ||
 			reflect.TypeOf(fieldVal) == marshalDType ||
 			reflect.TypeOf(fieldVal) == reflect.TypeOf([]interface{}{}) {
+			isCompound = true
 			buf, err := json.Marshal(fieldVal)
 			if err != nil {
 				rowOut = append(rowOut, "")
@@ -97,12 +336,13 @@ func (csvExporter *CSVExportOutput) ExportDocument(document bson.D) error {
 		} else {
 			rowOut = append(rowOut, fmt.Sprintf("%v", fieldVal))
 		}
+		quoted = append(quoted, isCompound)
 	}
-	if err = csvExporter.csvWriter.Write(rowOut); err != nil {
+	if err = csvExporter.writeRecord(rowOut, quoted); err != nil {
 		return err
 	}
 	csvExporter.NumExported++
-	return csvExporter.csvWriter.Error()
+	return nil
 }
 
 // extractFieldByName takes a field name and document, and returns a value representing
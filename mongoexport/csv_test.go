@@ -9,13 +9,18 @@ package mongoexport
 import (
 	"bytes"
 	"encoding/csv"
+	"io"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/mongoimport"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestWriteCSV(t *testing.T) {
@@ -82,6 +87,34 @@ func TestWriteCSV(t *testing.T) {
 			So(rec, ShouldResemble, []string{"", "", "", "T"})
 		})
 
+		Convey("Exported document with whole and empty subdocuments should print JSON", func() {
+			csvExporter := NewCSVExportOutput(fields, true, out)
+			z := []interface{}{bson.D{}, bson.D{{"a", bson.D{{"b", int32(1)}}}}}
+			err := csvExporter.ExportDocument(bson.D{{"x", bson.D{}}, {"z", z}})
+			So(err, ShouldBeNil)
+			err = csvExporter.WriteFooter()
+			So(err, ShouldBeNil)
+			err = csvExporter.Flush()
+			So(err, ShouldBeNil)
+			rec, err := csv.NewReader(strings.NewReader(out.String())).Read()
+			So(err, ShouldBeNil)
+			So(rec, ShouldResemble, []string{"", "{}", "", `{"b":1}`})
+		})
+
+		Convey("Exported document with nested binary data should print it as $binary", func() {
+			csvExporter := NewCSVExportOutput(fields, true, out)
+			x := bson.D{{"b", primitive.Binary{Subtype: 0x80, Data: []byte("xyz")}}}
+			err := csvExporter.ExportDocument(bson.D{{"x", x}})
+			So(err, ShouldBeNil)
+			err = csvExporter.WriteFooter()
+			So(err, ShouldBeNil)
+			err = csvExporter.Flush()
+			So(err, ShouldBeNil)
+			rec, err := csv.NewReader(strings.NewReader(out.String())).Read()
+			So(err, ShouldBeNil)
+			So(rec, ShouldResemble, []string{"", `{"b":{"$binary":"eHl6","$type":"80"}}`, "", ""})
+		})
+
 		Reset(func() {
 			out.Reset()
 		})
@@ -89,6 +122,183 @@ func TestWriteCSV(t *testing.T) {
 	})
 }
 
+func TestWriteCSVPreserveFieldOrder(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	exportAll := func(newFields string) [][]string {
+		docs := []bson.D{
+			{{"b", int32(1)}, {"a", "x"}, {"c", true}},
+			{{"a", "y"}, {"d", int32(2)}},
+			{{"c", false}, {"b", int32(3)}, {"d", int32(4)}},
+		}
+		out := &bytes.Buffer{}
+		csvExporter := NewCSVExportOutput(nil, false, out)
+		csvExporter.DeriveFields = true
+		csvExporter.NewFields = newFields
+		So(csvExporter.WriteHeader(), ShouldBeNil)
+		for _, doc := range docs {
+			So(csvExporter.ExportDocument(doc), ShouldBeNil)
+		}
+		So(csvExporter.WriteFooter(), ShouldBeNil)
+		So(csvExporter.Flush(), ShouldBeNil)
+		records, err := csv.NewReader(out).ReadAll()
+		So(err, ShouldBeNil)
+		return records
+	}
+
+	Convey("With a CSV export output that derives its fields", t, func() {
+		Convey("the header follows the first document and new fields are left out", func() {
+			So(exportAll(NewFieldsReport), ShouldResemble, [][]string{
+				{"b", "a", "c"},
+				{"1", "x", "true"},
+				{"", "y", ""},
+				{"3", "", "false"},
+			})
+		})
+
+		Convey("new fields are added as columns with --newFields=extend", func() {
+			So(exportAll(NewFieldsExtend), ShouldResemble, [][]string{
+				{"b", "a", "c", "d"},
+				{"1", "x", "true", ""},
+				{"", "y", "", "2"},
+				{"3", "", "false", "4"},
+			})
+		})
+
+		Convey("documents held with --newFields=extend are kept in a temporary file", func() {
+			csvExporter := NewCSVExportOutput(nil, false, &bytes.Buffer{})
+			csvExporter.DeriveFields = true
+			csvExporter.NewFields = NewFieldsExtend
+			So(csvExporter.ExportDocument(bson.D{{"a", int32(1)}}), ShouldBeNil)
+			So(csvExporter.heldFile, ShouldNotBeNil)
+			name := csvExporter.heldFile.Name()
+			_, err := os.Stat(name)
+			So(err, ShouldBeNil)
+
+			So(csvExporter.Close(), ShouldBeNil)
+			_, err = os.Stat(name)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("nothing is written without documents", func() {
+			out := &bytes.Buffer{}
+			csvExporter := NewCSVExportOutput(nil, false, out)
+			csvExporter.DeriveFields = true
+			So(csvExporter.WriteHeader(), ShouldBeNil)
+			So(csvExporter.WriteFooter(), ShouldBeNil)
+			So(csvExporter.Flush(), ShouldBeNil)
+			So(out.String(), ShouldEqual, "")
+		})
+	})
+}
+
+func TestWriteCSVReportNewFields(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a CSV export output that reports new fields", t, func() {
+		logs := &bytes.Buffer{}
+		log.SetWriter(logs)
+		defer log.SetWriter(os.Stderr)
+
+		out := &bytes.Buffer{}
+		csvExporter := NewCSVExportOutput([]string{"a", "b"}, false, out)
+		csvExporter.ReportNewFields = true
+		So(csvExporter.WriteHeader(), ShouldBeNil)
+		So(csvExporter.ExportDocument(bson.D{{"b", int32(1)}, {"c", "x"}}), ShouldBeNil)
+		So(csvExporter.ExportDocument(bson.D{{"a", int32(2)}, {"c", "y"}}), ShouldBeNil)
+		So(csvExporter.Flush(), ShouldBeNil)
+
+		So(out.String(), ShouldEqual, "a,b\n,1\n2,\n")
+		So(strings.Count(logs.String(), "field 'c' was not in the documents"), ShouldEqual, 1)
+		So(logs.String(), ShouldNotContainSubstring, "field 'a'")
+	})
+}
+
+func TestWriteStrictCSV(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	// ExportDocument converts documents in place, so each export needs new ones
+	newDocs := func() []bson.D {
+		return []bson.D{
+			{
+				{"_id", int32(1)},
+				{"plain", "abc"},
+				{"special", "a,b \"quoted\"\nnext line"},
+				{"sub", bson.D{{"x", int32(1)}, {"y", bson.A{"p", "q"}}}},
+			},
+			{
+				{"_id", int32(2)},
+				{"plain", "  padded "},
+				{"special", `""`},
+				{"sub", bson.A{}},
+			},
+		}
+	}
+	fields := []string{"_id", "plain", "special", "sub"}
+
+	export := func() string {
+		out := &bytes.Buffer{}
+		csvExporter := NewCSVExportOutput(fields, false, out)
+		csvExporter.Strict = true
+		So(csvExporter.WriteHeader(), ShouldBeNil)
+		for _, doc := range newDocs() {
+			So(csvExporter.ExportDocument(doc), ShouldBeNil)
+		}
+		So(csvExporter.WriteFooter(), ShouldBeNil)
+		So(csvExporter.Flush(), ShouldBeNil)
+		return out.String()
+	}
+
+	Convey("With a strict CSV export output", t, func() {
+		Convey("fields are quoted as RFC 4180 requires", func() {
+			So(export(), ShouldEqual, "_id,plain,special,sub\r\n"+
+				"1,abc,\"a,b \"\"quoted\"\"\nnext line\",\"{\"\"x\"\":1,\"\"y\"\":[\"\"p\"\",\"\"q\"\"]}\"\r\n"+
+				"2,\"  padded \",\"\"\"\"\"\",\"[]\"\r\n")
+		})
+
+		Convey("the output can be read back by a standard CSV reader", func() {
+			records, err := csv.NewReader(strings.NewReader(export())).ReadAll()
+			So(err, ShouldBeNil)
+			So(records, ShouldHaveLength, 3)
+			So(records[1][2], ShouldEqual, "a,b \"quoted\"\nnext line")
+			So(records[2][1], ShouldEqual, "  padded ")
+		})
+
+		Convey("the output round-trips through mongoimport", func() {
+			r := mongoimport.NewCSVInputReader(
+				nil,
+				strings.NewReader(export()),
+				io.Discard,
+				1,
+				false,
+				false,
+			)
+			So(r.ReadAndValidateHeader(), ShouldBeNil)
+			docChan := make(chan mongoimport.InputDocument, 2)
+			So(r.StreamDocument(true, docChan), ShouldBeNil)
+
+			var imported []bson.D
+			for doc := range docChan {
+				imported = append(imported, doc.Document)
+			}
+			So(imported, ShouldResemble, []bson.D{
+				{
+					{"_id", int32(1)},
+					{"plain", "abc"},
+					{"special", "a,b \"quoted\"\nnext line"},
+					{"sub", `{"x":1,"y":["p","q"]}`},
+				},
+				{
+					{"_id", int32(2)},
+					{"plain", "  padded "},
+					{"special", `""`},
+					{"sub", "[]"},
+				},
+			})
+		})
+	})
+}
+
 func TestExtractDField(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 	Convey("With a test bson.D", t, func() {
@@ -128,6 +128,16 @@ func (jsonExporter *JSONExportOutput) ExportDocument(document bson.D) error {
 		if _, err = jsonExporter.Out.Write(extendedDoc); err != nil {
 			return err
 		}
+
+		// In ndjson mode each line is flushed as soon as it is written so that
+		// downstream consumers see every document as it arrives.
+		if jsonExporter.JSONFormat == NDJSON {
+			if flusher, ok := jsonExporter.Out.(interface{ Flush() error }); ok {
+				if err = flusher.Flush(); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	jsonExporter.NumExported++
 	return nil
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/json"
+	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.mongodb.org/mongo-driver/bson"
@@ -52,6 +53,23 @@ func TestWriteJSON(t *testing.T) {
 				So(out.String(), ShouldEqual, `{"x":{"$numberInt":"1"}}`+"\n")
 			})
 
+			Convey("NDJSON format should write one compact relaxed document per line", func() {
+				exporter := NewJSONExportOutput(false, false, out, NDJSON)
+
+				err := exporter.WriteHeader()
+				So(err, ShouldBeNil)
+
+				err = exporter.ExportDocument(bson.D{{"x", int32(1)}, {"y", bson.D{{"z", "a"}}}})
+				So(err, ShouldBeNil)
+				err = exporter.ExportDocument(bson.D{{"x", int32(2)}})
+				So(err, ShouldBeNil)
+
+				err = exporter.WriteFooter()
+				So(err, ShouldBeNil)
+
+				So(out.String(), ShouldEqual, `{"x":1,"y":{"z":"a"}}`+"\n"+`{"x":2}`+"\n")
+			})
+
 			Reset(func() {
 				out.Reset()
 			})
@@ -100,3 +118,151 @@ func TestJSONArray(t *testing.T) {
 
 	})
 }
+
+func TestNDJSONValidation(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With --jsonFormat=ndjson", t, func() {
+		exporter := &MongoExport{
+			ToolOptions: &options.ToolOptions{
+				Namespace: &options.Namespace{DB: "db", Collection: "coll"},
+			},
+			OutputOpts: &OutputFormatOptions{Type: JSON, JSONFormat: NDJSON},
+			InputOpts:  &InputOptions{},
+		}
+
+		Convey("plain output should be accepted", func() {
+			So(exporter.validateSettings(), ShouldBeNil)
+		})
+
+		Convey("--jsonArray should be rejected", func() {
+			exporter.OutputOpts.JSONArray = true
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--pretty should be rejected", func() {
+			exporter.OutputOpts.Pretty = true
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestFormatSpecificOptionValidation(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With an exporter", t, func() {
+		exporter := &MongoExport{
+			ToolOptions: &options.ToolOptions{
+				Namespace: &options.Namespace{DB: "db", Collection: "coll"},
+			},
+			OutputOpts: &OutputFormatOptions{
+				Type:       CSV,
+				JSONFormat: Relaxed,
+				Fields:     "a,b",
+			},
+			InputOpts: &InputOptions{},
+		}
+
+		Convey("--noHeaderLine should be accepted for CSV", func() {
+			exporter.OutputOpts.NoHeaderLine = true
+			So(exporter.validateSettings(), ShouldBeNil)
+		})
+
+		Convey("--noHeaderLine should be rejected for JSON", func() {
+			exporter.OutputOpts.Type = JSON
+			exporter.OutputOpts.NoHeaderLine = true
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--noHeaderLine")
+		})
+
+		Convey("--preserveFieldOrder should be rejected for JSON", func() {
+			exporter.OutputOpts.Type = JSON
+			exporter.OutputOpts.PreserveFieldOrder = true
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--preserveFieldOrder")
+		})
+
+		Convey("--newFields should only accept report or extend", func() {
+			exporter.OutputOpts.PreserveFieldOrder = true
+			exporter.OutputOpts.NewFields = NewFieldsExtend
+			So(exporter.validateSettings(), ShouldBeNil)
+
+			exporter.OutputOpts.NewFields = "ignore"
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--newFields")
+		})
+
+		Convey("--fieldsAll should only be accepted for CSV without a field list", func() {
+			exporter.OutputOpts.Fields = ""
+			exporter.OutputOpts.FieldsAll = true
+			So(exporter.validateSettings(), ShouldBeNil)
+
+			exporter.OutputOpts.Fields = "a"
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--fieldsAll with --fields")
+
+			exporter.OutputOpts.Fields = ""
+			exporter.OutputOpts.PreserveFieldOrder = true
+			So(exporter.validateSettings(), ShouldNotBeNil)
+
+			exporter.OutputOpts.PreserveFieldOrder = false
+			exporter.OutputOpts.Type = JSON
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--fieldsAllSample should not be negative", func() {
+			exporter.OutputOpts.FieldsAllSample = -1
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--forceTableScan should be accepted without a query", func() {
+			exporter.InputOpts.ForceTableScan = true
+			So(exporter.validateSettings(), ShouldBeNil)
+		})
+
+		Convey("--forceTableScan should be rejected with --query or --queryFile", func() {
+			exporter.InputOpts.ForceTableScan = true
+			exporter.InputOpts.Query = "{}"
+			So(exporter.validateSettings(), ShouldNotBeNil)
+
+			exporter.InputOpts.Query = ""
+			exporter.InputOpts.QueryFile = "query.json"
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--pipeline should be rejected with find options", func() {
+			exporter.InputOpts.Pipeline = `[{$match: {a: 1}}]`
+			So(exporter.validateSettings(), ShouldBeNil)
+
+			exporter.InputOpts.Query = "{}"
+			exporter.InputOpts.Limit = 10
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--query, --limit")
+		})
+
+		Convey("--pipeline should be rejected if it isn't an array", func() {
+			exporter.InputOpts.Pipeline = `{$match: {a: 1}}`
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--type=bson should be accepted with --fields", func() {
+			exporter.OutputOpts.Type = BSON
+			So(exporter.validateSettings(), ShouldBeNil)
+		})
+
+		Convey("--type=bson should be rejected with JSON formatting options", func() {
+			exporter.OutputOpts.Type = BSON
+			exporter.OutputOpts.Pretty = true
+			So(exporter.validateSettings(), ShouldNotBeNil)
+
+			exporter.OutputOpts.Pretty = false
+			exporter.OutputOpts.JSONFormat = Canonical
+			So(exporter.validateSettings(), ShouldNotBeNil)
+		})
+	})
+}
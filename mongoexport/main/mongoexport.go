@@ -8,6 +8,7 @@
 package main
 
 import (
+	"io"
 	"os"
 
 	"github.com/mongodb/mongo-tools/common/log"
@@ -29,8 +30,6 @@ func main() {
 		os.Exit(util.ExitFailure)
 	}
 
-	signals.Handle()
-
 	// print help, if specified
 	if opts.PrintHelp(false) {
 		return
@@ -41,6 +40,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	exporter, err := mongoexport.New(opts)
 	if err != nil {
 		log.Logvf(log.Always, "%v", err)
@@ -53,18 +57,34 @@ func main() {
 	}
 	defer exporter.Close()
 
+	finishedChan := signals.HandleWithInterrupt(exporter.HandleInterrupt)
+	defer close(finishedChan)
+
+	// Check the assertions first so that no output file is left behind if they fail.
+	if err := exporter.CheckAssertions(); err != nil {
+		log.Logvf(log.Always, "Failed: %v", err)
+		os.Exit(util.ExitFailure)
+	}
+
 	writer, err := exporter.GetOutputWriter()
 	if err != nil {
 		log.Logvf(log.Always, "error opening output stream: %v", err)
 		os.Exit(util.ExitFailure)
 	}
-	if writer == nil {
-		writer = os.Stdout
-	} else {
-		defer writer.Close()
+	var out io.Writer = os.Stdout
+	if writer != nil {
+		out = writer
 	}
 
-	numDocs, err := exporter.Export(writer)
+	numDocs, err := exporter.Export(out)
+
+	// Close the writer before handling any error so that compressed output
+	// is finalized even if the export terminated early.
+	if writer != nil {
+		if closeErr := writer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
 		log.Logvf(log.Always, "Failed: %v", err)
 		os.Exit(util.ExitFailure)
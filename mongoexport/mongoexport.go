@@ -4,16 +4,19 @@
 // not use this file except in compliance with the License. You may obtain
 // a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
 
-// Package mongoexport produces a JSON or CSV export of data stored in a MongoDB instance.
+// Package mongoexport produces a JSON, CSV, or BSON export of data stored in a MongoDB instance.
 package mongoexport
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
@@ -33,7 +36,12 @@ import (
 const (
 	CSV                            = "csv"
 	JSON                           = "json"
+	BSON                           = "bson"
 	watchProgressorUpdateFrequency = 8000
+
+	// maxTimeMSExpiredErrorCode is the server error code for an operation
+	// that exceeded its maxTimeMS.
+	maxTimeMSExpiredErrorCode = 50
 )
 
 // JSONFormat is the type for all valid extended JSON formats to output.
@@ -44,6 +52,19 @@ const (
 	Canonical JSONFormat = "canonical"
 	// Relaxed indicates relaxed json format.
 	Relaxed JSONFormat = "relaxed"
+	// NDJSON indicates relaxed json format written as exactly one compact
+	// document per line, suitable for piping into line-oriented tools like jq.
+	NDJSON JSONFormat = "ndjson"
+)
+
+// Ways of handling new fields with --preserveFieldOrder.
+const (
+	// NewFieldsReport logs each field that isn't in the header once and
+	// leaves it out of the export.
+	NewFieldsReport = "report"
+	// NewFieldsExtend adds each field that isn't in the header as a new
+	// column at the end.
+	NewFieldsExtend = "extend"
 )
 
 const (
@@ -70,6 +91,20 @@ type MongoExport struct {
 
 	// Cached version of the collection info
 	collInfo *db.CollectionInfo
+
+	// prepared is set once prepare has checked the collection and opened cursor
+	prepared bool
+	// cursor over the documents to export, or nil if the collection doesn't exist
+	cursor *mongo.Cursor
+	// peeked is set while the current document of cursor has been read by
+	// prepare but not yet exported
+	peeked bool
+	// allFields are the CSV columns collected by prepare with --fieldsAll
+	allFields []string
+
+	// terminate is closed by HandleInterrupt to stop an in-progress export
+	terminate     chan struct{}
+	terminateOnce sync.Once
 }
 
 // ExportOutput is an interface that specifies how a document should be formatted
@@ -97,6 +132,7 @@ func New(opts Options) (*MongoExport, error) {
 		ToolOptions: opts.ToolOptions,
 		OutputOpts:  opts.OutputFormatOptions,
 		InputOpts:   opts.InputOptions,
+		terminate:   make(chan struct{}),
 	}
 
 	err := exporter.validateSettings()
@@ -147,6 +183,14 @@ func (exp *MongoExport) Close() {
 	}
 }
 
+// HandleInterrupt stops an in-progress export so that the output can be
+// closed cleanly.
+func (exp *MongoExport) HandleInterrupt() {
+	exp.terminateOnce.Do(func() {
+		close(exp.terminate)
+	})
+}
+
 // validateSettings returns an error if any settings specified on the command line
 // were invalid, or nil if they are valid.
 func (exp *MongoExport) validateSettings() error {
@@ -178,19 +222,77 @@ func (exp *MongoExport) validateSettings() error {
 		// special error for an empty type value
 		return fmt.Errorf("--type cannot be empty")
 	}
-	if exp.OutputOpts.Type != CSV && exp.OutputOpts.Type != JSON {
-		return fmt.Errorf("invalid output type '%v', choose 'json' or 'csv'", exp.OutputOpts.Type)
+	if exp.OutputOpts.Type != CSV && exp.OutputOpts.Type != JSON && exp.OutputOpts.Type != BSON {
+		return fmt.Errorf(
+			"invalid output type '%v', choose 'json', 'csv', or 'bson'",
+			exp.OutputOpts.Type,
+		)
+	}
+
+	if exp.OutputOpts.Type == BSON {
+		if exp.OutputOpts.JSONArray || exp.OutputOpts.Pretty {
+			return fmt.Errorf("cannot use --jsonArray or --pretty with --type=bson")
+		}
+		if exp.OutputOpts.JSONFormat != Relaxed {
+			return fmt.Errorf("--jsonFormat can only be used with --type=json")
+		}
+	}
+
+	if exp.OutputOpts.CSVStrict && exp.OutputOpts.Type != CSV {
+		return fmt.Errorf("--csvStrict can only be used with --type=csv")
+	}
+
+	if exp.OutputOpts.NoHeaderLine && exp.OutputOpts.Type != CSV {
+		return fmt.Errorf("--noHeaderLine can only be used with --type=csv")
 	}
 
-	if exp.OutputOpts.JSONFormat != Canonical && exp.OutputOpts.JSONFormat != Relaxed {
+	if exp.OutputOpts.PreserveFieldOrder && exp.OutputOpts.Type != CSV {
+		return fmt.Errorf("--preserveFieldOrder can only be used with --type=csv")
+	}
+
+	if exp.OutputOpts.FieldsAll {
+		if exp.OutputOpts.Type != CSV {
+			return fmt.Errorf("--fieldsAll can only be used with --type=csv")
+		}
+		if exp.OutputOpts.Fields != "" || exp.OutputOpts.FieldFile != "" {
+			return fmt.Errorf("cannot use --fieldsAll with --fields or --fieldFile")
+		}
+		if exp.OutputOpts.PreserveFieldOrder {
+			return fmt.Errorf("cannot use --fieldsAll with --preserveFieldOrder")
+		}
+	}
+	if exp.OutputOpts.FieldsAllSample < 0 {
+		return fmt.Errorf("--fieldsAllSample must not be negative")
+	}
+
+	switch exp.OutputOpts.NewFields {
+	case "", NewFieldsReport, NewFieldsExtend:
+	default:
 		return fmt.Errorf(
-			"invalid JSON format '%v', choose 'relaxed' or 'canonical'",
+			"invalid --newFields '%v', choose '%v' or '%v'",
+			exp.OutputOpts.NewFields, NewFieldsReport, NewFieldsExtend,
+		)
+	}
+
+	switch exp.OutputOpts.JSONFormat {
+	case Canonical, Relaxed:
+	case NDJSON:
+		if exp.OutputOpts.JSONArray || exp.OutputOpts.Pretty {
+			return fmt.Errorf("cannot use --jsonArray or --pretty with --jsonFormat=ndjson")
+		}
+	default:
+		return fmt.Errorf(
+			"invalid JSON format '%v', choose 'relaxed', 'canonical', or 'ndjson'",
 			exp.OutputOpts.JSONFormat,
 		)
 	}
 
-	if exp.InputOpts.Query != "" && exp.InputOpts.ForceTableScan {
-		return fmt.Errorf("cannot use --forceTableScan when specifying --query")
+	if _, err := exp.getExportFields(); err != nil {
+		return err
+	}
+
+	if exp.InputOpts != nil && exp.InputOpts.HasQuery() && exp.InputOpts.ForceTableScan {
+		return fmt.Errorf("cannot use --forceTableScan when specifying --query or --queryFile")
 	}
 
 	if exp.InputOpts.Query != "" && exp.InputOpts.QueryFile != "" {
@@ -214,13 +316,51 @@ func (exp *MongoExport) validateSettings() error {
 			return err
 		}
 	}
+
+	if exp.InputOpts != nil && exp.InputOpts.StableSort && exp.InputOpts.Sort == "" {
+		if exp.InputOpts.ForceTableScan {
+			return fmt.Errorf("cannot use --stableSort with --forceTableScan")
+		}
+		log.Logvf(log.Always, "warning: --stableSort sorts the documents by _id, "+
+			"so they will not be read in natural order; this can make the export slower")
+	}
+
+	if exp.InputOpts != nil && exp.InputOpts.MaxTimeMS < 0 {
+		return fmt.Errorf("--maxTimeMS must not be negative")
+	}
+
+	if exp.InputOpts != nil && exp.InputOpts.Pipeline != "" {
+		if conflicts := exp.InputOpts.pipelineConflicts(); len(conflicts) > 0 {
+			return fmt.Errorf(
+				"cannot use %v with --pipeline; add the equivalent stages to the pipeline instead",
+				strings.Join(conflicts, ", "),
+			)
+		}
+		if _, err := getPipelineFromArg(exp.InputOpts.Pipeline); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// nopCloseWriter wraps an io.Writer that should not be closed, such as stdout.
+type nopCloseWriter struct {
+	io.Writer
+}
+
+func (nopCloseWriter) Close() error { return nil }
+
 // GetOutputWriter opens and returns an io.WriteCloser for the output
 // options or nil if none is set. The caller is responsible for closing it.
+// If --gzip is set, the returned writer compresses its output and must be
+// closed to produce a complete gzip stream.
 func (exp *MongoExport) GetOutputWriter() (io.WriteCloser, error) {
 	if exp.OutputOpts.OutputFile != "" {
+		if exp.OutputOpts.Gzip && !strings.HasSuffix(exp.OutputOpts.OutputFile, ".gz") {
+			exp.OutputOpts.OutputFile += ".gz"
+			log.Logvf(log.Info, "writing gzipped output to %v", exp.OutputOpts.OutputFile)
+		}
+
 		// If the directory in which the output file is to be
 		// written does not exist, create it
 		fileDir := filepath.Dir(exp.OutputOpts.OutputFile)
@@ -233,8 +373,17 @@ func (exp *MongoExport) GetOutputWriter() (io.WriteCloser, error) {
 		if err != nil {
 			return nil, err
 		}
+		if exp.OutputOpts.Gzip {
+			return &util.WrappedWriteCloser{gzip.NewWriter(file), file}, nil
+		}
 		return file, err
 	}
+	if exp.OutputOpts.Gzip {
+		return &util.WrappedWriteCloser{
+			gzip.NewWriter(os.Stdout),
+			nopCloseWriter{os.Stdout},
+		}, nil
+	}
 	// No writer, so caller should assume Stdout (or some other reasonable default)
 	return nil, nil
 }
@@ -261,6 +410,71 @@ func makeFieldSelector(fields string) bson.M {
 	return selector
 }
 
+// exportField is a single field to export and the name it is given in the
+// CSV header.
+type exportField struct {
+	// Path is the (possibly dotted) path of the field in the document.
+	Path string
+	// Name is the column name for the field; it equals Path unless renamed.
+	Name string
+}
+
+// getExportFields returns the fields given with --fields or --fieldFile, or
+// nil if neither is set. Lines of a field file may use "path=name" to export
+// the field at path under a different column name; blank lines are ignored.
+func (exp *MongoExport) getExportFields() ([]exportField, error) {
+	if len(exp.OutputOpts.Fields) > 0 {
+		var fields []exportField
+		for _, field := range strings.Split(exp.OutputOpts.Fields, ",") {
+			fields = append(fields, exportField{Path: field, Name: field})
+		}
+		return fields, nil
+	}
+	if exp.OutputOpts.FieldFile == "" {
+		return nil, nil
+	}
+
+	lines, err := util.GetFieldsFromFile(exp.OutputOpts.FieldFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseFieldFileLines(lines)
+}
+
+// parseFieldFileLines parses the lines of a field file into export fields. It
+// returns an error if a renamed field would share a column name with another
+// field.
+func parseFieldFileLines(lines []string) ([]exportField, error) {
+	var fields []exportField
+	renamed := map[string]bool{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		field := exportField{Path: line, Name: line}
+		if i := strings.Index(line, "="); i != -1 {
+			field.Path = strings.TrimSpace(line[:i])
+			field.Name = strings.TrimSpace(line[i+1:])
+			if field.Path == "" || field.Name == "" {
+				return nil, fmt.Errorf("invalid field rename '%v', expected 'path=name'", line)
+			}
+			renamed[field.Name] = true
+		}
+		fields = append(fields, field)
+	}
+
+	seen := map[string]string{}
+	for _, field := range fields {
+		if other, ok := seen[field.Name]; ok && renamed[field.Name] {
+			return nil, fmt.Errorf(
+				"fields '%v' and '%v' are both exported as '%v'", other, field.Path, field.Name)
+		}
+		seen[field.Name] = field.Path
+	}
+	return fields, nil
+}
+
 // getCount returns an estimate of how many documents the cursor will fetch
 // It always returns Limit if there is a limit, assuming that in general
 // limits will less then the total possible.
@@ -275,7 +489,7 @@ func (exp *MongoExport) getCount() (int64, error) {
 	if exp.InputOpts != nil && exp.InputOpts.Limit != 0 {
 		return exp.InputOpts.Limit, nil
 	}
-	if exp.InputOpts != nil && exp.InputOpts.Query != "" {
+	if exp.InputOpts != nil && (exp.InputOpts.Query != "" || exp.InputOpts.Pipeline != "") {
 		return 0, nil
 	}
 	coll := session.Database(exp.ToolOptions.Namespace.DB).
@@ -312,14 +526,17 @@ func (exp *MongoExport) getCount() (int64, error) {
 // to export, based on the options given to mongoexport. Also returns the
 // associated session, so that it can be closed once the cursor is used up.
 func (exp *MongoExport) getCursor() (*mongo.Cursor, error) {
-	findOpts := mopt.Find()
+	if exp.InputOpts != nil && exp.InputOpts.Pipeline != "" {
+		return exp.getPipelineCursor()
+	}
 
-	if exp.InputOpts != nil && exp.InputOpts.Sort != "" {
-		sortD, err := getSortFromArg(exp.InputOpts.Sort)
-		if err != nil {
-			return nil, err
-		}
+	findOpts := mopt.Find()
 
+	sortD, err := exp.getSort()
+	if err != nil {
+		return nil, err
+	}
+	if sortD != nil {
 		findOpts.SetSort(sortD)
 	}
 
@@ -358,7 +575,7 @@ func (exp *MongoExport) getCursor() (*mongo.Cursor, error) {
 	// --forceTableScan.
 	shouldHintId := isMMAPV1 && (exp.InputOpts == nil || !exp.InputOpts.ForceTableScan)
 	// noSorting is true if the user did not ask for sorting.
-	noSorting := exp.InputOpts == nil || exp.InputOpts.Sort == ""
+	noSorting := sortD == nil
 	coll := intendedDB.Collection(exp.ToolOptions.Namespace.Collection)
 
 	// we want to hint _id if shouldHintId is true, and there is no query, and
@@ -380,16 +597,68 @@ func (exp *MongoExport) getCursor() (*mongo.Cursor, error) {
 	if exp.InputOpts != nil {
 		findOpts.SetLimit(exp.InputOpts.Limit)
 	}
+	if maxTime := exp.maxTime(); maxTime > 0 {
+		findOpts.SetMaxTime(maxTime)
+	}
 
-	if len(exp.OutputOpts.Fields) > 0 {
-		findOpts.SetProjection(makeFieldSelector(exp.OutputOpts.Fields))
+	fields, err := exp.getExportFields()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		paths := make([]string, 0, len(fields))
+		for _, field := range fields {
+			paths = append(paths, field.Path)
+		}
+		findOpts.SetProjection(makeFieldSelector(strings.Join(paths, ",")))
 	}
 
 	return coll.Find(context.TODO(), query, findOpts)
 }
 
+// getPipelineCursor returns a cursor over the results of running the
+// --pipeline aggregation on the collection.
+func (exp *MongoExport) getPipelineCursor() (*mongo.Cursor, error) {
+	pipeline, err := getPipelineFromArg(exp.InputOpts.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := exp.SessionProvider.GetSession()
+	if err != nil {
+		return nil, err
+	}
+	coll := session.Database(exp.ToolOptions.Namespace.DB).
+		Collection(exp.ToolOptions.Namespace.Collection)
+
+	aggOpts := mopt.Aggregate().SetAllowDiskUse(true)
+	if maxTime := exp.maxTime(); maxTime > 0 {
+		aggOpts.SetMaxTime(maxTime)
+	}
+
+	log.Logvf(log.DebugLow, "running aggregation pipeline: %v", pipeline)
+	return coll.Aggregate(context.TODO(), pipeline, aggOpts)
+}
+
+// maxTime returns the server-side time limit given with --maxTimeMS, or 0 if
+// there is none.
+func (exp *MongoExport) maxTime() time.Duration {
+	if exp.InputOpts == nil {
+		return 0
+	}
+	return time.Duration(exp.InputOpts.MaxTimeMS) * time.Millisecond
+}
+
+// isMaxTimeExpired reports whether err is the server terminating an operation
+// for exceeding its maxTimeMS.
+func isMaxTimeExpired(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(maxTimeMSExpiredErrorCode)
+}
+
 // verifyCollectionExists checks if the collection exists. If it does, a copy of the collection info will be cached
-// on the receiver. If the collection does not exist and AssertExists was specified, a non-nil error is returned.
+// on the receiver. If the collection does not exist and AssertExists or AssertNonEmpty was specified,
+// a non-nil error is returned.
 func (exp *MongoExport) verifyCollectionExists() (bool, error) {
 	session, err := exp.SessionProvider.GetSession()
 	if err != nil {
@@ -406,7 +675,7 @@ func (exp *MongoExport) verifyCollectionExists() (bool, error) {
 	// If the collection doesn't exist, GetCollectionInfo will return nil
 	if exp.collInfo == nil {
 		var collInfoErr error
-		if exp.InputOpts.AssertExists {
+		if exp.InputOpts.AssertExists || exp.InputOpts.AssertNonEmpty {
 			collInfoErr = fmt.Errorf(
 				"collection '%s' does not exist",
 				exp.ToolOptions.Namespace.Collection,
@@ -419,14 +688,113 @@ func (exp *MongoExport) verifyCollectionExists() (bool, error) {
 	return true, nil
 }
 
+// CheckAssertions returns an error if the collection doesn't exist and
+// --assertExists or --assertNonEmpty is set, or if there are no documents to
+// export and --assertNonEmpty is set. Calling it before opening the output
+// means a failed assertion leaves no output file behind; otherwise Export
+// checks the assertions itself.
+func (exp *MongoExport) CheckAssertions() error {
+	return exp.prepare()
+}
+
+// prepare checks that the collection exists and opens the cursor of documents
+// to export. With --assertNonEmpty, it reads the first document so that an
+// export with nothing to export fails before any output is written.
+func (exp *MongoExport) prepare() error {
+	if exp.prepared {
+		return nil
+	}
+	exists, err := exp.verifyCollectionExists()
+	if err != nil {
+		return err
+	}
+	exp.prepared = true
+	if !exists {
+		return nil
+	}
+
+	if exp.OutputOpts.FieldsAll {
+		if exp.allFields, err = exp.collectAllFields(); err != nil {
+			return err
+		}
+	}
+
+	cursor, err := exp.getCursor()
+	if err != nil {
+		return err
+	}
+	if exp.InputOpts != nil && exp.InputOpts.AssertNonEmpty {
+		if !cursor.Next(context.TODO()) {
+			err := cursor.Err()
+			_ = cursor.Close(context.TODO())
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf(
+				"no documents to export: collection '%s' is empty or no documents match the query",
+				exp.ToolOptions.Namespace.Collection,
+			)
+		}
+		exp.peeked = true
+	}
+	exp.cursor = cursor
+	return nil
+}
+
+// collectAllFields returns the union of the top-level fields of the documents
+// to export, in the order they are first seen. It reads at most
+// --fieldsAllSample documents, or all of them if that is 0.
+func (exp *MongoExport) collectAllFields() ([]string, error) {
+	sample := exp.OutputOpts.FieldsAllSample
+	if sample == 0 {
+		log.Logv(log.Info, "reading every document to collect the fields for --fieldsAll")
+	} else {
+		log.Logvf(log.Info, "reading up to %v documents to collect the fields for --fieldsAll",
+			sample)
+	}
+
+	cursor, err := exp.getCursor()
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var fields []string
+	known := map[string]bool{}
+	for read := int64(0); sample == 0 || read < sample; read++ {
+		if !cursor.Next(context.TODO()) {
+			break
+		}
+		elems, err := cursor.Current.Elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, elem := range elems {
+			if key := elem.Key(); !known[key] {
+				known[key] = true
+				fields = append(fields, key)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error collecting fields for --fieldsAll: %v", err)
+	}
+	log.Logvf(log.DebugLow, "--fieldsAll found fields: %v", fields)
+	return fields, nil
+}
+
 // Internal function that handles exporting to the given writer. Used primarily
 // for testing, because it bypasses writing to the file system.
 func (exp *MongoExport) exportInternal(out io.Writer) (int64, error) {
 	// Check if the collection exists before starting export
-	exists, err := exp.verifyCollectionExists()
-	if err != nil || !exists {
+	if err := exp.prepare(); err != nil {
 		return 0, err
 	}
+	if exp.cursor == nil {
+		return 0, nil
+	}
+	cursor := exp.cursor
+	defer cursor.Close(context.TODO())
 
 	max, err := exp.getCount()
 	if err != nil {
@@ -448,12 +816,10 @@ func (exp *MongoExport) exportInternal(out io.Writer) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-
-	cursor, err := exp.getCursor()
-	if err != nil {
-		return 0, err
+	if closer, ok := exportOutput.(io.Closer); ok {
+		// remove any temporary files, even if the export fails
+		defer closer.Close()
 	}
-	defer cursor.Close(context.TODO())
 
 	// Write headers
 	err = exportOutput.WriteHeader()
@@ -463,16 +829,29 @@ func (exp *MongoExport) exportInternal(out io.Writer) (int64, error) {
 
 	docsCount := int64(0)
 
-	// Write document content
-	for cursor.Next(context.TODO()) {
-		var result bson.D
-		if err := cursor.Decode(&result); err != nil {
-			return docsCount, err
+	// Write document content, starting with the document prepare read, if any
+	for exp.peeked || cursor.Next(context.TODO()) {
+		exp.peeked = false
+		select {
+		case <-exp.terminate:
+			return docsCount, util.ErrTerminated
+		default:
 		}
 
-		err := exportOutput.ExportDocument(result)
-		if err != nil {
-			return docsCount, err
+		if rawOutput, ok := exportOutput.(rawExportOutput); ok {
+			if err := rawOutput.ExportRawDocument(cursor.Current); err != nil {
+				return docsCount, err
+			}
+		} else {
+			var result bson.D
+			if err := cursor.Decode(&result); err != nil {
+				return docsCount, err
+			}
+
+			err := exportOutput.ExportDocument(result)
+			if err != nil {
+				return docsCount, err
+			}
 		}
 		docsCount++
 		if docsCount%watchProgressorUpdateFrequency == 0 {
@@ -481,6 +860,12 @@ func (exp *MongoExport) exportInternal(out io.Writer) (int64, error) {
 	}
 	watchProgressor.Set(docsCount)
 	if err := cursor.Err(); err != nil {
+		if isMaxTimeExpired(err) {
+			// Keep the documents exported before the server gave up.
+			if flushErr := exportOutput.Flush(); flushErr != nil {
+				return docsCount, flushErr
+			}
+		}
 		return docsCount, err
 	}
 
@@ -500,6 +885,13 @@ func (exp *MongoExport) exportInternal(out io.Writer) (int64, error) {
 // during the export operation.
 func (exp *MongoExport) Export(out io.Writer) (int64, error) {
 	count, err := exp.exportInternal(out)
+	if err != nil && isMaxTimeExpired(err) {
+		err = fmt.Errorf(
+			"export exceeded --maxTimeMS of %vms and was terminated by the server after "+
+				"exporting %v document(s): %w",
+			exp.InputOpts.MaxTimeMS, count, err,
+		)
+	}
 	return count, err
 }
 
@@ -509,30 +901,49 @@ func (exp *MongoExport) Export(out io.Writer) (int64, error) {
 func (exp *MongoExport) getExportOutput(out io.Writer) (ExportOutput, error) {
 	if exp.OutputOpts.Type == CSV {
 		// TODO what if user specifies *both* --fields and --fieldFile?
-		var fields []string
-		var err error
-		if len(exp.OutputOpts.Fields) > 0 {
-			fields = strings.Split(exp.OutputOpts.Fields, ",")
-		} else if exp.OutputOpts.FieldFile != "" {
-			fields, err = util.GetFieldsFromFile(exp.OutputOpts.FieldFile)
-			if err != nil {
-				return nil, err
-			}
-		} else {
+		fields, err := exp.getExportFields()
+		if err != nil {
+			return nil, err
+		}
+		if exp.OutputOpts.FieldsAll {
+			// there is no header without documents to export
+			noHeaderLine := exp.OutputOpts.NoHeaderLine || len(exp.allFields) == 0
+			csvOutput := NewCSVExportOutput(exp.allFields, noHeaderLine, out)
+			csvOutput.Strict = exp.OutputOpts.CSVStrict
+			csvOutput.ReportNewFields = true
+			return csvOutput, nil
+		}
+		if len(fields) == 0 && !exp.OutputOpts.PreserveFieldOrder {
 			return nil, fmt.Errorf("CSV mode requires a field list")
 		}
 
 		exportFields := make([]string, 0, len(fields))
+		headers := make([]string, 0, len(fields))
 		for _, field := range fields {
+			path, name := field.Path, field.Name
 			// for '$' field projections, exclude '.$' from the field name
-			if i := strings.LastIndex(field, "."); i != -1 && field[i+1:] == "$" {
-				exportFields = append(exportFields, field[:i])
-			} else {
-				exportFields = append(exportFields, field)
+			if i := strings.LastIndex(path, "."); i != -1 && path[i+1:] == "$" {
+				path = path[:i]
+				if name == field.Path {
+					name = path
+				}
 			}
+			exportFields = append(exportFields, path)
+			headers = append(headers, name)
 		}
 
-		return NewCSVExportOutput(exportFields, exp.OutputOpts.NoHeaderLine, out), nil
+		csvOutput := NewCSVExportOutput(exportFields, exp.OutputOpts.NoHeaderLine, out)
+		csvOutput.Strict = exp.OutputOpts.CSVStrict
+		if len(fields) == 0 {
+			csvOutput.DeriveFields = true
+			csvOutput.NewFields = exp.OutputOpts.NewFields
+		} else {
+			csvOutput.Headers = headers
+		}
+		return csvOutput, nil
+	}
+	if exp.OutputOpts.Type == BSON {
+		return NewBSONExportOutput(out), nil
 	}
 	return NewJSONExportOutput(
 		exp.OutputOpts.JSONArray,
@@ -559,6 +970,21 @@ func getObjectFromByteArg(queryRaw []byte) (map[string]interface{}, error) {
 	return parsedJSON, nil
 }
 
+// getSort returns the order to export the documents in: the one given with --sort, or _id
+// order if --stableSort is given without --sort. Returns nil if there is no order.
+func (exp *MongoExport) getSort() (bson.D, error) {
+	if exp.InputOpts == nil {
+		return nil, nil
+	}
+	if exp.InputOpts.Sort != "" {
+		return getSortFromArg(exp.InputOpts.Sort)
+	}
+	if exp.InputOpts.StableSort {
+		return bson.D{{"_id", 1}}, nil
+	}
+	return nil, nil
+}
+
 // getSortFromArg takes a sort specification in JSON and returns it as a bson.D
 // object which preserves the ordering of the keys as they appear in the input.
 func getSortFromArg(queryRaw string) (bson.D, error) {
@@ -570,3 +996,31 @@ func getSortFromArg(queryRaw string) (bson.D, error) {
 	// TODO: verify sort specification before returning a nil error
 	return parsedJSON, nil
 }
+
+// getPipelineFromArg takes an aggregation pipeline as a JSON array of stages
+// and returns it with any extended JSON values, such as dates, converted to
+// their BSON types. The order of the keys in each stage is preserved.
+func getPipelineFromArg(pipelineRaw string) ([]bson.D, error) {
+	var stages []bson.D
+	err := json.Unmarshal([]byte(pipelineRaw), &stages)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"pipeline '%v' is not a valid JSON array of stages: %v",
+			pipelineRaw,
+			err,
+		)
+	}
+	for i, stage := range stages {
+		if len(stage) != 1 {
+			return nil, fmt.Errorf(
+				"pipeline stage %v must have exactly one field, the stage name, but has %v",
+				i, len(stage),
+			)
+		}
+		stages[i], err = bsonutil.GetExtendedBsonD(stage)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing pipeline stage %v: %v", i, err)
+		}
+	}
+	return stages, nil
+}
@@ -8,12 +8,17 @@ package mongoexport
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
 	"github.com/mongodb/mongo-tools/common/db"
@@ -117,6 +122,96 @@ func TestFieldSelect(t *testing.T) {
 	})
 }
 
+func TestGetPipelineFromArg(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Parsing a --pipeline argument", t, func() {
+		Convey("keeps the stages and their keys in order", func() {
+			pipeline, err := getPipelineFromArg(
+				`[{$match: {a: {$gt: 1}}}, {$project: {b: 1, a: 1}}, {$limit: 5}]`,
+			)
+			So(err, ShouldBeNil)
+			So(pipeline, ShouldResemble, []bson.D{
+				{{"$match", bson.D{{"a", bson.D{{"$gt", int32(1)}}}}}},
+				{{"$project", bson.D{{"b", int32(1)}, {"a", int32(1)}}}},
+				{{"$limit", int32(5)}},
+			})
+		})
+
+		Convey("converts extended JSON values", func() {
+			pipeline, err := getPipelineFromArg(
+				`[{$match: {when: {$gte: {$date: "2020-01-01T00:00:00Z"}}}}]`,
+			)
+			So(err, ShouldBeNil)
+			match := pipeline[0][0].Value.(bson.D)
+			when := match[0].Value.(bson.D)
+			So(when[0].Value, ShouldHaveSameTypeAs, time.Time{})
+		})
+
+		Convey("accepts an empty pipeline", func() {
+			pipeline, err := getPipelineFromArg(`[]`)
+			So(err, ShouldBeNil)
+			So(pipeline, ShouldBeEmpty)
+		})
+
+		Convey("rejects anything but an array of single-field stages", func() {
+			_, err := getPipelineFromArg(`{$match: {}}`)
+			So(err, ShouldNotBeNil)
+			_, err = getPipelineFromArg(`[{$match: {}, $limit: 1}]`)
+			So(err, ShouldNotBeNil)
+			_, err = getPipelineFromArg(`[{$match: `)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMongoExportPipeline(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	if err != nil {
+		t.Fatalf("No cluster available: %v", err)
+	}
+	session, err := sessionProvider.GetSession()
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	collName := "pipeline-export"
+	coll := session.Database(testDB).Collection(collName)
+	if err := coll.Drop(context.Background()); err != nil {
+		t.Fatalf("Failed to drop collection: %v", err)
+	}
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+	for i := 0; i < 10; i++ {
+		_, err := coll.InsertOne(context.Background(), bson.D{{"_id", i}, {"x", i * 10}})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	Convey("exporting with --pipeline writes the aggregation results", t, func() {
+		opts := simpleMongoExportOpts()
+		opts.Collection = collName
+		opts.OutputFormatOptions.Type = CSV
+		opts.OutputFormatOptions.Fields = "_id,double"
+		opts.InputOptions.Pipeline = `[{$match: {x: {$gte: 70}}}, ` +
+			`{$project: {double: {$multiply: ["$x", 2]}}}, {$sort: {_id: 1}}]`
+
+		me, err := New(opts)
+		So(err, ShouldBeNil)
+		defer me.Close()
+		out := &bytes.Buffer{}
+		count, err := me.Export(out)
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 3)
+		So(out.String(), ShouldEqual, "_id,double\n7,140\n8,160\n9,180\n")
+	})
+}
+
 // Test exporting a collection with autoIndexId:false.  As of MongoDB 4.0,
 // this is only allowed on the 'local' database.
 func TestMongoExportTOOLS2174(t *testing.T) {
@@ -265,3 +360,448 @@ func TestMongoExportTOOLS1952(t *testing.T) {
 		}
 	})
 }
+
+func TestGzipOutputWriter(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With --gzip and an --out path without a .gz extension", t, func() {
+		dir, cleanup := testutil.MakeTempDir(t)
+		defer cleanup()
+
+		exporter := &MongoExport{
+			OutputOpts: &OutputFormatOptions{
+				OutputFile: filepath.Join(dir, "out.json"),
+				Gzip:       true,
+			},
+		}
+
+		writer, err := exporter.GetOutputWriter()
+		So(err, ShouldBeNil)
+		So(exporter.OutputOpts.OutputFile, ShouldEqual, filepath.Join(dir, "out.json.gz"))
+
+		jsonExporter := NewJSONExportOutput(false, false, writer, Relaxed)
+		So(jsonExporter.ExportDocument(bson.D{{"a", int32(1)}}), ShouldBeNil)
+		So(writer.Close(), ShouldBeNil)
+
+		Convey("the output file should contain a complete gzip stream", func() {
+			file, err := os.Open(exporter.OutputOpts.OutputFile)
+			So(err, ShouldBeNil)
+			defer file.Close()
+
+			gzipReader, err := gzip.NewReader(file)
+			So(err, ShouldBeNil)
+			contents, err := io.ReadAll(gzipReader)
+			So(err, ShouldBeNil)
+			So(string(contents), ShouldEqual, `{"a":1}`+"\n")
+		})
+	})
+}
+
+func TestFieldFileRenames(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a --fieldFile that renames nested fields", t, func() {
+		dir, cleanup := testutil.MakeTempDir(t)
+		defer cleanup()
+
+		fieldFile := filepath.Join(dir, "fields.txt")
+		So(os.WriteFile(fieldFile, []byte("_id\nuser.email=email\n\nscore\n"), 0644), ShouldBeNil)
+
+		exporter := &MongoExport{
+			OutputOpts: &OutputFormatOptions{Type: CSV, FieldFile: fieldFile},
+		}
+
+		Convey("the fields should carry both the path and the column name", func() {
+			fields, err := exporter.getExportFields()
+			So(err, ShouldBeNil)
+			So(fields, ShouldResemble, []exportField{
+				{Path: "_id", Name: "_id"},
+				{Path: "user.email", Name: "email"},
+				{Path: "score", Name: "score"},
+			})
+		})
+
+		Convey("the CSV output should use the renamed header and nested values", func() {
+			out := &bytes.Buffer{}
+			output, err := exporter.getExportOutput(out)
+			So(err, ShouldBeNil)
+			So(output.WriteHeader(), ShouldBeNil)
+			So(output.ExportDocument(bson.D{
+				{"_id", int32(1)},
+				{"user", bson.D{{"email", "a@example.com"}}},
+				{"score", int32(7)},
+			}), ShouldBeNil)
+			So(output.Flush(), ShouldBeNil)
+			So(out.String(), ShouldEqual, "_id,email,score\n1,a@example.com,7\n")
+		})
+	})
+
+	Convey("Parsing field file lines", t, func() {
+		Convey("should reject renames that collide with another column", func() {
+			_, err := parseFieldFileLines([]string{"a.b=x", "x"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "are both exported as 'x'")
+
+			_, err = parseFieldFileLines([]string{"a=x", "b=x"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should reject malformed renames", func() {
+			_, err := parseFieldFileLines([]string{"a="})
+			So(err, ShouldNotBeNil)
+			_, err = parseFieldFileLines([]string{"=a"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMaxTimeMS(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With an exporter", t, func() {
+		exporter := &MongoExport{
+			ToolOptions: &options.ToolOptions{
+				Namespace: &options.Namespace{DB: "db", Collection: "coll"},
+			},
+			OutputOpts: &OutputFormatOptions{Type: JSON, JSONFormat: Relaxed},
+			InputOpts:  &InputOptions{},
+		}
+
+		Convey("no time limit should be set by default", func() {
+			So(exporter.validateSettings(), ShouldBeNil)
+			So(exporter.maxTime(), ShouldEqual, 0)
+		})
+
+		Convey("--maxTimeMS should be converted to a duration", func() {
+			exporter.InputOpts.MaxTimeMS = 1500
+			So(exporter.validateSettings(), ShouldBeNil)
+			So(exporter.maxTime(), ShouldEqual, 1500*time.Millisecond)
+		})
+
+		Convey("a negative --maxTimeMS should be rejected", func() {
+			exporter.InputOpts.MaxTimeMS = -1
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--maxTimeMS")
+		})
+	})
+
+	Convey("Only MaxTimeMSExpired errors should be detected as timeouts", t, func() {
+		So(isMaxTimeExpired(mongo.CommandError{Code: 50}), ShouldBeTrue)
+		So(isMaxTimeExpired(fmt.Errorf("wrapped: %w", mongo.CommandError{Code: 50})), ShouldBeTrue)
+		So(isMaxTimeExpired(mongo.CommandError{Code: 26}), ShouldBeFalse)
+		So(isMaxTimeExpired(errors.New("operation exceeded time limit")), ShouldBeFalse)
+	})
+}
+
+func TestMongoExportMaxTimeMS(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	if err != nil {
+		t.Fatalf("No cluster available: %v", err)
+	}
+	session, err := sessionProvider.GetSession()
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	collName := "max-time-export"
+	coll := session.Database(testDB).Collection(collName)
+	if err := coll.Drop(context.Background()); err != nil {
+		t.Fatalf("Failed to drop collection: %v", err)
+	}
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+	for i := 0; i < 10; i++ {
+		_, err := coll.InsertOne(context.Background(), bson.D{{"_id", i}})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	// Make every operation with a maxTimeMS time out so the test does not
+	// depend on how fast the server is.
+	var result bson.M
+	err = sessionProvider.Run(bson.D{
+		{"configureFailPoint", "maxTimeAlwaysTimeOut"},
+		{"mode", "alwaysOn"},
+	}, &result, "admin")
+	if err != nil {
+		t.Skipf("Could not enable the maxTimeAlwaysTimeOut fail point: %v", err)
+	}
+	defer func() {
+		_ = sessionProvider.Run(bson.D{
+			{"configureFailPoint", "maxTimeAlwaysTimeOut"},
+			{"mode", "off"},
+		}, &result, "admin")
+	}()
+
+	Convey("an export that exceeds --maxTimeMS should fail with the exported count", t, func() {
+		opts := simpleMongoExportOpts()
+		opts.Collection = collName
+		opts.InputOptions.MaxTimeMS = 1000
+
+		for _, pipeline := range []string{"", `[{$match: {}}]`} {
+			opts.InputOptions.Pipeline = pipeline
+			me, err := New(opts)
+			So(err, ShouldBeNil)
+
+			count, err := me.Export(&bytes.Buffer{})
+			me.Close()
+			So(err, ShouldNotBeNil)
+			So(isMaxTimeExpired(err), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "--maxTimeMS of 1000ms")
+			So(err.Error(), ShouldContainSubstring, "after exporting 0 document(s)")
+			So(count, ShouldEqual, 0)
+		}
+	})
+}
+
+func TestMongoExportAssertions(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	if err != nil {
+		t.Fatalf("No cluster available: %v", err)
+	}
+	session, err := sessionProvider.GetSession()
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	collName := "assert-export"
+	coll := session.Database(testDB).Collection(collName)
+	if err := coll.Drop(context.Background()); err != nil {
+		t.Fatalf("Failed to drop collection: %v", err)
+	}
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+
+	exportWith := func(setOpts func(*InputOptions)) (string, int64, error) {
+		opts := simpleMongoExportOpts()
+		opts.Collection = collName
+		setOpts(opts.InputOptions)
+		me, err := New(opts)
+		So(err, ShouldBeNil)
+		defer me.Close()
+
+		if err := me.CheckAssertions(); err != nil {
+			return "", 0, err
+		}
+		out := &bytes.Buffer{}
+		count, err := me.Export(out)
+		return out.String(), count, err
+	}
+
+	Convey("--assertExists and --assertNonEmpty fail for a missing collection", t, func() {
+		_, _, err := exportWith(func(in *InputOptions) { in.AssertExists = true })
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "does not exist")
+
+		_, _, err = exportWith(func(in *InputOptions) { in.AssertNonEmpty = true })
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "does not exist")
+
+		_, count, err := exportWith(func(in *InputOptions) {})
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 0)
+	})
+
+	Convey("--assertNonEmpty fails for an empty collection", t, func() {
+		err := session.Database(testDB).CreateCollection(context.Background(), collName)
+		So(err, ShouldBeNil)
+
+		_, count, err := exportWith(func(in *InputOptions) { in.AssertExists = true })
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 0)
+
+		_, _, err = exportWith(func(in *InputOptions) { in.AssertNonEmpty = true })
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "no documents to export")
+	})
+
+	Convey("--assertNonEmpty checks the documents the query matches", t, func() {
+		for i := 0; i < 3; i++ {
+			_, err := coll.InsertOne(context.Background(), bson.D{{"_id", i}})
+			So(err, ShouldBeNil)
+		}
+
+		_, _, err := exportWith(func(in *InputOptions) {
+			in.AssertNonEmpty = true
+			in.Query = `{"_id": {"$gt": 5}}`
+		})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "no documents to export")
+
+		for _, pipeline := range []string{"", `[{$sort: {_id: 1}}]`} {
+			out, count, err := exportWith(func(in *InputOptions) {
+				in.AssertNonEmpty = true
+				in.Pipeline = pipeline
+			})
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 3)
+			So(strings.Count(out, "\n"), ShouldEqual, 3)
+		}
+	})
+}
+
+func TestMongoExportFieldsAll(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	if err != nil {
+		t.Fatalf("No cluster available: %v", err)
+	}
+	session, err := sessionProvider.GetSession()
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	collName := "fields-all-export"
+	coll := session.Database(testDB).Collection(collName)
+	if err := coll.Drop(context.Background()); err != nil {
+		t.Fatalf("Failed to drop collection: %v", err)
+	}
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+	_, err = coll.InsertMany(context.Background(), []interface{}{
+		bson.D{{"_id", 1}, {"a", "x"}},
+		bson.D{{"_id", 2}, {"b", true}},
+		bson.D{{"_id", 3}, {"a", "y"}, {"c", 3.5}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert documents: %v", err)
+	}
+
+	exportWith := func(sample int64) string {
+		opts := simpleMongoExportOpts()
+		opts.Collection = collName
+		opts.OutputFormatOptions.Type = CSV
+		opts.OutputFormatOptions.Fields = ""
+		opts.OutputFormatOptions.FieldsAll = true
+		opts.OutputFormatOptions.FieldsAllSample = sample
+		opts.InputOptions.Sort = `{"_id": 1}`
+		me, err := New(opts)
+		So(err, ShouldBeNil)
+		defer me.Close()
+
+		out := &bytes.Buffer{}
+		count, err := me.Export(out)
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 3)
+		return out.String()
+	}
+
+	Convey("--fieldsAll exports the union of the fields of every document", t, func() {
+		So(exportWith(0), ShouldEqual, "_id,a,b,c\n1,x,,\n2,,true,\n3,y,,3.5\n")
+	})
+
+	Convey("--fieldsAll only collects the fields of the sampled documents", t, func() {
+		So(exportWith(2), ShouldEqual, "_id,a,b\n1,x,\n2,,true\n3,y,\n")
+	})
+}
+
+func TestStableSort(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+	log.SetWriter(io.Discard)
+
+	Convey("With an exporter", t, func() {
+		exporter := &MongoExport{
+			ToolOptions: &options.ToolOptions{
+				Namespace: &options.Namespace{DB: "db", Collection: "coll"},
+			},
+			OutputOpts: &OutputFormatOptions{Type: JSON, JSONFormat: Relaxed},
+			InputOpts:  &InputOptions{},
+		}
+
+		Convey("there is no sort order by default", func() {
+			sortD, err := exporter.getSort()
+			So(err, ShouldBeNil)
+			So(sortD, ShouldBeNil)
+		})
+
+		Convey("--stableSort sorts by _id", func() {
+			exporter.InputOpts.StableSort = true
+			So(exporter.validateSettings(), ShouldBeNil)
+			sortD, err := exporter.getSort()
+			So(err, ShouldBeNil)
+			So(sortD, ShouldResemble, bson.D{{"_id", 1}})
+		})
+
+		Convey("--sort takes precedence over --stableSort", func() {
+			exporter.InputOpts.StableSort = true
+			exporter.InputOpts.Sort = `{a: -1}`
+			So(exporter.validateSettings(), ShouldBeNil)
+			sortD, err := exporter.getSort()
+			So(err, ShouldBeNil)
+			So(sortD, ShouldResemble, bson.D{{"a", int32(-1)}})
+		})
+
+		Convey("--stableSort cannot be used with --forceTableScan or --pipeline", func() {
+			exporter.InputOpts.StableSort = true
+			exporter.InputOpts.ForceTableScan = true
+			err := exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--forceTableScan")
+
+			exporter.InputOpts.ForceTableScan = false
+			exporter.InputOpts.Pipeline = `[]`
+			err = exporter.validateSettings()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--stableSort")
+		})
+	})
+}
+
+func TestMongoExportStableSort(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	log.SetWriter(io.Discard)
+
+	sessionProvider, _, err := testutil.GetBareSessionProvider()
+	if err != nil {
+		t.Fatalf("No cluster available: %v", err)
+	}
+	session, err := sessionProvider.GetSession()
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	collName := "stable-sort-export"
+	coll := session.Database(testDB).Collection(collName)
+	if err := coll.Drop(context.Background()); err != nil {
+		t.Fatalf("Failed to drop collection: %v", err)
+	}
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+	for _, id := range []int{3, 1, 2} {
+		_, err := coll.InsertOne(context.Background(), bson.D{{"_id", id}})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	Convey("exporting with --stableSort writes the documents in _id order", t, func() {
+		opts := simpleMongoExportOpts()
+		opts.Collection = collName
+		opts.OutputFormatOptions.Type = CSV
+		opts.OutputFormatOptions.Fields = "_id"
+		opts.InputOptions.StableSort = true
+
+		me, err := New(opts)
+		So(err, ShouldBeNil)
+		defer me.Close()
+		out := &bytes.Buffer{}
+		count, err := me.Export(out)
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 3)
+		So(out.String(), ShouldEqual, "_id\n1\n2\n3\n")
+	})
+}
@@ -17,7 +17,7 @@ import (
 
 var Usage = `<options> <connection-string>
 
-Export data from MongoDB in CSV or JSON format.
+Export data from MongoDB in CSV, JSON, or BSON format.
 
 Connection strings must begin with mongodb:// or mongodb+srv://.
 
@@ -29,10 +29,10 @@ type OutputFormatOptions struct {
 	Fields string `long:"fields" value-name:"<field>[,<field>]*" short:"f" description:"comma separated list of field names (required for exporting CSV) e.g. -f \"name,age\" "`
 
 	// FieldFile is a filename that refers to a list of fields to export, 1 per line.
-	FieldFile string `long:"fieldFile" value-name:"<filename>" description:"file with field names - 1 per line"`
+	FieldFile string `long:"fieldFile" value-name:"<filename>" description:"file with field names - 1 per line; use 'path=name' to rename a field in the CSV header"`
 
-	// Type selects the type of output to export as (json or csv).
-	Type string `long:"type" value-name:"<type>" default:"json" default-mask:"-" description:"the output format, either json or csv"`
+	// Type selects the type of output to export as (json, csv, or bson).
+	Type string `long:"type" value-name:"<type>" default:"json" default-mask:"-" description:"the output format, either json, csv, or bson (raw documents in the same format as mongodump's .bson files)"`
 
 	// Deprecated: allow legacy --csv option in place of --type=csv
 	CSVOutputType bool `long:"csv" hidden:"true"`
@@ -40,17 +40,39 @@ type OutputFormatOptions struct {
 	// OutputFile specifies an output file path.
 	OutputFile string `long:"out" value-name:"<filename>" short:"o" description:"output file; if not specified, stdout is used"`
 
+	// Gzip, if set, will compress the output with gzip as it is written.
+	Gzip bool `long:"gzip" description:"compress the output with Gzip; adds a .gz extension to --out if it does not have one"`
+
 	// JSONArray if set will export the documents an array of JSON documents.
 	JSONArray bool `long:"jsonArray" description:"output to a JSON array rather than one object per line"`
 
 	// Pretty displays JSON data in a human-readable form.
 	Pretty bool `long:"pretty" description:"output JSON formatted to be human-readable"`
 
-	// NoHeaderLine, if set, will export CSV data without a list of field names at the first line.
-	NoHeaderLine bool `long:"noHeaderLine" description:"export CSV data without a list of field names at the first line"`
+	// NoHeaderLine, if set, will export CSV data without a list of field names at the first line,
+	// e.g. to append to an existing file. Only valid with --type=csv.
+	NoHeaderLine bool `long:"noHeaderLine" description:"export CSV data without a list of field names at the first line; only valid with --type=csv"`
+
+	// PreserveFieldOrder, if set without --fields or --fieldFile, takes the CSV columns from the
+	// fields of the first document, in order.
+	PreserveFieldOrder bool `long:"preserveFieldOrder" description:"without --fields or --fieldFile, export CSV with the top-level fields of the first document as columns, in the same order; fields missing from later documents are left blank"`
+
+	// NewFields decides what --preserveFieldOrder does with fields that aren't in the first document.
+	NewFields string `long:"newFields" value-name:"<mode>" default:"report" description:"with --preserveFieldOrder, what to do with fields that aren't in the first document: 'report' logs each one once and leaves it out, 'extend' adds it as a new column, holding the documents in a temporary file until the export finishes so the header can list every column (defaults to 'report')"`
+
+	// FieldsAll, if set, takes the CSV columns from the union of the top-level fields of the
+	// documents to export, read in a preliminary pass limited by FieldsAllSample.
+	FieldsAll bool `long:"fieldsAll" description:"without --fields or --fieldFile, export CSV with a column for every top-level field found in the documents to export, in the order they are first seen; the fields are collected in a preliminary pass over the first --fieldsAllSample documents, and values missing from a document are left blank"`
+
+	// FieldsAllSample is the number of documents read to collect the fields for --fieldsAll, or 0
+	// to read all of them.
+	FieldsAllSample int64 `long:"fieldsAllSample" value-name:"<count>" default:"1000" description:"number of documents --fieldsAll reads to collect the fields, or 0 to read every document. A sample is faster, but fields that only appear in later documents are left out and reported; reading every document finds all fields at the cost of querying the data twice (defaults to 1000)"`
 
-	// JSONFormat specifies what extended JSON format to export (canonical or relaxed). Defaults to relaxed.
-	JSONFormat JSONFormat `long:"jsonFormat" value-name:"<type>" default:"relaxed" description:"the extended JSON format to output, either canonical or relaxed (defaults to 'relaxed')"`
+	// CSVStrict, if set, will export CSV data that follows RFC 4180 exactly.
+	CSVStrict bool `long:"csvStrict" description:"export CSV data that follows RFC 4180 exactly: CRLF line endings, fields kept byte for byte and quoted when needed, and arrays and subdocuments always quoted"`
+
+	// JSONFormat specifies what extended JSON format to export (canonical, relaxed, or ndjson). Defaults to relaxed.
+	JSONFormat JSONFormat `long:"jsonFormat" value-name:"<type>" default:"relaxed" description:"the extended JSON format to output, either canonical, relaxed, or ndjson (relaxed, one compact document per line) (defaults to 'relaxed')"`
 }
 
 // Name returns a human-readable group name for output format options.
@@ -64,11 +86,18 @@ type InputOptions struct {
 	QueryFile      string `long:"queryFile" value-name:"<filename>" description:"path to a file containing a query filter (JSON)"`
 	SlaveOk        bool   `long:"slaveOk" short:"k" hidden:"true" description:"allow secondary reads if available" default-mask:"-"`
 	ReadPreference string `long:"readPreference" value-name:"<string>|<json>" description:"specify either a preference mode (e.g. 'nearest') or a preference json object (e.g. '{mode: \"nearest\", tagSets: [{a: \"b\"}], maxStalenessSeconds: 123}')"`
-	ForceTableScan bool   `long:"forceTableScan" description:"force a table scan (do not use $snapshot or hint _id). Deprecated since this is default behavior on WiredTiger"`
+	ForceTableScan bool   `long:"forceTableScan" description:"force a table scan (do not use $snapshot or hint _id); cannot be used with --query or --queryFile. Deprecated since this is default behavior on WiredTiger"`
 	Skip           int64  `long:"skip" value-name:"<count>" description:"number of documents to skip"`
 	Limit          int64  `long:"limit" value-name:"<count>" description:"limit the number of documents to export"`
 	Sort           string `long:"sort" value-name:"<json>" description:"sort order, as a JSON string, e.g. '{x:1}'"`
 	AssertExists   bool   `long:"assertExists" description:"if specified, export fails if the collection does not exist"`
+	Pipeline       string `long:"pipeline" value-name:"<json>" description:"aggregation pipeline to export the results of instead of running a find, as a JSON array of stages, e.g. '[{$match: {x: 1}}, {$project: {x: 1}}]'; cannot be used with --query, --queryFile, --sort, --skip, --limit, --forceTableScan or --stableSort"`
+
+	AssertNonEmpty bool `long:"assertNonEmpty" description:"if specified, export fails if the collection does not exist or there are no documents to export; the check is made before the output file is created"`
+
+	MaxTimeMS int64 `long:"maxTimeMS" value-name:"<milliseconds>" description:"time limit for the server to run the export query or pipeline; the server terminates the query once the limit is exceeded (default: no limit)"`
+
+	StableSort bool `long:"stableSort" description:"sort by _id when no --sort is given, so that the documents are exported in the same order on every run; this disables reading in natural order, which can be slower; cannot be used with --forceTableScan"`
 }
 
 // Name returns a human-readable group name for input options.
@@ -80,6 +109,34 @@ func (inputOptions *InputOptions) HasQuery() bool {
 	return inputOptions.Query != "" || inputOptions.QueryFile != ""
 }
 
+// pipelineConflicts returns the names of the options given that can't be
+// combined with --pipeline.
+func (inputOptions *InputOptions) pipelineConflicts() []string {
+	var conflicts []string
+	if inputOptions.Query != "" {
+		conflicts = append(conflicts, "--query")
+	}
+	if inputOptions.QueryFile != "" {
+		conflicts = append(conflicts, "--queryFile")
+	}
+	if inputOptions.Sort != "" {
+		conflicts = append(conflicts, "--sort")
+	}
+	if inputOptions.Skip != 0 {
+		conflicts = append(conflicts, "--skip")
+	}
+	if inputOptions.Limit != 0 {
+		conflicts = append(conflicts, "--limit")
+	}
+	if inputOptions.ForceTableScan {
+		conflicts = append(conflicts, "--forceTableScan")
+	}
+	if inputOptions.StableSort {
+		conflicts = append(conflicts, "--stableSort")
+	}
+	return conflicts
+}
+
 func (inputOptions *InputOptions) GetQuery() ([]byte, error) {
 	if inputOptions.Query != "" {
 		return []byte(inputOptions.Query), nil
@@ -107,6 +107,7 @@ func TestParseOptions(t *testing.T) {
 		}{
 			{"JSON format defaults to relaxed", "", true, Relaxed},
 			{"JSON format can be set", Canonical, true, Canonical},
+			{"JSON format can be set to ndjson", NDJSON, true, NDJSON},
 		}
 
 		baseOpts := []string{"--host", "localhost:27017", "--db", "db", "--collection", "coll"}
@@ -42,6 +42,11 @@ func main() {
 		os.Exit(util.ExitSuccess)
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		os.Exit(util.ExitSuccess)
+	}
+
 	mf, err := mongofiles.New(opts)
 	if err != nil {
 		log.Logv(log.Always, err.Error())
@@ -14,8 +14,11 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/mongodb/mongo-tools/common/bsonutil"
 	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/json"
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/util"
@@ -197,12 +200,27 @@ func (mf *MongoFiles) findAndDisplay(query bson.M) (string, error) {
 
 	var display string
 	for _, gridFile := range gridFiles {
-		display += fmt.Sprintf("%s\t%d\n", gridFile.Name, gridFile.Length)
+		display += fmt.Sprintf(
+			"%s\t%d\t%s\n",
+			gridFile.Name,
+			gridFile.Length,
+			gridFile.UploadDate.UTC().Format(time.RFC3339),
+		)
 	}
 
 	return display, nil
 }
 
+// filenamePrefixQuery returns a query matching files whose names begin with
+// prefix. Regex metacharacters in prefix are escaped so they match literally.
+// An empty prefix matches every file.
+func filenamePrefixQuery(prefix string) bson.M {
+	if prefix == "" {
+		return bson.M{}
+	}
+	return bson.M{"filename": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+}
+
 // Return the local filename, as specified by the --local flag. Defaults to
 // the GridFile's name if not present. If GridFile is nil, uses the filename
 // given on the command line.
@@ -239,8 +257,11 @@ func (mf *MongoFiles) handleGet() (err error) {
 }
 
 // Gets all GridFS files that match the given query.
-func (mf *MongoFiles) findGFSFiles(query bson.M) (files []*gfsFile, err error) {
-	cursor, err := mf.bucket.Find(query)
+func (mf *MongoFiles) findGFSFiles(
+	query bson.M,
+	opts ...*driverOptions.GridFSFindOptions,
+) (files []*gfsFile, err error) {
+	cursor, err := mf.bucket.Find(query, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -281,13 +302,21 @@ func (mf *MongoFiles) getTargetGFSFiles() ([]*gfsFile, error) {
 		minimumExpectedDocsError = fmt.Errorf("files matching the following pattern were not found: %v", mf.FileNameRegex)
 	} else if mf.Id != "" {
 		// Case supporting queries by file ID specified in mongofiles ... get_id ...
-		minimumExpectedDocsError = fmt.Errorf("no such file with _id: %v", mf.Id)
-
 		id, err := mf.parseOrCreateID()
 		if err != nil {
 			return nil, err
 		}
 
+		minimumExpectedDocsError = fmt.Errorf("no such file with _id: %v", mf.Id)
+		if str, ok := id.(string); ok && primitive.IsValidObjectID(str) {
+			// a hex string never matches an ObjectId, so point out the likely mistake
+			minimumExpectedDocsError = fmt.Errorf(
+				`no such file with _id: %v (the _id was parsed as a string; `+
+					`use ObjectId("%v") to match an ObjectId)`,
+				mf.Id, str,
+			)
+		}
+
 		query = bson.M{"_id": id}
 	} else {
 		// Case supporting queries of a single file with specific local
@@ -325,6 +354,33 @@ func (mf *MongoFiles) deleteAll(filename string) error {
 	return nil
 }
 
+// replaceOlderFiles deletes every file with the given filename except the most
+// recently uploaded one, which is the file just written by put. Files are
+// ordered by upload date and then _id, so concurrent puts with --replace all
+// keep the same file and the filename always maps to at least one file.
+func (mf *MongoFiles) replaceOlderFiles(filename string) error {
+	findOpts := driverOptions.GridFSFind().SetSort(bson.D{{"uploadDate", -1}, {"_id", -1}})
+	gridFiles, err := mf.findGFSFiles(bson.M{"filename": filename}, findOpts)
+	if err != nil {
+		return err
+	}
+	if len(gridFiles) < 2 {
+		return nil
+	}
+
+	for _, gridFile := range gridFiles[1:] {
+		// a concurrent put with --replace may have deleted it already
+		err := mf.bucket.Delete(gridFile.ID)
+		if err != nil && err != gridfs.ErrFileNotFound {
+			return fmt.Errorf("error while removing '%v' from GridFS: %v", gridFile.Name, err)
+		}
+	}
+	log.Logvf(log.Always, "replaced %v older instance(s) of '%v' in GridFS\n",
+		len(gridFiles)-1, filename)
+
+	return nil
+}
+
 // handleDeleteID contains the logic for the 'delete_id' command.
 func (mf *MongoFiles) handleDeleteID() error {
 	files, err := mf.getTargetGFSFiles()
@@ -342,6 +398,10 @@ func (mf *MongoFiles) handleDeleteID() error {
 }
 
 // parse and convert input extended JSON _id. Generates a new ObjectID if no _id provided.
+// Besides canonical and relaxed extended JSON, the legacy shell syntax accepted by
+// common/json (e.g. ObjectId("..."), NumberLong(5) or a plain number) is supported
+// so the parsed _id has the same type as the stored one. Anything else is taken as
+// a string.
 func (mf *MongoFiles) parseOrCreateID() (interface{}, error) {
 	trimmed := strings.Trim(mf.Id, " ")
 
@@ -350,15 +410,14 @@ func (mf *MongoFiles) parseOrCreateID() (interface{}, error) {
 	}
 
 	// Wrap JSON bytes into a document for unmarshaling, then pick out the value after.
-	var wrapped string
-	switch trimmed[0] {
-	case '{':
-		wrapped = fmt.Sprintf(`{"_id":%s}`, trimmed)
-	default:
-		wrapped = fmt.Sprintf(`{"_id":"%s"}`, trimmed)
+	if trimmed[0] != '{' {
+		if id, err := parseLegacyID(trimmed); err == nil {
+			return id, nil
+		}
+		trimmed = fmt.Sprintf(`"%s"`, trimmed)
 	}
 	var idDoc bson.D
-	err := bson.UnmarshalExtJSON([]byte(wrapped), false, &idDoc)
+	err := bson.UnmarshalExtJSON([]byte(fmt.Sprintf(`{"_id":%s}`, trimmed)), false, &idDoc)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing id as Extended JSON: %v", err)
 	}
@@ -366,6 +425,15 @@ func (mf *MongoFiles) parseOrCreateID() (interface{}, error) {
 	return idDoc[0].Value, nil
 }
 
+// parseLegacyID parses a non-document _id with common/json and converts it to BSON.
+func parseLegacyID(raw string) (interface{}, error) {
+	var idDoc map[string]interface{}
+	if err := json.Unmarshal([]byte(fmt.Sprintf(`{"_id":%s}`, raw)), &idDoc); err != nil {
+		return nil, err
+	}
+	return bsonutil.ParseLegacyExtJSONValue(idDoc["_id"])
+}
+
 // writeGFSFileToLocal writes a file from gridFS to stdout or the filesystem.
 func (mf *MongoFiles) writeGFSFileToLocal(gridFile *gfsFile) (err error) {
 	localFileName := mf.getLocalFileName(gridFile)
@@ -396,7 +464,8 @@ func (mf *MongoFiles) writeGFSFileToLocal(gridFile *gfsFile) (err error) {
 	return nil
 }
 
-// Write the given GridFS file to the database. Will fail if file already exists and --replace flag turned off.
+// Write the given GridFS file to the database. With --replace, other files with the same name are
+// deleted once the new file has been written, so a failed upload never leaves the name without a file.
 func (mf *MongoFiles) put(id interface{}, name string) (bytesWritten int64, err error) {
 	gridFile, err := newGfsFile(id, name, mf)
 	if err != nil {
@@ -418,13 +487,6 @@ func (mf *MongoFiles) put(id interface{}, name string) (bytesWritten int64, err
 		log.Logvf(log.DebugLow, "creating GridFS gridFile '%v' from local gridFile '%v'", mf.FileName, localFileName)
 	}
 
-	// check if --replace flag turned on
-	if mf.StorageOptions.Replace {
-		if err = mf.deleteAll(gridFile.Name); err != nil {
-			return 0, err
-		}
-	}
-
 	if mf.StorageOptions.ContentType != "" {
 		gridFile.Metadata.ContentType = mf.StorageOptions.ContentType
 	}
@@ -433,14 +495,28 @@ func (mf *MongoFiles) put(id interface{}, name string) (bytesWritten int64, err
 	if err != nil {
 		return 0, err
 	}
-	dc := util.DeferredCloser{Closer: stream}
-	defer dc.CloseWithErrorCapture(&err)
 
 	n, err := io.Copy(stream, localFile)
 	if err != nil {
+		// remove the chunks written so far rather than closing the stream,
+		// which would store a truncated file
+		if abortErr := stream.Abort(); abortErr != nil {
+			log.Logvf(log.Always, "error removing partially written GridFS file '%v': %v",
+				gridFile.Name, abortErr)
+		}
+		return n, fmt.Errorf("error while storing '%v' into GridFS: %v", localFileName, err)
+	}
+	if err = stream.Close(); err != nil {
 		return n, fmt.Errorf("error while storing '%v' into GridFS: %v", localFileName, err)
 	}
 
+	// check if --replace flag turned on
+	if mf.StorageOptions.Replace {
+		if err = mf.replaceOlderFiles(gridFile.Name); err != nil {
+			return n, err
+		}
+	}
+
 	return n, nil
 }
 
@@ -523,12 +599,7 @@ func (mf *MongoFiles) Run(displayHost bool) (output string, finalErr error) {
 	switch mf.Command {
 
 	case List:
-		query := bson.M{}
-		if mf.FileName != "" {
-			regex := bson.M{"$regex": "^" + regexp.QuoteMeta(mf.FileName)}
-			query = bson.M{"filename": regex}
-		}
-		output, err = mf.findAndDisplay(query)
+		output, err = mf.findAndDisplay(filenamePrefixQuery(mf.FileName))
 
 	case Search:
 		regex := bson.M{"$regex": mf.FileName}
@@ -12,7 +12,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/db"
@@ -22,6 +24,9 @@ import (
 	"github.com/mongodb/mongo-tools/common/testutil"
 	"github.com/mongodb/mongo-tools/common/util"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
@@ -884,3 +889,174 @@ func runPutIDTestCase(idToTest string, t *testing.T) {
 	So(err, ShouldBeNil)
 	So(isContentSame, ShouldBeTrue)
 }
+
+// Test that the 'list' prefix query treats regex metacharacters literally.
+func TestFilenamePrefixQuery(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a prefix query", t, func() {
+		matches := func(prefix, filename string) bool {
+			query := filenamePrefixQuery(prefix)
+			regex := query["filename"].(bson.M)["$regex"].(string)
+			return regexp.MustCompile(regex).MatchString(filename)
+		}
+
+		Convey("an empty prefix matches every file", func() {
+			So(filenamePrefixQuery(""), ShouldResemble, bson.M{})
+		})
+
+		Convey("the prefix is anchored to the start of the filename", func() {
+			So(matches("test", "testfile1"), ShouldBeTrue)
+			So(matches("test", "mytestfile"), ShouldBeFalse)
+		})
+
+		Convey("regex metacharacters in the prefix are escaped", func() {
+			So(matches("a.b", "a.b.txt"), ShouldBeTrue)
+			So(matches("a.b", "axb.txt"), ShouldBeFalse)
+			So(matches("report(1)*", "report(1)*.pdf"), ShouldBeTrue)
+			So(matches("report(1)*", "report1.pdf"), ShouldBeFalse)
+			So(matches("[x]+?", "[x]+?.log"), ShouldBeTrue)
+			So(matches("[x]+?", "x.log"), ShouldBeFalse)
+			So(matches(`c:\dir$`, `c:\dir$\file`), ShouldBeTrue)
+			So(matches("^a|b", "b"), ShouldBeFalse)
+		})
+	})
+}
+
+// Test that _id arguments parse to the same BSON types the ids were stored with.
+func TestParseOrCreateID(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	oid, err := primitive.ObjectIDFromHex("5f1b2c3d4e5f60718293a4b5")
+	require.NoError(t, err)
+
+	tests := map[string]interface{}{
+		`{"$oid":"5f1b2c3d4e5f60718293a4b5"}`:  oid,
+		`ObjectId("5f1b2c3d4e5f60718293a4b5")`: oid,
+		`{"$numberLong":"7"}`:                  int64(7),
+		`NumberLong(7)`:                        int64(7),
+		`42`:                                   int32(42),
+		`"quoted"`:                             "quoted",
+		`plain`:                                "plain",
+		`5f1b2c3d4e5f60718293a4b5`:             "5f1b2c3d4e5f60718293a4b5",
+		` padded `:                             "padded",
+	}
+	for input, expected := range tests {
+		mf := MongoFiles{Id: input}
+		id, err := mf.parseOrCreateID()
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, id, input)
+	}
+
+	mf := MongoFiles{}
+	id, err := mf.parseOrCreateID()
+	require.NoError(t, err)
+	assert.IsType(t, primitive.ObjectID{}, id)
+
+	mf = MongoFiles{Id: `{"$oid":"nothex"}`}
+	_, err = mf.parseOrCreateID()
+	assert.Error(t, err)
+}
+
+func TestPutReplace(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+
+	const remoteName = "replaced.txt"
+	localFiles := []string{
+		util.ToUniversalPath("testdata/lorem_ipsum_multi_args_0.txt"),
+		util.ToUniversalPath("testdata/lorem_ipsum_multi_args_1.txt"),
+		util.ToUniversalPath("testdata/lorem_ipsum_multi_args_2.txt"),
+	}
+
+	sessionProvider, err := db.NewSessionProvider(*toolOptions)
+	require.NoError(t, err)
+	session, err := sessionProvider.GetSession()
+	require.NoError(t, err)
+	filesColl := session.Database(testDB).Collection("fs.files")
+	chunksColl := session.Database(testDB).Collection("fs.chunks")
+
+	put := func(localFile string, replace bool) error {
+		mf, err := simpleMongoFilesInstanceWithFilename("put", remoteName)
+		if err != nil {
+			return err
+		}
+		mf.StorageOptions.LocalFileName = localFile
+		mf.StorageOptions.Replace = replace
+		_, err = mf.Run(false)
+		return err
+	}
+
+	// remoteFileIDs returns the _id of every file named remoteName, and checks
+	// that the chunks collection holds chunks for no other files.
+	remoteFileIDs := func(t *testing.T) []interface{} {
+		ids, err := filesColl.Distinct(
+			context.Background(), "_id", bson.D{{"filename", remoteName}},
+		)
+		require.NoError(t, err)
+		chunkFileIDs, err := chunksColl.Distinct(context.Background(), "files_id", bson.D{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, ids, chunkFileIDs, "chunks belong only to stored files")
+		return ids
+	}
+
+	reset := func(t *testing.T) {
+		require.NoError(t, session.Database(testDB).Drop(context.Background()))
+	}
+	t.Cleanup(func() { reset(t) })
+
+	t.Run("put without --replace adds another file", func(t *testing.T) {
+		reset(t)
+		require.NoError(t, put(localFiles[0], false))
+		require.NoError(t, put(localFiles[1], false))
+		assert.Len(t, remoteFileIDs(t), 2)
+	})
+
+	t.Run("put with --replace leaves a single file", func(t *testing.T) {
+		reset(t)
+		require.NoError(t, put(localFiles[0], false))
+		require.NoError(t, put(localFiles[1], false))
+		require.NoError(t, put(localFiles[2], true))
+
+		ids := remoteFileIDs(t)
+		require.Len(t, ids, 1)
+
+		var file gfsFile
+		require.NoError(t, filesColl.FindOne(
+			context.Background(), bson.D{{"_id", ids[0]}},
+		).Decode(&file))
+		info, err := os.Stat(localFiles[2])
+		require.NoError(t, err)
+		assert.Equal(t, info.Size(), file.Length, "the newest file is kept")
+	})
+
+	t.Run("failed put with --replace keeps the existing file", func(t *testing.T) {
+		reset(t)
+		require.NoError(t, put(localFiles[0], false))
+
+		// reading a directory fails after the upload stream is opened
+		assert.Error(t, put("testdata", true))
+		assert.Len(t, remoteFileIDs(t), 1)
+	})
+
+	t.Run("concurrent puts with --replace leave a single file", func(t *testing.T) {
+		reset(t)
+		require.NoError(t, put(localFiles[0], false))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(localFile string) {
+				defer wg.Done()
+				errs <- put(localFile, true)
+			}(localFiles[i%len(localFiles)])
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		assert.Len(t, remoteFileIDs(t), 1)
+	})
+}
@@ -22,7 +22,7 @@ Manipulate gridfs files using the command line.
 Connection strings must begin with mongodb:// or mongodb+srv://.
 
 Possible commands include:
-	list      - list all files; 'filename' is an optional prefix which listed filenames must begin with
+	list      - list all files with their sizes and upload dates; 'filename' is an optional prefix which listed filenames must begin with
 	search    - search all files; 'filename' is a regex which listed filenames must match
 	put       - add files with filenames specified in the supporting arguments
 	put_id    - add a file with filename 'filename' and a given '_id'
@@ -103,8 +103,8 @@ type StorageOptions struct {
 	// 'ContentType' is an option that specifies the Content/MIME type to use for 'put'
 	ContentType string `long:"type" value-nane:"<content-type>" short:"t" description:"content/MIME type for put (optional)"`
 
-	// if set, 'Replace' will remove other files with same name after 'put'
-	Replace bool `long:"replace" short:"r" description:"remove other files with same name after put"`
+	// if set, 'Replace' will remove other files with same name after 'put' succeeds
+	Replace bool `long:"replace" short:"r" description:"after put succeeds, remove other files with the same name so that it maps to a single file"`
 
 	// GridFSPrefix specifies what GridFS prefix to use; defaults to 'fs'
 	GridFSPrefix string `long:"prefix" value-name:"<prefix>" default:"fs" default-mask:"-" description:"GridFS prefix to use"`
@@ -17,6 +17,7 @@ import (
 	"sync/atomic"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
+	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/util"
 	"go.mongodb.org/mongo-driver/bson"
@@ -40,9 +41,10 @@ func ValidatePG(pg string) (ParseGrace, error) {
 		return pgAutoCast, nil
 	case "skipField":
 		return pgSkipField, nil
-	case "skipRow":
+	case "skipRow", "skip":
+		// skip is an alias for skipRow
 		return pgSkipRow, nil
-	case "stop":
+	case "stop", "":
 		return pgStop, nil
 	default:
 		return pgAutoCast, fmt.Errorf("invalid parse grace: %s", pg)
@@ -62,6 +64,23 @@ type Converter interface {
 	Convert() (document bson.D, err error)
 }
 
+// An InputDocument is a document converted from the input source. With
+// --rejectFile, Source holds the input it was converted from, so that the
+// document can be written to the reject file if it fails to import. Line is
+// the line of the input it was read from, or 0 for input types that don't
+// track lines.
+type InputDocument struct {
+	Document bson.D
+	Source   string
+	Line     uint64
+}
+
+// A lineConverter is a Converter that knows the line of the input its
+// document was read from.
+type lineConverter interface {
+	Line() uint64
+}
+
 // An importWorker reads Converter from the unprocessedDataChan channel and
 // sends processed BSON documents on the processedDocumentChan channel.
 type importWorker struct {
@@ -69,10 +88,14 @@ type importWorker struct {
 	unprocessedDataChan chan Converter
 
 	// used to stream the processed document back to the caller
-	processedDocumentChan chan bson.D
+	processedDocumentChan chan InputDocument
 
 	// used to synchronize all worker goroutines
 	tomb *tomb.Tomb
+
+	// keepSource is whether each document is sent with the input it was
+	// converted from
+	keepSource bool
 }
 
 // an interface for tracking the number of bytes, which is used in mongoimport to feed
@@ -136,6 +159,10 @@ func newBomDiscardingReader(r io.Reader) *bomDiscardingReader {
 
 const quorum = 2
 
+// maxArrayIndex is the largest array index allowed in a field name with --useArrayIndexFields.
+// Each element of a BSON array takes at least 3 bytes, so no larger array fits in a document.
+const maxArrayIndex = db.MaxBSONSize / 3
+
 // channelQuorumError takes a channel and either returns the first non-nil error received on the
 // channel or nil if up to 2 nil errors are received.
 func channelQuorumError(ch <-chan error) (err error) {
@@ -147,23 +174,31 @@ func channelQuorumError(ch <-chan error) (err error) {
 	return
 }
 
-// constructUpsertDocument constructs a BSON document to use for upserts.
+// constructUpsertDocument constructs a BSON document to use for upserts. It
+// returns nil if any of the upsert fields cannot be resolved in document.
 func constructUpsertDocument(upsertFields []string, document bson.D) bson.D {
 	upsertDocument := bson.D{}
-	var hasDocumentKey bool
 	for _, key := range upsertFields {
-		val := getUpsertValue(key, document)
-		if val != nil {
-			hasDocumentKey = true
+		val, ok := lookupUpsertValue(key, document)
+		if !ok {
+			return nil
 		}
 		upsertDocument = append(upsertDocument, bson.E{Key: key, Value: val})
 	}
-	if !hasDocumentKey {
-		return nil
-	}
 	return upsertDocument
 }
 
+// missingUpsertField returns the first of the upsert fields that cannot be
+// resolved in document, or "" if they all can.
+func missingUpsertField(upsertFields []string, document bson.D) string {
+	for _, key := range upsertFields {
+		if _, ok := lookupUpsertValue(key, document); !ok {
+			return key
+		}
+	}
+	return ""
+}
+
 // doSequentialStreaming takes a slice of workers, a readDocs (input) channel and
 // an outputChan (output) channel. It sequentially writes unprocessed data read from
 // the input channel to each worker and then sequentially reads the processed data
@@ -171,7 +206,7 @@ func constructUpsertDocument(upsertFields []string, document bson.D) bson.D {
 func doSequentialStreaming(
 	workers []*importWorker,
 	readDocs chan Converter,
-	outputChan chan bson.D,
+	outputChan chan InputDocument,
 ) {
 	numWorkers := len(workers)
 
@@ -214,31 +249,37 @@ func doSequentialStreaming(
 // 34 in the document: bson.M{"person": bson.M{"age": 34}} whereas,
 // "person.name" would return nil.
 func getUpsertValue(field string, document bson.D) interface{} {
+	val, _ := lookupUpsertValue(field, document)
+	return val
+}
+
+// lookupUpsertValue is like getUpsertValue, but also reports whether the field
+// could be resolved in the document. Paths that pass through an array can't be
+// resolved to a single value, so they are reported as not found.
+func lookupUpsertValue(field string, document bson.D) (interface{}, bool) {
 	index := strings.Index(field, ".")
 	if index == -1 {
-		// grab the value (ignoring errors because we are okay with nil)
-		val, _ := bsonutil.FindValueByKey(field, &document)
-		return val
+		val, err := bsonutil.FindValueByKey(field, &document)
+		return val, err == nil
 	}
 	// recurse into subdocuments
 	left := field[0:index]
 	subDoc, _ := bsonutil.FindValueByKey(left, &document)
 	if subDoc == nil {
 		log.Logvf(log.DebugHigh, "no subdoc found for '%v'", left)
-		return nil
+		return nil, false
 	}
-	switch subDoc.(type) {
+	switch subDocD := subDoc.(type) {
 	case bson.D:
-		//nolint:errcheck
-		subDocD := subDoc.(bson.D)
-		return getUpsertValue(field[index+1:], subDocD)
+		return lookupUpsertValue(field[index+1:], subDocD)
 	case *bson.D:
-		//nolint:errcheck
-		subDocD := subDoc.(*bson.D)
-		return getUpsertValue(field[index+1:], *subDocD)
+		return lookupUpsertValue(field[index+1:], *subDocD)
+	case bson.A, []interface{}, *[]interface{}:
+		log.Logvf(log.DebugHigh, "subdoc found for '%v', but it is an array", left)
+		return nil, false
 	default:
 		log.Logvf(log.DebugHigh, "subdoc found for '%v', but couldn't coerce to bson.D", left)
-		return nil
+		return nil, false
 	}
 }
 
@@ -281,9 +322,10 @@ func removeBlankFields(document bson.D) (newDocument bson.D) {
 //     created, added to the document, and a reference is passed to those functions.
 //
 //  4. If setNestedArrayValue has been called, the first part of the field is an array index.
-//     If there is only one field part, setNestedArrayValue will append the provided value to the
-//     provided array. This is only if the size of the array is equal to the index (meaning
-//     elements of the array must be added sequentially: 0, 1, 2,...).
+//     The array is first extended with nulls up to the index, so that elements can be added in
+//     any order and indexes can be skipped. If there is only one field part,
+//     setNestedArrayValue will set the element at the index to the provided value, unless it
+//     has already been set.
 //
 //  5. setNestedArrayValue will call setNestedDocumentValue if the next part of the field is not a
 //     natural number (which implies the value is a document). setNestedArrayValue will call
@@ -370,24 +412,23 @@ func setNestedArrayValue(fieldParts []string, value interface{}, array *bson.A)
 		)
 	}
 
+	for len(*array) <= idx {
+		*array = append(*array, nil)
+	}
+
 	if len(fieldParts) == 1 {
-		if idx != len(*array) {
-			return fmt.Errorf(
-				"Trying to add value to array at index %d, but array is %d elements long. "+
-					"Array indices in fields must start from 0 and increase sequentially",
-				idx,
-				len(*array),
-			)
+		if (*array)[idx] != nil {
+			return fmt.Errorf("Array element at index %d has already been set: %#v",
+				idx, (*array)[idx])
 		}
-
-		*array = append(*array, value)
+		(*array)[idx] = value
 		return nil
 	}
 
 	if _, ok := isNatNum(fieldParts[1]); ok {
 		// next part of the field refers to an array
-		if idx < len(*array) {
-			// the index already exists in array
+		if (*array)[idx] != nil {
+			// the element at idx has already been set
 			// check the element is an array
 			subArray, ok := (*array)[idx].(*bson.A)
 			if !ok {
@@ -405,12 +446,12 @@ func setNestedArrayValue(fieldParts []string, value interface{}, array *bson.A)
 			if err != nil {
 				return err
 			}
-			*array = append(*array, subArray)
+			(*array)[idx] = subArray
 		}
 	} else {
 		// next part of the field refers to a document
-		if idx < len(*array) {
-			// the index already exists in array
+		if (*array)[idx] != nil {
+			// the element at idx has already been set
 			// check the element is an document
 			subDocument, ok := (*array)[idx].(*bson.D)
 			if !ok {
@@ -428,7 +469,7 @@ func setNestedArrayValue(fieldParts []string, value interface{}, array *bson.A)
 			if err != nil {
 				return err
 			}
-			*array = append(*array, subDocument)
+			(*array)[idx] = subDocument
 		}
 	}
 	return nil
@@ -452,12 +493,14 @@ func isNatNum(s string) (int, bool) {
 // streamDocuments concurrently processes data gotten from the inputChan
 // channel in parallel and then sends over the processed data to the outputChan
 // channel - either in sequence or concurrently (depending on the value of
-// ordered) - in which the data was received.
+// ordered) - in which the data was received. If keepSource is set, each
+// document is sent with the input it was converted from.
 func streamDocuments(
 	ordered bool,
 	numDecoders int,
 	readDocs chan Converter,
-	outputChan chan bson.D,
+	outputChan chan InputDocument,
+	keepSource bool,
 ) (retErr error) {
 	if numDecoders == 0 {
 		numDecoders = 1
@@ -470,12 +513,13 @@ func streamDocuments(
 	for i := 0; i < numDecoders; i++ {
 		if ordered {
 			inChan = make(chan Converter, workerBufferSize)
-			outChan = make(chan bson.D, workerBufferSize)
+			outChan = make(chan InputDocument, workerBufferSize)
 		}
 		iw := &importWorker{
 			unprocessedDataChan:   inChan,
 			processedDocumentChan: outChan,
 			tomb:                  importTomb,
+			keepSource:            keepSource,
 		}
 		importWorkers = append(importWorkers, iw)
 		wg.Add(1)
@@ -508,10 +552,13 @@ type coercionError struct{}
 func (coercionError) Error() string { return "coercionError" }
 
 // tokensToBSON reads in slice of records - along with ordered column names -
-// and returns a BSON document for the record.
+// and returns a BSON document for the record. If quoted is non-nil, it reports
+// which tokens were quoted in the input; quoted empty strings are kept even
+// when ignoreBlanks is set.
 func tokensToBSON(
 	colSpecs []ColumnSpec,
 	tokens []string,
+	quoted []bool,
 	numProcessed uint64,
 	ignoreBlanks bool,
 	useArrayIndexFields bool,
@@ -520,7 +567,7 @@ func tokensToBSON(
 	var parsedValue interface{}
 	document := bson.D{}
 	for index, token := range tokens {
-		if token == "" && ignoreBlanks {
+		if token == "" && ignoreBlanks && !(index < len(quoted) && quoted[index]) {
 			continue
 		}
 		if index < len(colSpecs) {
@@ -540,11 +587,12 @@ func tokensToBSON(
 				case pgStop:
 					return nil, fmt.Errorf(
 						"type coercion failure in document #%d for column '%s', "+
-							"could not parse token '%s' to type %s",
+							"could not parse token '%s' to type %s: %v",
 						numProcessed,
 						colSpecs[index].Name,
 						token,
 						colSpecs[index].TypeName,
+						err,
 					)
 				}
 			}
@@ -578,6 +626,75 @@ func tokensToBSON(
 	return document, nil
 }
 
+// countedTokensToBSON is like tokensToBSON, but first checks that the record on
+// the given input line has one token per column. A mismatch is handled
+// according to parseGrace: stop fails the import, skipRow rejects the record,
+// skipField drops extra tokens and leaves out missing columns, and autoCast
+// sets missing columns to null.
+func countedTokensToBSON(
+	colSpecs []ColumnSpec,
+	tokens []string,
+	quoted []bool,
+	numProcessed uint64,
+	line uint64,
+	parseGrace ParseGrace,
+	ignoreBlanks bool,
+	useArrayIndexFields bool,
+) (bson.D, error) {
+	if len(colSpecs) == 0 || len(tokens) == len(colSpecs) {
+		return tokensToBSON(colSpecs, tokens, quoted, numProcessed, ignoreBlanks, useArrayIndexFields)
+	}
+
+	switch parseGrace {
+	case pgStop:
+		return nil, fmt.Errorf(
+			"wrong number of fields on line %d: expected %d but found %d",
+			line, len(colSpecs), len(tokens),
+		)
+	case pgSkipRow:
+		log.Logvf(log.Always, "skipping row on line %d: expected %d fields but found %d",
+			line, len(colSpecs), len(tokens))
+		return nil, coercionError{}
+	case pgSkipField:
+		if len(tokens) > len(colSpecs) {
+			log.Logvf(log.Info, "dropping %d extra field(s) on line %d",
+				len(tokens)-len(colSpecs), line)
+			tokens = tokens[:len(colSpecs)]
+			if len(quoted) > len(colSpecs) {
+				quoted = quoted[:len(colSpecs)]
+			}
+		}
+		return tokensToBSON(colSpecs, tokens, quoted, numProcessed, ignoreBlanks, useArrayIndexFields)
+	}
+
+	document, err := tokensToBSON(
+		colSpecs,
+		tokens,
+		quoted,
+		numProcessed,
+		ignoreBlanks,
+		useArrayIndexFields,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > len(colSpecs) {
+		// extra tokens are kept as automatically named fields
+		return document, nil
+	}
+	for _, colSpec := range colSpecs[len(tokens):] {
+		if len(colSpec.NameParts) > 1 {
+			err = setNestedDocumentValue(colSpec.NameParts, nil, &document, useArrayIndexFields)
+			if err != nil {
+				return nil, fmt.Errorf("can't set value for key %s: %s", colSpec.Name, err)
+			}
+		} else {
+			document = append(document, bson.E{Key: colSpec.Name, Value: nil})
+		}
+	}
+	return document, nil
+}
+
 // validateFields takes a slice of fields and returns an error if the fields
 // are invalid, returns nil otherwise. Fields are invalid in the following cases:
 //
@@ -590,9 +707,10 @@ func tokensToBSON(
 //	(4). One field implies there is a value, another implies there is an array (e.g. a,a.0).
 //	(5). One field implies that there is a document, another implies there is an array.
 //	     (e.g. a.b,a.0 or a.b.c,a.0.c)
-//	(6). The indexes for an array don't start from 0 (e.g. a.1,a.2)
-//	(7). Array indexes are out of order (e.g. a.0,a.2,a.1)
-//	(8). An array is missing an index (e.g. a.0,a.2)
+//	(6). An array index is larger than maxArrayIndex.
+//
+// Array indexes don't have to start from 0 or be in order (e.g. a.2,a.0); the elements of an
+// array that no field refers to are set to null.
 func validateFields(inputFields []string, useArrayIndexFields bool) error {
 	for _, field := range inputFields {
 
@@ -730,32 +848,38 @@ func addFieldToArray(
 		)
 	}
 
+	if headIndex > maxArrayIndex {
+		// case (6): the array couldn't fit in a BSON document
+		return nil, fmt.Errorf("array index in field '%v' is too large", fullField)
+	}
+
+	// Elements that no field has referred to yet are nil.
+	for len(array) <= headIndex {
+		array = append(array, nil)
+	}
+
 	if len(tail) == 0 {
-		// We're at the terminus of a field so we have to check if we can append to the array
-		if headIndex == len(array) {
-			array = append(array, true)
+		// We're at the terminus of a field so we have to check if the element is free
+		switch array[headIndex] {
+		case nil:
+			array[headIndex] = true
 			return array, nil
+		case true:
+			// case (2): fields are the same
+			return nil, identicalError(fullField)
 		}
-		// headIndex > len(array) => case (6), (7), or (8): headIndex isn't the next index in the array
-		// headIndex < len(array) => case (2), (3), or (4): the element in the array is already set to another value, document, or array
-		return nil, indexError(fullField)
-	}
-
-	// If tail is not empty, we're either adding a new item to array, or we're adding a field to an item
-	// currently in array. Therefore headIndex cannot be > len(array).
-	if headIndex > len(array) {
-		// case (6), (7), or (8): headIndex isn't the next index in the array
-		return nil, indexError(fullField)
+		// case (3) or (4): the element in the array is already set to a document or an array
+		return nil, incompatibleError(fullField, fieldPrefix, array[headIndex])
 	}
 
 	// The tail is not empty which means there is a sub-field and we need to recurse.
 	// We determine the type implied by the next field in the tail (either document or array).
-	// If array[headIndex] exists we check the compatibility of the next field with that value.
-	// If it doesn't exist we create an empty structure of the appropriate type.
+	// If array[headIndex] is set we check the compatibility of the next field with that value.
+	// If it isn't we create an empty structure of the appropriate type.
 	if _, ok := isNatNum(tail[0]); ok {
 		// next part of the field refers to an array
-		if headIndex < len(array) {
-			// the index already exists in array
+		if array[headIndex] != nil {
+			// the element at headIndex has already been set
 			// check the element is an array
 			subArray, ok := array[headIndex].([]interface{})
 			if !ok {
@@ -774,12 +898,12 @@ func addFieldToArray(
 			if err != nil {
 				return nil, err
 			}
-			array = append(array, subArray)
+			array[headIndex] = subArray
 		}
 	} else {
 		// next part of the field refers to a document
-		if headIndex < len(array) {
-			// the index already exists in array
+		if array[headIndex] != nil {
+			// the element at headIndex has already been set
 			// check the element is an document
 			subTree, ok := array[headIndex].(map[string]interface{})
 			if !ok {
@@ -798,7 +922,7 @@ func addFieldToArray(
 			if err != nil {
 				return nil, err
 			}
-			array = append(array, subTree)
+			array[headIndex] = subTree
 		}
 	}
 	return array, nil
@@ -812,7 +936,11 @@ func findFirstField(i interface{}) string {
 	case bool:
 		return ""
 	case []interface{}:
-		return ".0" + findFirstField(v[0])
+		for i, elem := range v {
+			if elem != nil {
+				return "." + strconv.Itoa(i) + findFirstField(elem)
+			}
+		}
 	case map[string]interface{}:
 		for k, v := range v {
 			return "." + k + findFirstField(v)
@@ -830,13 +958,6 @@ func identicalError(field string) error {
 	return fmt.Errorf("fields cannot be identical: '%v' and '%v'", field, field)
 }
 
-func indexError(field string) error {
-	return fmt.Errorf(
-		"array index error with field '%v': array indexes in fields must start from 0 and increase sequentially",
-		field,
-	)
-}
-
 // validateReaderFields is a helper to validate fields for input readers.
 func validateReaderFields(fields []string, useArrayIndexFields bool) error {
 	if err := validateFields(fields, useArrayIndexFields); err != nil {
@@ -871,7 +992,14 @@ func (iw *importWorker) processDocuments(ordered bool) error {
 			if document == nil {
 				continue
 			}
-			iw.processedDocumentChan <- document
+			doc := InputDocument{Document: document}
+			if lc, ok := converter.(lineConverter); ok {
+				doc.Line = lc.Line()
+			}
+			if sc, ok := converter.(sourceConverter); ok && iw.keepSource {
+				doc.Source = sc.Source()
+			}
+			iw.processedDocumentChan <- doc
 		case <-iw.tomb.Dying():
 			return nil
 		}
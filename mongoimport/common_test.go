@@ -7,8 +7,10 @@
 package mongoimport
 
 import (
+	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/options"
@@ -131,6 +133,21 @@ func TestValidateFields(t *testing.T) {
 		Convey("if the fields contain the same keys, an error should be thrown", func() {
 			So(validateFields([]string{"a", "ba", "a"}, false), ShouldNotBeNil)
 		})
+		Convey("with array index fields, sparse and out of order indexes are allowed", func() {
+			So(validateFields([]string{"a.2", "a.0"}, true), ShouldBeNil)
+			So(validateFields([]string{"a.1.b", "a.3.0", "a.1.c"}, true), ShouldBeNil)
+		})
+		Convey("with array index fields, the same index can't be set twice", func() {
+			err := validateFields([]string{"a.2", "a.0", "a.2"}, true)
+			So(err, ShouldResemble, fmt.Errorf("fields cannot be identical: 'a.2' and 'a.2'"))
+			err = validateFields([]string{"a.3.b", "a.3"}, true)
+			So(err, ShouldResemble, fmt.Errorf("fields 'a.3.b' and 'a.3' are incompatible"))
+			err = validateFields([]string{"a.3.0", "a.3.b"}, true)
+			So(err, ShouldResemble, fmt.Errorf("fields 'a.3.0' and 'a.3.b' are incompatible"))
+		})
+		Convey("with array index fields, indexes can't be too large", func() {
+			So(validateFields([]string{"a.0", "a.100000000"}, true), ShouldNotBeNil)
+		})
 	})
 }
 
@@ -172,6 +189,34 @@ func TestGetUpsertValue(t *testing.T) {
 		Convey("the value of the key should be nil for nil document values", func() {
 			So(getUpsertValue("a", bson.D{{"a", nil}}), ShouldBeNil)
 		})
+		Convey("the value of the key should be nil for paths through arrays", func() {
+			bsonDocument := bson.D{{"a", bson.A{bson.D{{"b", 4}}}}}
+			So(getUpsertValue("a.b", bsonDocument), ShouldBeNil)
+			So(getUpsertValue("a.0.b", bsonDocument), ShouldBeNil)
+			_, ok := lookupUpsertValue("a.b", bsonDocument)
+			So(ok, ShouldBeFalse)
+		})
+		Convey("nil document values should still be found", func() {
+			val, ok := lookupUpsertValue("a.b", bson.D{{"a", bson.D{{"b", nil}}}})
+			So(val, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
+
+func TestValidatePG(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When validating a parse grace", t, func() {
+		Convey("skip is accepted as an alias for skipRow", func() {
+			pg, err := ValidatePG("skip")
+			So(err, ShouldBeNil)
+			So(pg, ShouldEqual, pgSkipRow)
+		})
+		Convey("unknown values are rejected", func() {
+			_, err := ValidatePG("skipRows")
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
 
@@ -214,6 +259,35 @@ func TestConstructUpsertDocument(t *testing.T) {
 			upsertDocument := constructUpsertDocument(upsertFields, bsonDocument)
 			So(upsertDocument, ShouldBeNil)
 		})
+		Convey("the upsert document should contain every dotted field of a compound key", func() {
+			address := bson.D{{"zip", "10001"}, {"country", "US"}}
+			bsonDocument := bson.D{{"name", "x"}, {"address", address}}
+			upsertFields := []string{"address.zip", "address.country"}
+			expectedDocument := bson.D{{"address.zip", "10001"}, {"address.country", "US"}}
+			upsertDocument := constructUpsertDocument(upsertFields, bsonDocument)
+			So(upsertDocument, ShouldResemble, expectedDocument)
+		})
+		Convey("the upsert document should be nil if any field of a compound key "+
+			"is missing", func() {
+			bsonDocument := bson.D{{"address", bson.D{{"zip", "10001"}}}}
+			upsertFields := []string{"address.zip", "address.country"}
+			upsertDocument := constructUpsertDocument(upsertFields, bsonDocument)
+			So(upsertDocument, ShouldBeNil)
+		})
+		Convey("the upsert document should be nil if a field can't be resolved "+
+			"through an array", func() {
+			addresses := bson.A{bson.D{{"zip", "10001"}}, bson.D{{"zip", "94105"}}}
+			bsonDocument := bson.D{{"_id", 1}, {"address", addresses}}
+			upsertFields := []string{"_id", "address.zip"}
+			upsertDocument := constructUpsertDocument(upsertFields, bsonDocument)
+			So(upsertDocument, ShouldBeNil)
+		})
+		Convey("the first missing upsert field should be reported", func() {
+			bsonDocument := bson.D{{"address", bson.D{{"zip", "10001"}}}}
+			So(missingUpsertField([]string{"address.zip", "address.country", "name"},
+				bsonDocument), ShouldEqual, "address.country")
+			So(missingUpsertField([]string{"address.zip"}, bsonDocument), ShouldEqual, "")
+		})
 	})
 }
 
@@ -271,6 +345,20 @@ func TestSetNestedDocumentValue(t *testing.T) {
 			So(len(newDocument), ShouldEqual, 3)
 			So(newDocument[2], ShouldResemble, expectedDocumentTwo)
 		})
+		Convey("ensure array elements can be set in any order, with holes set to null", func() {
+			testDocument := &bson.D{}
+			So(setNestedDocumentValue([]string{"t", "2"}, "c", testDocument, true), ShouldBeNil)
+			So(setNestedDocumentValue([]string{"t", "0"}, "a", testDocument, true), ShouldBeNil)
+			So(setNestedDocumentValue([]string{"u", "1", "x"}, 1, testDocument, true), ShouldBeNil)
+			So(*testDocument, ShouldHaveLength, 2)
+			So(*(*testDocument)[0].Value.(*bson.A), ShouldResemble, bson.A{"a", nil, "c"})
+			So(*(*testDocument)[1].Value.(*bson.A), ShouldResemble, bson.A{nil, &bson.D{{"x", 1}}})
+		})
+		Convey("ensure an array element can't be set twice", func() {
+			testDocument := &bson.D{}
+			So(setNestedDocumentValue([]string{"t", "1"}, "b", testDocument, true), ShouldBeNil)
+			So(setNestedDocumentValue([]string{"t", "1"}, "c", testDocument, true), ShouldNotBeNil)
+		})
 	})
 }
 
@@ -328,7 +416,7 @@ func TestTokensToBSON(t *testing.T) {
 				{"b", int32(2)},
 				{"c", "hello"},
 			}
-			bsonD, err := tokensToBSON(colSpecs, tokens, uint64(0), false, false)
+			bsonD, err := tokensToBSON(colSpecs, tokens, nil, uint64(0), false, false)
 			So(err, ShouldBeNil)
 			So(bsonD, ShouldResemble, expectedDocument)
 		})
@@ -347,7 +435,7 @@ func TestTokensToBSON(t *testing.T) {
 				{"field3", "mongodb"},
 				{"field4", "user"},
 			}
-			bsonD, err := tokensToBSON(colSpecs, tokens, uint64(0), false, false)
+			bsonD, err := tokensToBSON(colSpecs, tokens, nil, uint64(0), false, false)
 			So(err, ShouldBeNil)
 			So(bsonD, ShouldResemble, expectedDocument)
 		})
@@ -358,7 +446,7 @@ func TestTokensToBSON(t *testing.T) {
 				{"field3", new(FieldAutoParser), pgAutoCast, "auto", []string{"field3"}},
 			}
 			tokens := []string{"1", "2", "hello", "mongodb", "user"}
-			_, err := tokensToBSON(colSpecs, tokens, uint64(0), false, false)
+			_, err := tokensToBSON(colSpecs, tokens, nil, uint64(0), false, false)
 			So(err, ShouldNotBeNil)
 		})
 		Convey("fields with nested values should be set appropriately", func() {
@@ -376,7 +464,7 @@ func TestTokensToBSON(t *testing.T) {
 				{"b", int32(2)},
 				{"c", c},
 			}
-			bsonD, err := tokensToBSON(colSpecs, tokens, uint64(0), false, false)
+			bsonD, err := tokensToBSON(colSpecs, tokens, nil, uint64(0), false, false)
 			So(err, ShouldBeNil)
 			So(expectedDocument[0].Key, ShouldResemble, bsonD[0].Key)
 			So(expectedDocument[0].Value, ShouldResemble, bsonD[0].Value)
@@ -385,6 +473,110 @@ func TestTokensToBSON(t *testing.T) {
 			So(expectedDocument[2].Key, ShouldResemble, bsonD[2].Key)
 			So(expectedDocument[2].Value, ShouldResemble, *bsonD[2].Value.(*bson.D))
 		})
+		Convey("date columns should be parsed with their Go layout", func() {
+			dateParser, err := NewFieldParser(ctDate, "2006-01-02T15:04:05Z07:00")
+			So(err, ShouldBeNil)
+			colSpecs := []ColumnSpec{
+				{"a", new(FieldAutoParser), pgStop, "auto", []string{"a"}},
+				{"created", dateParser, pgStop, "date", []string{"created"}},
+			}
+
+			bsonD, err := tokensToBSON(
+				colSpecs, []string{"1", "2021-03-01T10:00:00Z"}, nil, uint64(0), false, false)
+			So(err, ShouldBeNil)
+			So(bsonD, ShouldResemble, bson.D{
+				{"a", int32(1)},
+				{"created", time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)},
+			})
+
+			Convey("and a bad value should be reported with its document number", func() {
+				_, err := tokensToBSON(
+					colSpecs, []string{"1", "03/01/2021"}, nil, uint64(7), false, false)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "document #7")
+				So(err.Error(), ShouldContainSubstring, "column 'created'")
+				So(err.Error(), ShouldContainSubstring, "'03/01/2021'")
+				So(err.Error(), ShouldContainSubstring, "cannot parse")
+			})
+
+			Convey("and a bad value should skip the row under skipRow", func() {
+				colSpecs[1].ParseGrace = pgSkipRow
+				_, err := tokensToBSON(
+					colSpecs, []string{"1", "03/01/2021"}, nil, uint64(7), false, false)
+				So(err, ShouldHaveSameTypeAs, coercionError{})
+			})
+		})
+	})
+}
+
+func TestCountedTokensToBSON(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Given typed columns and a row with the wrong number of fields", t, func() {
+		colSpecs, err := ParseTypedHeaders([]string{"a.int32()", "b.string()", "c.d.int32()"}, pgStop)
+		So(err, ShouldBeNil)
+		convert := func(pg ParseGrace, tokens []string) (bson.D, error) {
+			return countedTokensToBSON(colSpecs, tokens, nil, uint64(4), uint64(6), pg, false, false)
+		}
+
+		Convey("a row with the right number of fields is converted as usual", func() {
+			doc, err := convert(pgStop, []string{"1", "x", "2"})
+			So(err, ShouldBeNil)
+			So(doc, ShouldResemble, bson.D{
+				{"a", int32(1)}, {"b", "x"}, {"c", &bson.D{{"d", int32(2)}}},
+			})
+		})
+
+		Convey("stop reports the line number", func() {
+			_, err := convert(pgStop, []string{"1", "x"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 6")
+			So(err.Error(), ShouldContainSubstring, "expected 3 but found 2")
+		})
+
+		Convey("skipRow rejects the row", func() {
+			_, err := convert(pgSkipRow, []string{"1", "x", "2", "extra"})
+			So(err, ShouldHaveSameTypeAs, coercionError{})
+		})
+
+		Convey("skipField drops extra fields and leaves out missing ones", func() {
+			doc, err := convert(pgSkipField, []string{"1", "x", "2", "extra"})
+			So(err, ShouldBeNil)
+			So(doc, ShouldResemble, bson.D{
+				{"a", int32(1)}, {"b", "x"}, {"c", &bson.D{{"d", int32(2)}}},
+			})
+
+			doc, err = convert(pgSkipField, []string{"1"})
+			So(err, ShouldBeNil)
+			So(doc, ShouldResemble, bson.D{{"a", int32(1)}})
+		})
+
+		Convey("autoCast sets missing fields to null", func() {
+			doc, err := convert(pgAutoCast, []string{"1"})
+			So(err, ShouldBeNil)
+			So(doc, ShouldResemble, bson.D{
+				{"a", int32(1)}, {"b", nil}, {"c", &bson.D{{"d", nil}}},
+			})
+		})
+	})
+
+	Convey("Given untyped columns and a row with the wrong number of fields", t, func() {
+		colSpecs := ParseAutoHeaders([]string{"a", "b"})
+
+		Convey("stop reports the line number", func() {
+			_, err := countedTokensToBSON(
+				colSpecs, []string{"1"}, nil, uint64(0), uint64(2), pgStop, false, false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 2")
+			So(err.Error(), ShouldContainSubstring, "expected 2 but found 1")
+		})
+
+		Convey("skipRow rejects the row", func() {
+			tokens := []string{"1", "2", "3"}
+			_, err := countedTokensToBSON(
+				colSpecs, tokens, nil, uint64(0), uint64(2), pgSkipRow, false, false)
+			So(err, ShouldHaveSameTypeAs, coercionError{})
+		})
 	})
 }
 
@@ -427,7 +619,7 @@ func TestProcessDocuments(t *testing.T) {
 		Convey("processDocuments should execute the expected conversion for documents, "+
 			"pass then on the output channel, and close the input channel if ordered is true", func() {
 			inputChannel := make(chan Converter, 100)
-			outputChannel := make(chan bson.D, 100)
+			outputChannel := make(chan InputDocument, 100)
 			iw := &importWorker{
 				unprocessedDataChan:   inputChannel,
 				processedDocumentChan: outputChannel,
@@ -438,10 +630,10 @@ func TestProcessDocuments(t *testing.T) {
 			close(inputChannel)
 			So(iw.processDocuments(true), ShouldBeNil)
 			doc1, open := <-outputChannel
-			So(doc1, ShouldResemble, expectedDocuments[0])
+			So(doc1.Document, ShouldResemble, expectedDocuments[0])
 			So(open, ShouldEqual, true)
 			doc2, open := <-outputChannel
-			So(doc2, ShouldResemble, expectedDocuments[1])
+			So(doc2.Document, ShouldResemble, expectedDocuments[1])
 			So(open, ShouldEqual, true)
 			_, open = <-outputChannel
 			So(open, ShouldEqual, false)
@@ -449,7 +641,7 @@ func TestProcessDocuments(t *testing.T) {
 		Convey("processDocuments should execute the expected conversion for documents, "+
 			"pass then on the output channel, and leave the input channel open if ordered is false", func() {
 			inputChannel := make(chan Converter, 100)
-			outputChannel := make(chan bson.D, 100)
+			outputChannel := make(chan InputDocument, 100)
 			iw := &importWorker{
 				unprocessedDataChan:   inputChannel,
 				processedDocumentChan: outputChannel,
@@ -460,10 +652,10 @@ func TestProcessDocuments(t *testing.T) {
 			close(inputChannel)
 			So(iw.processDocuments(false), ShouldBeNil)
 			doc1, open := <-outputChannel
-			So(doc1, ShouldResemble, expectedDocuments[0])
+			So(doc1.Document, ShouldResemble, expectedDocuments[0])
 			So(open, ShouldEqual, true)
 			doc2, open := <-outputChannel
-			So(doc2, ShouldResemble, expectedDocuments[1])
+			So(doc2.Document, ShouldResemble, expectedDocuments[1])
 			So(open, ShouldEqual, true)
 			// close will throw a runtime error if outputChannel is already closed
 			close(outputChannel)
@@ -479,14 +671,14 @@ func TestDoSequentialStreaming(t *testing.T) {
 		t,
 		func() {
 			inputChannel := make(chan Converter, 5)
-			outputChannel := make(chan bson.D, 5)
+			outputChannel := make(chan InputDocument, 5)
 			workerInputChannel := []chan Converter{
 				make(chan Converter),
 				make(chan Converter),
 			}
-			workerOutputChannel := []chan bson.D{
-				make(chan bson.D),
-				make(chan bson.D),
+			workerOutputChannel := []chan InputDocument{
+				make(chan InputDocument),
+				make(chan InputDocument),
 			}
 			importWorkers := []*importWorker{
 				{
@@ -515,7 +707,7 @@ func TestDoSequentialStreaming(t *testing.T) {
 					close(inputChannel)
 					doSequentialStreaming(importWorkers, inputChannel, outputChannel)
 					for _, document := range expectedDocuments {
-						So(<-outputChannel, ShouldResemble, document)
+						So((<-outputChannel).Document, ShouldResemble, document)
 					}
 				},
 			)
@@ -531,7 +723,7 @@ func TestStreamDocuments(t *testing.T) {
 			3. an output channel where processed documents are streamed out`, t, func() {
 
 		inputChannel := make(chan Converter, 5)
-		outputChannel := make(chan bson.D, 5)
+		outputChannel := make(chan InputDocument, 5)
 
 		Convey(
 			"the entire pipeline should complete without error under normal circumstances",
@@ -541,11 +733,11 @@ func TestStreamDocuments(t *testing.T) {
 					inputChannel <- csvConverter
 				}
 				close(inputChannel)
-				So(streamDocuments(true, 3, inputChannel, outputChannel), ShouldBeNil)
+				So(streamDocuments(true, 3, inputChannel, outputChannel, false), ShouldBeNil)
 
 				// ensure documents are streamed out and processed in the correct manner
 				for _, expectedDocument := range expectedDocuments {
-					So(<-outputChannel, ShouldResemble, expectedDocument)
+					So((<-outputChannel).Document, ShouldResemble, expectedDocument)
 				}
 			},
 		)
@@ -563,7 +755,7 @@ func TestStreamDocuments(t *testing.T) {
 			close(inputChannel)
 
 			// ensure that an error is returned on the error channel
-			So(streamDocuments(true, 3, inputChannel, outputChannel), ShouldNotBeNil)
+			So(streamDocuments(true, 3, inputChannel, outputChannel, false), ShouldNotBeNil)
 		})
 	})
 }
@@ -10,6 +10,7 @@ import (
 	gocsv "encoding/csv"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/mongodb/mongo-tools/mongoimport/csv"
 	"go.mongodb.org/mongo-driver/bson"
@@ -29,6 +30,9 @@ type CSVInputReader struct {
 	// csvRecord stores each line of input we read from the underlying reader
 	csvRecord []string
 
+	// csvQuoted stores whether each field of csvRecord was quoted
+	csvQuoted []bool
+
 	// numProcessed tracks the number of CSV records processed by the underlying reader
 	numProcessed uint64
 
@@ -43,15 +47,27 @@ type CSVInputReader struct {
 
 	// useArrayIndexFields is whether field names include array indexes
 	useArrayIndexFields bool
+
+	// checkFieldCount is whether rows must have one field per column, with
+	// mismatches handled according to parseGrace
+	checkFieldCount bool
+	parseGrace      ParseGrace
+
+	// keepSource is whether documents are sent with the record they came from
+	keepSource bool
 }
 
 // CSVConverter implements the Converter interface for CSV input.
 type CSVConverter struct {
 	colSpecs            []ColumnSpec
 	data                []string
+	quoted              []bool
 	index               uint64
+	line                uint64
 	ignoreBlanks        bool
 	useArrayIndexFields bool
+	checkFieldCount     bool
+	parseGrace          ParseGrace
 	rejectWriter        *gocsv.Writer
 }
 
@@ -111,7 +127,7 @@ func (r *CSVInputReader) ReadAndValidateTypedHeader(parseGrace ParseGrace) (err
 // StreamDocument takes a boolean indicating if the documents should be streamed
 // in read order and a channel on which to stream the documents processed from
 // the underlying reader. Returns a non-nil error if streaming fails.
-func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (retErr error) {
+func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan InputDocument) (retErr error) {
 	csvRecordChan := make(chan Converter, r.numDecoders)
 	csvErrChan := make(chan error)
 
@@ -119,7 +135,7 @@ func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 	go func() {
 		var err error
 		for {
-			r.csvRecord, err = r.csvReader.Read()
+			r.csvRecord, r.csvQuoted, err = r.csvReader.ReadQuoted()
 			if err != nil {
 				close(csvRecordChan)
 				if err == io.EOF {
@@ -133,9 +149,13 @@ func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 			csvRecordChan <- CSVConverter{
 				colSpecs:            r.colSpecs,
 				data:                r.csvRecord,
+				quoted:              r.csvQuoted,
 				index:               r.numProcessed,
+				line:                uint64(r.csvReader.Line()),
 				ignoreBlanks:        r.ignoreBlanks,
 				useArrayIndexFields: r.useArrayIndexFields,
+				checkFieldCount:     r.checkFieldCount,
+				parseGrace:          r.parseGrace,
 				rejectWriter:        r.csvRejectWriter,
 			}
 			r.numProcessed++
@@ -143,7 +163,7 @@ func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 	}()
 
 	go func() {
-		csvErrChan <- streamDocuments(ordered, r.numDecoders, csvRecordChan, readDocs)
+		csvErrChan <- streamDocuments(ordered, r.numDecoders, csvRecordChan, readDocs, r.keepSource)
 	}()
 
 	return channelQuorumError(csvErrChan)
@@ -152,13 +172,27 @@ func (r *CSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 // Convert implements the Converter interface for CSV input. It converts a
 // CSVConverter struct to a BSON document.
 func (c CSVConverter) Convert() (b bson.D, err error) {
-	b, err = tokensToBSON(
-		c.colSpecs,
-		c.data,
-		c.index,
-		c.ignoreBlanks,
-		c.useArrayIndexFields,
-	)
+	if c.checkFieldCount {
+		b, err = countedTokensToBSON(
+			c.colSpecs,
+			c.data,
+			c.quoted,
+			c.index,
+			c.line,
+			c.parseGrace,
+			c.ignoreBlanks,
+			c.useArrayIndexFields,
+		)
+	} else {
+		b, err = tokensToBSON(
+			c.colSpecs,
+			c.data,
+			c.quoted,
+			c.index,
+			c.ignoreBlanks,
+			c.useArrayIndexFields,
+		)
+	}
 	if _, ok := err.(coercionError); ok {
 		if err = c.Print(); err != nil {
 			return
@@ -171,3 +205,19 @@ func (c CSVConverter) Convert() (b bson.D, err error) {
 func (c CSVConverter) Print() error {
 	return c.rejectWriter.Write(c.data)
 }
+
+// Line implements the lineConverter interface. It returns the line of the
+// input the record was read from.
+func (c CSVConverter) Line() uint64 {
+	return c.line
+}
+
+// Source implements the sourceConverter interface. It returns the record as a
+// line of CSV.
+func (c CSVConverter) Source() string {
+	var source strings.Builder
+	writer := gocsv.NewWriter(&source)
+	_ = writer.Write(c.data)
+	writer.Flush()
+	return strings.TrimRight(source.String(), "\n")
+}
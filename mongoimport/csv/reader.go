@@ -112,9 +112,11 @@ type Reader struct {
 	TrailingComma    bool // ignored; here for backwards compatibility
 	TrimLeadingSpace bool // trim leading space
 	line             int
+	recordLine       int // line on which the last record started
 	column           int
 	r                *bufio.Reader
 	field            bytes.Buffer
+	quoted           []bool // whether each field of the last record was quoted
 }
 
 // NewReader returns a new Reader that reads from r.
@@ -137,25 +139,40 @@ func (r *Reader) error(err error) error {
 // Read reads one record from r.  The record is a slice of strings with each
 // string representing one field.
 func (r *Reader) Read() (record []string, err error) {
+	record, _, err = r.ReadQuoted()
+	return record, err
+}
+
+// ReadQuoted is like Read, but also reports for each field whether it was
+// enclosed in quotes, so that a quoted empty string ("") can be told apart
+// from a blank field.
+func (r *Reader) ReadQuoted() (record []string, quoted []bool, err error) {
 	for {
 		record, err = r.parseRecord()
 		if record != nil {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
+	quoted = append([]bool(nil), r.quoted...)
 
 	if r.FieldsPerRecord > 0 {
 		if len(record) != r.FieldsPerRecord {
 			r.column = 0 // report at start of record
-			return record, r.error(ErrFieldCount)
+			return record, quoted, r.error(ErrFieldCount)
 		}
 	} else if r.FieldsPerRecord == 0 {
 		r.FieldsPerRecord = len(record)
 	}
-	return record, nil
+	return record, quoted, nil
+}
+
+// Line returns the line number on which the most recently read record started.
+// The first line is 1.
+func (r *Reader) Line() int {
+	return r.recordLine
 }
 
 // ReadAll reads all the remaining records from r.
@@ -219,7 +236,9 @@ func (r *Reader) parseRecord() (fields []string, err error) {
 	// number (lines start at 1, not 0) and set column to -1
 	// so as we increment in readRune it points to the character we read.
 	r.line++
+	r.recordLine = r.line
 	r.column = -1
+	r.quoted = r.quoted[:0]
 
 	// Peek at the first rune.  If it is an error we are done.
 	// If we are support comments and it is the comment character
@@ -239,9 +258,10 @@ func (r *Reader) parseRecord() (fields []string, err error) {
 
 	// At this point we have at least one field.
 	for {
-		haveField, delim, err := r.parseField()
+		haveField, quoted, delim, err := r.parseField()
 		if haveField {
 			fields = append(fields, r.field.String())
+			r.quoted = append(r.quoted, quoted)
 		}
 		if delim == '\n' || err == io.EOF {
 			return fields, err
@@ -253,8 +273,8 @@ func (r *Reader) parseRecord() (fields []string, err error) {
 
 // parseField parses the next field in the record.  The read field is
 // located in r.field.  Delim is the first character not part of the field
-// (r.Comma or '\n').
-func (r *Reader) parseField() (haveField bool, delim rune, err error) {
+// (r.Comma or '\n'). Quoted reports whether the field was enclosed in quotes.
+func (r *Reader) parseField() (haveField bool, quoted bool, delim rune, err error) {
 	r.field.Reset()
 
 	r1, err := r.readRune()
@@ -263,10 +283,10 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 	}
 
 	if err == io.EOF && r.column != 0 {
-		return true, 0, err
+		return true, quoted, 0, err
 	}
 	if err != nil {
-		return false, 0, err
+		return false, quoted, 0, err
 	}
 
 	var ws bytes.Buffer
@@ -278,23 +298,24 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 	case '\n':
 		// We are a trailing empty field or a blank line
 		if r.column == 0 {
-			return false, r1, nil
+			return false, quoted, r1, nil
 		}
-		return true, r1, nil
+		return true, quoted, r1, nil
 
 	case '"':
 		// quoted field
+		quoted = true
 	Quoted:
 		for {
 			r1, err = r.readRune()
 			if err != nil {
 				if err == io.EOF {
 					if r.LazyQuotes {
-						return true, 0, err
+						return true, quoted, 0, err
 					}
-					return false, 0, r.error(ErrQuote)
+					return false, quoted, 0, r.error(ErrQuote)
 				}
-				return false, 0, err
+				return false, quoted, 0, err
 			}
 			switch r1 {
 			case '"':
@@ -309,19 +330,19 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 					// followed by a '"'
 					if err == nil && r1 == '"' {
 						r.column--
-						return false, 0, r.error(ErrQuote)
+						return false, quoted, 0, r.error(ErrQuote)
 					}
 				}
 				if err != nil || r1 == r.Comma {
 					break Quoted
 				}
 				if r1 == '\n' {
-					return true, r1, nil
+					return true, quoted, r1, nil
 				}
 				if r1 != '"' {
 					if !r.LazyQuotes {
 						r.column--
-						return false, 0, r.error(ErrQuote)
+						return false, quoted, 0, r.error(ErrQuote)
 					}
 					// accept the bare quote
 					r.field.WriteRune('"')
@@ -349,10 +370,10 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 				break
 			}
 			if r1 == '\n' {
-				return true, r1, nil
+				return true, quoted, r1, nil
 			}
 			if !r.LazyQuotes && r1 == '"' {
-				return false, 0, r.error(ErrBareQuote)
+				return false, quoted, 0, r.error(ErrBareQuote)
 			}
 		}
 	}
@@ -363,10 +384,10 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 
 	if err != nil {
 		if err == io.EOF {
-			return true, 0, err
+			return true, quoted, 0, err
 		}
-		return false, 0, err
+		return false, quoted, 0, err
 	}
 
-	return true, r1, nil
+	return true, quoted, r1, nil
 }
@@ -29,6 +29,21 @@ func init() {
 func TestCSVStreamDocument(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 	Convey("With a CSV input reader", t, func() {
+		Convey("documents should record the line they were read from", func() {
+			contents := "1,a\n2,\"b\nc\"\n3,d\n"
+			colSpecs := []ColumnSpec{
+				{"a", new(FieldAutoParser), pgAutoCast, "auto", []string{"a"}},
+				{"b", new(FieldAutoParser), pgAutoCast, "auto", []string{"b"}},
+			}
+			r := NewCSVInputReader(colSpecs, strings.NewReader(contents), os.Stdout, 1, false, false)
+			docChan := make(chan InputDocument, 3)
+			So(r.StreamDocument(true, docChan), ShouldBeNil)
+			var lines []uint64
+			for doc := range docChan {
+				lines = append(lines, doc.Line)
+			}
+			So(lines, ShouldResemble, []uint64{1, 2, 4})
+		})
 		Convey("badly encoded CSV should result in a parsing error", func() {
 			contents := `1, 2, foo"bar`
 			colSpecs := []ColumnSpec{
@@ -44,7 +59,7 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldNotBeNil)
 		})
 		Convey("escaped quotes are parsed correctly", func() {
@@ -62,7 +77,7 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
 		})
 		Convey("multiple escaped quotes separated by whitespace parsed correctly", func() {
@@ -85,9 +100,9 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 		Convey("integer valued strings should be converted", func() {
 			contents := `1, 2, " 3e"`
@@ -109,9 +124,9 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 		Convey("extra fields should be prefixed with 'field'", func() {
 			contents := `1, 2f , " 3e" , " may"`
@@ -134,9 +149,9 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 		Convey("nested CSV fields should be imported properly", func() {
 			contents := `1, 2f , " 3e" , " may"`
@@ -160,10 +175,10 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 4)
+			docChan := make(chan InputDocument, 4)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
 
-			readDocument := <-docChan
+			readDocument := (<-docChan).Document
 			So(readDocument[0], ShouldResemble, expectedRead[0])
 			So(readDocument[1].Key, ShouldResemble, expectedRead[1].Key)
 			So(*readDocument[1].Value.(*bson.D), ShouldResemble, expectedRead[1].Value)
@@ -185,7 +200,7 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldNotBeNil)
 		})
 		Convey("nested CSV fields causing header collisions should error", func() {
@@ -203,7 +218,7 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldNotBeNil)
 		})
 		Convey("calling StreamDocument() for CSVs should return next set of "+
@@ -232,10 +247,10 @@ func TestCSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 2)
+			docChan := make(chan InputDocument, 2)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedReadOne)
-			So(<-docChan, ShouldResemble, expectedReadTwo)
+			So((<-docChan).Document, ShouldResemble, expectedReadOne)
+			So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 		})
 		Convey("valid CSV input file that starts with the UTF-8 BOM should "+
 			"not raise an error", func() {
@@ -258,15 +273,61 @@ func TestCSVStreamDocument(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test_bom.csv")
 			So(err, ShouldBeNil)
 			r := NewCSVInputReader(colSpecs, fileHandle, os.Stdout, 1, false, false)
-			docChan := make(chan bson.D, len(expectedReads))
+			docChan := make(chan InputDocument, len(expectedReads))
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
 			for _, expectedRead := range expectedReads {
-				for i, readDocument := range <-docChan {
+				for i, readDocument := range (<-docChan).Document {
 					So(readDocument.Key, ShouldResemble, expectedRead[i].Key)
 					So(readDocument.Value, ShouldResemble, expectedRead[i].Value)
 				}
 			}
 		})
+		Convey("with --ignoreBlanks, quoted empty strings should be kept and blanks dropped", func() {
+			contents := "1,,\"\"\n\"\",2,\n,\"\",\"x\"\n"
+			colSpecs := []ColumnSpec{
+				{"a", new(FieldAutoParser), pgAutoCast, "auto", []string{"a"}},
+				{"b", new(FieldAutoParser), pgAutoCast, "auto", []string{"b"}},
+				{"c", new(FieldAutoParser), pgAutoCast, "auto", []string{"c"}},
+			}
+			expectedReads := []bson.D{
+				{{"a", int32(1)}, {"c", ""}},
+				{{"a", ""}, {"b", int32(2)}},
+				{{"b", ""}, {"c", "x"}},
+			}
+			r := NewCSVInputReader(
+				colSpecs,
+				bytes.NewReader([]byte(contents)),
+				os.Stdout,
+				1,
+				true,
+				false,
+			)
+			docChan := make(chan InputDocument, len(expectedReads))
+			So(r.StreamDocument(true, docChan), ShouldBeNil)
+			for _, expectedRead := range expectedReads {
+				So((<-docChan).Document, ShouldResemble, expectedRead)
+			}
+		})
+		Convey("without --ignoreBlanks, blanks and quoted empty strings should be kept", func() {
+			contents := `1,,""`
+			colSpecs := []ColumnSpec{
+				{"a", new(FieldAutoParser), pgAutoCast, "auto", []string{"a"}},
+				{"b", new(FieldAutoParser), pgAutoCast, "auto", []string{"b"}},
+				{"c", new(FieldAutoParser), pgAutoCast, "auto", []string{"c"}},
+			}
+			expectedRead := bson.D{{"a", int32(1)}, {"b", ""}, {"c", ""}}
+			r := NewCSVInputReader(
+				colSpecs,
+				bytes.NewReader([]byte(contents)),
+				os.Stdout,
+				1,
+				false,
+				false,
+			)
+			docChan := make(chan InputDocument, 1)
+			So(r.StreamDocument(true, docChan), ShouldBeNil)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
+		})
 	})
 }
 
@@ -500,10 +561,10 @@ func TestCSVReadAndValidateHeader(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test.csv")
 			So(err, ShouldBeNil)
 			r := NewCSVInputReader(colSpecs, fileHandle, os.Stdout, 1, false, false)
-			docChan := make(chan bson.D, 50)
+			docChan := make(chan InputDocument, 50)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedReadOne)
-			So(<-docChan, ShouldResemble, expectedReadTwo)
+			So((<-docChan).Document, ShouldResemble, expectedReadOne)
+			So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 		})
 	})
 }
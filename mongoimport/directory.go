@@ -0,0 +1,209 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/util"
+)
+
+// listImportFiles returns the paths of the regular files directly in dir
+// whose names match glob, sorted by name. An empty glob matches every file.
+func listImportFiles(dir, glob string) ([]string, error) {
+	entries, err := os.ReadDir(util.ToUniversalPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if glob != "" {
+			matched, err := filepath.Match(glob, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// multiFileProgressor implements Progressor for a directory import, reporting
+// the bytes read from all of its files against their total size.
+type multiFileProgressor struct {
+	max      int64
+	lock     sync.Mutex
+	trackers []sizeTracker
+}
+
+func (p *multiFileProgressor) add(tracker sizeTracker) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.trackers = append(p.trackers, tracker)
+}
+
+func (p *multiFileProgressor) Progress() (int64, int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var read int64
+	for _, tracker := range p.trackers {
+		read += tracker.Size()
+	}
+	return read, p.max
+}
+
+// importDirectory imports every file from --dir that matches --glob into the
+// collection. The files share the insertion workers, and are read in parallel
+// unless --maintainInsertionOrder is set, in which case they are read one at
+// a time in name order.
+func (imp *MongoImport) importDirectory() (uint64, uint64, error) {
+	files, err := listImportFiles(imp.InputOptions.Dir, imp.InputOptions.Glob)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading --dir: %v", err)
+	}
+	if len(files) == 0 {
+		return 0, 0, fmt.Errorf("no files to import in %v", imp.InputOptions.Dir)
+	}
+	log.Logvf(log.Always, "importing %v %v from %v",
+		len(files), util.Pluralize(len(files), "file", "files"), imp.InputOptions.Dir)
+
+	progressor := &multiFileProgressor{}
+	if !imp.InputOptions.Gzip {
+		// the decompressed size isn't known up front
+		for _, file := range files {
+			if info, err := os.Stat(util.ToUniversalPath(file)); err == nil {
+				progressor.max += info.Size()
+			}
+		}
+	}
+
+	bar := imp.startProgressBar(progressor)
+	defer bar.Stop()
+	return imp.ingest(func(readDocs chan InputDocument) error {
+		return imp.streamFiles(files, progressor, readDocs)
+	})
+}
+
+// streamFiles sends the documents from each of the files to readDocs, and
+// closes readDocs when done. It stops starting new files after the first
+// error, which names the file it came from.
+func (imp *MongoImport) streamFiles(
+	files []string,
+	progressor *multiFileProgressor,
+	readDocs chan InputDocument,
+) error {
+	defer close(readDocs)
+
+	ordered := imp.IngestOptions.MaintainInsertionOrder
+	numReaders, numDecoders := splitDecodingWorkers(
+		imp.IngestOptions.NumDecodingWorkers, len(files), ordered)
+
+	fileChan := make(chan string)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	var firstErr error
+
+	go func() {
+		defer close(fileChan)
+		for _, file := range files {
+			select {
+			case fileChan <- file:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < numReaders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range fileChan {
+				err := imp.streamFile(file, ordered, numDecoders, progressor, readDocs)
+				if err != nil {
+					stopOnce.Do(func() {
+						firstErr = err
+						close(stop)
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// splitDecodingWorkers returns how many of numFiles files to read at once and
+// how many goroutines decode the documents from each of them, so that no more
+// than numWorkers decode at a time in total. Files are read one at a time if
+// ordered is set.
+func splitDecodingWorkers(numWorkers, numFiles int, ordered bool) (int, int) {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	numReaders := 1
+	if !ordered {
+		numReaders = numWorkers
+		if numFiles > 0 && numReaders > numFiles {
+			numReaders = numFiles
+		}
+	}
+	return numReaders, numWorkers / numReaders
+}
+
+// streamFile sends the documents from a single file to readDocs, decoding them
+// with numDecoders goroutines.
+func (imp *MongoImport) streamFile(
+	file string,
+	ordered bool,
+	numDecoders int,
+	progressor *multiFileProgressor,
+	readDocs chan InputDocument,
+) error {
+	source, _, err := imp.openSource(file)
+	if err != nil {
+		return fmt.Errorf("%v: %v", file, err)
+	}
+	defer source.Close()
+
+	inputReader, err := imp.newInputReader(source, numDecoders)
+	if err != nil {
+		return fmt.Errorf("%v: %v", file, err)
+	}
+	if err := imp.readHeader(inputReader); err != nil {
+		return fmt.Errorf("%v: %v", file, err)
+	}
+	progressor.add(inputReader)
+
+	log.Logvf(log.Info, "importing %v", file)
+	fileDocs := make(chan InputDocument, workerBufferSize)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- inputReader.StreamDocument(ordered, fileDocs)
+	}()
+	for doc := range fileDocs {
+		readDocs <- doc
+	}
+	if err := <-errChan; err != nil {
+		return fmt.Errorf("%v: %v", file, err)
+	}
+	return nil
+}
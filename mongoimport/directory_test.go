@@ -0,0 +1,143 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/stretchr/testify/require"
+)
+
+func writeImportFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.json"), 0755))
+	return dir
+}
+
+func TestListImportFiles(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir := writeImportFiles(t, map[string]string{
+		"b.json":    "",
+		"a.json":    "",
+		"notes.txt": "",
+	})
+
+	files, err := listImportFiles(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "a.json"),
+		filepath.Join(dir, "b.json"),
+		filepath.Join(dir, "notes.txt"),
+	}, files)
+
+	files, err = listImportFiles(dir, "*.json")
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")}, files)
+
+	_, err = listImportFiles(filepath.Join(dir, "missing"), "")
+	require.Error(t, err)
+}
+
+func TestSplitDecodingWorkers(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	cases := []struct {
+		name        string
+		numWorkers  int
+		numFiles    int
+		ordered     bool
+		numReaders  int
+		numDecoders int
+	}{
+		{"more files than workers", 4, 10, false, 4, 1},
+		{"fewer files than workers", 8, 3, false, 3, 2},
+		{"ordered", 8, 3, true, 1, 8},
+		{"no workers", 0, 3, false, 1, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			numReaders, numDecoders := splitDecodingWorkers(c.numWorkers, c.numFiles, c.ordered)
+			require.Equal(t, c.numReaders, numReaders)
+			require.Equal(t, c.numDecoders, numDecoders)
+		})
+	}
+}
+
+func TestStreamFiles(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	stream := func(imp *MongoImport, files []string) ([]int32, error) {
+		readDocs := make(chan InputDocument, workerBufferSize)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- imp.streamFiles(files, &multiFileProgressor{}, readDocs)
+		}()
+		var values []int32
+		for doc := range readDocs {
+			values = append(values, doc.Document[0].Value.(int32))
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		return values, <-errChan
+	}
+
+	dir := writeImportFiles(t, map[string]string{
+		"a.json": `{"x": 1}` + "\n" + `{"x": 2}`,
+		"b.json": `{"x": 3}`,
+		"c.json": `{"x": 4}` + "\n" + `{"x": 5}`,
+		"d.csv":  "x\n6\n7",
+		"e.json": `{"x": 8}` + "\n" + `{"x": `,
+	})
+	jsonFiles := []string{
+		filepath.Join(dir, "a.json"),
+		filepath.Join(dir, "b.json"),
+		filepath.Join(dir, "c.json"),
+	}
+
+	t.Run("reads every file in parallel", func(t *testing.T) {
+		imp := NewMockMongoImport()
+		imp.InputOptions.Type = JSON
+		imp.IngestOptions.NumDecodingWorkers = 2
+		values, err := stream(imp, jsonFiles)
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5}, values)
+	})
+
+	t.Run("reads the files in order", func(t *testing.T) {
+		imp := NewMockMongoImport()
+		imp.InputOptions.Type = JSON
+		imp.IngestOptions.MaintainInsertionOrder = true
+		values, err := stream(imp, jsonFiles)
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5}, values)
+	})
+
+	t.Run("reads the header of each CSV file", func(t *testing.T) {
+		imp := NewMockMongoImport()
+		imp.InputOptions.Type = CSV
+		imp.InputOptions.HeaderLine = true
+		values, err := stream(imp, []string{filepath.Join(dir, "d.csv")})
+		require.NoError(t, err)
+		require.Equal(t, []int32{6, 7}, values)
+	})
+
+	t.Run("names the file of a parse error", func(t *testing.T) {
+		imp := NewMockMongoImport()
+		imp.InputOptions.Type = JSON
+		badFile := filepath.Join(dir, "e.json")
+		_, err := stream(imp, []string{badFile})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), badFile)
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/util"
+)
+
+// The timeouts for requesting an input source URL. Reading the body has no
+// timeout, since a large input can take any amount of time to download.
+const (
+	httpConnectTimeout        = 30 * time.Second
+	httpResponseHeaderTimeout = time.Minute
+)
+
+// newHTTPClient returns a client for requesting input source URLs that gives up
+// if connecting to the server, or waiting for it to respond after sending the
+// request, takes longer than the given timeouts.
+func newHTTPClient(connectTimeout, responseHeaderTimeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   connectTimeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+		},
+	}
+}
+
+// isURL returns true if file names an HTTP or HTTPS URL rather than a local path.
+func isURL(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// urlBaseName returns the last element of the path of rawURL, ignoring any
+// query string, for use as the default collection name.
+func urlBaseName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return path.Base(rawURL)
+	}
+	return path.Base(u.Path)
+}
+
+// openURL starts an HTTP GET request for rawURL and returns its body. A body
+// sent with "Content-Encoding: gzip" is decompressed, or any body if
+// forceGzip is set. The returned size is the length of the body as read from
+// the returned reader, or 0 if it is unknown.
+func openURL(client *http.Client, rawURL string, forceGzip bool) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, -1, fmt.Errorf("invalid URL %v: %v", rawURL, err)
+	}
+	// asking for gzip ourselves stops the transport from transparently
+	// decompressing the body, so that Content-Encoding can be checked here
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, -1, fmt.Errorf("error requesting %v: %v", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, -1, fmt.Errorf("error requesting %v: server responded with %v", rawURL, resp.Status)
+	}
+
+	if !forceGzip && !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		size := resp.ContentLength
+		if size < 0 {
+			size = 0
+		}
+		log.Logvf(log.Info, "reading %v bytes from %v", size, rawURL)
+		return resp.Body, size, nil
+	}
+
+	gzr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, -1, fmt.Errorf("error decompressing %v: %v", rawURL, err)
+	}
+	log.Logvf(log.Info, "reading gzipped data from %v", rawURL)
+	// the decompressed size isn't known up front
+	return &util.WrappedReadCloser{ReadCloser: gzr, Inner: resp.Body}, 0, nil
+}
@@ -0,0 +1,171 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// inferIntRegex matches integers without a sign, leading zeros or
+	// whitespace, so that values such as zip codes and "+1" stay strings.
+	inferIntRegex = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+	// inferFloatRegex matches decimal numbers with a fraction or an exponent
+	// and the same integer part as inferIntRegex.
+	inferFloatRegex = regexp.MustCompile(
+		`^-?(0|[1-9][0-9]*)(\.[0-9]+([eE][+-]?[0-9]+)?|[eE][+-]?[0-9]+)$`)
+
+	// inferDateRegex matches RFC 3339 timestamps, which must have a time
+	// and a time zone.
+	inferDateRegex = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}` +
+		`T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})$`)
+)
+
+// typeInferrer converts the string values of JSON documents that are clearly
+// integers, doubles, booleans or dates into those types, for --inferTypes.
+// Values that could be read more than one way are left as strings. It is safe
+// for concurrent use.
+type typeInferrer struct {
+	// patterns select the fields to convert. A field is converted if a
+	// pattern matches its dotted name or the name of a field containing it.
+	patterns []string
+
+	// counts of converted values, updated atomically
+	ints, doubles, bools, dates uint64
+}
+
+// newTypeInferrer returns a typeInferrer for the comma-separated patterns of
+// --inferTypes. Each pattern matches the dotted name of a field, with '*'
+// matching any part of a name except a dot.
+func newTypeInferrer(patterns string) (*typeInferrer, error) {
+	inferrer := &typeInferrer{}
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, fmt.Errorf("empty field pattern")
+		}
+		if _, err := path.Match(toPathPattern(pattern), ""); err != nil {
+			return nil, fmt.Errorf("invalid field pattern '%v': %v", pattern, err)
+		}
+		inferrer.patterns = append(inferrer.patterns, toPathPattern(pattern))
+	}
+	return inferrer, nil
+}
+
+// toPathPattern replaces the dots of a field name or pattern with slashes, so
+// that path.Match doesn't let '*' match across them.
+func toPathPattern(field string) string {
+	return strings.ReplaceAll(field, ".", "/")
+}
+
+// matches returns whether the field with the given dotted name is converted.
+func (ti *typeInferrer) matches(field string) bool {
+	name := toPathPattern(field)
+	for {
+		for _, pattern := range ti.patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+// apply converts the string values of the selected fields of doc in place.
+func (ti *typeInferrer) apply(doc bson.D) {
+	ti.applyDocument(doc, "")
+}
+
+func (ti *typeInferrer) applyDocument(doc bson.D, prefix string) {
+	for i, elem := range doc {
+		doc[i].Value = ti.applyValue(elem.Value, prefix+elem.Key)
+	}
+}
+
+func (ti *typeInferrer) applyValue(value interface{}, field string) interface{} {
+	switch v := value.(type) {
+	case string:
+		if ti.matches(field) {
+			return ti.infer(v)
+		}
+	case bson.D:
+		ti.applyDocument(v, field+".")
+	case bson.A:
+		for i := range v {
+			v[i] = ti.applyValue(v[i], field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = ti.applyValue(v[i], field)
+		}
+	}
+	return value
+}
+
+// infer returns the value a string is converted to, or the string itself if
+// it isn't clearly of another type.
+func (ti *typeInferrer) infer(s string) interface{} {
+	switch {
+	case s == "true" || s == "false":
+		atomic.AddUint64(&ti.bools, 1)
+		return s == "true"
+	case inferIntRegex.MatchString(s):
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			// too large to be stored without losing digits
+			return s
+		}
+		atomic.AddUint64(&ti.ints, 1)
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int32(n)
+		}
+		return n
+	case inferFloatRegex.MatchString(s):
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return s
+		}
+		atomic.AddUint64(&ti.doubles, 1)
+		return f
+	case inferDateRegex.MatchString(s):
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return s
+		}
+		atomic.AddUint64(&ti.dates, 1)
+		return primitive.NewDateTimeFromTime(t)
+	}
+	return s
+}
+
+// Count returns the number of string values converted so far.
+func (ti *typeInferrer) Count() uint64 {
+	return atomic.LoadUint64(&ti.ints) + atomic.LoadUint64(&ti.doubles) +
+		atomic.LoadUint64(&ti.bools) + atomic.LoadUint64(&ti.dates)
+}
+
+// Summary describes the number of values converted to each type.
+func (ti *typeInferrer) Summary() string {
+	return fmt.Sprintf("%v integer(s), %v double(s), %v boolean(s), %v date(s)",
+		atomic.LoadUint64(&ti.ints), atomic.LoadUint64(&ti.doubles),
+		atomic.LoadUint64(&ti.bools), atomic.LoadUint64(&ti.dates))
+}
@@ -0,0 +1,123 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTypeInferrer(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a typeInferrer for every field", t, func() {
+		inferrer, err := newTypeInferrer("*")
+		So(err, ShouldBeNil)
+
+		Convey("values that are clearly of another type are converted", func() {
+			So(inferrer.infer("42"), ShouldEqual, int32(42))
+			So(inferrer.infer("-7"), ShouldEqual, int32(-7))
+			So(inferrer.infer("0"), ShouldEqual, int32(0))
+			So(inferrer.infer("3000000000"), ShouldEqual, int64(3000000000))
+			So(inferrer.infer("1.5"), ShouldEqual, 1.5)
+			So(inferrer.infer("-0.25e2"), ShouldEqual, -25.0)
+			So(inferrer.infer("2e3"), ShouldEqual, 2000.0)
+			So(inferrer.infer("true"), ShouldEqual, true)
+			So(inferrer.infer("false"), ShouldEqual, false)
+
+			date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			So(inferrer.infer("2024-01-02T03:04:05Z"), ShouldEqual,
+				primitive.NewDateTimeFromTime(date))
+			So(inferrer.infer("2024-01-02T04:04:05.000+01:00"), ShouldEqual,
+				primitive.NewDateTimeFromTime(date))
+
+			So(inferrer.Count(), ShouldEqual, 11)
+			So(inferrer.Summary(), ShouldEqual,
+				"4 integer(s), 3 double(s), 2 boolean(s), 2 date(s)")
+		})
+
+		Convey("ambiguous values are left as strings", func() {
+			for _, s := range []string{
+				"", "007", "+1", "1.", ".5", "1,000", " 1", "1 ", "0x10", "1e",
+				"NaN", "Infinity", "1e400", "99999999999999999999",
+				"True", "FALSE", "yes",
+				"2024-01-02", "2024-01-02T03:04:05", "2024-13-02T03:04:05Z", "01/02/2024",
+			} {
+				So(inferrer.infer(s), ShouldEqual, s)
+			}
+			So(inferrer.Count(), ShouldEqual, 0)
+		})
+
+		Convey("nested documents and arrays are converted", func() {
+			doc := bson.D{
+				{"a", "1"},
+				{"b", bson.D{{"c", "true"}}},
+				{"d", bson.A{"2.5", "x", bson.D{{"e", "3"}}}},
+				{"f", []interface{}{"4"}},
+				{"g", int32(5)},
+			}
+			inferrer.apply(doc)
+			So(doc, ShouldResemble, bson.D{
+				{"a", int32(1)},
+				{"b", bson.D{{"c", true}}},
+				{"d", bson.A{2.5, "x", bson.D{{"e", int32(3)}}}},
+				{"f", []interface{}{int32(4)}},
+				{"g", int32(5)},
+			})
+		})
+	})
+
+	Convey("With a typeInferrer for some fields", t, func() {
+		inferrer, err := newTypeInferrer("price, stats.*")
+		So(err, ShouldBeNil)
+
+		doc := bson.D{
+			{"price", "9.99"},
+			{"zip", "12345"},
+			{"stats", bson.D{{"views", "10"}, {"deep", bson.D{{"n", "2"}}}}},
+			{"other", bson.D{{"price", "1"}}},
+		}
+		inferrer.apply(doc)
+		So(doc, ShouldResemble, bson.D{
+			{"price", 9.99},
+			{"zip", "12345"},
+			{"stats", bson.D{{"views", int32(10)}, {"deep", bson.D{{"n", int32(2)}}}}},
+			{"other", bson.D{{"price", "1"}}},
+		})
+	})
+
+	Convey("Invalid patterns are rejected", t, func() {
+		_, err := newTypeInferrer("a,,b")
+		So(err, ShouldNotBeNil)
+		_, err = newTypeInferrer("[a")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("JSON documents are converted with --inferTypes", t, func() {
+		inferrer, err := newTypeInferrer("*")
+		So(err, ShouldBeNil)
+		for _, legacyExtJSON := range []bool{false, true} {
+			converter := JSONConverter{
+				data:          []byte(`{"n": "12", "s": "012", "a": ["true"]}`),
+				legacyExtJSON: legacyExtJSON,
+				inferrer:      inferrer,
+			}
+			doc, err := converter.Convert()
+			So(err, ShouldBeNil)
+			So(doc[0], ShouldResemble, bson.E{"n", int32(12)})
+			So(doc[1], ShouldResemble, bson.E{"s", "012"})
+			So(doc[2].Key, ShouldEqual, "a")
+			So(fmt.Sprint(doc[2].Value), ShouldEqual, "[true]")
+		}
+	})
+}
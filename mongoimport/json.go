@@ -52,13 +52,28 @@ type JSONInputReader struct {
 
 	// legacyExtJSON specifies whether or not the legacy extended JSON format should be used.
 	legacyExtJSON bool
+
+	// canonicalExtJSON specifies whether documents must be in canonical extended JSON.
+	canonicalExtJSON bool
+
+	// strictKeys specifies whether a key repeated within an object is an error.
+	strictKeys bool
+
+	// keepSource specifies whether documents are sent with the JSON they came from.
+	keepSource bool
+
+	// inferrer converts string values to other types, if set.
+	inferrer *typeInferrer
 }
 
 // JSONConverter implements the Converter interface for JSON input.
 type JSONConverter struct {
-	data          []byte
-	index         uint64
-	legacyExtJSON bool
+	data             []byte
+	index            uint64
+	legacyExtJSON    bool
+	canonicalExtJSON bool
+	strictKeys       bool
+	inferrer         *typeInferrer
 }
 
 var (
@@ -93,6 +108,16 @@ func NewJSONInputReader(
 	}
 }
 
+// setFormat makes the reader parse documents in the given --jsonInputFormat.
+// Mongo shell values are a syntax error in every format but legacy.
+func (r *JSONInputReader) setFormat(format string) {
+	r.legacyExtJSON = format == JSONFormatLegacy
+	r.canonicalExtJSON = format == JSONFormatCanonical
+	if !r.legacyExtJSON {
+		r.decoder.DisallowShellValues()
+	}
+}
+
 // ReadAndValidateHeader is a no-op for JSON imports; always returns nil.
 func (r *JSONInputReader) ReadAndValidateHeader() error {
 	return nil
@@ -106,7 +131,7 @@ func (r *JSONInputReader) ReadAndValidateTypedHeader(parseGrace ParseGrace) erro
 // StreamDocument takes a boolean indicating if the documents should be streamed
 // in read order and a channel on which to stream the documents processed from
 // the underlying reader. Returns a non-nil error if encountered.
-func (r *JSONInputReader) StreamDocument(ordered bool, readChan chan bson.D) (retErr error) {
+func (r *JSONInputReader) StreamDocument(ordered bool, readChan chan InputDocument) (retErr error) {
 	rawChan := make(chan Converter, r.numDecoders)
 	jsonErrChan := make(chan error)
 
@@ -138,9 +163,12 @@ func (r *JSONInputReader) StreamDocument(ordered bool, readChan chan bson.D) (re
 				return
 			}
 			rawChan <- JSONConverter{
-				data:          rawBytes,
-				index:         r.numProcessed,
-				legacyExtJSON: r.legacyExtJSON,
+				data:             rawBytes,
+				index:            r.numProcessed,
+				legacyExtJSON:    r.legacyExtJSON,
+				canonicalExtJSON: r.canonicalExtJSON,
+				strictKeys:       r.strictKeys,
+				inferrer:         r.inferrer,
 			}
 			r.numProcessed++
 		}
@@ -148,7 +176,7 @@ func (r *JSONInputReader) StreamDocument(ordered bool, readChan chan bson.D) (re
 
 	// begin processing read bytes
 	go func() {
-		jsonErrChan <- streamDocuments(ordered, r.numDecoders, rawChan, readChan)
+		jsonErrChan <- streamDocuments(ordered, r.numDecoders, rawChan, readChan, r.keepSource)
 	}()
 
 	return channelQuorumError(jsonErrChan)
@@ -157,20 +185,62 @@ func (r *JSONInputReader) StreamDocument(ordered bool, readChan chan bson.D) (re
 // Convert implements the Converter interface for JSON input. It converts a
 // JSONConverter struct to a BSON document.
 func (c JSONConverter) Convert() (bson.D, error) {
+	doc, err := c.convert()
+	if err != nil {
+		return nil, err
+	}
+	if c.inferrer != nil {
+		c.inferrer.apply(doc)
+	}
+	return doc, nil
+}
+
+func (c JSONConverter) convert() (bson.D, error) {
 	if c.legacyExtJSON {
 		return c.convertLegacyExtJSON()
 	}
 
+	if c.strictKeys {
+		// the driver's parser keeps repeated keys, so check for them first
+		if _, err := json.UnmarshalBsonDStrict(c.data); err != nil {
+			return nil, fmt.Errorf("error unmarshaling bytes on document #%v: %v", c.index, err)
+		}
+	}
+
+	// integers too large for an int64 are imported as Decimal128 rather than
+	// losing digits as a double, except in canonical Extended JSON, which
+	// requires them to be given as $numberDecimal
+	data := c.data
+	if c.canonicalExtJSON {
+		if n := json.FirstLargeInteger(data); n != "" {
+			return nil, fmt.Errorf(
+				"error unmarshaling bytes on document #%v: integer %v is too large for an "+
+					"int64; use {\"$numberDecimal\": \"%v\"} in canonical Extended JSON",
+				c.index, n, n,
+			)
+		}
+	} else {
+		data = json.LargeIntegersToDecimal128(data)
+	}
 	var doc bson.D
-	if err := bson.UnmarshalExtJSON(c.data, false, &doc); err != nil {
+	if err := bson.UnmarshalExtJSON(data, c.canonicalExtJSON, &doc); err != nil {
 		return nil, err
 	}
 
 	return doc, nil
 }
 
+// Source implements the sourceConverter interface. It returns the JSON the
+// document was decoded from.
+func (c JSONConverter) Source() string {
+	return string(c.data)
+}
+
 func (c JSONConverter) convertLegacyExtJSON() (bson.D, error) {
-	document, err := json.UnmarshalBsonD(c.data)
+	document, err := json.UnmarshalBsonDWithOptions(c.data, json.UnmarshalOptions{
+		StrictKeys:            c.strictKeys,
+		PreserveLargeIntegers: true,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling bytes on document #%v: %v", c.index, err)
 	}
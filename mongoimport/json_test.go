@@ -8,9 +8,11 @@ package mongoimport
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -29,31 +31,31 @@ func TestJSONArrayStreamDocument(t *testing.T) {
 		Convey("an error should be thrown if a plain JSON document is supplied", func() {
 			contents := `{"a": "ae"}`
 			r := NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-			So(r.StreamDocument(true, make(chan bson.D, 1)), ShouldNotBeNil)
+			So(r.StreamDocument(true, make(chan InputDocument, 1)), ShouldNotBeNil)
 		})
 
 		Convey("reading a JSON object that has no opening bracket should "+
 			"error out", func() {
 			contents := `{"a":3},{"b":4}]`
 			r := NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-			So(r.StreamDocument(true, make(chan bson.D, 1)), ShouldNotBeNil)
+			So(r.StreamDocument(true, make(chan InputDocument, 1)), ShouldNotBeNil)
 		})
 
 		Convey("JSON arrays that do not end with a closing bracket should "+
 			"error out", func() {
 			contents := `[{"a": "ae"}`
 			r := NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldNotBeNil)
 			// though first read should be fine
-			So(<-docChan, ShouldResemble, bson.D{{"a", "ae"}})
+			So((<-docChan).Document, ShouldResemble, bson.D{{"a", "ae"}})
 		})
 
 		Convey("an error should be thrown if a plain JSON file is supplied", func() {
 			fileHandle, err := os.Open("testdata/test_plain.json")
 			So(err, ShouldBeNil)
 			r := NewJSONInputReader(true, true, fileHandle, 1)
-			So(r.StreamDocument(true, make(chan bson.D, 50)), ShouldNotBeNil)
+			So(r.StreamDocument(true, make(chan InputDocument, 50)), ShouldNotBeNil)
 		})
 
 		Convey("array JSON input file sources should be parsed correctly and "+
@@ -72,10 +74,10 @@ func TestJSONArrayStreamDocument(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test_array.json")
 			So(err, ShouldBeNil)
 			r := NewJSONInputReader(true, true, fileHandle, 1)
-			docChan := make(chan bson.D, 50)
+			docChan := make(chan InputDocument, 50)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedReadOne)
-			So(<-docChan, ShouldResemble, expectedReadTwo)
+			So((<-docChan).Document, ShouldResemble, expectedReadOne)
+			So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 		})
 
 		Reset(func() {
@@ -93,9 +95,9 @@ func TestJSONPlainStreamDocument(t *testing.T) {
 			contents := `{"a": "ae"}`
 			expectedRead := bson.D{{"a", "ae"}}
 			r := NewJSONInputReader(false, true, bytes.NewReader([]byte(contents)), 1)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("several string valued JSON documents should be imported "+
@@ -104,25 +106,25 @@ func TestJSONPlainStreamDocument(t *testing.T) {
 			expectedReadOne := bson.D{{"a", "ae"}}
 			expectedReadTwo := bson.D{{"b", "dc"}}
 			r := NewJSONInputReader(false, true, bytes.NewReader([]byte(contents)), 1)
-			docChan := make(chan bson.D, 2)
+			docChan := make(chan InputDocument, 2)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedReadOne)
-			So(<-docChan, ShouldResemble, expectedReadTwo)
+			So((<-docChan).Document, ShouldResemble, expectedReadOne)
+			So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 		})
 
 		Convey("number valued JSON documents should be imported properly", func() {
 			contents := `{"a": "ae", "b": 2.0}`
 			expectedRead := bson.D{{"a", "ae"}, {"b", 2.0}}
 			r := NewJSONInputReader(false, true, bytes.NewReader([]byte(contents)), 1)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("JSON arrays should return an error", func() {
 			contents := `[{"a": "ae", "b": 2.0}]`
 			r := NewJSONInputReader(false, true, bytes.NewReader([]byte(contents)), 1)
-			So(r.StreamDocument(true, make(chan bson.D, 50)), ShouldNotBeNil)
+			So(r.StreamDocument(true, make(chan InputDocument, 50)), ShouldNotBeNil)
 		})
 
 		Convey("plain JSON input file sources should be parsed correctly and "+
@@ -145,10 +147,10 @@ func TestJSONPlainStreamDocument(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test_plain.json")
 			So(err, ShouldBeNil)
 			r := NewJSONInputReader(false, true, fileHandle, 1)
-			docChan := make(chan bson.D, len(expectedReads))
+			docChan := make(chan InputDocument, len(expectedReads))
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
 			for i := 0; i < len(expectedReads); i++ {
-				for j, readDocument := range <-docChan {
+				for j, readDocument := range (<-docChan).Document {
 					So(readDocument.Key, ShouldEqual, expectedReads[i][j].Key)
 					So(readDocument.Value, ShouldEqual, expectedReads[i][j].Value)
 				}
@@ -171,10 +173,10 @@ func TestJSONPlainStreamDocument(t *testing.T) {
 				fileHandle, err := os.Open("testdata/test_bom.json")
 				So(err, ShouldBeNil)
 				r := NewJSONInputReader(false, true, fileHandle, 1)
-				docChan := make(chan bson.D, 2)
+				docChan := make(chan InputDocument, 2)
 				So(r.StreamDocument(true, docChan), ShouldBeNil)
 				for _, expectedRead := range expectedReads {
-					for i, readDocument := range <-docChan {
+					for i, readDocument := range (<-docChan).Document {
 						So(readDocument.Key, ShouldEqual, expectedRead[i].Key)
 						So(readDocument.Value, ShouldEqual, expectedRead[i].Value)
 					}
@@ -235,7 +237,7 @@ func TestReadJSONArraySeparator(t *testing.T) {
 			func() {
 				contents := `[{"a":3}x{"b":4}]`
 				r := NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-				docChan := make(chan bson.D, 1)
+				docChan := make(chan InputDocument, 1)
 				So(r.StreamDocument(true, docChan), ShouldNotBeNil)
 				// read first valid document
 				<-docChan
@@ -246,10 +248,10 @@ func TestReadJSONArraySeparator(t *testing.T) {
 			func() {
 				contents := `[{"a":3},b{"b":4}]`
 				r := NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-				So(r.StreamDocument(true, make(chan bson.D, 1)), ShouldNotBeNil)
+				So(r.StreamDocument(true, make(chan InputDocument, 1)), ShouldNotBeNil)
 				contents = `[{"a":3},,{"b":4}]`
 				r = NewJSONInputReader(true, true, bytes.NewReader([]byte(contents)), 1)
-				So(r.StreamDocument(true, make(chan bson.D, 1)), ShouldNotBeNil)
+				So(r.StreamDocument(true, make(chan InputDocument, 1)), ShouldNotBeNil)
 			})
 	})
 }
@@ -303,3 +305,131 @@ func TestJSONConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONConvertStrictKeys(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	data := []byte(`{"a": 1, "b": {"$numberLong": "2"}, "a": 3}`)
+
+	for _, legacyExtJSON := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacyExtJSON=%v", legacyExtJSON), func(t *testing.T) {
+			converter := JSONConverter{
+				data:          data,
+				index:         7,
+				legacyExtJSON: legacyExtJSON,
+			}
+			_, err := converter.Convert()
+			if err != nil {
+				t.Fatalf("err running lenient Convert: %s", err)
+			}
+
+			converter.strictKeys = true
+			_, err = converter.Convert()
+			expected := "error unmarshaling bytes on document #7: duplicate key 'a' at offset 36"
+			if err == nil || err.Error() != expected {
+				t.Fatalf("expected error %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestJSONInputFormat(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	// readFormat streams the documents from one of the test_format fixtures,
+	// parsed as the given --jsonInputFormat, and returns them marshaled to BSON.
+	readFormat := func(file, format string) ([][]byte, error) {
+		fileHandle, err := os.Open("testdata/test_format_" + file + ".json")
+		if err != nil {
+			return nil, err
+		}
+		defer fileHandle.Close()
+		r := NewJSONInputReader(false, false, fileHandle, 1)
+		r.setFormat(format)
+		docChan := make(chan InputDocument, 10)
+		if err := r.StreamDocument(true, docChan); err != nil {
+			return nil, err
+		}
+		var docs [][]byte
+		for doc := range docChan {
+			raw, err := bson.Marshal(doc.Document)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, raw)
+		}
+		return docs, nil
+	}
+
+	Convey("With the same documents in each extended JSON format", t, func() {
+		expected, err := readFormat(JSONFormatCanonical, JSONFormatCanonical)
+		So(err, ShouldBeNil)
+		So(len(expected), ShouldEqual, 2)
+
+		Convey("each fixture should be read the same in its own format", func() {
+			for _, format := range []string{JSONFormatLegacy, JSONFormatRelaxed} {
+				docs, err := readFormat(format, format)
+				So(err, ShouldBeNil)
+				So(docs, ShouldResemble, expected)
+			}
+		})
+
+		Convey("canonical and relaxed should reject mongo shell values", func() {
+			for _, format := range []string{JSONFormatCanonical, JSONFormatRelaxed} {
+				_, err := readFormat(JSONFormatLegacy, format)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "only accepted in legacy extended JSON")
+			}
+		})
+
+		Convey("canonical should reject relaxed values", func() {
+			_, err := readFormat(JSONFormatRelaxed, JSONFormatCanonical)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("relaxed should accept canonical values", func() {
+			docs, err := readFormat(JSONFormatCanonical, JSONFormatRelaxed)
+			So(err, ShouldBeNil)
+			So(docs, ShouldResemble, expected)
+		})
+	})
+}
+
+func TestJSONConvertLargeIntegers(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	data := []byte(`{"balance": 12345678901234567890, "cents": -98765432109876543210, "n": 5}`)
+	balance, _ := primitive.ParseDecimal128("12345678901234567890")
+	cents, _ := primitive.ParseDecimal128("-98765432109876543210")
+	expected := bson.D{{"balance", balance}, {"cents", cents}, {"n", int32(5)}}
+
+	for _, legacyExtJSON := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacyExtJSON=%v", legacyExtJSON), func(t *testing.T) {
+			converter := JSONConverter{
+				data:          data,
+				legacyExtJSON: legacyExtJSON,
+			}
+			doc, err := converter.Convert()
+			if err != nil {
+				t.Fatalf("err running Convert: %s", err)
+			}
+			if !reflect.DeepEqual(doc, expected) {
+				t.Fatalf("doc mismatch; expected %v, got %v", expected, doc)
+			}
+		})
+	}
+
+	t.Run("canonical", func(t *testing.T) {
+		converter := JSONConverter{
+			data:             data,
+			canonicalExtJSON: true,
+		}
+		doc, err := converter.Convert()
+		if err == nil {
+			t.Fatalf("expected an error for bare large integers, got %v", doc)
+		}
+		if !strings.Contains(err.Error(), "integer 12345678901234567890 is too large") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -41,6 +41,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	m, err := mongoimport.New(opts)
 	if err != nil {
 		log.Logvf(log.Always, err.Error())
@@ -63,9 +68,30 @@ func main() {
 				)
 			} else {
 				log.Logvf(log.Always, "%v document(s) imported successfully. %v document(s) failed to import.", numDocs, numFailure)
+				if numFailure > 0 {
+					duplicates := m.DuplicateKeyCount()
+					log.Logvf(log.Always,
+						"%v document(s) failed because of a duplicate key, %v because of other errors.",
+						duplicates, numFailure-duplicates)
+				}
+				if opts.SkipExisting != "" || opts.Mode == "insertIfAbsent" {
+					log.Logvf(log.Always, "%v document(s) skipped because they already exist.",
+						m.SkippedCount())
+				}
+				if opts.RejectFile != "" {
+					log.Logvf(log.Always, "%v document(s) written to reject file %v.",
+						m.RejectedCount(), opts.RejectFile)
+				}
+				if opts.InferTypes != "" {
+					count, summary := m.InferredTypes()
+					log.Logvf(log.Always, "%v string value(s) converted by --inferTypes: %v.",
+						count, summary)
+				}
 			}
 		} else {
-			log.Logvf(log.Always, "done")
+			log.Logvf(log.Always,
+				"%v document(s) sent with an unacknowledged write concern; "+
+					"the server did not report how many were imported.", numDocs)
 		}
 	}
 	if err != nil {
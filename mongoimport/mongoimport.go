@@ -8,6 +8,7 @@
 package mongoimport
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/log"
@@ -34,17 +36,30 @@ const (
 	JSON = "json"
 )
 
+// JSON input formats accepted by --jsonInputFormat.
+const (
+	JSONFormatLegacy    = "legacy"
+	JSONFormatCanonical = "canonical"
+	JSONFormatRelaxed   = "relaxed"
+)
+
 // Modes accepted by mongoimport.
 const (
-	modeInsert = "insert"
-	modeUpsert = "upsert"
-	modeMerge  = "merge"
-	modeDelete = "delete"
+	modeInsert         = "insert"
+	modeUpsert         = "upsert"
+	modeMerge          = "merge"
+	modeDelete         = "delete"
+	modeInsertIfAbsent = "insertIfAbsent"
 )
 
 const (
 	workerBufferSize  = 16
 	progressBarLength = 24
+
+	// batchFlushInterval is how long an insertion worker waits for more
+	// documents before writing a partial batch, so that slow inputs such as
+	// a trickle on stdin still reach the server.
+	batchFlushInterval = time.Second
 )
 
 // MongoImport is a container for the user-specified options and
@@ -59,6 +74,28 @@ type MongoImport struct {
 	// Should be updated atomically.
 	failureCount uint64
 
+	// duplicateKeyCount counts the failures caused by duplicate key errors.
+	// Should be updated atomically.
+	duplicateKeyCount uint64
+
+	// sentCount counts the documents handed to the bulk writer, which is the
+	// best estimate of the documents processed when writes are unacknowledged.
+	// Should be updated atomically.
+	sentCount uint64
+
+	// documentsRead counts the documents handed to importDocument, used to
+	// identify documents in log messages. Should be updated atomically.
+	documentsRead uint64
+
+	// skippedCount counts the documents skipped by --skipExisting or
+	// --mode=insertIfAbsent because they already exist in the collection.
+	// Should be updated atomically.
+	skippedCount uint64
+
+	// inFlightBatches counts the bulk writes currently being sent to the
+	// server by the insertion workers. Should be updated atomically.
+	inFlightBatches int64
+
 	// generic mongo tool options
 	ToolOptions *options.ToolOptions
 
@@ -80,13 +117,22 @@ type MongoImport struct {
 
 	// type of node the SessionProvider is connected to
 	nodeType db.NodeType
+
+	// values of the --skipExisting field already in the collection
+	existing *existingKeys
+
+	// where documents that fail to import are written with --rejectFile
+	rejects *rejectWriter
+
+	// converts string values to other types with --inferTypes
+	inferrer *typeInferrer
 }
 
 type InputReader interface {
 	// StreamDocument takes a boolean indicating if the documents should be streamed
 	// in read order and a channel on which to stream the documents processed from
 	// the underlying reader.  Returns a non-nil error if encountered.
-	StreamDocument(ordered bool, read chan bson.D) error
+	StreamDocument(ordered bool, read chan InputDocument) error
 
 	// ReadAndValidateHeader reads the header line from the InputReader and returns
 	// a non-nil error if the fields from the header line are invalid; returns
@@ -140,6 +186,17 @@ func (imp *MongoImport) validateSettings() error {
 		return fmt.Errorf("invalid database name: %v", err)
 	}
 
+	if imp.InputOptions.Dir != "" {
+		if imp.InputOptions.File != "" {
+			return fmt.Errorf("incompatible options: --file and --dir")
+		}
+	} else if imp.InputOptions.Glob != "" {
+		return fmt.Errorf("cannot use --glob without --dir")
+	}
+	if _, err := filepath.Match(imp.InputOptions.Glob, ""); err != nil {
+		return fmt.Errorf("invalid --glob pattern '%v': %v", imp.InputOptions.Glob, err)
+	}
+
 	imp.InputOptions.Type = strings.ToLower(imp.InputOptions.Type)
 	// use JSON as default input type
 	if imp.InputOptions.Type == "" {
@@ -185,6 +242,15 @@ func (imp *MongoImport) validateSettings() error {
 		if imp.InputOptions.Legacy {
 			return fmt.Errorf("cannot use --legacy if input type is not JSON")
 		}
+		if imp.InputOptions.StrictJSON {
+			return fmt.Errorf("cannot use --strictJSON if input type is not JSON")
+		}
+		if imp.InputOptions.InferTypes != "" {
+			return fmt.Errorf("cannot use --inferTypes if input type is not JSON")
+		}
+		if imp.InputOptions.JSONInputFormat != "" {
+			return fmt.Errorf("cannot use --jsonInputFormat if input type is not JSON")
+		}
 	} else {
 		// input type is JSON
 		if imp.InputOptions.HeaderLine {
@@ -202,6 +268,21 @@ func (imp *MongoImport) validateSettings() error {
 		if imp.InputOptions.ColumnsHaveTypes {
 			return fmt.Errorf("cannot use --columnsHaveTypes when input type is JSON")
 		}
+		switch imp.InputOptions.JSONInputFormat {
+		case "", JSONFormatLegacy:
+		case JSONFormatCanonical, JSONFormatRelaxed:
+			if imp.InputOptions.Legacy {
+				return fmt.Errorf(
+					"cannot use --legacy with --jsonInputFormat=%v",
+					imp.InputOptions.JSONInputFormat,
+				)
+			}
+		default:
+			return fmt.Errorf(
+				"invalid --jsonInputFormat '%v', choose 'legacy', 'canonical', or 'relaxed'",
+				imp.InputOptions.JSONInputFormat,
+			)
+		}
 	}
 
 	// deprecated
@@ -229,11 +310,33 @@ func (imp *MongoImport) validateSettings() error {
 		imp.IngestOptions.Mode = modeInsert
 	}
 
+	if imp.InputOptions.InferTypes != "" {
+		var err error
+		imp.inferrer, err = newTypeInferrer(imp.InputOptions.InferTypes)
+		if err != nil {
+			return fmt.Errorf("invalid --inferTypes argument: %v", err)
+		}
+	}
+
+	if imp.IngestOptions.SkipExisting != "" {
+		if imp.IngestOptions.Mode != modeInsert {
+			return fmt.Errorf("cannot use --skipExisting with --mode=%v", imp.IngestOptions.Mode)
+		}
+		err := validateFields(
+			[]string{imp.IngestOptions.SkipExisting},
+			imp.InputOptions.UseArrayIndexFields,
+		)
+		if err != nil {
+			return fmt.Errorf("invalid --skipExisting argument: %v", err)
+		}
+	}
+
 	// double-check mode choices
 	if !(imp.IngestOptions.Mode == modeInsert ||
 		imp.IngestOptions.Mode == modeUpsert ||
 		imp.IngestOptions.Mode == modeDelete ||
-		imp.IngestOptions.Mode == modeMerge) {
+		imp.IngestOptions.Mode == modeMerge ||
+		imp.IngestOptions.Mode == modeInsertIfAbsent) {
 		return fmt.Errorf("invalid --mode argument: %v", imp.IngestOptions.Mode)
 	}
 
@@ -258,6 +361,10 @@ func (imp *MongoImport) validateSettings() error {
 	log.Logvf(log.DebugLow, "using %v decoding workers", imp.IngestOptions.NumDecodingWorkers)
 	log.Logvf(log.DebugLow, "using %v insert workers", imp.IngestOptions.NumInsertionWorkers)
 
+	if imp.IngestOptions.RejectFile != "" && !imp.ToolOptions.WriteConcern.Acknowledged() {
+		return fmt.Errorf("cannot use --rejectFile with an unacknowledged write concern")
+	}
+
 	// get the number of documents per batch
 	if imp.IngestOptions.BulkBufferSize <= 0 || imp.IngestOptions.BulkBufferSize > 1000 {
 		imp.IngestOptions.BulkBufferSize = 1000
@@ -266,13 +373,22 @@ func (imp *MongoImport) validateSettings() error {
 	// ensure we have a valid string to use for the collection
 	if imp.ToolOptions.Collection == "" {
 		log.Logvf(log.Always, "no collection specified")
-		fileBaseName := filepath.Base(imp.InputOptions.File)
-		lastDotIndex := strings.LastIndex(fileBaseName, ".")
-		if lastDotIndex != -1 {
-			fileBaseName = fileBaseName[0:lastDotIndex]
+		if imp.InputOptions.Dir != "" {
+			dirBaseName := filepath.Base(filepath.Clean(imp.InputOptions.Dir))
+			log.Logvf(log.Always, "using directory name '%v' as collection", dirBaseName)
+			imp.ToolOptions.Collection = dirBaseName
+		} else {
+			fileBaseName := filepath.Base(imp.InputOptions.File)
+			if isURL(imp.InputOptions.File) {
+				fileBaseName = urlBaseName(imp.InputOptions.File)
+			}
+			lastDotIndex := strings.LastIndex(fileBaseName, ".")
+			if lastDotIndex != -1 {
+				fileBaseName = fileBaseName[0:lastDotIndex]
+			}
+			log.Logvf(log.Always, "using filename '%v' as collection", fileBaseName)
+			imp.ToolOptions.Collection = fileBaseName
 		}
-		log.Logvf(log.Always, "using filename '%v' as collection", fileBaseName)
-		imp.ToolOptions.Collection = fileBaseName
 	}
 	err = util.ValidateCollectionName(imp.ToolOptions.Collection)
 	if err != nil {
@@ -281,12 +397,25 @@ func (imp *MongoImport) validateSettings() error {
 	return nil
 }
 
-// getSourceReader returns an io.Reader to read from the input source. Also
-// returns a progress.Progressor which can be used to track progress if the
-// reader supports it.
+// getSourceReader returns an io.Reader to read from the input source, along
+// with the size of the input if it is known. The input source is a local
+// file, an HTTP or HTTPS URL, or stdin, and is decompressed if --gzip is set.
 func (imp *MongoImport) getSourceReader() (io.ReadCloser, int64, error) {
-	if imp.InputOptions.File != "" {
-		file, err := os.Open(util.ToUniversalPath(imp.InputOptions.File))
+	return imp.openSource(imp.InputOptions.File)
+}
+
+// openSource returns an io.Reader to read from the file or URL at path, or
+// from stdin if path is empty. See getSourceReader.
+func (imp *MongoImport) openSource(path string) (io.ReadCloser, int64, error) {
+	if isURL(path) {
+		client := newHTTPClient(httpConnectTimeout, httpResponseHeaderTimeout)
+		return openURL(client, path, imp.InputOptions.Gzip)
+	}
+
+	var source io.ReadCloser
+	var size int64
+	if path != "" {
+		file, err := os.Open(util.ToUniversalPath(path))
 		if err != nil {
 			return nil, -1, err
 		}
@@ -295,13 +424,24 @@ func (imp *MongoImport) getSourceReader() (io.ReadCloser, int64, error) {
 			return nil, -1, err
 		}
 		log.Logvf(log.Info, "filesize: %v bytes", fileStat.Size())
-		return file, fileStat.Size(), err
-	}
+		source, size = file, fileStat.Size()
+	} else {
+		log.Logvf(log.Info, "reading from stdin")
 
-	log.Logvf(log.Info, "reading from stdin")
+		// Stdin has undefined max size, so return 0
+		source, size = os.Stdin, 0
+	}
 
-	// Stdin has undefined max size, so return 0
-	return os.Stdin, 0, nil
+	if !imp.InputOptions.Gzip {
+		return source, size, nil
+	}
+	gzr, err := gzip.NewReader(source)
+	if err != nil {
+		source.Close()
+		return nil, -1, fmt.Errorf("error decompressing input: %v", err)
+	}
+	// the decompressed size isn't known up front
+	return &util.WrappedReadCloser{ReadCloser: gzr, Inner: source}, 0, nil
 }
 
 // fileSizeProgressor implements Progressor to allow a sizeTracker to hook up with a
@@ -319,6 +459,10 @@ func (fsp *fileSizeProgressor) Progress() (int64, int64) {
 // number of documents successfully imported to the appropriate namespace,
 // the number of failures, and any error encountered in doing this.
 func (imp *MongoImport) ImportDocuments() (uint64, uint64, error) {
+	if imp.InputOptions.Dir != "" {
+		return imp.importDirectory()
+	}
+
 	source, fileSize, err := imp.getSourceReader()
 	if err != nil {
 		return 0, 0, err
@@ -330,27 +474,76 @@ func (imp *MongoImport) ImportDocuments() (uint64, uint64, error) {
 		return 0, 0, err
 	}
 
-	if imp.InputOptions.HeaderLine {
-		if imp.InputOptions.ColumnsHaveTypes {
-			err = inputReader.ReadAndValidateTypedHeader(ParsePG(imp.InputOptions.ParseGrace))
-		} else {
-			err = inputReader.ReadAndValidateHeader()
-		}
-		if err != nil {
-			return 0, 0, err
-		}
+	if err := imp.readHeader(inputReader); err != nil {
+		return 0, 0, err
 	}
 
+	bar := imp.startProgressBar(&fileSizeProgressor{fileSize, inputReader})
+	defer bar.Stop()
+	return imp.importDocuments(inputReader)
+}
+
+// startProgressBar starts a progress bar that shows the bytes of input read
+// according to progressor. The caller must stop it.
+func (imp *MongoImport) startProgressBar(progressor progress.Progressor) *progress.Bar {
 	bar := &progress.Bar{
 		Name:      fmt.Sprintf("%v.%v", imp.ToolOptions.DB, imp.ToolOptions.Collection),
-		Watching:  &fileSizeProgressor{fileSize, inputReader},
+		Watching:  progressor,
 		Writer:    log.Writer(0),
 		BarLength: progressBarLength,
 		IsBytes:   true,
+		ShowRate:  true,
+		Status:    imp.inFlightStatus,
 	}
 	bar.Start()
-	defer bar.Stop()
-	return imp.importDocuments(inputReader)
+	return bar
+}
+
+// readHeader reads the header line of the input with --headerline.
+func (imp *MongoImport) readHeader(inputReader InputReader) error {
+	if !imp.InputOptions.HeaderLine {
+		return nil
+	}
+	if imp.InputOptions.ColumnsHaveTypes {
+		return inputReader.ReadAndValidateTypedHeader(ParsePG(imp.InputOptions.ParseGrace))
+	}
+	return inputReader.ReadAndValidateHeader()
+}
+
+// SkippedCount returns the number of documents that --skipExisting or
+// --mode=insertIfAbsent didn't import because they already exist in the
+// collection.
+func (imp *MongoImport) SkippedCount() uint64 {
+	return atomic.LoadUint64(&imp.skippedCount)
+}
+
+// InferredTypes returns the number of string values that --inferTypes
+// converted, and a summary of the types they were converted to.
+func (imp *MongoImport) InferredTypes() (uint64, string) {
+	if imp.inferrer == nil {
+		return 0, ""
+	}
+	return imp.inferrer.Count(), imp.inferrer.Summary()
+}
+
+// DuplicateKeyCount returns the number of documents that failed to import
+// because of a duplicate key error. They are included in the failure count.
+func (imp *MongoImport) DuplicateKeyCount() uint64 {
+	return atomic.LoadUint64(&imp.duplicateKeyCount)
+}
+
+// RejectedCount returns the number of documents written to the --rejectFile.
+func (imp *MongoImport) RejectedCount() uint64 {
+	if imp.rejects == nil {
+		return 0
+	}
+	return imp.rejects.Count()
+}
+
+// inFlightStatus reports the number of batches being written, for display
+// alongside the progress bar.
+func (imp *MongoImport) inFlightStatus() string {
+	return fmt.Sprintf("(%v batches in flight)", atomic.LoadInt64(&imp.inFlightBatches))
 }
 
 // importDocuments is a helper to ImportDocuments and does all the ingestion
@@ -359,6 +552,19 @@ func (imp *MongoImport) ImportDocuments() (uint64, uint64, error) {
 // imported to the appropriate namespace, the number of failures, and any error
 // encountered in doing this.
 func (imp *MongoImport) importDocuments(inputReader InputReader) (uint64, uint64, error) {
+	ordered := imp.IngestOptions.MaintainInsertionOrder
+	return imp.ingest(func(readDocs chan InputDocument) error {
+		return inputReader.StreamDocument(ordered, readDocs)
+	})
+}
+
+// ingest writes the documents sent by stream to the appropriate namespace.
+// stream must close readDocs when it returns. It returns the number of
+// documents successfully imported, the number of failures, and any error
+// encountered in doing this.
+func (imp *MongoImport) ingest(
+	stream func(readDocs chan InputDocument) error,
+) (uint64, uint64, error) {
 	session, err := imp.SessionProvider.GetSession()
 	if err != nil {
 		return 0, 0, err
@@ -393,13 +599,29 @@ func (imp *MongoImport) importDocuments(inputReader InputReader) (uint64, uint64
 		}
 	}
 
-	readDocs := make(chan bson.D, workerBufferSize)
+	if imp.IngestOptions.SkipExisting != "" && !imp.IngestOptions.Drop {
+		collection := session.Database(imp.ToolOptions.DB).
+			Collection(imp.ToolOptions.Collection)
+		imp.existing, err = loadExistingKeys(collection, imp.IngestOptions.SkipExisting)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error loading existing values of %v: %v",
+				imp.IngestOptions.SkipExisting, err)
+		}
+	}
+
+	if imp.IngestOptions.RejectFile != "" {
+		imp.rejects, err = newRejectWriter(imp.IngestOptions.RejectFile)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	readDocs := make(chan InputDocument, workerBufferSize)
 	processingErrChan := make(chan error)
-	ordered := imp.IngestOptions.MaintainInsertionOrder
 
 	// read and process from the input reader
 	go func() {
-		processingErrChan <- inputReader.StreamDocument(ordered, readDocs)
+		processingErrChan <- stream(readDocs)
 	}()
 
 	// insert documents into the target database
@@ -408,7 +630,20 @@ func (imp *MongoImport) importDocuments(inputReader InputReader) (uint64, uint64
 	}()
 
 	e1 := channelQuorumError(processingErrChan)
+	if imp.rejects != nil {
+		if err := imp.rejects.Close(); err != nil && e1 == nil {
+			e1 = err
+		}
+	}
+	if imp.existing != nil {
+		log.Logvf(log.Info, "%v document(s) matched the bloom filter but did not exist",
+			atomic.LoadUint64(&imp.existing.falsePositives))
+	}
 	processedCount := atomic.LoadUint64(&imp.processedCount)
+	if !imp.ToolOptions.WriteConcern.Acknowledged() {
+		// the server doesn't report what it did with unacknowledged writes
+		processedCount = atomic.LoadUint64(&imp.sentCount)
+	}
 	failureCount := atomic.LoadUint64(&imp.failureCount)
 	return processedCount, failureCount, e1
 }
@@ -416,7 +651,7 @@ func (imp *MongoImport) importDocuments(inputReader InputReader) (uint64, uint64
 // ingestDocuments accepts a channel from which it reads documents to be inserted
 // into the target collection. It spreads the insert/upsert workload across one
 // or more workers.
-func (imp *MongoImport) ingestDocuments(readDocs chan bson.D) (retErr error) {
+func (imp *MongoImport) ingestDocuments(readDocs chan InputDocument) (retErr error) {
 	numInsertionWorkers := imp.IngestOptions.NumInsertionWorkers
 	if numInsertionWorkers <= 0 {
 		numInsertionWorkers = 1
@@ -449,7 +684,7 @@ func (imp *MongoImport) ingestDocuments(readDocs chan bson.D) (retErr error) {
 
 // runInsertionWorker is a helper to InsertDocuments - it reads document off
 // the read channel and prepares then in batches for insertion into the database.
-func (imp *MongoImport) runInsertionWorker(readDocs chan bson.D) (err error) {
+func (imp *MongoImport) runInsertionWorker(readDocs chan InputDocument) (err error) {
 	session, err := imp.SessionProvider.GetSession()
 	if err != nil {
 		return fmt.Errorf("error connecting to mongod: %v", err)
@@ -459,16 +694,39 @@ func (imp *MongoImport) runInsertionWorker(readDocs chan bson.D) (err error) {
 	inserter := db.NewUnorderedBufferedBulkInserter(collection, imp.IngestOptions.BulkBufferSize).
 		SetBypassDocumentValidation(imp.IngestOptions.BypassDocumentValidation).
 		SetOrdered(imp.IngestOptions.MaintainInsertionOrder).
-		SetUpsert(true)
+		SetUpsert(true).
+		SetInFlightCounter(&imp.inFlightBatches)
+	if imp.rejects != nil {
+		inserter.SetWriteErrorHandler(imp.rejects.reject)
+	}
+
+	// readDocs is bounded, so while every worker is busy writing, reading
+	// the input blocks rather than buffering documents without limit
+	flushTicker := time.NewTicker(batchFlushInterval)
+	defer flushTicker.Stop()
+	idle := true
 
 readLoop:
 	for {
 		select {
-		case document, alive := <-readDocs:
+		case doc, alive := <-readDocs:
 			if !alive {
 				break readLoop
 			}
-			err := imp.importDocument(inserter, document)
+			idle = false
+			err := imp.importDocument(inserter, doc)
+			if db.FilterError(imp.IngestOptions.StopOnError, err) != nil {
+				return err
+			}
+		case <-flushTicker.C:
+			// only write a partial batch once no documents arrived for a
+			// whole interval, so busy workers still send full batches
+			if !idle {
+				idle = true
+				continue
+			}
+			result, err := inserter.Flush()
+			imp.updateCounts(result, err)
 			if db.FilterError(imp.IngestOptions.StopOnError, err) != nil {
 				return err
 			}
@@ -495,42 +753,86 @@ func (imp *MongoImport) updateCounts(result *mongo.BulkWriteResult, err error) {
 				result.DeletedCount,
 			),
 		)
+		if imp.IngestOptions.Mode == modeInsertIfAbsent {
+			// the $setOnInsert of a matched document changes nothing
+			atomic.AddUint64(&imp.skippedCount, uint64(result.MatchedCount))
+		}
 	}
 	if bwe, ok := err.(mongo.BulkWriteException); ok {
 		atomic.AddUint64(&imp.failureCount, uint64(len(bwe.WriteErrors)))
+		var duplicates uint64
+		for _, writeErr := range bwe.WriteErrors {
+			if writeErr.Code == db.ErrDuplicateKeyCode {
+				duplicates++
+			}
+		}
+		atomic.AddUint64(&imp.duplicateKeyCount, duplicates)
 	}
 }
 
-func (imp *MongoImport) importDocument(inserter *db.BufferedBulkInserter, document bson.D) error {
+func (imp *MongoImport) importDocument(inserter *db.BufferedBulkInserter, doc InputDocument) error {
 	var result *mongo.BulkWriteResult
 	var err error
 
+	document := doc.Document
+	if imp.rejects != nil {
+		inserter.TagNext(doc.Source)
+		// clear the tag if the document isn't written
+		defer inserter.TagNext(nil)
+	}
+
+	// Non-insert modes always maintain insertion order with a single worker,
+	// so this is the document's position in the input.
+	docNum := atomic.AddUint64(&imp.documentsRead, 1)
 	selector := constructUpsertDocument(imp.upsertFields, document)
 
 	if imp.IngestOptions.Mode == modeInsert {
+		if imp.existing != nil {
+			exists, err := imp.existing.contains(document)
+			if err != nil {
+				return err
+			}
+			if exists {
+				atomic.AddUint64(&imp.skippedCount, 1)
+				return nil
+			}
+		}
 		result, err = inserter.Insert(document)
 	} else if imp.IngestOptions.Mode == modeUpsert {
 		if selector == nil {
-			result, err = imp.fallbackToInsert(inserter, document)
+			result, err = imp.fallbackToInsert(inserter, doc, docNum)
 		} else {
 			result, err = inserter.Replace(selector, document)
 		}
 	} else if imp.IngestOptions.Mode == modeMerge {
 		if selector == nil {
-			result, err = imp.fallbackToInsert(inserter, document)
+			result, err = imp.fallbackToInsert(inserter, doc, docNum)
 		} else {
 			updateDoc := bson.D{{"$set", document}}
 			result, err = inserter.Update(selector, updateDoc)
 		}
-	} else if imp.IngestOptions.Mode == modeDelete {
+	} else if imp.IngestOptions.Mode == modeInsertIfAbsent {
 		if selector == nil {
-			log.Logvf(log.Info, "Could not construct selector from %v, skipping document", imp.upsertFields)
+			result, err = imp.fallbackToInsert(inserter, doc, docNum)
 		} else {
-			result, err = inserter.Delete(selector, document)
+			updateDoc := bson.D{{"$setOnInsert", document}}
+			result, err = inserter.Update(selector, updateDoc)
 		}
+	} else if imp.IngestOptions.Mode == modeDelete {
+		if selector == nil {
+			log.Logvf(
+				log.Info,
+				"upsert field '%v' not found in %v, skipping document",
+				missingUpsertField(imp.upsertFields, document),
+				describeInputDocument(doc, docNum),
+			)
+			return nil
+		}
+		result, err = inserter.Delete(selector, document)
 	} else {
-		err = fmt.Errorf("Invalid mode: %v", imp.IngestOptions.Mode)
+		return fmt.Errorf("Invalid mode: %v", imp.IngestOptions.Mode)
 	}
+	atomic.AddUint64(&imp.sentCount, 1)
 
 	// Update success and failure counts
 	imp.updateCounts(result, err)
@@ -540,17 +842,28 @@ func (imp *MongoImport) importDocument(inserter *db.BufferedBulkInserter, docume
 
 func (imp *MongoImport) fallbackToInsert(
 	inserter *db.BufferedBulkInserter,
-	document bson.D,
+	doc InputDocument,
+	docNum uint64,
 ) (result *mongo.BulkWriteResult, err error) {
 	log.Logvf(
 		log.Info,
-		"Could not construct selector from %v, falling back to insert mode",
-		imp.upsertFields,
+		"upsert field '%v' not found in %v, falling back to insert mode",
+		missingUpsertField(imp.upsertFields, doc.Document),
+		describeInputDocument(doc, docNum),
 	)
-	result, err = inserter.Insert(document)
+	result, err = inserter.Insert(doc.Document)
 	return
 }
 
+// describeInputDocument names where doc is in the input for log messages: its
+// line if the input type tracks lines, or else its position docNum.
+func describeInputDocument(doc InputDocument, docNum uint64) string {
+	if doc.Line > 0 {
+		return fmt.Sprintf("the document on line %v", doc.Line)
+	}
+	return fmt.Sprintf("document #%v", docNum)
+}
+
 func splitInlineHeader(header string) (headers []string) {
 	var level uint8
 	var currentField string
@@ -573,6 +886,12 @@ func splitInlineHeader(header string) (headers []string) {
 
 // getInputReader returns an implementation of InputReader based on the input type.
 func (imp *MongoImport) getInputReader(in io.Reader) (InputReader, error) {
+	return imp.newInputReader(in, imp.IngestOptions.NumDecodingWorkers)
+}
+
+// newInputReader returns an implementation of InputReader based on the input
+// type, which decodes documents with numDecoders goroutines.
+func (imp *MongoImport) newInputReader(in io.Reader, numDecoders int) (InputReader, error) {
 	var colSpecs []ColumnSpec
 	var headers []string
 	var err error
@@ -603,22 +922,45 @@ func (imp *MongoImport) getInputReader(in io.Reader) (InputReader, error) {
 	out := os.Stdout
 
 	ignoreBlanks := imp.IngestOptions.IgnoreBlanks && imp.InputOptions.Type != JSON
+	keepSource := imp.IngestOptions.RejectFile != ""
 	if imp.InputOptions.Type == CSV {
-		return NewCSVInputReader(
+		r := NewCSVInputReader(
 			colSpecs,
 			in,
 			out,
-			imp.IngestOptions.NumDecodingWorkers,
+			numDecoders,
 			ignoreBlanks,
 			imp.InputOptions.UseArrayIndexFields,
-		), nil
+		)
+		r.checkFieldCount = imp.InputOptions.ParseGrace != ""
+		r.parseGrace = ParsePG(imp.InputOptions.ParseGrace)
+		r.keepSource = keepSource
+		return r, nil
 	} else if imp.InputOptions.Type == TSV {
-		return NewTSVInputReader(colSpecs, in, out, imp.IngestOptions.NumDecodingWorkers, ignoreBlanks, imp.InputOptions.UseArrayIndexFields), nil
+		r := NewTSVInputReader(
+			colSpecs,
+			in,
+			out,
+			numDecoders,
+			ignoreBlanks,
+			imp.InputOptions.UseArrayIndexFields,
+		)
+		r.checkFieldCount = imp.InputOptions.ParseGrace != ""
+		r.parseGrace = ParsePG(imp.InputOptions.ParseGrace)
+		r.keepSource = keepSource
+		return r, nil
 	}
-	return NewJSONInputReader(
+	r := NewJSONInputReader(
 		imp.InputOptions.JSONArray,
 		imp.InputOptions.Legacy,
 		in,
-		imp.IngestOptions.NumDecodingWorkers,
-	), nil
+		numDecoders,
+	)
+	r.strictKeys = imp.InputOptions.StrictJSON
+	r.inferrer = imp.inferrer
+	r.keepSource = keepSource
+	if imp.InputOptions.JSONInputFormat != "" {
+		r.setFormat(imp.InputOptions.JSONInputFormat)
+	}
+	return r, nil
 }
@@ -8,14 +8,21 @@ package mongoimport
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/options"
@@ -24,7 +31,9 @@ import (
 	"github.com/mongodb/mongo-tools/common/util"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	mopt "go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 const (
@@ -117,10 +126,8 @@ func getBasicToolOptions() *options.ToolOptions {
 
 func newOptions() Options {
 	return Options{
-		ToolOptions: getBasicToolOptions(),
-		InputOptions: &InputOptions{
-			ParseGrace: "stop",
-		},
+		ToolOptions:   getBasicToolOptions(),
+		InputOptions:  &InputOptions{},
 		IngestOptions: &IngestOptions{},
 	}
 }
@@ -133,9 +140,7 @@ func NewMongoImport() (*MongoImport, error) {
 // Use this for tests that don't communicate with the server (e.g. options parsing tests).
 func NewMockMongoImport() *MongoImport {
 	toolOptions := getBasicToolOptions()
-	inputOptions := &InputOptions{
-		ParseGrace: "stop",
-	}
+	inputOptions := &InputOptions{}
 	ingestOptions := &IngestOptions{}
 
 	return &MongoImport{
@@ -225,6 +230,32 @@ func TestMongoImportValidateSettings(t *testing.T) {
 			So(imp.validateSettings(), ShouldBeNil)
 		})
 
+		Convey("--skipExisting should only be allowed with --mode=insert", func() {
+			imp := NewMockMongoImport()
+			imp.IngestOptions.SkipExisting = "_id"
+			So(imp.validateSettings(), ShouldBeNil)
+
+			imp = NewMockMongoImport()
+			imp.IngestOptions.SkipExisting = "a.b"
+			imp.IngestOptions.Mode = modeUpsert
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.IngestOptions.SkipExisting = "a..b"
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--rejectFile should need an acknowledged write concern", func() {
+			imp := NewMockMongoImport()
+			imp.IngestOptions.RejectFile = "rejects.json"
+			So(imp.validateSettings(), ShouldBeNil)
+
+			imp = NewMockMongoImport()
+			imp.IngestOptions.RejectFile = "rejects.json"
+			imp.ToolOptions.WriteConcern = writeconcern.Unacknowledged()
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
 		Convey("no error should be thrown if no input type is supplied", func() {
 			imp := NewMockMongoImport()
 			So(imp.validateSettings(), ShouldBeNil)
@@ -336,6 +367,27 @@ func TestMongoImportValidateSettings(t *testing.T) {
 			So(imp.upsertFields, ShouldResemble, []string{"_id"})
 		})
 
+		Convey("if --mode=insertIfAbsent is used without --upsertFields, _id should be set "+
+			"as the upsert field and insertion order should be maintained", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.HeaderLine = true
+			imp.InputOptions.Type = CSV
+			imp.IngestOptions.Mode = modeInsertIfAbsent
+			So(imp.validateSettings(), ShouldBeNil)
+			So(imp.upsertFields, ShouldResemble, []string{"_id"})
+			So(imp.IngestOptions.MaintainInsertionOrder, ShouldBeTrue)
+		})
+
+		Convey("an error should be thrown if --skipExisting is used with "+
+			"--mode=insertIfAbsent", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.HeaderLine = true
+			imp.InputOptions.Type = CSV
+			imp.IngestOptions.Mode = modeInsertIfAbsent
+			imp.IngestOptions.SkipExisting = "_id"
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
 		Convey("if --mode=delete is used without --upsertFields, _id should be set as "+
 			"the upsert field", func() {
 			imp := NewMockMongoImport()
@@ -427,6 +479,83 @@ func TestMongoImportValidateSettings(t *testing.T) {
 				So(imp.validateSettings(), ShouldNotBeNil)
 			},
 		)
+
+		Convey("--jsonInputFormat should be a known format for JSON input", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.JSONInputFormat = JSONFormatCanonical
+			So(imp.validateSettings(), ShouldBeNil)
+
+			imp.InputOptions.Legacy = true
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.JSONInputFormat = "shell"
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.Type = CSV
+			fieldFile := "test.csv"
+			imp.InputOptions.FieldFile = &fieldFile
+			imp.InputOptions.JSONInputFormat = JSONFormatRelaxed
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--strictJSON should only be allowed for JSON input", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.StrictJSON = true
+			So(imp.validateSettings(), ShouldBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.Type = CSV
+			fieldFile := "test.csv"
+			imp.InputOptions.FieldFile = &fieldFile
+			imp.InputOptions.StrictJSON = true
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--inferTypes should only be allowed for JSON input", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.InferTypes = "price,stats.*"
+			So(imp.validateSettings(), ShouldBeNil)
+			So(imp.inferrer, ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.InferTypes = "price,"
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.InferTypes = "[price"
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.Type = CSV
+			fieldFile := "test.csv"
+			imp.InputOptions.FieldFile = &fieldFile
+			imp.InputOptions.InferTypes = "*"
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--dir should name the collection and reject --file", func() {
+			imp := NewMockMongoImport()
+			imp.ToolOptions.Namespace.Collection = ""
+			imp.InputOptions.Dir = "exports/people/"
+			imp.InputOptions.Glob = "*.json"
+			So(imp.validateSettings(), ShouldBeNil)
+			So(imp.ToolOptions.Namespace.Collection, ShouldEqual, "people")
+
+			imp.InputOptions.File = "input.json"
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
+
+		Convey("--glob should require --dir and a valid pattern", func() {
+			imp := NewMockMongoImport()
+			imp.InputOptions.Glob = "*.json"
+			So(imp.validateSettings(), ShouldNotBeNil)
+
+			imp.InputOptions.Dir = "exports"
+			imp.InputOptions.Glob = "[.json"
+			So(imp.validateSettings(), ShouldNotBeNil)
+		})
 	})
 }
 
@@ -458,9 +587,109 @@ func TestGetSourceReader(t *testing.T) {
 				_, _, err := imp.getSourceReader()
 				So(err, ShouldBeNil)
 			})
+
+			Convey("a local file should be decompressed if --gzip is set", func() {
+				path := filepath.Join(t.TempDir(), "input.json.gz")
+				So(os.WriteFile(path, gzipBytes(`{"a":1}`), 0o644), ShouldBeNil)
+
+				imp := NewMockMongoImport()
+				imp.InputOptions.File = path
+				imp.InputOptions.Gzip = true
+				source, _, err := imp.getSourceReader()
+				So(err, ShouldBeNil)
+				defer source.Close()
+				data, err := io.ReadAll(source)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldEqual, `{"a":1}`)
+			})
 		})
 }
 
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, _ = gzw.Write([]byte(s))
+	_ = gzw.Close()
+	return buf.Bytes()
+}
+
+func TestGetSourceReaderFromURL(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/plain.json":
+			_, _ = w.Write([]byte(`{"a":1}`))
+		case "/encoded.json":
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(gzipBytes(`{"a":2}`))
+		case "/unlabeled.json.gz":
+			_, _ = w.Write(gzipBytes(`{"a":3}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	read := func(path string, gzip bool) (string, int64, error) {
+		imp := NewMockMongoImport()
+		imp.InputOptions.File = server.URL + path
+		imp.InputOptions.Gzip = gzip
+		source, size, err := imp.getSourceReader()
+		if err != nil {
+			return "", size, err
+		}
+		defer source.Close()
+		data, err := io.ReadAll(source)
+		return string(data), size, err
+	}
+
+	Convey("Given an HTTP URL as the input file", t, func() {
+		Convey("the response body should be streamed as is", func() {
+			data, size, err := read("/plain.json", false)
+			So(err, ShouldBeNil)
+			So(data, ShouldEqual, `{"a":1}`)
+			So(size, ShouldEqual, 7)
+		})
+
+		Convey("a body with a gzip Content-Encoding should be decompressed", func() {
+			data, _, err := read("/encoded.json", false)
+			So(err, ShouldBeNil)
+			So(data, ShouldEqual, `{"a":2}`)
+		})
+
+		Convey("--gzip should decompress a body without a Content-Encoding", func() {
+			data, _, err := read("/unlabeled.json.gz", true)
+			So(err, ShouldBeNil)
+			So(data, ShouldEqual, `{"a":3}`)
+		})
+
+		Convey("a response other than 200 OK should be an error", func() {
+			_, _, err := read("/missing.json", false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "server responded with 404 Not Found")
+		})
+
+		Convey("the default collection name should come from the URL path", func() {
+			So(urlBaseName(server.URL+"/dir/data.json?token=x"), ShouldEqual, "data.json")
+		})
+	})
+
+	Convey("Given a server that doesn't respond", t, func() {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer slow.Close()
+
+		Convey("the request should time out", func() {
+			client := newHTTPClient(time.Second, 50*time.Millisecond)
+			_, _, err := openURL(client, slow.URL+"/data.json", false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "timeout awaiting response headers")
+		})
+	})
+}
+
 func TestGetInputReader(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 	Convey("Given a io.Reader on calling getInputReader", t, func() {
@@ -531,6 +760,40 @@ func TestGetInputReader(t *testing.T) {
 			_, err := imp.getInputReader(&os.File{})
 			So(err, ShouldBeNil)
 		})
+		Convey("CSV rows with the wrong number of fields should only be checked if "+
+			"--parseGrace is set", func() {
+			readAll := func(imp *MongoImport, input string) ([]bson.D, error) {
+				r, err := imp.getInputReader(bytes.NewReader([]byte(input)))
+				if err != nil {
+					return nil, err
+				}
+				docs := make(chan InputDocument, 10)
+				err = r.StreamDocument(true, docs)
+				var result []bson.D
+				for doc := range docs {
+					result = append(result, doc.Document)
+				}
+				return result, err
+			}
+
+			imp := NewMockMongoImport()
+			imp.InputOptions.Type = CSV
+			fields := "a.int32(),b.string()"
+			imp.InputOptions.Fields = &fields
+			imp.InputOptions.ColumnsHaveTypes = true
+			docs, err := readAll(imp, "1\n")
+			So(err, ShouldBeNil)
+			So(docs, ShouldResemble, []bson.D{{{"a", int32(1)}}})
+
+			imp = NewMockMongoImport()
+			imp.InputOptions.Type = CSV
+			fields = "a,b"
+			imp.InputOptions.Fields = &fields
+			imp.InputOptions.ParseGrace = "stop"
+			_, err = readAll(imp, "1,x\n2\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "wrong number of fields on line 2")
+		})
 		Convey("an error should be thrown if --fieldFile fields are invalid", func() {
 			imp := NewMockMongoImport()
 			fieldFile := "testdata/test_fields_invalid.txt"
@@ -932,6 +1195,43 @@ func TestImportDocuments(t *testing.T) {
 			}
 			So(checkOnlyHasDocuments(imp.SessionProvider, expectedDocuments), ShouldBeNil)
 		})
+		Convey("CSV import with --mode=insertIfAbsent should leave existing documents "+
+			"unchanged and be safe to re-run", func() {
+			imp, err := NewMongoImport()
+			So(err, ShouldBeNil)
+			imp.InputOptions.Type = CSV
+			imp.InputOptions.File = "testdata/test_duplicate.csv"
+			fields := "_id,b,c"
+			imp.InputOptions.Fields = &fields
+			imp.IngestOptions.Mode = modeInsertIfAbsent
+			imp.upsertFields = []string{"_id"}
+			numProcessed, numFailed, err := imp.ImportDocuments()
+			So(err, ShouldBeNil)
+			So(numProcessed, ShouldEqual, 4)
+			So(numFailed, ShouldEqual, 0)
+			So(imp.SkippedCount(), ShouldEqual, 1)
+			expectedDocuments := []bson.M{
+				{"_id": int32(1), "b": int32(2), "c": int32(3)},
+				{"_id": int32(3), "b": 5.4, "c": "string"},
+				{"_id": int32(5), "b": int32(6), "c": int32(6)},
+				{"_id": int32(8), "b": int32(6), "c": int32(6)},
+			}
+			So(checkOnlyHasDocuments(imp.SessionProvider, expectedDocuments), ShouldBeNil)
+
+			imp, err = NewMongoImport()
+			So(err, ShouldBeNil)
+			imp.InputOptions.Type = CSV
+			imp.InputOptions.File = "testdata/test_duplicate.csv"
+			imp.InputOptions.Fields = &fields
+			imp.IngestOptions.Mode = modeInsertIfAbsent
+			imp.upsertFields = []string{"_id"}
+			numProcessed, numFailed, err = imp.ImportDocuments()
+			So(err, ShouldBeNil)
+			So(numProcessed, ShouldEqual, 0)
+			So(numFailed, ShouldEqual, 0)
+			So(imp.SkippedCount(), ShouldEqual, 5)
+			So(checkOnlyHasDocuments(imp.SessionProvider, expectedDocuments), ShouldBeNil)
+		})
 		Convey("an error should be thrown for CSV import on test data with "+
 			"duplicate _id if --stopOnError is set", func() {
 			imp, err := NewMongoImport()
@@ -970,7 +1270,7 @@ func TestImportDocuments(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test_plain.json")
 			So(err, ShouldBeNil)
 			jsonInputReader := NewJSONInputReader(true, true, fileHandle, 1)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(jsonInputReader.StreamDocument(true, docChan), ShouldNotBeNil)
 		})
 		Convey("an error should be thrown for invalid CSV import on test data", func() {
@@ -1160,37 +1460,38 @@ func TestImportDocuments(t *testing.T) {
 			nestedFieldsTestHelper(
 				"_id,a.0,a.0\n1,2,3",
 				nil,
-				fmt.Errorf(
-					"array index error with field 'a.0': array indexes in fields must start from 0 and increase sequentially",
-				),
+				fmt.Errorf("fields cannot be identical: 'a.0' and 'a.0'"),
 			),
 		)
-		Convey("With --useArrayIndexFields: Array fields not starting at 0 should throw an error",
+		Convey("With --useArrayIndexFields: Array fields out of order should insert an array",
 			nestedFieldsTestHelper(
 				"_id,a.1,a.0\n1,2,3",
+				[]bson.M{
+					{"_id": int32(1), "a": bson.A{int32(3), int32(2)}},
+				},
 				nil,
-				fmt.Errorf(
-					"array index error with field 'a.1': array indexes in fields must start from 0 and increase sequentially",
-				),
 			),
 		)
-		Convey("With --useArrayIndexFields: Array fields skipping an index should throw an error",
+		Convey("With --useArrayIndexFields: Array fields skipping an index should insert null",
 			nestedFieldsTestHelper(
 				"_id,a.0,a.2\n1,2,3",
+				[]bson.M{
+					{"_id": int32(1), "a": bson.A{int32(2), nil, int32(3)}},
+				},
 				nil,
-				fmt.Errorf(
-					"array index error with field 'a.2': array indexes in fields must start from 0 and increase sequentially",
-				),
 			),
 		)
 		Convey(
-			"With --useArrayIndexFields: Array fields with sub documents skipping an index should throw an error",
+			"With --useArrayIndexFields: Array fields with sub documents skipping an index should insert null",
 			nestedFieldsTestHelper(
-				"_id,a.0.a,a.2.a\n1,2,3",
+				"_id,a.2.a,a.0.a\n1,2,3",
+				[]bson.M{
+					{
+						"_id": int32(1),
+						"a":   bson.A{bson.M{"a": int32(3)}, nil, bson.M{"a": int32(2)}},
+					},
+				},
 				nil,
-				fmt.Errorf(
-					"array index error with field 'a.2.a': array indexes in fields must start from 0 and increase sequentially",
-				),
 			),
 		)
 		Convey(
@@ -1249,23 +1550,23 @@ func TestImportDocuments(t *testing.T) {
 				fmt.Errorf("fields 'a./' and 'a.0' are incompatible"),
 			),
 		)
-		Convey("With --useArrayIndexFields: Indexes in fields must start from 0",
+		Convey("With --useArrayIndexFields: Indexes in fields don't have to start from 0",
 			nestedFieldsTestHelper(
 				"_id,a,b.1\n1,2,3",
+				[]bson.M{
+					{"_id": int32(1), "a": int32(2), "b": bson.A{nil, int32(3)}},
+				},
 				nil,
-				fmt.Errorf(
-					"array index error with field 'b.1': array indexes in fields must start from 0 and increase sequentially",
-				),
 			),
 		)
 		Convey(
-			"With --useArrayIndexFields: Indexes in fields must start from 0 (last field same length)",
+			"With --useArrayIndexFields: Indexes in fields don't have to start from 0 (last field same length)",
 			nestedFieldsTestHelper(
 				"_id,a.b,b.1\n1,2,3",
+				[]bson.M{
+					{"_id": int32(1), "a": bson.M{"b": int32(2)}, "b": bson.A{nil, int32(3)}},
+				},
 				nil,
-				fmt.Errorf(
-					"array index error with field 'b.1': array indexes in fields must start from 0 and increase sequentially",
-				),
 			),
 		)
 		Convey(
@@ -1280,9 +1581,7 @@ func TestImportDocuments(t *testing.T) {
 			nestedFieldsTestHelper(
 				"_id,a.0,a.1,a.2,a.0\n1,2,3,4,5",
 				nil,
-				fmt.Errorf(
-					"array index error with field 'a.0': array indexes in fields must start from 0 and increase sequentially",
-				),
+				fmt.Errorf("fields cannot be identical: 'a.0' and 'a.0'"),
 			),
 		)
 		Convey("With --useArrayIndexFields: Array entries of different types should throw an error",
@@ -1362,6 +1661,38 @@ func TestHiddenOptionsDefaults(t *testing.T) {
 	})
 }
 
+func TestUpdateCounts(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a mongoimport counting write results", t, func() {
+		imp := NewMockMongoImport()
+
+		imp.updateCounts(&mongo.BulkWriteResult{InsertedCount: 3, UpsertedCount: 1}, nil)
+		imp.updateCounts(nil, mongo.BulkWriteException{
+			WriteErrors: []mongo.BulkWriteError{
+				{WriteError: mongo.WriteError{Code: db.ErrDuplicateKeyCode}},
+				{WriteError: mongo.WriteError{Code: 121}},
+				{WriteError: mongo.WriteError{Code: db.ErrDuplicateKeyCode}},
+			},
+		})
+
+		So(imp.processedCount, ShouldEqual, 4)
+		So(imp.failureCount, ShouldEqual, 3)
+		So(imp.DuplicateKeyCount(), ShouldEqual, 2)
+		So(imp.SkippedCount(), ShouldEqual, 0)
+	})
+
+	Convey("With --mode=insertIfAbsent, matched documents should be counted as skipped", t, func() {
+		imp := NewMockMongoImport()
+		imp.IngestOptions.Mode = modeInsertIfAbsent
+
+		imp.updateCounts(&mongo.BulkWriteResult{MatchedCount: 2, UpsertedCount: 3}, nil)
+
+		So(imp.processedCount, ShouldEqual, 3)
+		So(imp.SkippedCount(), ShouldEqual, 2)
+	})
+}
+
 // generateTestData creates the files used in TestImportMIOSOE.
 func generateTestData() error {
 	// If file exists already, don't both regenerating it.
@@ -1430,12 +1761,29 @@ func TestImportMIOSOE(t *testing.T) {
 
 		So(nSuccess, ShouldEqual, 20000)
 		So(nFailure, ShouldEqual, 1)
+		So(imp.DuplicateKeyCount(), ShouldEqual, 1)
 
 		count, err := coll.CountDocuments(context.Background(), bson.M{})
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 20000)
 	})
 
+	Convey("unacknowledged writes report the documents sent", t, func() {
+		imp, err := getImportWithArgs(mioSoeFile,
+			"--collection", coll.Name(),
+			"--db", database.Name(),
+			"--drop",
+			"--writeConcern", "{w:0}")
+		So(err, ShouldBeNil)
+		So(imp.ToolOptions.WriteConcern.Acknowledged(), ShouldBeFalse)
+
+		nSuccess, nFailure, err := imp.ImportDocuments()
+		So(err, ShouldBeNil)
+
+		So(nSuccess, ShouldEqual, 20001)
+		So(nFailure, ShouldEqual, 0)
+	})
+
 	Convey("--maintainInsertionOrder stops exactly on dup key errors", t, func() {
 		imp, err := getImportWithArgs(mioSoeFile,
 			"--collection", coll.Name(),
@@ -1480,3 +1828,88 @@ func TestImportMIOSOE(t *testing.T) {
 
 	_ = database.Drop(context.Background())
 }
+
+func TestImportRejectFile(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+
+	client, err := testutil.GetBareSession()
+	if err != nil {
+		t.Fatalf("No server available?? (%v)", err)
+	}
+	coll := client.Database(testDb).Collection(testCollection)
+
+	dir := t.TempDir()
+	rejectFile := filepath.Join(dir, "rejects.json")
+	readRejects := func() []map[string]string {
+		contents, err := os.ReadFile(rejectFile)
+		So(err, ShouldBeNil)
+		var rejects []map[string]string
+		scanner := bufio.NewScanner(bytes.NewReader(contents))
+		for scanner.Scan() {
+			var reject map[string]string
+			So(json.Unmarshal(scanner.Bytes(), &reject), ShouldBeNil)
+			rejects = append(rejects, reject)
+		}
+		return rejects
+	}
+
+	Convey("With --rejectFile", t, func() {
+		Convey("CSV rows with duplicate keys should be written with their error", func() {
+			input := filepath.Join(dir, "dups.csv")
+			So(os.WriteFile(input, []byte("_id,name\n1,a\n2,b\n1,\"c, again\"\n3,d\n2,e\n"), 0644), ShouldBeNil)
+
+			imp, err := getImportWithArgs(input,
+				"--type", "csv", "--headerline",
+				"--collection", coll.Name(),
+				"--db", testDb,
+				"--drop",
+				"--rejectFile", rejectFile)
+			So(err, ShouldBeNil)
+
+			nSuccess, nFailure, err := imp.ImportDocuments()
+			So(err, ShouldBeNil)
+			So(nSuccess, ShouldEqual, 3)
+			So(nFailure, ShouldEqual, 2)
+			So(imp.RejectedCount(), ShouldEqual, 2)
+
+			rejects := readRejects()
+			So(len(rejects), ShouldEqual, 2)
+			sources := []string{rejects[0]["source"], rejects[1]["source"]}
+			So(sources, ShouldContain, `1,"c, again"`)
+			So(sources, ShouldContain, "2,e")
+			for _, reject := range rejects {
+				So(reject["error"], ShouldContainSubstring, "E11000")
+			}
+		})
+
+		Convey("JSON documents that fail validation should be written as they appeared", func() {
+			So(coll.Drop(context.Background()), ShouldBeNil)
+			err := client.Database(testDb).CreateCollection(context.Background(), coll.Name(),
+				mopt.CreateCollection().SetValidator(bson.D{{"n", bson.D{{"$type", "int"}}}}))
+			So(err, ShouldBeNil)
+
+			input := filepath.Join(dir, "invalid.json")
+			So(os.WriteFile(input, []byte("{\"n\": 1}\n{\"n\": \"x\"}\n{\"n\": 2}\n"), 0644), ShouldBeNil)
+
+			imp, err := getImportWithArgs(input,
+				"--collection", coll.Name(),
+				"--db", testDb,
+				"--rejectFile", rejectFile)
+			So(err, ShouldBeNil)
+
+			nSuccess, nFailure, err := imp.ImportDocuments()
+			So(err, ShouldBeNil)
+			So(nSuccess, ShouldEqual, 2)
+			So(nFailure, ShouldEqual, 1)
+
+			rejects := readRejects()
+			So(len(rejects), ShouldEqual, 1)
+			So(rejects[0]["source"], ShouldEqual, `{"n": "x"}`)
+			So(rejects[0]["error"], ShouldContainSubstring, "validation")
+		})
+
+		Reset(func() {
+			So(coll.Drop(context.Background()), ShouldBeNil)
+		})
+	})
+}
@@ -31,7 +31,16 @@ type InputOptions struct {
 	FieldFile *string `long:"fieldFile" value-name:"<filename>" description:"file with field names - 1 per line"`
 
 	// Specifies the location and name of a file containing the data to import.
-	File string `long:"file" value-name:"<filename>" description:"file to import from; if not specified, stdin is used"`
+	File string `long:"file" value-name:"<filename>" description:"file or HTTP(S) URL to import from; if not specified, stdin is used"`
+
+	// Specifies a directory of files to import into the same collection.
+	Dir string `long:"dir" value-name:"<directory-path>" description:"directory of files to import into the same collection, read in parallel unless --maintainInsertionOrder is set; only the files directly in the directory are imported"`
+
+	// Restricts the files imported from --dir to those whose names match a pattern.
+	Glob string `long:"glob" value-name:"<pattern>" description:"with --dir, only import files whose names match the pattern, e.g. '*.json'"`
+
+	// Decompresses the input source with gzip, regardless of its Content-Encoding.
+	Gzip bool `long:"gzip" description:"decompress gzipped input; URLs sent with 'Content-Encoding: gzip' are decompressed without this option"`
 
 	// Treats the input source's first line as field list (csv and tsv only).
 	HeaderLine bool `long:"headerline" description:"use first line in input source as the field list (CSV and TSV only)"`
@@ -39,19 +48,30 @@ type InputOptions struct {
 	// Indicates that the underlying input source contains a single JSON array with the documents to import.
 	JSONArray bool `long:"jsonArray" description:"treat input source as a JSON array"`
 
-	// Indicates how to handle type coercion failures
-	ParseGrace string `long:"parseGrace" value-name:"<grace>" default:"stop" description:"controls behavior when type coercion fails - one of: autoCast, skipField, skipRow, stop"`
+	// Indicates how to handle type coercion failures and rows with the wrong number of fields.
+	// We don't set `default: stop` here since rows with the wrong number of fields are only
+	// checked if --parseGrace is set.
+	ParseGrace string `long:"parseGrace" value-name:"<grace>" description:"controls behavior when type coercion fails, or, if set, when a CSV or TSV row has the wrong number of fields - one of: autoCast, skipField, skipRow (or its alias skip), stop (default: stop)"`
 
 	// Specifies the file type to import. The default format is JSON, but it’s possible to import CSV and TSV files.
 	Type string `long:"type" value-name:"<type>" default:"json" default-mask:"-" description:"input format to import: json, csv, or tsv"`
 
 	// Indicates that field names include type descriptions
-	ColumnsHaveTypes bool `long:"columnsHaveTypes" description:"indicates that the field list (from --fields, --fieldsFile, or --headerline) specifies types; They must be in the form of '<colName>.<type>(<arg>)'. The type can be one of: auto, binary, boolean, date, date_go, date_ms, date_oracle, decimal, double, int32, int64, string. For each of the date types, the argument is a datetime layout string. For the binary type, the argument can be one of: base32, base64, hex. All other types take an empty argument. Only valid for CSV and TSV imports. e.g. zipcode.string(), thumbnail.binary(base64)"`
+	ColumnsHaveTypes bool `long:"columnsHaveTypes" description:"indicates that the field list (from --fields, --fieldsFile, or --headerline) specifies types; They must be in the form of '<colName>.<type>(<arg>)'. The type can be one of: auto, binary, bool, boolean, date, date_go, date_ms, date_oracle, decimal, double, int32, int64, objectid, string. For each of the date types, the argument is a datetime layout string. For the binary type, the argument can be one of: base32, base64, hex. All other types take an empty argument. Only valid for CSV and TSV imports. e.g. zipcode.string(), thumbnail.binary(base64)"`
 
 	// Indicates that the legacy extended JSON format should be used to parse JSON documents. Defaults to false.
 	Legacy bool `long:"legacy" description:"use the legacy extended JSON format"`
 
-	UseArrayIndexFields bool `long:"useArrayIndexFields" description:"indicates that field names may include array indexes that should be used to construct arrays during import (e.g. foo.0,foo.1). Indexes must start from 0 and increase sequentially (foo.1,foo.0 would fail)."`
+	// Sets the extended JSON format to parse JSON documents as, instead of deciding by --legacy.
+	JSONInputFormat string `long:"jsonInputFormat" value-name:"<format>" description:"the extended JSON format of the input: legacy (which accepts mongo shell values such as ObjectId(...) and ISODate(...)), canonical, or relaxed; with canonical or relaxed, shell values are an error (JSON only)"`
+
+	// Indicates that a key repeated within a JSON object is an error rather than overriding the earlier value.
+	StrictJSON bool `long:"strictJSON" description:"fail on JSON objects that contain the same key more than once, instead of keeping the last value (JSON only)"`
+
+	UseArrayIndexFields bool `long:"useArrayIndexFields" description:"indicates that field names may include array indexes that should be used to construct arrays during import (e.g. foo.0,foo.1). Indexes may be in any order, and the elements of an array that no field refers to are set to null (foo.2,foo.0 gives [<foo.0>, null, <foo.2>])."`
+
+	// Converts string values that are clearly of another type in the matching fields.
+	InferTypes string `long:"inferTypes" value-name:"<pattern>[,<pattern>]*" optional:"true" optional-value:"*" description:"convert string values that are clearly integers, doubles, booleans (true or false) or RFC 3339 dates, such as \"42\" or \"2024-01-02T03:04:05Z\", to those types, in the fields matching the comma-separated patterns and the fields they contain; '*' matches any part of a field name except a dot, and with no patterns every field is converted. Values such as \"007\", \"+1\" or \"1.\" are left as strings (JSON only)"`
 }
 
 // Name returns a description of the InputOptions struct.
@@ -65,7 +85,7 @@ type IngestOptions struct {
 	Drop bool `long:"drop" description:"drop collection before inserting documents"`
 
 	// Ignores fields with empty values in CSV and TSV imports.
-	IgnoreBlanks bool `long:"ignoreBlanks" description:"ignore fields with empty values in CSV and TSV"`
+	IgnoreBlanks bool `long:"ignoreBlanks" description:"ignore fields with empty values in CSV and TSV (quoted empty strings in CSV are kept)"`
 
 	// Indicates that documents will be inserted in the order of their appearance in the input source.
 	MaintainInsertionOrder bool `long:"maintainInsertionOrder" description:"insert the documents in the order of their appearance in the input source. By default the insertions will be performed in an arbitrary order. Setting this flag also enables the behavior of --stopOnError and restricts NumInsertionWorkers to 1."`
@@ -82,15 +102,22 @@ type IngestOptions struct {
 	// "upsert": Insert new documents or replace existing ones.
 	// "merge": Insert new documents or modify existing ones; Preserve values in the database that are not overwritten.
 	// "delete": Skip new documents or delete existing ones that match --upsertFields.
+	// "insertIfAbsent": Insert new documents and leave existing ones unchanged.
 	// We don't set `default: insert` here since we need to be able to set mode to upsert if --mode isn't set and --upsertFields is set.
 	//
 	//nolint:staticcheck
-	Mode string `long:"mode" choice:"insert" choice:"upsert" choice:"merge" choice:"delete" description:"insert: insert only, skips matching documents. upsert: insert new documents or replace existing documents. merge: insert new documents or modify existing documents. delete: deletes matching documents only. If upsert fields match more than one document, only one document is deleted. (default: insert)"`
+	Mode string `long:"mode" choice:"insert" choice:"upsert" choice:"merge" choice:"delete" choice:"insertIfAbsent" description:"insert: insert only, skips matching documents. upsert: insert new documents or replace existing documents. merge: insert new documents or modify existing documents. delete: deletes matching documents only. If upsert fields match more than one document, only one document is deleted. insertIfAbsent: insert new documents with an upsert that leaves existing documents unchanged, so that an import can be safely re-run. (default: insert)"`
 
 	Upsert bool `long:"upsert" hidden:"true" description:"(deprecated; same as --mode=upsert) insert or update objects that already exist"`
 
 	// Specifies a list of fields for the query portion of the upsert; defaults to _id field.
-	UpsertFields string `long:"upsertFields" value-name:"<field>[,<field>]*" description:"comma-separated fields for the query part when --mode is set to upsert or merge"`
+	UpsertFields string `long:"upsertFields" value-name:"<field>[,<field>]*" description:"comma-separated fields for the query part when --mode is set to upsert, merge or insertIfAbsent"`
+
+	// Skips input documents whose value for this field already exists in the collection.
+	SkipExisting string `long:"skipExisting" value-name:"<field>" optional:"true" optional-value:"_id" description:"with --mode=insert, skip documents whose value for the given field (default _id) already exists in the collection. Existing values are loaded into an in-memory bloom filter before importing, and documents that match it are checked with a query, so no new document is skipped by mistake"`
+
+	// Writes the input of each document that fails to import, with the error, to this file.
+	RejectFile string `long:"rejectFile" value-name:"<filename>" description:"write each document that the server fails to import (e.g. because of a duplicate key or document validation) to this file, as a line of JSON with the document's original input in 'source' and the error in 'error'"`
 
 	// Sets write concern level for write operations.
 	// By default mongoimport uses a write concern of 'majority'.
@@ -157,7 +184,7 @@ func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, erro
 		)
 	}
 
-	if inputOpts.File == "" {
+	if inputOpts.File == "" && inputOpts.Dir == "" {
 		if len(extraArgs) != 0 {
 			// if --file is not supplied, use the positional argument supplied
 			inputOpts.File = extraArgs[0]
@@ -0,0 +1,97 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mongodb/mongo-tools/common/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// A sourceConverter is a Converter that can also return the input it
+// converts, so that documents which fail to import can be written to the
+// --rejectFile as they appeared in the input.
+type sourceConverter interface {
+	Source() string
+}
+
+// rejectedDocument is a line of the --rejectFile.
+type rejectedDocument struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// rejectWriter writes the documents that the server fails to import to the
+// --rejectFile, one JSON object per line holding the document's original input
+// and the error. It is safe for use by concurrent insertion workers.
+type rejectWriter struct {
+	// count is updated atomically, aligned at the beginning of the struct
+	count uint64
+
+	sync.Mutex
+	file   io.WriteCloser
+	buffer *bufio.Writer
+	err    error
+}
+
+// newRejectWriter creates the reject file at path, replacing any existing
+// file.
+func newRejectWriter(path string) (*rejectWriter, error) {
+	file, err := os.Create(util.ToUniversalPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("error creating --rejectFile: %v", err)
+	}
+	return &rejectWriter{file: file, buffer: bufio.NewWriter(file)}, nil
+}
+
+// reject writes a document that failed with writeErr. Its tag is the source
+// of the document, or nil if it isn't known. It is used as the write error
+// handler of the insertion workers' bulk inserters.
+func (rw *rejectWriter) reject(tag interface{}, writeErr mongo.BulkWriteError) {
+	source, _ := tag.(string)
+	line, err := json.Marshal(rejectedDocument{Source: source, Error: writeErr.Message})
+	atomic.AddUint64(&rw.count, 1)
+
+	rw.Lock()
+	defer rw.Unlock()
+	if rw.err != nil {
+		return
+	}
+	if err == nil {
+		_, err = rw.buffer.Write(append(line, '\n'))
+	}
+	rw.err = err
+}
+
+// Count returns the number of documents rejected.
+func (rw *rejectWriter) Count() uint64 {
+	return atomic.LoadUint64(&rw.count)
+}
+
+// Close flushes and closes the reject file, returning the first error that
+// occurred while writing it.
+func (rw *rejectWriter) Close() error {
+	rw.Lock()
+	defer rw.Unlock()
+	if rw.err == nil {
+		rw.err = rw.buffer.Flush()
+	}
+	if err := rw.file.Close(); err != nil && rw.err == nil {
+		rw.err = err
+	}
+	if rw.err != nil {
+		return fmt.Errorf("error writing --rejectFile: %v", rw.err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestDocumentSource(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With documents that carry their source", t, func() {
+		Convey("each converter should return the input it converts", func() {
+			csvConverter := CSVConverter{data: []string{"1", "a,b", `say "hi"`}}
+			So(csvConverter.Source(), ShouldEqual, `1,"a,b","say ""hi"""`)
+
+			tsvConverter := TSVConverter{data: "1\ta\tb\r\n"}
+			So(tsvConverter.Source(), ShouldEqual, "1\ta\tb")
+
+			jsonConverter := JSONConverter{data: []byte(`{"a": 1}`)}
+			So(jsonConverter.Source(), ShouldEqual, `{"a": 1}`)
+		})
+
+		Convey("streamDocuments should only add sources when asked to", func() {
+			colSpecs := ParseAutoHeaders([]string{"a", "b"})
+			for _, keepSource := range []bool{false, true} {
+				inputChannel := make(chan Converter, 1)
+				outputChannel := make(chan InputDocument, 1)
+				inputChannel <- CSVConverter{colSpecs: colSpecs, data: []string{"1", "2"}}
+				close(inputChannel)
+				So(streamDocuments(true, 1, inputChannel, outputChannel, keepSource), ShouldBeNil)
+
+				doc := <-outputChannel
+				So(doc.Document, ShouldResemble, bson.D{{"a", int32(1)}, {"b", int32(2)}})
+				if keepSource {
+					So(doc.Source, ShouldEqual, "1,2")
+				} else {
+					So(doc.Source, ShouldBeEmpty)
+				}
+			}
+		})
+	})
+}
+
+func TestRejectWriter(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a reject writer", t, func() {
+		path := filepath.Join(t.TempDir(), "rejects.json")
+		rejects, err := newRejectWriter(path)
+		So(err, ShouldBeNil)
+
+		writeErr := func(message string) mongo.BulkWriteError {
+			return mongo.BulkWriteError{WriteError: mongo.WriteError{Code: 11000, Message: message}}
+		}
+
+		Convey("each rejected document should be written as a line of JSON", func() {
+			rejects.reject(`1,"a,b"`, writeErr("E11000 duplicate key"))
+			rejects.reject("{\n  \"_id\": 1\n}", writeErr("validation failed"))
+			rejects.reject(nil, writeErr("no source"))
+			So(rejects.Close(), ShouldBeNil)
+			So(rejects.Count(), ShouldEqual, 3)
+
+			contents, err := os.ReadFile(path)
+			So(err, ShouldBeNil)
+			lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+			So(lines, ShouldResemble, []string{
+				`{"source":"1,\"a,b\"","error":"E11000 duplicate key"}`,
+				`{"source":"{\n  \"_id\": 1\n}","error":"validation failed"}`,
+				`{"source":"","error":"no source"}`,
+			})
+		})
+	})
+
+	Convey("Creating a reject writer in a missing directory should fail", t, func() {
+		_, err := newRejectWriter(filepath.Join(t.TempDir(), "missing", "rejects.json"))
+		So(err, ShouldNotBeNil)
+	})
+}
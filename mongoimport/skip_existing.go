@@ -0,0 +1,230 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/text"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bloomFalsePositiveRate is the rate of false positives that the
+// --skipExisting filter is sized for. Each false positive costs one query.
+const bloomFalsePositiveRate = 0.01
+
+// bloomFilter is a fixed-size set of BSON values that can report false
+// positives but never false negatives. It is safe for concurrent reads once
+// it has been filled.
+type bloomFilter struct {
+	bits      []uint64
+	numHashes uint64
+}
+
+// newBloomFilter returns a bloomFilter sized to hold n values with the given
+// false positive rate.
+func newBloomFilter(n uint64, falsePositiveRate float64) *bloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	numBits := math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	numHashes := math.Max(1, math.Round(numBits/float64(n)*math.Ln2))
+	return &bloomFilter{
+		bits:      make([]uint64, (uint64(numBits)+63)/64),
+		numHashes: uint64(numHashes),
+	}
+}
+
+// positions calls fn with each bit index for the value. The indexes are
+// derived from a single 64-bit hash using double hashing.
+func (f *bloomFilter) positions(t bsontype.Type, data []byte, fn func(uint64)) {
+	t, data = bloomKey(t, data)
+	h := fnv.New64a()
+	_, _ = h.Write([]byte{byte(t)})
+	_, _ = h.Write(data)
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+	numBits := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.numHashes; i++ {
+		fn((h1 + i*h2) % numBits)
+	}
+}
+
+// bloomKey returns the type and data that are hashed for a BSON value.
+// Numbers that compare equal in a query, such as 1, NumberLong(1), 1.0 and
+// NumberDecimal("1"), have the same key. Other values are returned unchanged.
+func bloomKey(t bsontype.Type, data []byte) (bsontype.Type, []byte) {
+	value := bson.RawValue{Type: t, Value: data}
+	var f float64
+	switch t {
+	case bson.TypeInt32:
+		return integerKey(int64(value.Int32()))
+	case bson.TypeInt64:
+		return integerKey(value.Int64())
+	case bson.TypeDouble:
+		f = value.Double()
+	case bson.TypeDecimal128:
+		s := value.Decimal128().String()
+		if r, ok := new(big.Rat).SetString(s); ok {
+			if r.IsInt() && r.Num().IsInt64() {
+				return integerKey(r.Num().Int64())
+			}
+			// a decimal equal to a double converts to exactly that double
+			f, _ = r.Float64()
+		} else {
+			// NaN and Infinity
+			f, _ = strconv.ParseFloat(s, 64)
+		}
+	default:
+		return t, data
+	}
+
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return integerKey(int64(f))
+	}
+	if math.IsNaN(f) {
+		// all NaNs are equal in a query
+		f = math.NaN()
+	}
+	key := make([]byte, 8)
+	binary.LittleEndian.PutUint64(key, math.Float64bits(f))
+	return bson.TypeDouble, key
+}
+
+// integerKey returns the bloom filter key for an integral number.
+func integerKey(n int64) (bsontype.Type, []byte) {
+	key := make([]byte, 8)
+	binary.LittleEndian.PutUint64(key, uint64(n))
+	return bson.TypeInt64, key
+}
+
+// add records a BSON value in the filter.
+func (f *bloomFilter) add(t bsontype.Type, data []byte) {
+	f.positions(t, data, func(bit uint64) {
+		f.bits[bit/64] |= 1 << (bit % 64)
+	})
+}
+
+// mayContain returns false if the BSON value was never added to the filter.
+func (f *bloomFilter) mayContain(t bsontype.Type, data []byte) bool {
+	found := true
+	f.positions(t, data, func(bit uint64) {
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+// sizeInBytes returns the memory used by the filter's bits.
+func (f *bloomFilter) sizeInBytes() int64 {
+	return int64(len(f.bits)) * 8
+}
+
+// existingKeys tracks which values of a field are already present in the
+// target collection, for --skipExisting.
+type existingKeys struct {
+	filter     *bloomFilter
+	field      string
+	collection *mongo.Collection
+
+	// falsePositives counts documents that matched the filter but weren't
+	// found by a query. Should be updated atomically.
+	falsePositives uint64
+}
+
+// loadExistingKeys reads the value of field from every document in the
+// collection into a bloom filter. It returns nil if the collection is empty.
+func loadExistingKeys(collection *mongo.Collection, field string) (*existingKeys, error) {
+	count, err := collection.EstimatedDocumentCount(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("error counting documents: %v", err)
+	}
+	if count == 0 {
+		log.Logvf(log.Info, "collection is empty, no documents will be skipped by --skipExisting")
+		return nil, nil
+	}
+
+	keys := &existingKeys{
+		filter:     newBloomFilter(uint64(count), bloomFalsePositiveRate),
+		field:      field,
+		collection: collection,
+	}
+	projection := bson.D{{field, 1}}
+	if field != "_id" {
+		projection = append(projection, bson.E{"_id", 0})
+	}
+	cursor, err := collection.Find(
+		context.TODO(),
+		bson.D{},
+		mopt.Find().SetProjection(projection),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	path := strings.Split(field, ".")
+	var loaded uint64
+	for cursor.Next(context.TODO()) {
+		value, err := cursor.Current.LookupErr(path...)
+		if err != nil {
+			continue
+		}
+		keys.filter.add(value.Type, value.Value)
+		loaded++
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	log.Logvf(log.Info, "loaded %v existing value(s) of %v into a %v bloom filter",
+		loaded, field, text.FormatByteAmount(keys.filter.sizeInBytes()))
+	return keys, nil
+}
+
+// contains reports whether a document with the same value of the field as
+// document exists in the collection. Only values that match the bloom filter
+// are looked up with a query. Documents without the field never match.
+func (k *existingKeys) contains(document bson.D) (bool, error) {
+	value, ok := lookupUpsertValue(k.field, document)
+	if !ok {
+		return false, nil
+	}
+	t, data, err := bson.MarshalValue(value)
+	if err != nil {
+		return false, err
+	}
+	if !k.filter.mayContain(t, data) {
+		return false, nil
+	}
+
+	n, err := k.collection.CountDocuments(
+		context.TODO(),
+		bson.D{{k.field, value}},
+		mopt.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, fmt.Errorf("error checking for existing document: %v", err)
+	}
+	if n == 0 {
+		atomic.AddUint64(&k.falsePositives, 1)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoimport
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBloomFilter(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With a bloom filter holding 10000 values", t, func() {
+		const n = 10000
+		filter := newBloomFilter(n, bloomFalsePositiveRate)
+		for i := int32(0); i < n; i++ {
+			typ, data, err := bson.MarshalValue(i)
+			So(err, ShouldBeNil)
+			filter.add(typ, data)
+		}
+
+		Convey("every added value is reported as present", func() {
+			for i := int32(0); i < n; i++ {
+				typ, data, _ := bson.MarshalValue(i)
+				So(filter.mayContain(typ, data), ShouldBeTrue)
+			}
+		})
+
+		Convey("few other values are reported as present", func() {
+			falsePositives := 0
+			for i := int32(n); i < 2*n; i++ {
+				typ, data, _ := bson.MarshalValue(i)
+				if filter.mayContain(typ, data) {
+					falsePositives++
+				}
+			}
+			So(falsePositives, ShouldBeLessThan, n*3*bloomFalsePositiveRate)
+		})
+
+		Convey("values of a different type don't match", func() {
+			typ, data, _ := bson.MarshalValue("1")
+			So(filter.mayContain(typ, data), ShouldBeFalse)
+		})
+	})
+}
+
+func TestBloomFilterNumbers(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	mayContain := func(filter *bloomFilter, value interface{}) bool {
+		typ, data, err := bson.MarshalValue(value)
+		So(err, ShouldBeNil)
+		return filter.mayContain(typ, data)
+	}
+	decimal := func(s string) primitive.Decimal128 {
+		d, err := primitive.ParseDecimal128(s)
+		So(err, ShouldBeNil)
+		return d
+	}
+
+	Convey("With a bloom filter holding numbers of each type", t, func() {
+		filter := newBloomFilter(100, bloomFalsePositiveRate)
+		for _, value := range []interface{}{
+			int32(1),
+			int64(2),
+			3.0,
+			2.5,
+			decimal("4"),
+			math.NaN(),
+		} {
+			typ, data, err := bson.MarshalValue(value)
+			So(err, ShouldBeNil)
+			filter.add(typ, data)
+		}
+
+		Convey("equal numbers of other types match", func() {
+			for _, value := range []interface{}{int64(1), 1.0, decimal("1.0")} {
+				So(mayContain(filter, value), ShouldBeTrue)
+			}
+			So(mayContain(filter, int32(2)), ShouldBeTrue)
+			So(mayContain(filter, 2.0), ShouldBeTrue)
+			So(mayContain(filter, int32(3)), ShouldBeTrue)
+			So(mayContain(filter, int64(4)), ShouldBeTrue)
+			So(mayContain(filter, 4.0), ShouldBeTrue)
+			So(mayContain(filter, decimal("2.50")), ShouldBeTrue)
+			So(mayContain(filter, decimal("NaN")), ShouldBeTrue)
+		})
+
+		Convey("numbers with other values don't match", func() {
+			So(mayContain(filter, 1.5), ShouldBeFalse)
+			So(mayContain(filter, "1"), ShouldBeFalse)
+		})
+	})
+}
@@ -50,6 +50,17 @@ type TSVInputReader struct {
 
 	// useArrayIndexFields is whether field names include array indexes
 	useArrayIndexFields bool
+
+	// checkFieldCount is whether rows must have one field per column, with
+	// mismatches handled according to parseGrace
+	checkFieldCount bool
+	parseGrace      ParseGrace
+
+	// numLines tracks the number of lines read, including the header
+	numLines uint64
+
+	// keepSource is whether documents are sent with the line they came from
+	keepSource bool
 }
 
 // TSVConverter implements the Converter interface for TSV input.
@@ -57,8 +68,11 @@ type TSVConverter struct {
 	colSpecs            []ColumnSpec
 	data                string
 	index               uint64
+	line                uint64
 	ignoreBlanks        bool
 	useArrayIndexFields bool
+	checkFieldCount     bool
+	parseGrace          ParseGrace
 	rejectWriter        io.Writer
 }
 
@@ -92,6 +106,7 @@ func (r *TSVInputReader) ReadAndValidateHeader() (err error) {
 	if err != nil {
 		return err
 	}
+	r.numLines++
 	var headerFields []string
 	for _, field := range strings.Split(header, tokenSeparator) {
 		headerFields = append(headerFields, strings.TrimRight(field, "\r\n"))
@@ -107,6 +122,7 @@ func (r *TSVInputReader) ReadAndValidateTypedHeader(parseGrace ParseGrace) (err
 	if err != nil {
 		return err
 	}
+	r.numLines++
 	var headerFields []string
 	for _, field := range strings.Split(header, tokenSeparator) {
 		headerFields = append(headerFields, strings.TrimRight(field, "\r\n"))
@@ -121,7 +137,7 @@ func (r *TSVInputReader) ReadAndValidateTypedHeader(parseGrace ParseGrace) (err
 // StreamDocument takes a boolean indicating if the documents should be streamed
 // in read order and a channel on which to stream the documents processed from
 // the underlying reader. Returns a non-nil error if streaming fails.
-func (r *TSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (retErr error) {
+func (r *TSVInputReader) StreamDocument(ordered bool, readDocs chan InputDocument) (retErr error) {
 	tsvRecordChan := make(chan Converter, r.numDecoders)
 	tsvErrChan := make(chan error)
 
@@ -140,12 +156,16 @@ func (r *TSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 				}
 				return
 			}
+			r.numLines++
 			tsvRecordChan <- TSVConverter{
 				colSpecs:            r.colSpecs,
 				data:                r.tsvRecord,
 				index:               r.numProcessed,
+				line:                r.numLines,
 				ignoreBlanks:        r.ignoreBlanks,
 				useArrayIndexFields: r.useArrayIndexFields,
+				checkFieldCount:     r.checkFieldCount,
+				parseGrace:          r.parseGrace,
 				rejectWriter:        r.tsvRejectWriter,
 			}
 			r.numProcessed++
@@ -154,7 +174,7 @@ func (r *TSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 
 	// begin processing read bytes
 	go func() {
-		tsvErrChan <- streamDocuments(ordered, r.numDecoders, tsvRecordChan, readDocs)
+		tsvErrChan <- streamDocuments(ordered, r.numDecoders, tsvRecordChan, readDocs, r.keepSource)
 	}()
 
 	return channelQuorumError(tsvErrChan)
@@ -163,13 +183,21 @@ func (r *TSVInputReader) StreamDocument(ordered bool, readDocs chan bson.D) (ret
 // Convert implements the Converter interface for TSV input. It converts a
 // TSVConverter struct to a BSON document.
 func (c TSVConverter) Convert() (b bson.D, err error) {
-	b, err = tokensToBSON(
-		c.colSpecs,
-		strings.Split(strings.TrimRight(c.data, "\r\n"), tokenSeparator),
-		c.index,
-		c.ignoreBlanks,
-		c.useArrayIndexFields,
-	)
+	tokens := strings.Split(strings.TrimRight(c.data, "\r\n"), tokenSeparator)
+	if c.checkFieldCount {
+		b, err = countedTokensToBSON(
+			c.colSpecs,
+			tokens,
+			nil,
+			c.index,
+			c.line,
+			c.parseGrace,
+			c.ignoreBlanks,
+			c.useArrayIndexFields,
+		)
+	} else {
+		b, err = tokensToBSON(c.colSpecs, tokens, nil, c.index, c.ignoreBlanks, c.useArrayIndexFields)
+	}
 	if _, ok := err.(coercionError); ok {
 		err = c.Print()
 	}
@@ -180,3 +208,15 @@ func (c TSVConverter) Print() error {
 	_, err := c.rejectWriter.Write([]byte(c.data + "\n"))
 	return err
 }
+
+// Line implements the lineConverter interface. It returns the line of the
+// input the record was read from.
+func (c TSVConverter) Line() uint64 {
+	return c.line
+}
+
+// Source implements the sourceConverter interface. It returns the line the
+// record was read from.
+func (c TSVConverter) Source() string {
+	return strings.TrimRight(c.data, "\r\n")
+}
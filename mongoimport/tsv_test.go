@@ -39,9 +39,9 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("valid TSV input file that starts with the UTF-8 BOM should "+
@@ -59,9 +59,9 @@ func TestTSVStreamDocument(t *testing.T) {
 			fileHandle, err := os.Open("testdata/test_bom.tsv")
 			So(err, ShouldBeNil)
 			r := NewTSVInputReader(colSpecs, fileHandle, os.Stdout, 1, false, false)
-			docChan := make(chan bson.D, 2)
+			docChan := make(chan InputDocument, 2)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("integer valued strings should be converted tsv2", func() {
@@ -85,9 +85,9 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("extra columns should be prefixed with 'field'", func() {
@@ -111,9 +111,9 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("mixed values should be parsed correctly", func() {
@@ -138,9 +138,9 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 1)
+			docChan := make(chan InputDocument, 1)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedRead)
+			So((<-docChan).Document, ShouldResemble, expectedRead)
 		})
 
 		Convey("calling StreamDocument() in succession for TSVs should "+
@@ -170,10 +170,10 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, len(expectedReads))
+			docChan := make(chan InputDocument, len(expectedReads))
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
 			for i := 0; i < len(expectedReads); i++ {
-				for j, readDocument := range <-docChan {
+				for j, readDocument := range (<-docChan).Document {
 					So(readDocument.Key, ShouldEqual, expectedReads[i][j].Key)
 					So(readDocument.Value, ShouldEqual, expectedReads[i][j].Value)
 				}
@@ -206,10 +206,10 @@ func TestTSVStreamDocument(t *testing.T) {
 				false,
 				false,
 			)
-			docChan := make(chan bson.D, 2)
+			docChan := make(chan InputDocument, 2)
 			So(r.StreamDocument(true, docChan), ShouldBeNil)
-			So(<-docChan, ShouldResemble, expectedReadOne)
-			So(<-docChan, ShouldResemble, expectedReadTwo)
+			So((<-docChan).Document, ShouldResemble, expectedReadOne)
+			So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 		})
 
 		Convey("plain TSV input file sources should be parsed correctly and "+
@@ -233,10 +233,10 @@ func TestTSVStreamDocument(t *testing.T) {
 				fileHandle, err := os.Open("testdata/test.tsv")
 				So(err, ShouldBeNil)
 				r := NewTSVInputReader(colSpecs, fileHandle, os.Stdout, 1, false, false)
-				docChan := make(chan bson.D, 50)
+				docChan := make(chan InputDocument, 50)
 				So(r.StreamDocument(true, docChan), ShouldBeNil)
-				So(<-docChan, ShouldResemble, expectedReadOne)
-				So(<-docChan, ShouldResemble, expectedReadTwo)
+				So((<-docChan).Document, ShouldResemble, expectedReadOne)
+				So((<-docChan).Document, ShouldResemble, expectedReadTwo)
 			})
 	})
 }
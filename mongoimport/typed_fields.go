@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +38,7 @@ const (
 	ctInt64
 	ctDecimal
 	ctString
+	ctObjectID
 )
 
 var (
@@ -44,6 +46,7 @@ var (
 	columnTypeNameMap = map[string]columnType{
 		"auto":        ctAuto,
 		"binary":      ctBinary,
+		"bool":        ctBoolean,
 		"boolean":     ctBoolean,
 		"date":        ctDate,
 		"decimal":     ctDecimal,
@@ -53,10 +56,21 @@ var (
 		"double":      ctDouble,
 		"int32":       ctInt32,
 		"int64":       ctInt64,
+		"objectid":    ctObjectID,
 		"string":      ctString,
 	}
 )
 
+// columnTypeNames returns the sorted names of the supported column types.
+func columnTypeNames() []string {
+	names := make([]string, 0, len(columnTypeNameMap))
+	for name := range columnTypeNameMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type binaryEncoding int
 
 const (
@@ -98,7 +112,12 @@ func ParseTypedHeader(header string, parseGrace ParseGrace) (f ColumnSpec, err e
 	}
 	t, ok := columnTypeNameMap[match[2]]
 	if !ok {
-		err = fmt.Errorf("invalid type %s in header %s", match[2], header)
+		err = fmt.Errorf(
+			"invalid type %s in header %s; supported types are: %s",
+			match[2],
+			header,
+			strings.Join(columnTypeNames(), ", "),
+		)
 		return
 	}
 	p, err := NewFieldParser(t, match[3])
@@ -191,6 +210,8 @@ func NewFieldParser(t columnType, arg string) (parser FieldParser, err error) {
 		parser = new(FieldDecimalParser)
 	case ctString:
 		parser = new(FieldStringParser)
+	case ctObjectID:
+		parser = new(FieldObjectIDParser)
 	default: // ctAuto
 		parser = new(FieldAutoParser)
 	}
@@ -291,3 +312,9 @@ type FieldStringParser struct{}
 func (sp *FieldStringParser) Parse(in string) (interface{}, error) {
 	return in, nil
 }
+
+type FieldObjectIDParser struct{}
+
+func (op *FieldObjectIDParser) Parse(in string) (interface{}, error) {
+	return primitive.ObjectIDFromHex(in)
+}
@@ -69,6 +69,18 @@ func TestTypedHeaderParser(t *testing.T) {
 		},
 	)
 
+	Convey("Using 'price.decimal(),active.bool(),ref.objectid(),at.date(2006-01-02)'", t, func() {
+		headers := []string{"price.decimal()", "active.bool()", "ref.objectid()", "at.date(2006-01-02)"}
+		colSpecs, err := ParseTypedHeaders(headers, pgAutoCast)
+		So(err, ShouldBeNil)
+		So(colSpecs, ShouldResemble, []ColumnSpec{
+			{"price", new(FieldDecimalParser), pgAutoCast, "decimal", []string{"price"}},
+			{"active", new(FieldBooleanParser), pgAutoCast, "bool", []string{"active"}},
+			{"ref", new(FieldObjectIDParser), pgAutoCast, "objectid", []string{"ref"}},
+			{"at", &FieldDateParser{"2006-01-02"}, pgAutoCast, "date", []string{"at"}},
+		})
+	})
+
 	Convey("Using various bad headers", t, func() {
 		var err error
 
@@ -86,6 +98,21 @@ func TestTypedHeaderParser(t *testing.T) {
 			_, err = ParseTypedHeader("zip.auto(0)", pgAutoCast)
 			So(err, ShouldNotBeNil)
 		})
+		Convey("with non-empty arguments for the new types", func() {
+			_, err = ParseTypedHeader("price.decimal(0)", pgAutoCast)
+			So(err, ShouldNotBeNil)
+			_, err = ParseTypedHeader("active.bool(0)", pgAutoCast)
+			So(err, ShouldNotBeNil)
+			_, err = ParseTypedHeader("ref.objectid(hex)", pgAutoCast)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("with an unknown type, listing the supported types", func() {
+			_, err = ParseTypedHeader("zip.zipcode()", pgAutoCast)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid type zipcode")
+			So(err.Error(), ShouldContainSubstring, "bool, boolean, date")
+			So(err.Error(), ShouldContainSubstring, "objectid")
+		})
 		Convey("with bad arguments for the binary type", func() {
 			_, err = ParseTypedHeader("zip.binary(blah)", pgAutoCast)
 			So(err, ShouldNotBeNil)
@@ -422,6 +449,24 @@ func TestFieldParsers(t *testing.T) {
 		})
 	})
 
+	Convey("Using FieldObjectIDParser", t, func() {
+		var p, _ = NewFieldParser(ctObjectID, "")
+		var err error
+
+		Convey("parses valid hex ObjectIDs correctly", func() {
+			oid := primitive.NewObjectID()
+			value, err := p.Parse(oid.Hex())
+			So(err, ShouldBeNil)
+			So(value.(primitive.ObjectID), ShouldEqual, oid)
+		})
+		Convey("does not parse invalid ObjectIDs", func() {
+			for _, ts := range []string{"", "abcd", "5a934e000102030405000000zz", "5a934e00010203040500000"} {
+				_, err = p.Parse(ts)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
 	Convey("Using FieldStringParser", t, func() {
 		var p, _ = NewFieldParser(ctString, "")
 		var value interface{}
@@ -0,0 +1,391 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongorestore
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/progress"
+	"github.com/mongodb/mongo-tools/common/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// openCollectionsPerWorker bounds the number of collections that are restored
+// at once with --fairScheduling, and so the number of open files, to this many
+// per worker.
+const openCollectionsPerWorker = 8
+
+// collectionJob is a collection being restored with --fairScheduling. Its
+// documents are read and inserted a batch at a time, by whichever worker
+// takes the collection's turn.
+type collectionJob struct {
+	intent *intents.Intent
+
+	// set when the job is opened, by the worker taking its first turn
+	opened     bool
+	bsonSource *db.DecodedBSONSource
+	collection *mongo.Collection
+	progressor *progress.CountProgressor
+	writeBatch func(*mongo.BulkWriteResult, error) Result
+	startTime  time.Time
+
+	// The fields below are guarded by the scheduler's mutex.
+
+	// queued is set while the job waits for a turn, and reading while a
+	// worker reads a batch from it.
+	queued, reading bool
+	// exhausted is set once all of the job's documents have been read.
+	exhausted bool
+	// inFlight is the number of workers reading or inserting its batches.
+	inFlight int
+	result   Result
+}
+
+// fairScheduler hands out turns to restore a batch of documents from the
+// collections being restored, so that each open collection gets its turn in
+// round-robin order and a small collection doesn't wait for large ones to
+// finish. Turns to open a new collection alternate with turns of collections
+// that are already open.
+type fairScheduler struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+
+	manager *intents.Manager
+	// perCollection is the number of batches of a collection that can be
+	// inserted at once.
+	perCollection int
+	maxOpen       int
+
+	// ready holds the open jobs waiting for a turn, in turn order.
+	ready []*collectionJob
+	open  map[*collectionJob]struct{}
+	// noMoreIntents is set once the manager has no intents left to pop.
+	noMoreIntents bool
+	preferNew     bool
+
+	total Result
+	err   error
+}
+
+func newFairScheduler(manager *intents.Manager, workers, perCollection int) *fairScheduler {
+	sched := &fairScheduler{
+		manager:       manager,
+		perCollection: perCollection,
+		maxOpen:       workers * openCollectionsPerWorker,
+		open:          map[*collectionJob]struct{}{},
+		preferNew:     true,
+	}
+	sched.cond = sync.NewCond(&sched.mutex)
+	return sched
+}
+
+// next waits for a job with a batch to restore, and returns it for the
+// calling worker to read the batch from, opening it first if it isn't yet.
+// It returns nil once every collection is restored or the restore failed.
+func (sched *fairScheduler) next() *collectionJob {
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+	for {
+		if sched.err != nil {
+			return nil
+		}
+		canOpen := !sched.noMoreIntents && len(sched.open) < sched.maxOpen
+		if canOpen && (sched.preferNew || len(sched.ready) == 0) {
+			sched.preferNew = false
+			intent := sched.manager.Pop()
+			if intent == nil {
+				sched.noMoreIntents = true
+				continue
+			}
+			job := &collectionJob{intent: intent, reading: true, inFlight: 1}
+			sched.open[job] = struct{}{}
+			return job
+		}
+		if len(sched.ready) > 0 {
+			sched.preferNew = true
+			job := sched.ready[0]
+			sched.ready = sched.ready[1:]
+			job.queued = false
+			job.reading = true
+			job.inFlight++
+			return job
+		}
+		if sched.noMoreIntents && len(sched.open) == 0 {
+			return nil
+		}
+		sched.cond.Wait()
+	}
+}
+
+// enqueue gives the job another turn. The mutex must be held.
+func (sched *fairScheduler) enqueue(job *collectionJob) {
+	job.queued = true
+	sched.ready = append(sched.ready, job)
+	sched.cond.Signal()
+}
+
+// doneReading records that a worker has read a batch from the job, and gives
+// the job its next turn right away if more of its batches can be inserted at
+// once.
+func (sched *fairScheduler) doneReading(job *collectionJob, exhausted bool) {
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+	job.reading = false
+	job.exhausted = exhausted
+	if !exhausted && job.inFlight < sched.perCollection {
+		sched.enqueue(job)
+	}
+}
+
+// doneInserting records the result of inserting a batch of the job. It
+// returns true if that was the job's last batch, in which case the caller
+// must finish the job.
+func (sched *fairScheduler) doneInserting(job *collectionJob, result Result) bool {
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+	job.inFlight--
+	job.result.Successes += result.Successes
+	job.result.Failures += result.Failures
+	if sched.err != nil {
+		return false
+	}
+	if job.exhausted {
+		return job.inFlight == 0
+	}
+	if !job.queued && !job.reading {
+		sched.enqueue(job)
+	}
+	return false
+}
+
+// finished records that the job is done, making room to open another.
+func (sched *fairScheduler) finished(job *collectionJob) {
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+	delete(sched.open, job)
+	sched.total.Successes += job.result.Successes
+	sched.total.Failures += job.result.Failures
+	sched.cond.Broadcast()
+}
+
+// fail stops the restore with the given error, unless it already failed.
+func (sched *fairScheduler) fail(err error) {
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+	if sched.err == nil {
+		sched.err = err
+	}
+	sched.cond.Broadcast()
+}
+
+// restoreIntentsFairly restores all of the intents in the IntentManager with a
+// pool of workers that take turns restoring a batch of documents from each
+// collection, for --fairScheduling. Up to --numParallelCollections times
+// --numInsertionWorkersPerCollection batches are inserted at once.
+func (restore *MongoRestore) restoreIntentsFairly() Result {
+	workers := util.MaxInt(restore.OutputOptions.NumParallelCollections, 1) *
+		restore.OutputOptions.NumInsertionWorkers
+	log.Logvf(log.DebugLow, "restoring collections in turn with %v workers", workers)
+
+	sched := newFairScheduler(restore.manager, workers, restore.OutputOptions.NumInsertionWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			restore.runFairWorker(sched)
+		}()
+	}
+	wg.Wait()
+
+	// close the collections left open by a failed restore
+	for job := range sched.open {
+		job.close(restore.ProgressManager)
+	}
+	return sched.total.withErr(sched.err)
+}
+
+// runFairWorker restores batches of documents until there are none left.
+func (restore *MongoRestore) runFairWorker(sched *fairScheduler) {
+	for {
+		job := sched.next()
+		if job == nil {
+			return
+		}
+		ns := job.intent.Namespace()
+
+		if !job.opened {
+			if err := restore.openCollectionJob(job); err != nil {
+				sched.fail(fmt.Errorf("%v: %v", ns, err))
+				return
+			}
+		}
+		var docs []bson.Raw
+		exhausted := job.bsonSource == nil
+		if !exhausted {
+			var err error
+			docs, err = job.readBatch(restore)
+			if err != nil {
+				sched.fail(fmt.Errorf("%v: error restoring from %v: %v",
+					ns, job.intent.Location, err))
+				return
+			}
+			exhausted = len(docs) < restore.OutputOptions.BulkBufferSize
+		}
+		sched.doneReading(job, exhausted)
+
+		result := restore.insertBatch(job, docs)
+		if result.Err != nil {
+			sched.fail(fmt.Errorf("%v: error restoring from %v: %v",
+				ns, job.intent.Location, result.Err))
+			return
+		}
+		if !sched.doneInserting(job, result) {
+			continue
+		}
+
+		if err := restore.finishCollectionJob(job); err != nil {
+			sched.fail(fmt.Errorf("%v: %v", ns, err))
+			return
+		}
+		sched.finished(job)
+	}
+}
+
+// openCollectionJob prepares the collection of a job and opens its data, if
+// there is any to restore. It is done by the worker taking the job's first
+// turn, so nothing else uses the job meanwhile.
+func (restore *MongoRestore) openCollectionJob(job *collectionJob) error {
+	intent := job.intent
+	job.opened = true
+	job.startTime = time.Now()
+
+	if intent.IsView() {
+		return skipViewData(intent)
+	}
+	if restore.ledger != nil {
+		skip, err := restore.startInLedger(intent)
+		if err != nil {
+			return err
+		}
+		if skip {
+			job.result.skipped = true
+			return nil
+		}
+	}
+	if err := restore.prepareCollection(intent); err != nil {
+		return err
+	}
+	if intent.BSONFile == nil {
+		return nil
+	}
+
+	session, err := restore.SessionProvider.GetSession()
+	if err != nil {
+		return fmt.Errorf("error establishing connection: %v", err)
+	}
+	if err := intent.BSONFile.Open(); err != nil {
+		return err
+	}
+	log.Logvf(log.Always, "restoring %v from %v", intent.DataNamespace(), intent.Location)
+
+	job.bsonSource = db.NewDecodedBSONSource(db.NewBSONSource(intent.BSONFile))
+	job.collection = session.Database(intent.DB).Collection(intent.DataCollection())
+	job.writeBatch = restore.batchWriter(intent.DataNamespace())
+	job.progressor = progress.NewCounter(intent.Size)
+	if restore.ProgressManager != nil {
+		restore.ProgressManager.Attach(intent.DataNamespace(), job.progressor)
+	}
+	return nil
+}
+
+// readBatch reads up to --batchSize documents from the job. Fewer documents
+// are only returned once all of them are read.
+func (job *collectionJob) readBatch(restore *MongoRestore) ([]bson.Raw, error) {
+	var docs []bson.Raw
+	for len(docs) < restore.OutputOptions.BulkBufferSize {
+		if restore.terminate.Load() {
+			log.Logvf(log.Always, "terminating read on %v", job.intent.DataNamespace())
+			return nil, util.ErrTerminated
+		}
+		doc := job.bsonSource.LoadNext()
+		if doc == nil {
+			if err := job.bsonSource.Err(); err != nil {
+				return nil, fmt.Errorf("reading bson input: %v", err)
+			}
+			break
+		}
+		rawBytes := make([]byte, len(doc))
+		copy(rawBytes, doc)
+		docs = append(docs, bson.Raw(rawBytes))
+	}
+	job.progressor.Set(job.intent.BSONFile.Pos())
+	return docs, nil
+}
+
+// insertBatch inserts a batch of documents read from the job.
+func (restore *MongoRestore) insertBatch(job *collectionJob, docs []bson.Raw) Result {
+	if len(docs) == 0 {
+		return Result{}
+	}
+	bulk := restore.newBulkInserter(job.collection, job.intent.Type)
+	defer bulk.ResetBulk()
+
+	var result Result
+	for _, rawDoc := range docs {
+		if restore.objCheck {
+			if err := bson.Unmarshal(rawDoc, &bson.D{}); err != nil {
+				return result.withErr(err)
+			}
+		}
+		result.combineWith(job.writeBatch(bulk.InsertRaw(rawDoc)))
+		result.Err = db.FilterError(restore.OutputOptions.StopOnError, result.Err)
+		if result.Err != nil {
+			return result
+		}
+	}
+	result.combineWith(restore.flushBulk(
+		bulk, job.intent.DB, job.intent.DataCollection(), job.writeBatch))
+	return result.withErr(db.FilterError(restore.OutputOptions.StopOnError, result.Err))
+}
+
+// finishCollectionJob closes a job once all of its documents are restored,
+// and records it as restored.
+func (restore *MongoRestore) finishCollectionJob(job *collectionJob) error {
+	job.close(restore.ProgressManager)
+	intent := job.intent
+	if restore.ledger != nil && !intent.IsView() && !job.result.skipped {
+		if err := restore.finishInLedger(intent); err != nil {
+			return err
+		}
+	}
+	if !intent.IsView() && !job.result.skipped {
+		job.result.Elapsed = time.Since(job.startTime)
+		job.result.log(intent.Namespace())
+	}
+	restore.manager.Finish(intent)
+	return nil
+}
+
+// close closes the data of the job, if it was opened.
+func (job *collectionJob) close(manager progress.Manager) {
+	if job.bsonSource == nil {
+		return
+	}
+	job.bsonSource.Close()
+	job.intent.BSONFile.Close()
+	if manager != nil {
+		manager.Detach(job.intent.DataNamespace())
+	}
+	job.bsonSource = nil
+}
@@ -0,0 +1,169 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongorestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/intents"
+	commonOpts "github.com/mongodb/mongo-tools/common/options"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestFairScheduler(workers, perCollection int, collections ...string) *fairScheduler {
+	manager := intents.NewIntentManager()
+	for _, c := range collections {
+		manager.Put(&intents.Intent{DB: "db", C: c})
+	}
+	manager.Finalize(intents.Legacy)
+	return newFairScheduler(manager, workers, perCollection)
+}
+
+func TestFairScheduler(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	t.Run("a small collection finishes before a large one", func(t *testing.T) {
+		sched := newTestFairScheduler(1, 1, "big", "small")
+
+		// restoreBatch takes the next turn, and restores a batch of the
+		// collection, returning its name
+		restoreBatch := func(last bool) string {
+			job := sched.next()
+			require.NotNil(t, job)
+			sched.doneReading(job, last)
+			if sched.doneInserting(job, Result{Successes: 1}) {
+				sched.finished(job)
+			}
+			return job.intent.C
+		}
+
+		assert.Equal(t, "big", restoreBatch(false))
+		assert.Equal(t, "big", restoreBatch(false))
+		assert.Equal(t, "small", restoreBatch(true))
+		assert.Len(t, sched.open, 1)
+		assert.Equal(t, "big", restoreBatch(false))
+		assert.Equal(t, "big", restoreBatch(true))
+		assert.Nil(t, sched.next())
+		assert.EqualValues(t, 5, sched.total.Successes)
+	})
+
+	t.Run("batches of a collection are inserted at once up to the limit", func(t *testing.T) {
+		sched := newTestFairScheduler(4, 2, "a")
+
+		first := sched.next()
+		sched.doneReading(first, false)
+		second := sched.next()
+		assert.Same(t, first, second)
+		sched.doneReading(second, false)
+		assert.Equal(t, 2, first.inFlight)
+		assert.Empty(t, sched.ready)
+
+		assert.False(t, sched.doneInserting(first, Result{}))
+		assert.Equal(t, []*collectionJob{first}, sched.ready)
+		third := sched.next()
+		sched.doneReading(third, true)
+		assert.False(t, sched.doneInserting(second, Result{}))
+		assert.True(t, sched.doneInserting(third, Result{}))
+	})
+
+	t.Run("the number of open collections is limited", func(t *testing.T) {
+		var collections []string
+		for i := 0; i <= openCollectionsPerWorker; i++ {
+			collections = append(collections, fmt.Sprintf("c%v", i))
+		}
+		sched := newTestFairScheduler(1, 1, collections...)
+
+		// turns to open a collection alternate with turns of open ones
+		for i := 0; i < 4*openCollectionsPerWorker; i++ {
+			job := sched.next()
+			assert.NotEqual(t, collections[openCollectionsPerWorker], job.intent.C)
+			sched.doneReading(job, false)
+			sched.doneInserting(job, Result{})
+		}
+		assert.Len(t, sched.open, openCollectionsPerWorker)
+	})
+
+	t.Run("no more turns are given once the restore fails", func(t *testing.T) {
+		sched := newTestFairScheduler(1, 1, "a", "b")
+
+		job := sched.next()
+		sched.doneReading(job, false)
+		sched.fail(errors.New("failed"))
+		assert.False(t, sched.doneInserting(job, Result{}))
+		assert.Nil(t, sched.next())
+	})
+}
+
+func TestFairSchedulingOptionValidation(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	restore := newMongoRestore()
+	restore.ToolOptions.Namespace = &commonOpts.Namespace{}
+	restore.InputOptions = &InputOptions{Archive: "dump.archive"}
+	restore.OutputOptions = &OutputOptions{
+		FairScheduling:      true,
+		NumInsertionWorkers: 1,
+		BulkBufferSize:      1000,
+	}
+	assert.ErrorContains(t, restore.ParseAndValidateOptions(),
+		"cannot use --fairScheduling with --archive")
+}
+
+func TestRestoreWithFairScheduling(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	session, err := testutil.GetBareSession()
+	require.NoError(t, err, "No server available")
+
+	database := session.Database("fair_scheduling_test")
+	require.NoError(t, database.Drop(context.Background()))
+	defer func() {
+		_ = database.Drop(context.Background())
+	}()
+
+	dir, cleanup := testutil.MakeTempDir(t)
+	defer cleanup()
+	counts := map[string]int{"big": 2500, "small": 3, "empty": 0}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "fair_scheduling_test"), 0755))
+	for c, count := range counts {
+		var data []byte
+		for i := 0; i < count; i++ {
+			doc, err := bson.Marshal(bson.D{{"_id", i}})
+			require.NoError(t, err)
+			data = append(data, doc...)
+		}
+		path := filepath.Join(dir, "fair_scheduling_test", c+".bson")
+		require.NoError(t, os.WriteFile(path, data, 0644))
+	}
+
+	restore, err := getRestoreWithArgs(
+		FairSchedulingOption,
+		NumInsertionWorkersOption, "2",
+		BulkBufferSizeOption, "100",
+	)
+	require.NoError(t, err)
+	defer restore.Close()
+	restore.TargetDirectory = dir
+
+	result := restore.Restore()
+	require.NoError(t, result.Err)
+	assert.EqualValues(t, 2503, result.Successes)
+	assert.EqualValues(t, 0, result.Failures)
+	for c, count := range counts {
+		n, err := database.Collection(c).CountDocuments(context.Background(), bson.D{})
+		require.NoError(t, err)
+		assert.EqualValues(t, count, n, c)
+	}
+}
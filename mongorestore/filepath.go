@@ -16,6 +16,7 @@ import (
 	"sync/atomic"
 
 	"github.com/mongodb/mongo-tools/common/archive"
+	"github.com/mongodb/mongo-tools/common/dumprestore"
 	"github.com/mongodb/mongo-tools/common/intents"
 	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/util"
@@ -269,6 +270,37 @@ func (restore *MongoRestore) getInfoFromFile(filename string) (string, FileType,
 	return unescapedCollName, fileType, nil
 }
 
+// getInfoFromBSONFile returns the collection name from the name of a bson file given as the
+// mongorestore target, and whether the file is gzipped. A file with a .bson.gz extension is read as
+// gzip even without --gzip, so that a single compressed file can be restored on its own.
+func (restore *MongoRestore) getInfoFromBSONFile(path string) (string, bool, error) {
+	baseFileName := filepath.Base(path)
+	if !restore.InputOptions.Gzip && strings.HasSuffix(baseFileName, ".bson.gz") {
+		log.Logvf(log.DebugLow, "reading %v as gzip because of its .gz extension", path)
+		collName, err := util.UnescapeCollectionName(strings.TrimSuffix(baseFileName, ".bson.gz"))
+		if err != nil {
+			return "", false, fmt.Errorf(
+				"error parsing collection name from filename \"%v\": %v",
+				baseFileName,
+				err,
+			)
+		}
+		return collName, true, nil
+	}
+
+	collName, fileType, err := restore.getInfoFromFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	if fileType != BSONFileType {
+		if restore.InputOptions.Gzip {
+			return "", false, fmt.Errorf("file %v does not have .bson.gz extension", path)
+		}
+		return "", false, fmt.Errorf("file %v does not have .bson or .bson.gz extension", path)
+	}
+	return collName, restore.InputOptions.Gzip, nil
+}
+
 // getCollectionNameFromMetadata returns the escaped collection name from a metadata file on disk.
 // It returns the collection name found in the metadata file under the `collectionName` field. This
 // is only valid for newer metadata files and metadata files with truncated names, as there may be
@@ -375,7 +407,15 @@ func (restore *MongoRestore) CreateAllIntents(dir archive.DirLike) error {
 					oplogIntent.BSONFile = &realBSONFile{path: entry.Path(), intent: oplogIntent, gzip: restore.InputOptions.Gzip}
 				}
 				restore.manager.Put(oplogIntent)
-			} else {
+			} else if restore.InputOptions.Archive != "" &&
+				entry.Name() == archive.ManifestCollection+".bson" {
+				// the manifest written by mongodump --writeManifest isn't restored
+				manifestIntent := &intents.Intent{C: archive.ManifestCollection}
+				restore.archive.Demux.Open(
+					manifestIntent.Namespace(),
+					&archive.MutedCollection{Intent: manifestIntent, Demux: restore.archive.Demux},
+				)
+			} else if !strings.HasSuffix(entry.Name(), dumprestore.ChecksumSuffix) {
 				log.Logvf(log.Always, `don't know what to do with file "%v", skipping...`, entry.Path())
 			}
 		}
@@ -556,6 +596,10 @@ func (restore *MongoRestore) CreateIntentsForDB(db string, dir archive.DirLike)
 				log.Logvf(log.DebugLow, "adding intent for %v", sourceNS)
 				restore.manager.PutWithNamespace(sourceNS, intent)
 			default:
+				if strings.HasSuffix(entry.Name(), dumprestore.ChecksumSuffix) {
+					// checksums are read alongside their BSON files by verifyChecksums
+					continue
+				}
 				log.Logvf(log.Always, `don't know what to do with file "%v", skipping...`,
 					entry.Path())
 			}
@@ -600,13 +644,10 @@ func (restore *MongoRestore) CreateIntentForCollection(
 	if bsonFile.IsDir() {
 		return fmt.Errorf("file %v is a directory, not a bson file", bsonFile.Path())
 	}
-	_, fileType, err := restore.getInfoFromFile(bsonFile.Path())
+	_, gzipped, err := restore.getInfoFromBSONFile(bsonFile.Path())
 	if err != nil {
 		return err
 	}
-	if fileType != BSONFileType {
-		return fmt.Errorf("file %v does not have .bson or .bson.gz extension", bsonFile.Path())
-	}
 
 	var isTimeseries bool
 	if strings.HasPrefix(bsonFile.Name(), "system.buckets.") {
@@ -625,28 +666,12 @@ func (restore *MongoRestore) CreateIntentForCollection(
 	intent.BSONFile = &realBSONFile{
 		path:   bsonFile.Path(),
 		intent: intent,
-		gzip:   restore.InputOptions.Gzip,
-	}
-	// Check if the bson file has a corresponding .metadata.json file in its folder. If there's a
-	// directory error, log a note but attempt to restore without the metadata file anyway.
-	log.Logvf(log.DebugLow, "scanning directory %v for metadata", bsonFile.Parent())
-	entries, err := bsonFile.Parent().ReadDir()
-	if err != nil {
-		if isTimeseries {
-			return fmt.Errorf(
-				"could not find the timeseries collection metadata file for %s",
-				db+"."+collection,
-			)
-		}
-		log.Logvf(log.Info, "error attempting to locate metadata for file: %v", err)
-		log.Logv(log.Info, "restoring collection without metadata")
-		restore.manager.Put(intent)
-		return nil
+		gzip:   gzipped,
 	}
 
 	// Change out the extension from the bson file name to get the metadata file name.
 	var metadataName string
-	if restore.InputOptions.Gzip {
+	if gzipped {
 		metadataName = strings.TrimSuffix(bsonFile.Name(), ".bson.gz") + ".metadata.json.gz"
 	} else {
 		metadataName = strings.TrimSuffix(bsonFile.Name(), ".bson") + ".metadata.json"
@@ -656,19 +681,20 @@ func (restore *MongoRestore) CreateIntentForCollection(
 		metadataName = strings.TrimPrefix(metadataName, "system.buckets.")
 	}
 
-	// If the metadata file is found, add it to the intent.
-	for _, entry := range entries {
-		if entry.Name() == metadataName {
-			metadataPath := entry.Path()
-			log.Logvf(log.Info, "found metadata for collection at %v", metadataPath)
-			intent.MetadataLocation = metadataPath
-			intent.MetadataFile = &realMetadataFile{
-				path:   metadataPath,
-				intent: intent,
-				gzip:   restore.InputOptions.Gzip,
-			}
-			break
+	// If the bson file has a corresponding .metadata.json file next to it, add it to the intent.
+	// A loose bson file usually has none, in which case it is restored without metadata.
+	metadataPath := filepath.Join(filepath.Dir(bsonFile.Path()), metadataName)
+	log.Logvf(log.DebugLow, "checking for metadata at %v", metadataPath)
+	if stat, err := os.Stat(metadataPath); err == nil && !stat.IsDir() {
+		log.Logvf(log.Info, "found metadata for collection at %v", metadataPath)
+		intent.MetadataLocation = metadataPath
+		intent.MetadataFile = &realMetadataFile{
+			path:   metadataPath,
+			intent: intent,
+			gzip:   gzipped,
 		}
+	} else if err != nil && !os.IsNotExist(err) {
+		log.Logvf(log.Info, "error attempting to locate metadata for file: %v", err)
 	}
 
 	if intent.MetadataFile == nil {
@@ -707,14 +733,10 @@ func (restore *MongoRestore) handleBSONInsteadOfDirectory(path string) error {
 	// like a bson file and infer as much as we can
 	if restore.ToolOptions.Namespace.Collection == "" {
 		// if the user did not set -c, get the collection name from the bson file
-		newCollectionName, fileType, err := restore.getInfoFromFile(path)
+		newCollectionName, _, err := restore.getInfoFromBSONFile(path)
 		if err != nil {
 			return err
 		}
-
-		if fileType != BSONFileType {
-			return fmt.Errorf("file %v does not have .bson extension", path)
-		}
 		restore.ToolOptions.Namespace.Collection = newCollectionName
 		log.Logvf(
 			log.DebugLow,
@@ -811,3 +833,21 @@ func (ap actualPath) IsDir() bool {
 	}
 	return stat.IsDir()
 }
+
+// verifyChecksums checks every BSON file to be restored against the checksum
+// recorded for it by mongodump --writeChecksums, so that a corrupt dump is
+// caught before anything is written.
+func (restore *MongoRestore) verifyChecksums() error {
+	for _, intent := range restore.manager.Intents() {
+		bsonFile, ok := intent.BSONFile.(*realBSONFile)
+		if !ok {
+			continue
+		}
+		if err := dumprestore.VerifyChecksum(bsonFile.path); err != nil {
+			return err
+		}
+		log.Logvf(log.DebugLow, "verified checksum of %v", bsonFile.path)
+	}
+	log.Logv(log.Info, "verified checksums of all BSON files")
+	return nil
+}
@@ -8,17 +8,25 @@ package mongorestore
 
 import (
 	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/mongodb/mongo-tools/common/intents"
 	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/ns"
 	"github.com/mongodb/mongo-tools/common/options"
 	commonOpts "github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/testutil"
 	"github.com/mongodb/mongo-tools/common/util"
-	"github.com/mongodb/mongo-tools/mongorestore/ns"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func init() {
@@ -460,6 +468,107 @@ func TestCreateIntentsForCollection(t *testing.T) {
 	})
 }
 
+func TestCreateIntentForBareBSONFile(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir, cleanup := testutil.MakeTempDir(t)
+	defer cleanup()
+
+	raw, err := bson.Marshal(bson.D{{"_id", 1}})
+	require.NoError(t, err)
+	plainPath := filepath.Join(dir, "plain.bson")
+	require.NoError(t, os.WriteFile(plainPath, raw, 0644))
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, err = gzipWriter.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+	gzipPath := filepath.Join(dir, "compressed.bson.gz")
+	require.NoError(t, os.WriteFile(gzipPath, gzipped.Bytes(), 0644))
+
+	createIntent := func(t *testing.T, path string, useGzip bool) *intents.Intent {
+		mr := newMongoRestore()
+		mr.InputOptions.Gzip = useGzip
+		mr.ToolOptions.Namespace = &commonOpts.Namespace{DB: "myDB"}
+		require.NoError(t, mr.handleBSONInsteadOfDirectory(path))
+
+		file, err := newActualPath(path)
+		require.NoError(t, err)
+		require.NoError(t, mr.CreateIntentForCollection(
+			mr.ToolOptions.Namespace.DB, mr.ToolOptions.Namespace.Collection, file))
+		mr.manager.Finalize(intents.Legacy)
+		intent := mr.manager.Pop()
+		require.NotNil(t, intent)
+		assert.Nil(t, mr.manager.Pop())
+		return intent
+	}
+
+	readDocs := func(t *testing.T, intent *intents.Intent) {
+		require.NoError(t, intent.BSONFile.Open())
+		defer intent.BSONFile.Close()
+		data, err := io.ReadAll(intent.BSONFile)
+		require.NoError(t, err)
+		assert.Equal(t, raw, data)
+	}
+
+	t.Run("a plain bson file is restored without metadata", func(t *testing.T) {
+		intent := createIntent(t, plainPath, false)
+		assert.Equal(t, "myDB", intent.DB)
+		assert.Equal(t, "plain", intent.C)
+		assert.Empty(t, intent.MetadataLocation)
+		assert.Nil(t, intent.MetadataFile)
+		readDocs(t, intent)
+	})
+
+	t.Run("a gzipped bson file is read as gzip without --gzip", func(t *testing.T) {
+		intent := createIntent(t, gzipPath, false)
+		assert.Equal(t, "compressed", intent.C)
+		assert.Nil(t, intent.MetadataFile)
+		readDocs(t, intent)
+	})
+
+	t.Run("a gzipped bson file is read with --gzip", func(t *testing.T) {
+		intent := createIntent(t, gzipPath, true)
+		assert.Equal(t, "compressed", intent.C)
+		readDocs(t, intent)
+	})
+
+	t.Run("the collection from -c is kept", func(t *testing.T) {
+		mr := newMongoRestore()
+		mr.ToolOptions.Namespace = &commonOpts.Namespace{Collection: "myC"}
+		require.NoError(t, mr.handleBSONInsteadOfDirectory(gzipPath))
+		assert.Equal(t, "myC", mr.ToolOptions.Namespace.Collection)
+		assert.Equal(t, filepath.Base(dir), mr.ToolOptions.Namespace.DB)
+	})
+
+	t.Run("metadata next to a gzipped file is found", func(t *testing.T) {
+		metadataPath := filepath.Join(dir, "compressed.metadata.json.gz")
+		var metadata bytes.Buffer
+		metadataWriter := gzip.NewWriter(&metadata)
+		_, err := metadataWriter.Write([]byte(`{"indexes":[]}`))
+		require.NoError(t, err)
+		require.NoError(t, metadataWriter.Close())
+		require.NoError(t, os.WriteFile(metadataPath, metadata.Bytes(), 0644))
+		defer os.Remove(metadataPath)
+
+		intent := createIntent(t, gzipPath, false)
+		assert.Equal(t, metadataPath, intent.MetadataLocation)
+		assert.NotNil(t, intent.MetadataFile)
+	})
+
+	t.Run("a file without a bson extension is rejected", func(t *testing.T) {
+		mr := newMongoRestore()
+		mr.ToolOptions.Namespace = &commonOpts.Namespace{}
+		err := mr.handleBSONInsteadOfDirectory(filepath.Join(dir, "data.json"))
+		assert.ErrorContains(t, err, "does not have .bson or .bson.gz extension")
+
+		mr.InputOptions.Gzip = true
+		err = mr.handleBSONInsteadOfDirectory(plainPath)
+		assert.ErrorContains(t, err, "does not have .bson.gz extension")
+	})
+}
+
 func TestCreateIntentsForLongCollectionName(t *testing.T) {
 	// Disabled: see TOOLS-2658
 	t.Skip()
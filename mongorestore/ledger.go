@@ -0,0 +1,259 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongorestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
+)
+
+// Statuses of a namespace in the restore ledger.
+const (
+	ledgerStarted = "started"
+	ledgerDone    = "done"
+)
+
+// ledgerEntry is a single line of the restore ledger. A namespace is marked
+// started before its documents are inserted and done once all of them are.
+type ledgerEntry struct {
+	Namespace string `json:"ns"`
+	Status    string `json:"status"`
+	// Count is the number of documents in the collection: before any were
+	// restored for a started entry, and after all were for a done entry.
+	Count int64 `json:"count"`
+}
+
+// ledgerState is what an earlier restore recorded about a namespace.
+type ledgerState struct {
+	started bool
+	// existing is the number of documents the collection had before the
+	// earlier restore started inserting into it.
+	existing int64
+	done     bool
+	// count is the number of documents the collection had once the earlier
+	// restore finished with it.
+	count int64
+}
+
+// restoreLedger records which collections have been fully restored, so that
+// an interrupted restore can be resumed with --resume. Each entry is synced
+// to disk before the restore moves on.
+type restoreLedger struct {
+	mutex sync.Mutex
+	file  *os.File
+	// previous holds the state recorded by earlier restores, when resuming.
+	previous map[string]ledgerState
+}
+
+// openLedger opens the ledger file at path. When resuming, the entries
+// already in the file are loaded and new ones are appended; otherwise the file
+// is truncated.
+func openLedger(path string, resume bool) (*restoreLedger, error) {
+	ledger := &restoreLedger{previous: map[string]ledgerState{}}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if resume {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading ledger file: %v", err)
+		}
+		if os.IsNotExist(err) {
+			log.Logvf(log.Always, "ledger file %v does not exist, restoring everything", path)
+		}
+		complete, err := ledger.load(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ledger file %v: %v", path, err)
+		}
+		if complete < len(data) {
+			// Remove the line that was cut off so the next entry starts on
+			// its own line.
+			if err := os.Truncate(path, int64(complete)); err != nil {
+				return nil, fmt.Errorf("error truncating ledger file: %v", err)
+			}
+		}
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening ledger file: %v", err)
+	}
+	ledger.file = file
+	return ledger, nil
+}
+
+// load parses the entries of an existing ledger. A final line without a
+// newline was cut off by an interrupted write and is ignored; load returns
+// the length of the data before it.
+func (ledger *restoreLedger) load(data []byte) (int, error) {
+	lines := bytes.Split(data, []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) > 0 {
+		log.Logvf(log.Always, "ignoring incomplete last line of ledger file: %s", last)
+	}
+
+	for i, line := range lines[:len(lines)-1] {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry ledgerEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return 0, fmt.Errorf("line %v: %v", i+1, err)
+		}
+		state := ledger.previous[entry.Namespace]
+		switch entry.Status {
+		case ledgerStarted:
+			state = ledgerState{started: true, existing: entry.Count}
+		case ledgerDone:
+			state.done = true
+			state.count = entry.Count
+		default:
+			return 0, fmt.Errorf("line %v: unknown status '%v'", i+1, entry.Status)
+		}
+		ledger.previous[entry.Namespace] = state
+	}
+	return len(data) - len(last), nil
+}
+
+// record durably appends an entry to the ledger.
+func (ledger *restoreLedger) record(entry ledgerEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+	if _, err := ledger.file.Write(line); err != nil {
+		return fmt.Errorf("error writing ledger file: %v", err)
+	}
+	if err := ledger.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing ledger file: %v", err)
+	}
+	return nil
+}
+
+// Close closes the ledger file.
+func (ledger *restoreLedger) Close() error {
+	return ledger.file.Close()
+}
+
+// countDocuments returns the number of documents in the collection the intent
+// restores data to, or 0 if it does not exist.
+func (restore *MongoRestore) countDocuments(intent *intents.Intent) (int64, error) {
+	session, err := restore.SessionProvider.GetSession()
+	if err != nil {
+		return 0, fmt.Errorf("error establishing connection: %v", err)
+	}
+	return session.Database(intent.DB).
+		Collection(intent.DataCollection()).
+		EstimatedDocumentCount(context.TODO())
+}
+
+// restoreIntentWithLedger restores an intent, recording it in the ledger. When
+// resuming, a collection the ledger records as done is skipped if it still
+// has the number of documents it had when it was finished; otherwise a
+// collection the earlier restore started on is restored again from scratch.
+func (restore *MongoRestore) restoreIntentWithLedger(intent *intents.Intent) Result {
+	skip, err := restore.startInLedger(intent)
+	if err != nil || skip {
+		return Result{Err: err, skipped: skip}
+	}
+
+	result := restore.restoreIntent(intent)
+	if result.Err != nil {
+		return result
+	}
+	return result.withErr(restore.finishInLedger(intent))
+}
+
+// startInLedger records in the ledger that the restore of an intent is
+// starting, dropping what an earlier restore left of its collection first. It
+// returns true instead if the collection doesn't need to be restored again.
+func (restore *MongoRestore) startInLedger(intent *intents.Intent) (bool, error) {
+	ns := intent.Namespace()
+	previous := restore.ledger.previous[ns]
+
+	count, err := restore.countDocuments(intent)
+	if err != nil {
+		return false, fmt.Errorf("error counting documents: %v", err)
+	}
+	if previous.done && count == previous.count {
+		log.Logvf(log.Always, "skipping %v, which the ledger records as already restored", ns)
+		// The data of the collection still has to be read past in an archive.
+		if restore.InputOptions.Archive != "" && intent.BSONFile != nil {
+			if _, err := countBSONDocuments(intent); err != nil {
+				return false, fmt.Errorf("error reading %v: %v", intent.Location, err)
+			}
+		}
+		return true, nil
+	}
+
+	if previous.started {
+		if previous.done {
+			log.Logvf(log.Always,
+				"%v has %v documents but had %v when it was restored; restoring it again",
+				ns, count, previous.count)
+		} else {
+			log.Logvf(log.Always, "%v was partially restored; restoring it again", ns)
+		}
+		if !restore.OutputOptions.Drop {
+			if previous.existing > 0 {
+				return false, fmt.Errorf(
+					"cannot restore %v from scratch: it had %v documents before the "+
+						"earlier restore started on it; use %v to replace it",
+					ns, previous.existing, DropOption)
+			}
+			if err := restore.dropForRetry(intent); err != nil {
+				return false, err
+			}
+			count = 0
+		}
+	}
+
+	// --drop replaces the collection, so nothing in it is kept.
+	if restore.OutputOptions.Drop {
+		count = 0
+	}
+	return false, restore.ledger.record(
+		ledgerEntry{Namespace: ns, Status: ledgerStarted, Count: count})
+}
+
+// finishInLedger records in the ledger that an intent is fully restored.
+func (restore *MongoRestore) finishInLedger(intent *intents.Intent) error {
+	count, err := restore.countDocuments(intent)
+	if err != nil {
+		return fmt.Errorf("error counting documents: %v", err)
+	}
+	return restore.ledger.record(
+		ledgerEntry{Namespace: intent.Namespace(), Status: ledgerDone, Count: count})
+}
+
+// dropForRetry drops a partially restored collection so that it can be
+// restored again from scratch.
+func (restore *MongoRestore) dropForRetry(intent *intents.Intent) error {
+	exists, err := restore.CollectionExists(intent.DB, intent.C)
+	if err != nil {
+		return fmt.Errorf("error reading database: %v", err)
+	}
+	if !exists {
+		return nil
+	}
+	log.Logvf(log.Always, "dropping partially restored collection %v", intent.Namespace())
+	if err := restore.DropCollection(intent); err != nil {
+		return err
+	}
+	restore.removeFromKnownCollections(intent)
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongorestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	commonOpts "github.com/mongodb/mongo-tools/common/options"
+	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLedger(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir, cleanup := testutil.MakeTempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "ledger")
+
+	t.Run("entries are appended when resuming", func(t *testing.T) {
+		ledger, err := openLedger(path, false)
+		require.NoError(t, err)
+		require.NoError(t, ledger.record(ledgerEntry{Namespace: "db.a", Status: ledgerStarted}))
+		require.NoError(t, ledger.record(ledgerEntry{Namespace: "db.a", Status: ledgerDone, Count: 3}))
+		require.NoError(t, ledger.record(ledgerEntry{Namespace: "db.b", Status: ledgerStarted, Count: 2}))
+		require.NoError(t, ledger.Close())
+
+		ledger, err = openLedger(path, true)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]ledgerState{
+			"db.a": {started: true, done: true, count: 3},
+			"db.b": {started: true, existing: 2},
+		}, ledger.previous)
+		require.NoError(t, ledger.record(ledgerEntry{Namespace: "db.b", Status: ledgerDone, Count: 5}))
+		require.NoError(t, ledger.Close())
+
+		ledger, err = openLedger(path, true)
+		require.NoError(t, err)
+		assert.Equal(t, ledgerState{started: true, existing: 2, done: true, count: 5},
+			ledger.previous["db.b"])
+		require.NoError(t, ledger.Close())
+	})
+
+	t.Run("a new restore truncates the ledger", func(t *testing.T) {
+		ledger, err := openLedger(path, false)
+		require.NoError(t, err)
+		require.NoError(t, ledger.Close())
+
+		ledger, err = openLedger(path, true)
+		require.NoError(t, err)
+		assert.Empty(t, ledger.previous)
+		require.NoError(t, ledger.Close())
+	})
+
+	t.Run("restarting a namespace clears its done state", func(t *testing.T) {
+		ledger := &restoreLedger{previous: map[string]ledgerState{}}
+		_, err := ledger.load([]byte(`{"ns":"db.a","status":"started","count":0}
+{"ns":"db.a","status":"done","count":3}
+{"ns":"db.a","status":"started","count":0}
+`))
+		require.NoError(t, err)
+		assert.Equal(t, ledgerState{started: true}, ledger.previous["db.a"])
+	})
+
+	t.Run("a cut off last line is ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(
+			`{"ns":"db.a","status":"started","count":0}`+"\n"+`{"ns":"db.a","sta`), 0644))
+
+		ledger, err := openLedger(path, true)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]ledgerState{"db.a": {started: true}}, ledger.previous)
+		require.NoError(t, ledger.record(ledgerEntry{Namespace: "db.a", Status: ledgerDone, Count: 1}))
+		require.NoError(t, ledger.Close())
+
+		ledger, err = openLedger(path, true)
+		require.NoError(t, err)
+		assert.Equal(t, ledgerState{started: true, done: true, count: 1}, ledger.previous["db.a"])
+		require.NoError(t, ledger.Close())
+	})
+
+	t.Run("malformed lines are rejected", func(t *testing.T) {
+		ledger := &restoreLedger{previous: map[string]ledgerState{}}
+		_, err := ledger.load([]byte("not json\n"))
+		assert.ErrorContains(t, err, "line 1")
+
+		_, err = ledger.load([]byte(`{"ns":"db.a","status":"finished"}` + "\n"))
+		assert.ErrorContains(t, err, "unknown status 'finished'")
+	})
+
+	t.Run("resuming without a ledger file restores everything", func(t *testing.T) {
+		ledger, err := openLedger(filepath.Join(dir, "missing"), true)
+		require.NoError(t, err)
+		assert.Empty(t, ledger.previous)
+		require.NoError(t, ledger.Close())
+	})
+}
+
+func TestLedgerOptionValidation(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	restore := newMongoRestore()
+	restore.ToolOptions.Namespace = &commonOpts.Namespace{}
+
+	restore.OutputOptions = &OutputOptions{Resume: true}
+	assert.ErrorContains(t, restore.ParseAndValidateOptions(),
+		"cannot use --resume without --ledgerFile")
+
+	restore.OutputOptions = &OutputOptions{LedgerFile: "ledger", DryRun: true}
+	assert.ErrorContains(t, restore.ParseAndValidateOptions(),
+		"cannot use --ledgerFile with --dryRun")
+}
+
+func TestRestoreResume(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+	session, err := testutil.GetBareSession()
+	require.NoError(t, err, "No server available")
+
+	coll := session.Database("test").Collection("foo")
+	require.NoError(t, coll.Drop(context.Background()))
+	defer func() {
+		_ = coll.Drop(context.Background())
+	}()
+
+	dir, cleanup := testutil.MakeTempDir(t)
+	defer cleanup()
+	ledgerFile := filepath.Join(dir, "ledger")
+
+	restoreFoo := func(args ...string) Result {
+		args = append([]string{
+			NumParallelCollectionsOption, "1",
+			LedgerFileOption, ledgerFile,
+		}, args...)
+		restore, err := getRestoreWithArgs(args...)
+		require.NoError(t, err)
+		defer restore.Close()
+		restore.TargetDirectory = "testdata/foodump"
+		return restore.Restore()
+	}
+
+	result := restoreFoo()
+	require.NoError(t, result.Err)
+	assert.EqualValues(t, 1, result.Successes)
+
+	ledger, err := openLedger(ledgerFile, true)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Close())
+	assert.Equal(t, ledgerState{started: true, done: true, count: 1}, ledger.previous["test.foo"])
+
+	t.Run("fully restored collections are skipped", func(t *testing.T) {
+		result := restoreFoo(ResumeOption)
+		require.NoError(t, result.Err)
+		assert.EqualValues(t, 0, result.Successes)
+	})
+
+	t.Run("collections changed since they were restored are restored again", func(t *testing.T) {
+		_, err := coll.DeleteMany(context.Background(), bson.D{})
+		require.NoError(t, err)
+
+		result := restoreFoo(ResumeOption)
+		require.NoError(t, result.Err)
+		assert.EqualValues(t, 1, result.Successes)
+		count, err := coll.CountDocuments(context.Background(), bson.D{})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+
+	t.Run("partially restored collections are restored from scratch", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(ledgerFile,
+			[]byte(`{"ns":"test.foo","status":"started","count":0}`+"\n"), 0644))
+
+		result := restoreFoo(ResumeOption)
+		require.NoError(t, result.Err)
+		assert.EqualValues(t, 1, result.Successes)
+		assert.EqualValues(t, 0, result.Failures)
+		count, err := coll.CountDocuments(context.Background(), bson.D{})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+
+	t.Run("collections with earlier documents are only replaced with --drop", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(ledgerFile,
+			[]byte(`{"ns":"test.foo","status":"started","count":5}`+"\n"), 0644))
+
+		result := restoreFoo(ResumeOption)
+		assert.ErrorContains(t, result.Err, "use --drop to replace it")
+
+		result = restoreFoo(ResumeOption, DropOption)
+		require.NoError(t, result.Err)
+		assert.EqualValues(t, 1, result.Successes)
+	})
+}
@@ -39,6 +39,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	restore, err := mongorestore.New(opts)
 	if err != nil {
 		log.Logvf(log.Always, err.Error())
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mongodb/mongo-tools/common/bsonutil"
 	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/idx"
 	"github.com/mongodb/mongo-tools/common/intents"
@@ -150,6 +151,73 @@ func (restore *MongoRestore) addToKnownCollections(intent *intents.Intent) {
 	restore.knownCollections[intent.DB] = append(restore.knownCollections[intent.DB], intent.C)
 }
 
+// removeFromKnownCollections removes a dropped collection from the cache used
+// by CollectionExists.
+func (restore *MongoRestore) removeFromKnownCollections(intent *intents.Intent) {
+	restore.knownCollectionsMutex.Lock()
+	defer restore.knownCollectionsMutex.Unlock()
+
+	collections := restore.knownCollections[intent.DB]
+	for i, coll := range collections {
+		if coll == intent.C {
+			restore.knownCollections[intent.DB] = append(collections[:i], collections[i+1:]...)
+			return
+		}
+	}
+}
+
+// supportedIndexVersions returns the lowest and highest index versions the
+// connected server can build. Version 0 was last supported by MongoDB 3.0 and
+// version 2 was added in MongoDB 3.4.
+func (restore *MongoRestore) supportedIndexVersions() (int, int) {
+	minVersion, maxVersion := 0, 1
+	if restore.serverVersion.GTE(db.Version{3, 2, 0}) {
+		minVersion = 1
+	}
+	if restore.serverVersion.GTE(db.Version{3, 4, 0}) {
+		maxVersion = 2
+	}
+	return minVersion, maxVersion
+}
+
+// setIndexVersion sets the version an index is built with. By default the
+// dumped version is removed so that the server uses its default version,
+// unless required is true, in which case it is left as is. With
+// --upgradeIndexVersion it is set to the newest version the server supports,
+// and with --keepIndexVersion it is kept, but it is an error if the server
+// can't build it.
+func (restore *MongoRestore) setIndexVersion(
+	dbName string,
+	collectionName string,
+	index *idx.IndexDocument,
+	required bool,
+) error {
+	minVersion, maxVersion := restore.supportedIndexVersions()
+	switch {
+	case restore.OutputOptions.UpgradeIndexVersion:
+		index.Options["v"] = int32(maxVersion)
+	case restore.OutputOptions.KeepIndexVersion:
+		v, ok := index.Options["v"]
+		if !ok {
+			return nil
+		}
+		version, ok := bsonutil.Bson2Float64(v)
+		if !ok || version != float64(int(version)) ||
+			int(version) < minVersion || int(version) > maxVersion {
+			return fmt.Errorf(
+				"cannot restore index '%v' on %v.%v with %v: its version %v is not supported "+
+					"by the server, which builds index versions %v to %v; use %v to build it "+
+					"with the newest supported version",
+				index.Options["name"], dbName, collectionName, KeepIndexVersionOption,
+				v, minVersion, maxVersion, UpgradeIndexVersionOption,
+			)
+		}
+	case !required:
+		delete(index.Options, "v")
+	}
+	return nil
+}
+
 // CreateIndexes takes in an intent and an array of index documents and
 // attempts to create them using the createIndexes command. If that command
 // fails, we fall back to individual index creation.
@@ -175,10 +243,8 @@ func (restore *MongoRestore) CreateIndexes(
 		}
 		indexNames = append(indexNames, index.Options["name"].(string))
 
-		// remove the index version, forcing an update,
-		// unless we specifically want to keep it
-		if !restore.OutputOptions.KeepIndexVersion {
-			delete(index.Options, "v")
+		if err := restore.setIndexVersion(dbName, collectionName, index, false); err != nil {
+			return err
 		}
 	}
 
@@ -338,6 +404,49 @@ func createCollectionCommand(intent *intents.Intent, options bson.D) bson.D {
 	return append(bson.D{{"create", intent.C}}, options...)
 }
 
+// validateTempAuthCollections checks the names given to --tempUsersColl and
+// --tempRolesColl.
+func validateTempAuthCollections(tempUsersColl, tempRolesColl string) error {
+	for _, opt := range []struct{ option, name string }{
+		{TempUsersCollOption, tempUsersColl},
+		{TempRolesCollOption, tempRolesColl},
+	} {
+		option, name := opt.option, opt.name
+		if err := util.ValidateCollectionName(name); err != nil {
+			return fmt.Errorf("invalid %v: %v", option, err)
+		}
+		if strings.HasPrefix(name, "system.") {
+			return fmt.Errorf("invalid %v: %v is a system collection", option, name)
+		}
+	}
+	if tempUsersColl == tempRolesColl {
+		return fmt.Errorf("%v and %v must be different collections, both are %v",
+			TempUsersCollOption, TempRolesCollOption, tempUsersColl)
+	}
+	return nil
+}
+
+// dropTempCollection drops a temporary collection in the admin database used
+// by RestoreUsersOrRoles. Errors are only logged, since this runs deferred and
+// must not mask the error that ended the restore.
+func (restore *MongoRestore) dropTempCollection(name string) {
+	session, err := restore.SessionProvider.GetSession()
+	if err != nil {
+		log.Logvf(
+			log.Info,
+			"error establishing connection to drop temporary collection admin.%v: %v",
+			name,
+			err,
+		)
+		return
+	}
+	log.Logvf(log.DebugHigh, "dropping temporary collection admin.%v", name)
+	err = session.Database("admin").Collection(name).Drop(context.TODO())
+	if err != nil {
+		log.Logvf(log.Info, "error dropping temporary collection admin.%v: %v", name, err)
+	}
+}
+
 // RestoreUsersOrRoles accepts a users intent and a roles intent, and restores
 // them via _mergeAuthzCollections. Either or both can be nil. In the latter case
 // nothing is done.
@@ -462,6 +571,10 @@ func (restore *MongoRestore) RestoreUsersOrRoles(users, roles *intents.Intent) e
 			}
 		}
 
+		// make sure we always drop the temporary collection, including when
+		// restoring to it fails part way through
+		defer restore.dropTempCollection(arg.tempCollectionName)
+
 		log.Logvf(log.DebugLow, "restoring %v to temporary collection", arg.intentType)
 		result := restore.RestoreCollectionToDB(
 			"admin",
@@ -475,36 +588,6 @@ func (restore *MongoRestore) RestoreUsersOrRoles(users, roles *intents.Intent) e
 			return fmt.Errorf("error restoring %v: %v", arg.intentType, result.Err)
 		}
 
-		// make sure we always drop the temporary collection
-		defer func(cleanupArg loopArg) {
-			session, e := restore.SessionProvider.GetSession()
-			if e != nil {
-				// logging errors here because this has no way of returning that doesn't mask other errors
-				log.Logvf(
-					log.Info,
-					"error establishing connection to drop temporary collection admin.%v: %v",
-					cleanupArg.tempCollectionName,
-					e,
-				)
-				return
-			}
-			log.Logvf(
-				log.DebugHigh,
-				"dropping temporary collection admin.%v",
-				cleanupArg.tempCollectionName,
-			)
-			e = session.Database("admin").
-				Collection(cleanupArg.tempCollectionName).
-				Drop(context.TODO())
-			if e != nil {
-				log.Logvf(
-					log.Info,
-					"error dropping temporary collection admin.%v: %v",
-					cleanupArg.tempCollectionName,
-					e,
-				)
-			}
-		}(arg)
 		userTargetDB = arg.intent.DB
 	}
 
@@ -7,16 +7,24 @@
 package mongorestore
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/idx"
 	"github.com/mongodb/mongo-tools/common/intents"
+	"github.com/mongodb/mongo-tools/common/log"
 	commonOpts "github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
 	"github.com/mongodb/mongo-tools/common/testutil"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -294,3 +302,299 @@ func readCollationTestData(filename string) (bson.D, error) {
 	}
 	return data, nil
 }
+
+func TestDryRunPlan(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	writeDump := func(t *testing.T, metadata string) string {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "db"), 0o755))
+
+		var data []byte
+		for i := 0; i < 3; i++ {
+			doc, err := bson.Marshal(bson.D{{"_id", i}})
+			require.NoError(t, err)
+			data = append(data, doc...)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "db", "c.bson"), data, 0o644))
+		require.NoError(
+			t,
+			os.WriteFile(filepath.Join(dir, "db", "c.metadata.json"), []byte(metadata), 0o644),
+		)
+		return dir
+	}
+
+	newDryRunRestore := func(t *testing.T, dir string) (*MongoRestore, *bytes.Buffer) {
+		restore := newMongoRestore()
+		restore.OutputOptions = &OutputOptions{DryRun: true, Drop: true}
+		restore.indexCatalog = idx.NewIndexCatalog()
+		buff := &bytes.Buffer{}
+		log.SetWriter(buff)
+
+		target, err := newActualPath(dir)
+		require.NoError(t, err)
+		require.NoError(t, restore.CreateAllIntents(target))
+		return restore, buff
+	}
+
+	t.Run("reports planned operations", func(t *testing.T) {
+		dir := writeDump(
+			t,
+			`{"indexes":[{"v":2,"key":{"_id":1},"name":"_id_"},`+
+				`{"v":2,"key":{"a":1},"name":"a_1"}]}`,
+		)
+		restore, buff := newDryRunRestore(t, dir)
+
+		require.NoError(t, restore.logDryRunPlan())
+		out := buff.String()
+		assert.Contains(t, out, "would drop collection db.c")
+		assert.Contains(t, out, "would create collection db.c")
+		assert.Contains(t, out, "would restore 3 documents to db.c")
+		assert.Contains(t, out, "would build index a_1 on db.c")
+		assert.NotContains(t, out, "would build index _id_")
+	})
+
+	t.Run("surfaces metadata parse errors", func(t *testing.T) {
+		dir := writeDump(t, `{"indexes":[`)
+		restore, _ := newDryRunRestore(t, dir)
+
+		err := restore.logDryRunPlan()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error parsing metadata")
+	})
+}
+
+func TestSkipIndexes(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	t.Run("parses namespace and index name pairs", func(t *testing.T) {
+		skip, err := parseSkipIndexes([]string{"db.coll:a_1", "db.coll:b_1", "db.other:c:1"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]bool{
+			"db.coll":    {"a_1": true, "b_1": true},
+			"db.other:c": {"1": true},
+		}, skip)
+	})
+
+	t.Run("rejects malformed entries and the _id index", func(t *testing.T) {
+		for _, entry := range []string{"db.coll", "db.coll:", "db:a_1", ":a_1", "db.coll:_id_"} {
+			_, err := parseSkipIndexes([]string{entry})
+			assert.Error(t, err, entry)
+		}
+	})
+
+	t.Run("filters only the named indexes and warns about missing ones", func(t *testing.T) {
+		restore := newMongoRestore()
+		restore.indexCatalog = idx.NewIndexCatalog()
+		var err error
+		restore.skipIndexes, err = parseSkipIndexes([]string{"db.c:a_1", "db.c:missing_1"})
+		require.NoError(t, err)
+
+		var indexes []*idx.IndexDocument
+		for _, name := range []string{"_id_", "a_1", "b_1"} {
+			index := &idx.IndexDocument{
+				Options: bson.M{"name": name},
+				Key:     bson.D{{strings.TrimSuffix(name, "_1"), 1}},
+			}
+			indexes = append(indexes, index)
+			restore.indexCatalog.AddIndex("db", "c", index)
+		}
+
+		var names []string
+		for _, index := range restore.filterSkippedIndexes("db.c", indexes) {
+			names = append(names, index.Options["name"].(string))
+		}
+		assert.Equal(t, []string{"_id_", "b_1"}, names)
+		assert.Len(t, restore.filterSkippedIndexes("db.other", indexes), 3)
+
+		buff := &bytes.Buffer{}
+		log.SetWriter(buff)
+		restore.warnMissingSkippedIndexes()
+		assert.Contains(t, buff.String(), "index missing_1 on db.c given with --skipIndexes does not exist")
+		assert.NotContains(t, buff.String(), "index a_1 on db.c given")
+	})
+}
+
+func TestPreserveUUIDServerVersion(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	restore := newMongoRestore()
+	restore.ToolOptions.Namespace = &commonOpts.Namespace{}
+	restore.OutputOptions = &OutputOptions{PreserveUUID: true, Drop: true}
+	restore.serverVersion = db.Version{3, 4, 24}
+
+	err := restore.ParseAndValidateOptions()
+	assert.ErrorContains(t, err, "--preserveUUID requires a server version of 3.6.0 or later")
+	assert.ErrorContains(t, err, "found 3.4.24")
+}
+
+func TestConvertLegacyIndexesFromMetadata(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	restore := newMongoRestore()
+	restore.serverVersion = db.Version{4, 0, 0}
+
+	jsonBytes, err := os.ReadFile("testdata/legacy_indexes/legacy.metadata.json")
+	require.NoError(t, err)
+	meta, err := restore.MetadataFromJSON(jsonBytes)
+	require.NoError(t, err)
+
+	indexes := restore.convertLegacyIndexes(meta.Indexes, "legacy.legacy")
+	require.Len(t, indexes, 5)
+
+	t.Run("drops unsupported versions and keeps supported ones", func(t *testing.T) {
+		assert.NotContains(t, indexes[0].Options, "v")
+		assert.NotContains(t, indexes[1].Options, "v")
+		assert.EqualValues(t, 1, indexes[2].Options["v"])
+	})
+
+	t.Run("rewrites legacy key values", func(t *testing.T) {
+		assert.Equal(t, bson.D{{"a", int32(1)}}, indexes[1].Key)
+		assert.Equal(t, bson.D{{"b", int32(1)}}, indexes[2].Key)
+		assert.Equal(t, bson.D{{"c", int32(1)}, {"d", int32(-1)}}, indexes[3].Key)
+	})
+
+	t.Run("removes unknown options", func(t *testing.T) {
+		assert.NotContains(t, indexes[1].Options, "dropDups")
+		assert.Equal(t, true, indexes[1].Options["background"])
+		assert.NotContains(t, indexes[2].Options, "safe")
+		assert.Equal(t, true, indexes[3].Options["unique"])
+	})
+
+	t.Run("leaves valid specs untouched and is idempotent", func(t *testing.T) {
+		before := fmt.Sprintf("%v", indexes)
+		again := restore.convertLegacyIndexes(indexes, "legacy.legacy")
+		assert.Equal(t, before, fmt.Sprintf("%v", again))
+		assert.Equal(t, bson.D{{"e", int32(1)}}, again[4].Key)
+		assert.Equal(t, true, again[4].Options["sparse"])
+	})
+}
+
+func TestValidateTempAuthCollections(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	require.NoError(t, validateTempAuthCollections("tempusers", "temproles"))
+	require.NoError(t, validateTempAuthCollections("my.users", "my.roles"))
+
+	for _, names := range [][2]string{
+		{"", "temproles"},
+		{"tempusers", "bad$name"},
+		{"system.users", "temproles"},
+		{"temp", "temp"},
+	} {
+		assert.Error(t, validateTempAuthCollections(names[0], names[1]), names)
+	}
+}
+
+func TestRestoreUsersOrRolesDropsTempCollectionOnFailure(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.IntegrationTestType)
+
+	session, err := testutil.GetBareSession()
+	require.NoError(t, err)
+
+	const tempUsersColl = "tempusers_failedrestore"
+	restore, err := getRestoreWithArgs(TempUsersCollOption, tempUsersColl)
+	require.NoError(t, err)
+	defer restore.Close()
+
+	// A users file whose first document is valid and whose second is
+	// truncated, so the restore fails after writing to the temp collection.
+	users, err := os.ReadFile("testdata/usersdump/admin/system.users.bson")
+	require.NoError(t, err)
+	firstLen := binary.LittleEndian.Uint32(users)
+	corrupt := append(append([]byte{}, users[:firstLen]...), 0xff, 0x00, 0x00, 0x00, 0x03)
+	path := filepath.Join(t.TempDir(), "system.users.bson")
+	require.NoError(t, os.WriteFile(path, corrupt, 0o644))
+
+	intent := &intents.Intent{
+		DB:       "admin",
+		C:        "system.users",
+		Location: path,
+		Size:     int64(len(corrupt)),
+	}
+	intent.BSONFile = &realBSONFile{path: path, intent: intent}
+
+	err = restore.RestoreUsersOrRoles(intent, nil)
+	require.Error(t, err)
+
+	names, err := session.Database("admin").
+		ListCollectionNames(context.Background(), bson.M{"name": tempUsersColl})
+	require.NoError(t, err)
+	assert.Empty(t, names, "temporary users collection was not dropped")
+}
+
+func TestSetIndexVersion(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	newIndex := func(v interface{}) *idx.IndexDocument {
+		return &idx.IndexDocument{
+			Key:     bson.D{{"a", int32(1)}},
+			Options: bson.M{"name": "a_1", "v": v},
+		}
+	}
+
+	t.Run("removes the version by default", func(t *testing.T) {
+		restore := newMongoRestore()
+		restore.OutputOptions = &OutputOptions{}
+		restore.serverVersion = db.Version{4, 0, 0}
+
+		index := newIndex(int32(1))
+		require.NoError(t, restore.setIndexVersion("db", "c", index, false))
+		assert.NotContains(t, index.Options, "v")
+
+		index = newIndex(int32(1))
+		require.NoError(t, restore.setIndexVersion("db", "c", index, true))
+		assert.EqualValues(t, 1, index.Options["v"])
+	})
+
+	t.Run("keeps a supported version", func(t *testing.T) {
+		restore := newMongoRestore()
+		restore.OutputOptions = &OutputOptions{KeepIndexVersion: true}
+		restore.serverVersion = db.Version{4, 0, 0}
+
+		index := newIndex(float64(2))
+		require.NoError(t, restore.setIndexVersion("db", "c", index, false))
+		assert.EqualValues(t, 2, index.Options["v"])
+	})
+
+	t.Run("fails on a version the server can't build", func(t *testing.T) {
+		restore := newMongoRestore()
+		restore.OutputOptions = &OutputOptions{KeepIndexVersion: true}
+		restore.serverVersion = db.Version{3, 2, 0}
+
+		err := restore.setIndexVersion("db", "c", newIndex(int32(2)), false)
+		assert.ErrorContains(t, err, "cannot restore index 'a_1' on db.c with --keepIndexVersion")
+		assert.ErrorContains(t, err, "builds index versions 1 to 1")
+
+		restore.serverVersion = db.Version{4, 0, 0}
+		err = restore.setIndexVersion("db", "c", newIndex(int32(0)), false)
+		assert.ErrorContains(t, err, "its version 0 is not supported")
+	})
+
+	t.Run("upgrades to the newest supported version", func(t *testing.T) {
+		restore := newMongoRestore()
+		restore.OutputOptions = &OutputOptions{UpgradeIndexVersion: true}
+
+		restore.serverVersion = db.Version{4, 0, 0}
+		index := newIndex(int32(1))
+		require.NoError(t, restore.setIndexVersion("db", "c", index, false))
+		assert.Equal(t, int32(2), index.Options["v"])
+
+		restore.serverVersion = db.Version{3, 2, 0}
+		index = newIndex(int32(0))
+		require.NoError(t, restore.setIndexVersion("db", "c", index, false))
+		assert.Equal(t, int32(1), index.Options["v"])
+	})
+}
+
+func TestKeepAndUpgradeIndexVersionConflict(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	restore := newMongoRestore()
+	restore.ToolOptions.Namespace = &commonOpts.Namespace{}
+	restore.OutputOptions = &OutputOptions{KeepIndexVersion: true, UpgradeIndexVersion: true}
+	restore.serverVersion = db.Version{4, 0, 0}
+
+	err := restore.ParseAndValidateOptions()
+	assert.ErrorContains(t, err, "cannot use --keepIndexVersion with --upgradeIndexVersion")
+}
@@ -24,10 +24,10 @@ import (
 	"github.com/mongodb/mongo-tools/common/idx"
 	"github.com/mongodb/mongo-tools/common/intents"
 	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/ns"
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/progress"
 	"github.com/mongodb/mongo-tools/common/util"
-	"github.com/mongodb/mongo-tools/mongorestore/ns"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -80,8 +80,17 @@ type MongoRestore struct {
 
 	indexCatalog *idx.IndexCatalog
 
+	// views to create once all collections are restored, in dependency order
+	viewOrder []*intents.Intent
+
+	// index names to skip, keyed by destination namespace, from --skipIndexes
+	skipIndexes map[string]map[string]bool
+
 	archive *archive.Reader
 
+	// records fully restored collections, with --ledgerFile
+	ledger *restoreLedger
+
 	// boolean set if termination signal received; false by default
 	terminate atomic.Bool
 
@@ -206,6 +215,9 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 			return fmt.Errorf("error parsing timestamp argument to --oplogLimit: %v", err)
 		}
 	}
+	if restore.InputOptions.OplogInclusive && restore.InputOptions.OplogLimit == "" {
+		return fmt.Errorf("cannot use --oplogInclusive without --oplogLimit")
+	}
 	if restore.InputOptions.OplogFile != "" {
 		if !restore.InputOptions.OplogReplay {
 			return fmt.Errorf("cannot use --oplogFile without --oplogReplay enabled")
@@ -215,6 +227,38 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 		}
 	}
 
+	if restore.OutputOptions.KeepIndexVersion && restore.OutputOptions.UpgradeIndexVersion {
+		return fmt.Errorf(
+			"cannot use %v with %v", KeepIndexVersionOption, UpgradeIndexVersionOption,
+		)
+	}
+
+	if restore.OutputOptions.Resume && restore.OutputOptions.LedgerFile == "" {
+		return fmt.Errorf("cannot use %v without %v", ResumeOption, LedgerFileOption)
+	}
+	if restore.OutputOptions.LedgerFile != "" && restore.OutputOptions.DryRun {
+		return fmt.Errorf("cannot use %v with %v", LedgerFileOption, DryRunOption)
+	}
+	if restore.OutputOptions.FairScheduling && restore.InputOptions.Archive != "" {
+		// an archive has to be read in the order its collections were dumped
+		return fmt.Errorf("cannot use %v with %v", FairSchedulingOption, ArchiveOption)
+	}
+
+	restore.skipIndexes, err = parseSkipIndexes(restore.OutputOptions.SkipIndexes)
+	if err != nil {
+		return err
+	}
+
+	// collections are created with their original UUID by an applyOps create
+	// with a 'ui' field, which servers before 3.6 don't support
+	if restore.OutputOptions.PreserveUUID && restore.serverVersion.LT(db.Version{3, 6, 0}) {
+		sv := restore.serverVersion
+		return fmt.Errorf(
+			"--preserveUUID requires a server version of 3.6.0 or later, found %d.%d.%d",
+			sv[0], sv[1], sv[2],
+		)
+	}
+
 	// check if we are using a replica set and fall back to w=1 if we aren't (for <= 2.4)
 	nodeType, err := restore.SessionProvider.GetNodeType()
 	if err != nil {
@@ -225,7 +269,8 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 
 	// deprecations with --nsInclude --nsExclude
 	if restore.ToolOptions.Namespace.DB != "" || restore.ToolOptions.Namespace.Collection != "" {
-		if filepath.Ext(restore.TargetDirectory) != ".bson" {
+		if !strings.HasSuffix(restore.TargetDirectory, ".bson") &&
+			!strings.HasSuffix(restore.TargetDirectory, ".bson.gz") {
 			log.Logvf(log.Always, deprecatedDBAndCollectionsOptionsWarning)
 		}
 	}
@@ -298,9 +343,15 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 		return fmt.Errorf(
 			"cannot specify a negative number of insertion workers per collection")
 	}
+	if restore.OutputOptions.NumInsertionWorkers == 0 {
+		return fmt.Errorf("must specify at least one insertion worker per collection")
+	}
+
+	if restore.OutputOptions.BulkBufferSize < 1 {
+		return fmt.Errorf("%v must be at least 1", BulkBufferSizeOption)
+	}
 
 	if restore.OutputOptions.MaintainInsertionOrder {
-		restore.OutputOptions.StopOnError = true
 		restore.OutputOptions.NumInsertionWorkers = 1
 	}
 
@@ -308,6 +359,13 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 		return fmt.Errorf("cannot specify --preserveUUID without --drop")
 	}
 
+	if err := validateTempAuthCollections(
+		restore.OutputOptions.TempUsersColl,
+		restore.OutputOptions.TempRolesColl,
+	); err != nil {
+		return err
+	}
+
 	// a single dash signals reading from stdin
 	if restore.TargetDirectory == "-" {
 		if restore.InputOptions.Archive != "" {
@@ -317,6 +375,13 @@ func (restore *MongoRestore) ParseAndValidateOptions() error {
 		if restore.ToolOptions.Namespace.Collection == "" {
 			return fmt.Errorf("cannot restore from stdin without a specified collection")
 		}
+		if restore.InputOptions.VerifyChecksums {
+			return fmt.Errorf("cannot verify checksums when restoring from stdin")
+		}
+	}
+	if restore.InputOptions.VerifyChecksums && restore.InputOptions.Archive != "" {
+		log.Logv(log.Info, "archives always include per-collection checksums, "+
+			"which are verified as each collection is read")
 	}
 	if restore.InputReader == nil {
 		restore.InputReader = os.Stdin
@@ -503,7 +568,18 @@ func (restore *MongoRestore) Restore() Result {
 		return Result{Err: fmt.Errorf("cannot restore with conflicting namespace destinations")}
 	}
 
+	if restore.InputOptions.VerifyChecksums && restore.InputOptions.Archive == "" {
+		err = restore.verifyChecksums()
+		if err != nil {
+			return Result{Err: err}
+		}
+	}
+
 	if restore.OutputOptions.DryRun {
+		err = restore.logDryRunPlan()
+		if err != nil {
+			return Result{Err: fmt.Errorf("dry run error: %v", err)}
+		}
 		log.Logvf(log.Always, "dry run completed")
 		return Result{}
 	}
@@ -584,12 +660,18 @@ func (restore *MongoRestore) Restore() Result {
 	if err != nil {
 		return Result{Err: fmt.Errorf("restore error: %v", err)}
 	}
+	restore.warnMissingSkippedIndexes()
 
 	err = restore.preFlightChecks()
 	if err != nil {
 		return Result{Err: fmt.Errorf("restore error: %v", err)}
 	}
 
+	restore.viewOrder, err = intents.SortViewsByDependency(restore.manager.NormalIntents())
+	if err != nil {
+		return Result{Err: fmt.Errorf("restore error: %v", err)}
+	}
+
 	// Restore the regular collections
 	if restore.InputOptions.Archive != "" {
 		restore.manager.UsePrioritizer(restore.archive.Demux.NewPrioritizer(restore.manager))
@@ -609,11 +691,26 @@ func (restore *MongoRestore) Restore() Result {
 		restore.manager.Finalize(intents.Legacy)
 	}
 
+	if restore.OutputOptions.LedgerFile != "" {
+		restore.ledger, err = openLedger(
+			restore.OutputOptions.LedgerFile, restore.OutputOptions.Resume)
+		if err != nil {
+			return Result{Err: fmt.Errorf("restore error: %v", err)}
+		}
+		defer restore.ledger.Close()
+	}
+
 	result := restore.RestoreIntents()
 	if result.Err != nil {
 		return result
 	}
 
+	viewResult := restore.RestoreViews()
+	result.combineWith(viewResult)
+	if result.Err != nil {
+		return result
+	}
+
 	// Restore users/roles
 	if restore.ShouldRestoreUsersAndRoles() {
 		err = restore.RestoreUsersOrRoles(restore.manager.Users(), restore.manager.Roles())
@@ -31,6 +31,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
@@ -691,7 +692,7 @@ func TestMongorestoreMIOSOE(t *testing.T) {
 		So(count, ShouldEqual, 20000)
 	})
 
-	Convey("--maintainInsertionOrder stops exactly on dup key errors", t, func() {
+	Convey("--maintainInsertionOrder continues in order past dup key errors", t, func() {
 		restore, err := getRestoreWithArgs(mioSoeFile,
 			CollectionOption, coll.Name(),
 			DBOption, database.Name(),
@@ -700,6 +701,41 @@ func TestMongorestoreMIOSOE(t *testing.T) {
 		So(err, ShouldBeNil)
 		defer restore.Close()
 		So(restore.OutputOptions.MaintainInsertionOrder, ShouldBeTrue)
+		So(restore.OutputOptions.StopOnError, ShouldBeFalse)
+		So(restore.OutputOptions.NumInsertionWorkers, ShouldEqual, 1)
+
+		result := restore.Restore()
+		So(result.Err, ShouldBeNil)
+		So(result.Successes, ShouldEqual, 20000)
+		So(result.Failures, ShouldEqual, 1)
+
+		// The collection is new, so its natural order is the insertion order.
+		cursor, err := coll.Find(
+			context.Background(),
+			bson.D{},
+			mopt.Find().SetSort(bson.D{{"$natural", 1}}),
+		)
+		So(err, ShouldBeNil)
+		var docs []bson.M
+		So(cursor.All(context.Background(), &docs), ShouldBeNil)
+		So(docs, ShouldHaveLength, 20000)
+		for i, doc := range docs {
+			if doc["_id"] != int32(i+1) {
+				So(doc["_id"], ShouldEqual, i+1)
+			}
+		}
+	})
+
+	Convey("--maintainInsertionOrder with --stopOnError stops exactly on dup key errors", t, func() {
+		restore, err := getRestoreWithArgs(mioSoeFile,
+			CollectionOption, coll.Name(),
+			DBOption, database.Name(),
+			DropOption,
+			MaintainInsertionOrderOption,
+			StopOnErrorOption)
+		So(err, ShouldBeNil)
+		defer restore.Close()
+		So(restore.OutputOptions.MaintainInsertionOrder, ShouldBeTrue)
 		So(restore.OutputOptions.NumInsertionWorkers, ShouldEqual, 1)
 
 		result := restore.Restore()
@@ -3210,3 +3246,56 @@ func clearDB(t *testing.T, db *mongo.Database) {
 		_, _ = collection.DeleteMany(context.Background(), bson.M{})
 	}
 }
+
+func TestInsertionWorkerResults(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	t.Run("the result of a collection includes its insertion rate", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		log.SetWriter(buff)
+		defer log.SetWriter(os.Stderr)
+
+		result := Result{Successes: 5000, Failures: 1, Elapsed: 2 * time.Second}
+		result.log("db.c")
+		require.Contains(t, buff.String(),
+			"finished restoring db.c (5000 documents, 1 failure, 2500 docs/sec)")
+
+		buff.Reset()
+		(&Result{Successes: 1}).log("db.c")
+		require.Contains(t, buff.String(), "finished restoring db.c (1 document, 0 failures)")
+	})
+
+	t.Run("errors from every failed worker are surfaced", func(t *testing.T) {
+		first := fmt.Errorf("E11000 duplicate key error")
+		require.Equal(t, first, combineWorkerErrors([]error{first}))
+
+		err := combineWorkerErrors([]error{first, fmt.Errorf("connection reset")})
+		require.EqualError(t, err,
+			"2 insertion workers failed: E11000 duplicate key error; connection reset")
+	})
+
+	t.Run("write concern timeouts name the namespace and batch", func(t *testing.T) {
+		wcTimeout := mongo.BulkWriteException{
+			WriteConcernError: &mongo.WriteConcernError{
+				Code:    64,
+				Message: "waiting for replication timed out",
+			},
+		}
+		restore := &MongoRestore{OutputOptions: &OutputOptions{StopOnError: true}}
+		err := restore.handleWriteConcernTimeout("db.c", 3, wcTimeout)
+		require.ErrorContains(t, err, "write concern timed out for batch 3 of db.c")
+
+		buff := &bytes.Buffer{}
+		log.SetWriter(buff)
+		defer log.SetWriter(os.Stderr)
+
+		restore.OutputOptions.StopOnError = false
+		require.NoError(t, restore.handleWriteConcernTimeout("db.c", 3, wcTimeout))
+		require.Contains(t, buff.String(), "write concern timed out for batch 3 of db.c")
+
+		dupKey := mongo.BulkWriteError{WriteError: mongo.WriteError{Code: 11000}}
+		wcTimeout.WriteErrors = []mongo.BulkWriteError{dupKey}
+		err = restore.handleWriteConcernTimeout("db.c", 3, wcTimeout)
+		require.Equal(t, mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{dupKey}}, err)
+	})
+}
@@ -9,7 +9,6 @@ package mongorestore
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
@@ -169,7 +168,7 @@ func (restore *MongoRestore) HandleOp(oplogCtx *oplogContext, op db.Oplog) error
 	if !restore.TimestampBeforeLimit(op.Timestamp) {
 		log.Logvf(
 			log.DebugLow,
-			"timestamp %v is not below limit of %v; ending oplog restoration",
+			"timestamp %v is past the limit of %v; ending oplog restoration",
 			op.Timestamp,
 			restore.oplogLimit,
 		)
@@ -427,48 +426,25 @@ func (restore *MongoRestore) ApplyOps(session *mongo.Client, entries []interface
 }
 
 // TimestampBeforeLimit returns true if the given timestamp is allowed to be
-// applied to mongorestore's target database.
+// applied to mongorestore's target database. Timestamps are compared by
+// seconds and then by ordinal, so with a limit of 5:2 the entry 5:1 is applied
+// and 5:3 is not. The entry at exactly the limit is only applied with
+// --oplogInclusive.
 func (restore *MongoRestore) TimestampBeforeLimit(ts primitive.Timestamp) bool {
 	if restore.oplogLimit.T == 0 && restore.oplogLimit.I == 0 {
 		// always valid if there is no --oplogLimit set
 		return true
 	}
+	if restore.InputOptions != nil && restore.InputOptions.OplogInclusive {
+		return !util.TimestampGreaterThan(ts, restore.oplogLimit)
+	}
 	return util.TimestampGreaterThan(restore.oplogLimit, ts)
 }
 
-// ParseTimestampFlag takes in a string the form of <time_t>:<ordinal>,
-// where <time_t> is the seconds since the UNIX epoch, and <ordinal> represents
-// a counter of operations in the oplog that occurred in the specified second.
-// It parses this timestamp string and returns a bson.MongoTimestamp type.
+// ParseTimestampFlag parses the argument to --oplogLimit. See
+// util.ParseTimestampFlag.
 func ParseTimestampFlag(ts string) (primitive.Timestamp, error) {
-	var seconds, increment int
-	timestampFields := strings.Split(ts, ":")
-	if len(timestampFields) > 2 {
-		return primitive.Timestamp{}, fmt.Errorf("too many : characters")
-	}
-
-	seconds, err := strconv.Atoi(timestampFields[0])
-	if err != nil {
-		return primitive.Timestamp{}, fmt.Errorf("error parsing timestamp seconds: %v", err)
-	}
-
-	// parse the increment field if it exists
-	if len(timestampFields) == 2 {
-		if len(timestampFields[1]) > 0 {
-			increment, err = strconv.Atoi(timestampFields[1])
-			if err != nil {
-				return primitive.Timestamp{}, fmt.Errorf(
-					"error parsing timestamp increment: %v",
-					err,
-				)
-			}
-		} else {
-			// handle the case where the user writes "<time_t>:" with no ordinal
-			increment = 0
-		}
-	}
-
-	return primitive.Timestamp{T: uint32(seconds), I: uint32(increment)}, nil
+	return util.ParseTimestampFlag(ts)
 }
 
 // Server versions 3.6.0-3.6.8 and 4.0.0-4.0.2 require a 'ui' field
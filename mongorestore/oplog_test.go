@@ -114,6 +114,40 @@ func TestValidOplogLimitChecking(t *testing.T) {
 		})
 	})
 
+	Convey("With a MongoRestore instance with oplogLimit of 5:2", t, func() {
+		mr := &MongoRestore{
+			InputOptions: &InputOptions{},
+			oplogLimit:   primitive.Timestamp{T: 5, I: 2},
+		}
+
+		Convey("entries in the same second are compared by ordinal", func() {
+			So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 1}), ShouldBeTrue)
+			So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 3}), ShouldBeFalse)
+		})
+
+		Convey("the entry at the limit is excluded by default", func() {
+			So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 2}), ShouldBeFalse)
+		})
+
+		Convey("with --oplogInclusive", func() {
+			mr.InputOptions.OplogInclusive = true
+
+			Convey("the entry at the limit is included", func() {
+				So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 2}), ShouldBeTrue)
+			})
+
+			Convey("entries after the limit are still excluded", func() {
+				So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 3}), ShouldBeFalse)
+				So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 6, I: 0}), ShouldBeFalse)
+			})
+
+			Convey("entries before the limit are still included", func() {
+				So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 5, I: 1}), ShouldBeTrue)
+				So(mr.TimestampBeforeLimit(primitive.Timestamp{T: 4, I: 9}), ShouldBeTrue)
+			})
+		})
+	})
+
 	Convey("With a MongoRestore instance with no oplogLimit", t, func() {
 		mr := &MongoRestore{}
 
@@ -41,6 +41,7 @@ const (
 	ObjcheckOption               = "--objcheck"
 	OplogReplayOption            = "--oplogReplay"
 	OplogLimitOption             = "--oplogLimit"
+	OplogInclusiveOption         = "--oplogInclusive"
 	OplogFileOption              = "--oplogFile"
 	ArchiveOption                = "--archive" // Value is optional, so must use '=' if specifying one
 	RestoreDBUsersAndRolesOption = "--restoreDbUsersAndRoles"
@@ -52,12 +53,14 @@ const (
 type InputOptions struct {
 	Objcheck               bool   `long:"objcheck" description:"validate all objects before inserting"`
 	OplogReplay            bool   `long:"oplogReplay" description:"for recovering a point-in-time snapshot on a replica set that is not part of a sharded cluster."`
-	OplogLimit             string `long:"oplogLimit" value-name:"<seconds>[:ordinal]" description:"only include oplog entries before the provided Timestamp"`
+	OplogLimit             string `long:"oplogLimit" value-name:"<seconds>[:ordinal]" description:"only include oplog entries before the provided Timestamp; an ordinal of 0 is used if none is given, so the limit <seconds> excludes every entry in that second"`
+	OplogInclusive         bool   `long:"oplogInclusive" description:"with --oplogLimit, also include the oplog entry at exactly the provided Timestamp"`
 	OplogFile              string `long:"oplogFile" value-name:"<filename>" description:"oplog file to use for replay of oplog"`
 	Archive                string `long:"archive" value-name:"<filename>" optional:"true" optional-value:"-" description:"restore dump from the specified archive file.  If flag is specified without a value, archive is read from stdin"`
 	RestoreDBUsersAndRoles bool   `long:"restoreDbUsersAndRoles" description:"restore user and role definitions for the given database"`
 	Directory              string `long:"dir" value-name:"<directory-name>" description:"input directory, use '-' for stdin"`
 	Gzip                   bool   `long:"gzip" description:"decompress gzipped input"`
+	VerifyChecksums        bool   `long:"verifyChecksums" description:"before restoring anything, check each .bson file against the .sha256 file written by mongodump --writeChecksums and fail on any mismatch; archives always verify the checksum of each collection"`
 }
 
 // Name returns a human-readable group name for input options.
@@ -74,6 +77,7 @@ const (
 	ConvertLegacyIndexesOption     = "--convertLegacyIndexes"
 	NoOptionsRestoreOption         = "--noOptionsRestore"
 	KeepIndexVersionOption         = "--keepIndexVersion"
+	UpgradeIndexVersionOption      = "--upgradeIndexVersion"
 	MaintainInsertionOrderOption   = "--maintainInsertionOrder"
 	NumParallelCollectionsOption   = "--numParallelCollections"
 	NumInsertionWorkersOption      = "--numInsertionWorkersPerCollection"
@@ -84,6 +88,10 @@ const (
 	TempRolesCollOption            = "--tempRolesColl"
 	BulkBufferSizeOption           = "--batchSize"
 	FixDottedHashedIndexesOption   = "--fixDottedHashIndex"
+	SkipIndexesOption              = "--skipIndexes"
+	LedgerFileOption               = "--ledgerFile"
+	ResumeOption                   = "--resume"
+	FairSchedulingOption           = "--fairScheduling"
 )
 
 // OutputOptions defines the set of options for restoring dump data.
@@ -92,21 +100,28 @@ type OutputOptions struct {
 	DryRun bool `long:"dryRun" description:"view summary without importing anything. recommended with verbosity"`
 
 	// By default mongorestore uses a write concern of 'majority'.
-	WriteConcern             string `long:"writeConcern" value-name:"<write-concern>" default-mask:"-" description:"write concern options e.g. --writeConcern majority, --writeConcern '{w: 3, wtimeout: 500, fsync: true, j: true}'"`
-	NoIndexRestore           bool   `long:"noIndexRestore" description:"don't restore indexes"`
-	ConvertLegacyIndexes     bool   `long:"convertLegacyIndexes" description:"Removes invalid index options and rewrites legacy option values (e.g. true becomes 1)."`
-	NoOptionsRestore         bool   `long:"noOptionsRestore" description:"don't restore collection options"`
-	KeepIndexVersion         bool   `long:"keepIndexVersion" description:"don't update index version"`
-	MaintainInsertionOrder   bool   `long:"maintainInsertionOrder" description:"restore the documents in the order of their appearance in the input source. By default the insertions will be performed in an arbitrary order. Setting this flag also enables the behavior of --stopOnError and restricts NumInsertionWorkersPerCollection to 1."`
-	NumParallelCollections   int    `long:"numParallelCollections" short:"j" description:"number of collections to restore in parallel" default:"4" default-mask:"-"`
-	NumInsertionWorkers      int    `long:"numInsertionWorkersPerCollection" description:"number of insert operations to run concurrently per collection" default:"1" default-mask:"-"`
-	StopOnError              bool   `long:"stopOnError" description:"halt after encountering any error during insertion. By default, mongorestore will attempt to continue through document validation and DuplicateKey errors, but with this option enabled, the tool will stop instead. A small number of documents may be inserted after encountering an error even with this option enabled; use --maintainInsertionOrder to halt immediately after an error"`
-	BypassDocumentValidation bool   `long:"bypassDocumentValidation" description:"bypass document validation"`
-	PreserveUUID             bool   `long:"preserveUUID" description:"preserve original collection UUIDs (off by default, requires drop)"`
-	TempUsersColl            string `long:"tempUsersColl" default:"tempusers" hidden:"true"`
-	TempRolesColl            string `long:"tempRolesColl" default:"temproles" hidden:"true"`
-	BulkBufferSize           int    `long:"batchSize" default:"1000" hidden:"true"`
-	FixDottedHashedIndexes   bool   `long:"fixDottedHashIndex" description:"when enabled, all the hashed indexes on dotted fields will be created as single field ascending indexes on the destination"`
+	WriteConcern             string   `long:"writeConcern" value-name:"<write-concern>" default-mask:"-" description:"write concern options e.g. --writeConcern majority, --writeConcern '{w: 3, wtimeout: 500, fsync: true, j: true}'"`
+	NoIndexRestore           bool     `long:"noIndexRestore" description:"don't restore indexes"`
+	ConvertLegacyIndexes     bool     `long:"convertLegacyIndexes" description:"Removes invalid index options, rewrites legacy option values (e.g. true becomes 1) and drops index versions that are no longer supported (e.g. v:0)."`
+	NoOptionsRestore         bool     `long:"noOptionsRestore" description:"don't restore collection options"`
+	KeepIndexVersion         bool     `long:"keepIndexVersion" description:"build indexes with their dumped index version rather than the server's default, and fail if the server doesn't support that version"`
+	UpgradeIndexVersion      bool     `long:"upgradeIndexVersion" description:"build indexes with the newest index version the server supports"`
+	MaintainInsertionOrder   bool     `long:"maintainInsertionOrder" description:"restore the documents in the order of their appearance in the input source. By default the insertions will be performed in an arbitrary order. Setting this flag restricts NumInsertionWorkersPerCollection to 1. Unless --stopOnError is also set, documents after a document validation or DuplicateKey error are still inserted, in order."`
+	NumParallelCollections   int      `long:"numParallelCollections" short:"j" description:"number of collections to restore in parallel" default:"4" default-mask:"-"`
+	NumInsertionWorkers      int      `long:"numInsertionWorkersPerCollection" description:"number of insert operations to run concurrently per collection" default:"1" default-mask:"-"`
+	StopOnError              bool     `long:"stopOnError" description:"halt after encountering any error during insertion. By default, mongorestore will attempt to continue through document validation and DuplicateKey errors, but with this option enabled, the tool will stop instead. A small number of documents may be inserted after encountering an error even with this option enabled; use it together with --maintainInsertionOrder to halt immediately after an error"`
+	BypassDocumentValidation bool     `long:"bypassDocumentValidation" description:"bypass document validation"`
+	PreserveUUID             bool     `long:"preserveUUID" description:"preserve original collection UUIDs (off by default, requires drop)"`
+	TempUsersColl            string   `long:"tempUsersColl" value-name:"<collection-name>" default:"tempusers" description:"collection in the admin database that users are restored to before they are merged into admin.system.users; it is dropped afterward, even if the restore fails"`
+	TempRolesColl            string   `long:"tempRolesColl" value-name:"<collection-name>" default:"temproles" description:"collection in the admin database that roles are restored to before they are merged into admin.system.roles; it is dropped afterward, even if the restore fails"`
+	BulkBufferSize           int      `long:"batchSize" value-name:"<count>" default:"1000" default-mask:"-" description:"maximum number of documents to send in each bulk insert; batches are also kept below the server's maximum message size, so large documents are sent in smaller batches (defaults to 1000)"`
+	FixDottedHashedIndexes   bool     `long:"fixDottedHashIndex" description:"when enabled, all the hashed indexes on dotted fields will be created as single field ascending indexes on the destination"`
+	SkipIndexes              []string `long:"skipIndexes" value-name:"<namespace>:<index-name>" description:"don't restore the named index on the given destination namespace, e.g. 'db.coll:email_1' (may be specified multiple times)"`
+
+	LedgerFile string `long:"ledgerFile" value-name:"<filename>" description:"record each collection in the given file once it is fully restored, so that an interrupted restore can be continued with --resume"`
+	Resume     bool   `long:"resume" description:"skip the collections that --ledgerFile records as fully restored, and restore collections that were only partially restored again from scratch"`
+
+	FairScheduling bool `long:"fairScheduling" description:"restore collections together, inserting a batch of documents from each in turn, so that small collections finish promptly instead of waiting for large ones; up to --numParallelCollections times --numInsertionWorkersPerCollection batches are inserted at once (not valid with --archive)"`
 }
 
 // Name returns a human-readable group name for output options.
@@ -132,7 +147,7 @@ type NSOptions struct {
 	ExcludedCollectionPrefixes []string `long:"excludeCollectionsWithPrefix" value-name:"<collection-prefix>" description:"DEPRECATED; collections to skip over during restore that have the given prefix (may be specified multiple times to exclude additional prefixes)"`
 	NSExclude                  []string `long:"nsExclude" value-name:"<namespace-pattern>" description:"exclude matching namespaces"`
 	NSInclude                  []string `long:"nsInclude" value-name:"<namespace-pattern>" description:"include matching namespaces"`
-	NSFrom                     []string `long:"nsFrom" value-name:"<namespace-pattern>" description:"rename matching namespaces, must have matching nsTo"`
+	NSFrom                     []string `long:"nsFrom" value-name:"<namespace-pattern>" description:"rename matching namespaces, must have matching nsTo; if several patterns match a namespace, the last one given is used"`
 	NSTo                       []string `long:"nsTo" value-name:"<namespace-pattern>" description:"rename matched namespaces, must have matching nsFrom"`
 }
 
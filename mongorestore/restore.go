@@ -9,7 +9,9 @@ package mongorestore
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/bsonutil"
@@ -32,13 +34,25 @@ type Result struct {
 	Successes int64
 	Failures  int64
 	Err       error
+
+	// Elapsed is the time spent inserting the documents of a single
+	// collection. It is not combined across results.
+	Elapsed time.Duration
+
+	// skipped is set when a collection was not restored because the ledger
+	// records it as already restored.
+	skipped bool
 }
 
 // log pretty-prints the result, associated with restoring the given namespace.
 func (result *Result) log(ns string) {
-	log.Logvf(log.Always, "finished restoring %v (%v %v, %v %v)",
+	var rate string
+	if result.Elapsed > 0 {
+		rate = fmt.Sprintf(", %.0f docs/sec", float64(result.Successes)/result.Elapsed.Seconds())
+	}
+	log.Logvf(log.Always, "finished restoring %v (%v %v, %v %v%v)",
 		ns, result.Successes, util.Pluralize(int(result.Successes), "document", "documents"),
-		result.Failures, util.Pluralize(int(result.Failures), "failure", "failures"))
+		result.Failures, util.Pluralize(int(result.Failures), "failure", "failures"), rate)
 }
 
 // combineWith sums the successes and failures from both results and the overwrites the existing Err with the Err from
@@ -68,7 +82,29 @@ func NewResultFromBulkResult(result *mongo.BulkWriteResult, err error) Result {
 		nFailure = int64(len(bwe.WriteErrors))
 	}
 
-	return Result{nSuccess, nFailure, err}
+	return Result{Successes: nSuccess, Failures: nFailure, Err: err}
+}
+
+// handleWriteConcernTimeout handles an error of the given batch of inserts into
+// ns that includes a write concern timeout. The documents of the batch were
+// inserted, but may not have been replicated as the write concern requires.
+// With --stopOnError the timeout is returned as an error naming the namespace
+// and batch; otherwise it is logged and any write errors of the batch are
+// returned on their own.
+func (restore *MongoRestore) handleWriteConcernTimeout(ns string, batch int64, err error) error {
+	if !db.IsWriteConcernTimeout(err) {
+		return err
+	}
+	if restore.OutputOptions.StopOnError {
+		return fmt.Errorf("write concern timed out for batch %v of %v: %v", batch, ns, err)
+	}
+	log.Logvf(log.Always, "warning: write concern timed out for batch %v of %v; its documents "+
+		"were inserted but may not be replicated as requested: %v", batch, ns, err)
+	if bwe, ok := err.(mongo.BulkWriteException); ok && len(bwe.WriteErrors) > 0 {
+		bwe.WriteConcernError = nil
+		return bwe
+	}
+	return nil
 }
 
 func (restore *MongoRestore) RestoreIndexes() error {
@@ -149,6 +185,7 @@ func (restore *MongoRestore) RestoreIndexesForNamespace(namespace *options.Names
 			break
 		}
 	}
+	indexes = restore.filterSkippedIndexes(namespaceString, indexes)
 
 	if len(indexes) > 0 && !restore.OutputOptions.NoIndexRestore {
 		log.Logvf(log.Always, "restoring indexes for collection %v from metadata", namespaceString)
@@ -177,6 +214,71 @@ func (restore *MongoRestore) RestoreIndexesForNamespace(namespace *options.Names
 	return nil
 }
 
+// parseSkipIndexes parses --skipIndexes entries of the form
+// "<namespace>:<index-name>" into a set of index names keyed by namespace.
+// The last ':' separates the namespace from the index name.
+func parseSkipIndexes(entries []string) (map[string]map[string]bool, error) {
+	skip := map[string]map[string]bool{}
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, ":")
+		if i == -1 {
+			return nil, fmt.Errorf(
+				"invalid %v value '%v': expected <namespace>:<index-name>", SkipIndexesOption, entry)
+		}
+		namespace, name := entry[:i], entry[i+1:]
+		if dbName, collName := util.SplitNamespace(namespace); dbName == "" || collName == "" ||
+			name == "" {
+			return nil, fmt.Errorf(
+				"invalid %v value '%v': expected <namespace>:<index-name>", SkipIndexesOption, entry)
+		}
+		if name == "_id_" {
+			return nil, fmt.Errorf(
+				"invalid %v value '%v': the _id index cannot be skipped", SkipIndexesOption, entry)
+		}
+		if skip[namespace] == nil {
+			skip[namespace] = map[string]bool{}
+		}
+		skip[namespace][name] = true
+	}
+	return skip, nil
+}
+
+// filterSkippedIndexes removes the indexes named with --skipIndexes for the
+// given namespace.
+func (restore *MongoRestore) filterSkippedIndexes(
+	namespace string,
+	indexes []*idx.IndexDocument,
+) []*idx.IndexDocument {
+	skip := restore.skipIndexes[namespace]
+	if len(skip) == 0 {
+		return indexes
+	}
+	kept := make([]*idx.IndexDocument, 0, len(indexes))
+	for _, index := range indexes {
+		if name, _ := index.Options["name"].(string); skip[name] {
+			log.Logvf(log.Always, "skipping index %v on %v because of %v",
+				name, namespace, SkipIndexesOption)
+			continue
+		}
+		kept = append(kept, index)
+	}
+	return kept
+}
+
+// warnMissingSkippedIndexes logs a warning for each index named with
+// --skipIndexes that does not exist in the dump.
+func (restore *MongoRestore) warnMissingSkippedIndexes() {
+	for namespace, names := range restore.skipIndexes {
+		dbName, collName := util.SplitNamespace(namespace)
+		for name := range names {
+			if restore.indexCatalog.GetIndex(dbName, collName, name) == nil {
+				log.Logvf(log.Always, "warning: index %v on %v given with %v does not exist in the dump",
+					name, namespace, SkipIndexesOption)
+			}
+		}
+	}
+}
+
 func (restore *MongoRestore) PopulateMetadataForIntents() error {
 	intents := restore.manager.NormalIntents()
 
@@ -218,6 +320,9 @@ func (restore *MongoRestore) PopulateMetadataForIntents() error {
 				if err == nil {
 					intent.Type = "timeseries"
 				}
+				if intent.ViewOn() != "" {
+					intent.Type = "view"
+				}
 
 				restore.indexCatalog.SetCollation(intent.DB, intent.C, intent.HasSimpleCollation())
 
@@ -233,8 +338,91 @@ func (restore *MongoRestore) PopulateMetadataForIntents() error {
 	return nil
 }
 
+// logDryRunPlan reads and validates the metadata for every intent and logs the
+// collection creates, index builds, and document counts that a restore would
+// perform. It issues no writes to the target. Document counts are only
+// available when restoring from a directory, since archive and stdin data
+// cannot be read ahead of the restore.
+func (restore *MongoRestore) logDryRunPlan() error {
+	canReadData := restore.InputOptions.Archive == "" && restore.TargetDirectory != "-"
+	if canReadData {
+		err := restore.LoadIndexesFromBSON()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := restore.PopulateMetadataForIntents()
+	if err != nil {
+		return err
+	}
+	restore.warnMissingSkippedIndexes()
+
+	if _, err := intents.SortViewsByDependency(restore.manager.NormalIntents()); err != nil {
+		return err
+	}
+
+	for _, intent := range restore.manager.NormalIntents() {
+		if restore.OutputOptions.Drop {
+			log.Logvf(log.Always, "dry run: would drop collection %v", intent.Namespace())
+		}
+		log.Logvf(log.Always, "dry run: would create collection %v", intent.Namespace())
+
+		if canReadData && intent.BSONFile != nil {
+			count, err := countBSONDocuments(intent)
+			if err != nil {
+				return fmt.Errorf("error reading %v: %v", intent.Location, err)
+			}
+			log.Logvf(log.Always, "dry run: would restore %v %v to %v",
+				count, util.Pluralize(int(count), "document", "documents"), intent.Namespace())
+		}
+
+		if restore.OutputOptions.NoIndexRestore {
+			continue
+		}
+		var indexNames []string
+		indexes := restore.filterSkippedIndexes(
+			intent.Namespace(),
+			restore.indexCatalog.GetIndexes(intent.DB, intent.C),
+		)
+		for _, index := range indexes {
+			name, _ := index.Options["name"].(string)
+			if name == "_id_" {
+				// the _id index is built along with the collection
+				continue
+			}
+			indexNames = append(indexNames, name)
+		}
+		sort.Strings(indexNames)
+		for _, name := range indexNames {
+			log.Logvf(log.Always, "dry run: would build index %v on %v", name, intent.Namespace())
+		}
+	}
+	return nil
+}
+
+// countBSONDocuments opens the intent's BSON file and counts the documents in
+// it, returning an error if the file does not contain valid BSON.
+func countBSONDocuments(intent *intents.Intent) (int64, error) {
+	err := intent.BSONFile.Open()
+	if err != nil {
+		return 0, err
+	}
+	bsonSource := db.NewBufferlessBSONSource(intent.BSONFile)
+	defer bsonSource.Close()
+
+	var count int64
+	for bsonSource.LoadNext() != nil {
+		count++
+	}
+	return count, bsonSource.Err()
+}
+
 // RestoreIntents iterates through all of the intents stored in the IntentManager, and restores them.
 func (restore *MongoRestore) RestoreIntents() Result {
+	if restore.OutputOptions.FairScheduling {
+		return restore.restoreIntentsFairly()
+	}
 	log.Logvf(
 		log.DebugLow,
 		"restoring up to %v collections in parallel",
@@ -268,7 +456,9 @@ func (restore *MongoRestore) RestoreIntents() Result {
 						fileNeedsIOBuffer.TakeIOBuffer(ioBuf)
 					}
 					result := restore.RestoreIntent(intent)
-					result.log(intent.Namespace())
+					if !intent.IsView() && !result.skipped {
+						result.log(intent.Namespace())
+					}
 					workerResult.combineWith(result)
 					if result.Err != nil {
 						resultChan <- workerResult.withErr(fmt.Errorf("%v: %v", intent.Namespace(), result.Err))
@@ -303,7 +493,9 @@ func (restore *MongoRestore) RestoreIntents() Result {
 			break
 		}
 		result := restore.RestoreIntent(intent)
-		result.log(intent.Namespace())
+		if !intent.IsView() && !result.skipped {
+			result.log(intent.Namespace())
+		}
 		totalResult.combineWith(result)
 		if result.Err != nil {
 			return totalResult.withErr(fmt.Errorf("%v: %v", intent.Namespace(), result.Err))
@@ -313,11 +505,93 @@ func (restore *MongoRestore) RestoreIntents() Result {
 	return totalResult
 }
 
-// RestoreIntent attempts to restore a given intent into MongoDB.
+// RestoreViews creates the views that were skipped by RestoreIntents. They are
+// created one at a time, after every collection, so that a view defined on
+// another view is created after it.
+func (restore *MongoRestore) RestoreViews() Result {
+	var totalResult Result
+	for _, intent := range restore.viewOrder {
+		result := restore.restoreIntent(intent)
+		totalResult.combineWith(result)
+		if result.Err != nil {
+			return totalResult.withErr(fmt.Errorf("%v: %v", intent.Namespace(), result.Err))
+		}
+		log.Logvf(log.Always, "finished creating view %v on %v", intent.Namespace(), intent.ViewOn())
+	}
+	return totalResult
+}
+
+// RestoreIntent attempts to restore a given intent into MongoDB. Views are
+// not created here but by RestoreViews, once all collections are restored.
+// With --ledgerFile, the restore is recorded in the ledger.
 func (restore *MongoRestore) RestoreIntent(intent *intents.Intent) Result {
+	if intent.IsView() {
+		return Result{Err: skipViewData(intent)}
+	}
+	if restore.ledger != nil {
+		return restore.restoreIntentWithLedger(intent)
+	}
+	return restore.restoreIntent(intent)
+}
+
+// skipViewData reads past the data of a view, if any. Views have no documents,
+// but an archive still holds an empty data section for them.
+func skipViewData(intent *intents.Intent) error {
+	if intent.BSONFile == nil {
+		return nil
+	}
+	count, err := countBSONDocuments(intent)
+	if err != nil {
+		return fmt.Errorf("error reading %v: %v", intent.Location, err)
+	}
+	if count > 0 {
+		log.Logvf(log.Always, "ignoring %v %v found in the dump for view %v",
+			count, util.Pluralize(int(count), "document", "documents"), intent.Namespace())
+	}
+	return nil
+}
+
+func (restore *MongoRestore) restoreIntent(intent *intents.Intent) Result {
+	if err := restore.prepareCollection(intent); err != nil {
+		return Result{Err: err}
+	}
+
+	var result Result
+	if intent.BSONFile != nil && !intent.IsView() {
+		err := intent.BSONFile.Open()
+		if err != nil {
+			return Result{Err: err}
+		}
+		defer intent.BSONFile.Close()
+
+		log.Logvf(log.Always, "restoring %v from %v", intent.DataNamespace(), intent.Location)
+
+		bsonSource := db.NewDecodedBSONSource(db.NewBSONSource(intent.BSONFile))
+		defer bsonSource.Close()
+
+		result = restore.RestoreCollectionToDB(
+			intent.DB,
+			intent.DataCollection(),
+			bsonSource,
+			intent.BSONFile,
+			intent.Size,
+			intent.Type,
+		)
+		if result.Err != nil {
+			result.Err = fmt.Errorf("error restoring from %v: %v", intent.Location, result.Err)
+			return result
+		}
+	}
+
+	return result
+}
+
+// prepareCollection drops the collection of an intent if --drop is set and
+// creates it with the options from its metadata, unless it already exists.
+func (restore *MongoRestore) prepareCollection(intent *intents.Intent) error {
 	collectionExists, err := restore.CollectionExists(intent.DB, intent.C)
 	if err != nil {
-		return Result{Err: fmt.Errorf("error reading database: %v", err)}
+		return fmt.Errorf("error reading database: %v", err)
 	}
 
 	if !restore.OutputOptions.Drop && collectionExists {
@@ -340,7 +614,7 @@ func (restore *MongoRestore) RestoreIntent(intent *intents.Intent) Result {
 				log.Logvf(log.Always, "dropping collection %v before restoring", intent.Namespace())
 				err = restore.DropCollection(intent)
 				if err != nil {
-					return Result{Err: err} // no context needed
+					return err // no context needed
 				}
 				collectionExists = false
 			}
@@ -369,11 +643,12 @@ func (restore *MongoRestore) RestoreIntent(intent *intents.Intent) Result {
 		// The only way to specify options on the idIndex is at collection creation time.
 		IDIndex := restore.indexCatalog.GetIndex(intent.DB, intent.C, "_id_")
 		if IDIndex != nil {
-			// Remove the index version (to use the default) unless otherwise specified.
 			// If preserving UUID, we have to create a collection via
 			// applyops, which requires the "v" key.
-			if !restore.OutputOptions.KeepIndexVersion && !restore.OutputOptions.PreserveUUID {
-				delete(IDIndex.Options, "v")
+			err = restore.setIndexVersion(
+				intent.DB, intent.C, IDIndex, restore.OutputOptions.PreserveUUID)
+			if err != nil {
+				return err
 			}
 			IDIndex.Options["ns"] = intent.Namespace()
 
@@ -400,43 +675,13 @@ func (restore *MongoRestore) RestoreIntent(intent *intents.Intent) Result {
 		log.Logvf(log.DebugHigh, "using collection options: %#v", options)
 		err = restore.CreateCollection(intent, options, uuid)
 		if err != nil {
-			return Result{
-				Err: fmt.Errorf("error creating collection %v: %v", intent.Namespace(), err),
-			}
+			return fmt.Errorf("error creating collection %v: %v", intent.Namespace(), err)
 		}
 		restore.addToKnownCollections(intent)
 	} else {
 		log.Logvf(log.Info, "collection %v already exists - skipping collection create", intent.Namespace())
 	}
-
-	var result Result
-	if intent.BSONFile != nil {
-		err = intent.BSONFile.Open()
-		if err != nil {
-			return Result{Err: err}
-		}
-		defer intent.BSONFile.Close()
-
-		log.Logvf(log.Always, "restoring %v from %v", intent.DataNamespace(), intent.Location)
-
-		bsonSource := db.NewDecodedBSONSource(db.NewBSONSource(intent.BSONFile))
-		defer bsonSource.Close()
-
-		result = restore.RestoreCollectionToDB(
-			intent.DB,
-			intent.DataCollection(),
-			bsonSource,
-			intent.BSONFile,
-			intent.Size,
-			intent.Type,
-		)
-		if result.Err != nil {
-			result.Err = fmt.Errorf("error restoring from %v: %v", intent.Location, result.Err)
-			return result
-		}
-	}
-
-	return result
+	return nil
 }
 
 func (restore *MongoRestore) convertLegacyIndexes(
@@ -473,11 +718,25 @@ func (restore *MongoRestore) convertLegacyIndexes(
 		if restore.serverVersion.LT(db.Version{4, 1, 9}) {
 			bsonutil.ConvertLegacyIndexOptions(index.Options)
 		}
+		bsonutil.ConvertLegacyIndexVersion(index.Options, ns)
 		indexesConverted = append(indexesConverted, index)
 	}
 	return indexesConverted
 }
 
+// combineWorkerErrors returns a single error describing the errors of all
+// the insertion workers that failed while restoring a collection.
+func combineWorkerErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%v insertion workers failed: %v", len(errs), strings.Join(msgs, "; "))
+}
+
 func fixDottedHashedIndexes(indexes []*idx.IndexDocument) {
 	for _, index := range indexes {
 		fixDottedHashedIndex(index)
@@ -498,6 +757,63 @@ func fixDottedHashedIndex(index *idx.IndexDocument) {
 	}
 }
 
+// newBulkInserter returns a bulk inserter for the documents of a collection of
+// the given type, set up according to the restore options.
+func (restore *MongoRestore) newBulkInserter(
+	collection *mongo.Collection,
+	collectionType string,
+) *db.BufferedBulkInserter {
+	bulk := db.NewUnorderedBufferedBulkInserter(collection, restore.OutputOptions.BulkBufferSize).
+		SetOrdered(restore.OutputOptions.MaintainInsertionOrder).
+		SetContinueOnError(!restore.OutputOptions.StopOnError)
+	if collectionType != "timeseries" {
+		bulk.SetBypassDocumentValidation(restore.OutputOptions.BypassDocumentValidation)
+	}
+	return bulk
+}
+
+// batchWriter returns a function turning the outcome of each batch of inserts
+// into ns into a Result. It numbers the batches, to report which one a write
+// concern timeout happened on, and is safe for concurrent use.
+func (restore *MongoRestore) batchWriter(
+	ns string,
+) func(*mongo.BulkWriteResult, error) Result {
+	var batches atomic.Int64
+	return func(bwResult *mongo.BulkWriteResult, bwErr error) Result {
+		if bwResult == nil && bwErr == nil {
+			return Result{}
+		}
+		bwErr = restore.handleWriteConcernTimeout(ns, batches.Add(1), bwErr)
+		return NewResultFromBulkResult(bwResult, bwErr)
+	}
+}
+
+// flushBulk flushes the documents left in bulk, which inserts into
+// dbName.colName. If a timeseries bucket collection needs mixed schema
+// support for them, it is enabled and the documents are flushed again.
+func (restore *MongoRestore) flushBulk(
+	bulk *db.BufferedBulkInserter,
+	dbName, colName string,
+	writeBatch func(*mongo.BulkWriteResult, error) Result,
+) Result {
+	bwResult, bwErr := bulk.TryFlush()
+	if db.TimeseriesBucketNeedsMixedSchema(bwErr) {
+		// Modify the timeseries collection and retry flushing the bulk writer.
+		logicalColName, nameErr := db.GetTimeseriesCollNameFromBucket(colName)
+		if nameErr != nil {
+			return Result{Err: nameErr}
+		}
+
+		collModErr := restore.EnableMixedSchemaInTimeseriesBucket(dbName, logicalColName)
+		if collModErr != nil {
+			return Result{Err: errors.Wrap(
+				collModErr, "failed to enable mixed schema in a timeseries bucket")}
+		}
+		bwResult, bwErr = bulk.TryFlush()
+	}
+	return writeBatch(bwResult, bwErr)
+}
+
 // RestoreCollectionToDB pipes the given BSON data into the database.
 // Returns the number of documents restored and any errors that occurred.
 func (restore *MongoRestore) RestoreCollectionToDB(
@@ -515,19 +831,20 @@ func (restore *MongoRestore) RestoreCollectionToDB(
 	}
 
 	collection := session.Database(dbName).Collection(colName)
+	ns := fmt.Sprintf("%v.%v", dbName, colName)
 
 	documentCount := int64(0)
 	watchProgressor := progress.NewCounter(fileSize)
 	if restore.ProgressManager != nil {
-		name := fmt.Sprintf("%v.%v", dbName, colName)
-		restore.ProgressManager.Attach(name, watchProgressor)
-		defer restore.ProgressManager.Detach(name)
+		restore.ProgressManager.Attach(ns, watchProgressor)
+		defer restore.ProgressManager.Detach(ns)
 	}
 
 	maxInsertWorkers := restore.OutputOptions.NumInsertionWorkers
 
 	docChan := make(chan bson.Raw, insertBufferFactor)
 	resultChan := make(chan Result, maxInsertWorkers)
+	writeBatch := restore.batchWriter(ns)
 
 	// stream documents for this collection on docChan
 	go func() {
@@ -553,16 +870,13 @@ func (restore *MongoRestore) RestoreCollectionToDB(
 	}()
 
 	log.Logvf(log.DebugLow, "using %v insertion workers", maxInsertWorkers)
+	startTime := time.Now()
 
 	for i := 0; i < maxInsertWorkers; i++ {
 		go func() {
 			var result Result
 
-			bulk := db.NewUnorderedBufferedBulkInserter(collection, restore.OutputOptions.BulkBufferSize).
-				SetOrdered(restore.OutputOptions.MaintainInsertionOrder)
-			if collectionType != "timeseries" {
-				bulk.SetBypassDocumentValidation(restore.OutputOptions.BypassDocumentValidation)
-			}
+			bulk := restore.newBulkInserter(collection, collectionType)
 			for rawDoc := range docChan {
 				if restore.objCheck {
 					result.Err = bson.Unmarshal(rawDoc, &bson.D{})
@@ -571,7 +885,7 @@ func (restore *MongoRestore) RestoreCollectionToDB(
 						return
 					}
 				}
-				result.combineWith(NewResultFromBulkResult(bulk.InsertRaw(rawDoc)))
+				result.combineWith(writeBatch(bulk.InsertRaw(rawDoc)))
 				result.Err = db.FilterError(restore.OutputOptions.StopOnError, result.Err)
 				if result.Err != nil {
 					resultChan <- result
@@ -580,24 +894,8 @@ func (restore *MongoRestore) RestoreCollectionToDB(
 				watchProgressor.Set(file.Pos())
 			}
 			// flush the remaining docs
-			bwResult, bwErr := bulk.TryFlush()
 			defer bulk.ResetBulk()
-
-			if db.TimeseriesBucketNeedsMixedSchema(bwErr) {
-				// Modify the timeseries collection and retry flushing the bulk writer.
-				logicalColName, nameErr := db.GetTimeseriesCollNameFromBucket(colName)
-				if nameErr != nil {
-					resultChan <- result.withErr(nameErr)
-					return
-				}
-
-				if collModErr := restore.EnableMixedSchemaInTimeseriesBucket(dbName, logicalColName); collModErr != nil {
-					resultChan <- result.withErr(errors.Wrap(collModErr, "failed to enable mixed schema in a timeseries bucket"))
-					return
-				}
-				bwResult, bwErr = bulk.TryFlush()
-			}
-			result.combineWith(NewResultFromBulkResult(bwResult, bwErr))
+			result.combineWith(restore.flushBulk(bulk, dbName, colName, writeBatch))
 			resultChan <- result.withErr(db.FilterError(restore.OutputOptions.StopOnError, result.Err))
 			return
 		}()
@@ -607,19 +905,28 @@ func (restore *MongoRestore) RestoreCollectionToDB(
 	}
 
 	var totalResult Result
-	var finalErr error
+	var workerErrs []error
 
-	// wait until all insert jobs finish
+	// wait until all insert jobs finish, collecting the error of every worker
+	// that failed rather than just the first
 	for done := 0; done < maxInsertWorkers; done++ {
 		totalResult.combineWith(<-resultChan)
-		if finalErr == nil && totalResult.Err != nil {
-			finalErr = totalResult.Err
-			restore.terminate.Store(true)
+		if totalResult.Err != nil {
+			if len(workerErrs) == 0 {
+				restore.terminate.Store(true)
+			}
+			workerErrs = append(workerErrs, totalResult.Err)
 		}
 	}
+	totalResult.Elapsed = time.Since(startTime)
+
+	// if every worker stopped early, the reader may be blocked sending a
+	// document; drain it so that it notices the termination and exits
+	for range docChan {
+	}
 
-	if finalErr != nil {
-		totalResult.Err = finalErr
+	if len(workerErrs) > 0 {
+		totalResult.Err = combineWorkerErrors(workerErrs)
 	} else if err = bsonSource.Err(); err != nil {
 		totalResult.Err = fmt.Errorf("reading bson input: %v", err)
 	} else if termErr != nil {
@@ -76,6 +76,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	// verify uri options and log them
 	opts.URI.LogUnsupportedOptions()
 
@@ -110,6 +115,23 @@ func main() {
 		os.Exit(util.ExitFailure)
 	}
 
+	var numHosts int
+	if cs := opts.URI.ParsedConnString(); cs != nil {
+		numHosts = len(cs.Hosts)
+	}
+	if opts.Total && !opts.Discover && numHosts < 2 {
+		log.Logvf(
+			log.Always,
+			"--total can only be used with --discover or when monitoring multiple hosts",
+		)
+		os.Exit(util.ExitFailure)
+	}
+
+	if opts.Smooth < 0 {
+		log.Logvf(log.Always, "--smooth must not be negative")
+		os.Exit(util.ExitFailure)
+	}
+
 	if opts.Columns != "" && opts.AppendColumns != "" {
 		log.Logvf(log.Always, "-O cannot be used if -o is also specified")
 		os.Exit(util.ExitFailure)
@@ -120,6 +142,20 @@ func main() {
 		os.Exit(util.ExitFailure)
 	}
 
+	alerts := make([]*line.Alert, 0, len(opts.Alert))
+	for _, expr := range opts.Alert {
+		alert, err := line.ParseAlert(expr)
+		if err != nil {
+			log.Logvf(log.Always, "%v", err)
+			os.Exit(util.ExitFailure)
+		}
+		alerts = append(alerts, alert)
+	}
+	// a probe only needs to look at a single sample
+	if len(alerts) > 0 && opts.RowCount == 0 {
+		opts.RowCount = 1
+	}
+
 	// we have to check this here, otherwise the user will be prompted
 	// for a password for each discovered node
 	if opts.Auth.ShouldAskForPassword() {
@@ -180,13 +216,34 @@ func main() {
 
 	readerConfig := &status.ReaderConfig{
 		HumanReadable: opts.HumanReadable == "true",
+		NetUnits:      opts.NetUnits,
+		RawNetRates:   opts.Json,
 	}
 	if opts.Json {
 		readerConfig.TimeFormat = "15:04:05"
+		if opts.SleepInterval%time.Second != 0 {
+			readerConfig.TimeFormat = "15:04:05.000"
+		}
 	}
 
 	consumer := stat_consumer.NewStatConsumer(cliFlags, customHeaders,
 		keyNames, readerConfig, formatter, os.Stdout)
+	if !opts.Json {
+		consumer.SetMovingAverage(opts.Smooth)
+	}
+	if !opts.Json && !opts.Interactive {
+		consumer.SetHostHeaders(opts.Headers)
+	}
+	consumer.SetAlerts(alerts)
+	var csvOutput *stat_consumer.CSVOutput
+	if opts.OutputFile != "" {
+		csvOutput, err = stat_consumer.OpenCSVOutput(opts.OutputFile)
+		if err != nil {
+			log.Logvf(log.Always, "%v", err)
+			os.Exit(util.ExitFailure)
+		}
+		consumer.SetCSVOutput(csvOutput)
+	}
 	seedHosts := util.CreateConnectionAddrs(opts.Host, opts.Port)
 	var cluster mongostat.ClusterMonitor
 	if opts.Discover || len(seedHosts) > 1 {
@@ -195,6 +252,7 @@ func main() {
 			ErrorChan:     make(chan *status.NodeError),
 			LastStatLines: map[string]*line.StatLine{},
 			Consumer:      consumer,
+			Total:         opts.Total,
 		}
 	} else {
 		cluster = &mongostat.SyncClusterMonitor{
@@ -215,7 +273,7 @@ func main() {
 		StatOptions:   opts.StatOptions,
 		Nodes:         map[string]*mongostat.NodeMonitor{},
 		Discovered:    discoverChan,
-		SleepInterval: time.Duration(opts.SleepInterval) * time.Second,
+		SleepInterval: opts.SleepInterval,
 		Cluster:       cluster,
 	}
 
@@ -232,8 +290,19 @@ func main() {
 		monitor.Disconnect()
 	}
 	formatter.Finish()
+	if csvOutput != nil {
+		if closeErr := csvOutput.Close(); closeErr != nil {
+			log.Logvf(log.Always, "error closing output file: %v", closeErr)
+		}
+	}
 	if err != nil {
 		log.Logvf(log.Always, "Failed: %v", err)
 		os.Exit(util.ExitFailure)
 	}
+	if fired := consumer.FiredAlerts(); len(fired) > 0 {
+		for _, msg := range fired {
+			log.Logvf(log.Always, "alert fired: %v", msg)
+		}
+		os.Exit(util.ExitFailure)
+	}
 }
@@ -104,6 +104,9 @@ type ClusterMonitor interface {
 type AsyncClusterMonitor struct {
 	Discover bool
 
+	// If true, each snapshot ends with a line summing the other lines
+	Total bool
+
 	// Channel to listen for incoming stat data
 	ReportChan chan *status.ServerStatus
 
@@ -182,6 +185,9 @@ func (cluster *AsyncClusterMonitor) printSnapshot() bool {
 	if len(lines) == 0 {
 		return false
 	}
+	if cluster.Total {
+		lines = append(lines, line.NewTotalStatLine(lines))
+	}
 	return cluster.Consumer.FormatLines(lines)
 }
 
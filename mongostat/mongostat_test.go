@@ -7,12 +7,19 @@
 package mongostat
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/testtype"
+	"github.com/mongodb/mongo-tools/common/testutil"
+	"github.com/mongodb/mongo-tools/mongostat/stat_consumer"
 	"github.com/mongodb/mongo-tools/mongostat/stat_consumer/line"
 	"github.com/mongodb/mongo-tools/mongostat/status"
 	. "github.com/smartystreets/goconvey/convey"
@@ -112,6 +119,115 @@ func TestStatLine(t *testing.T) {
 	})
 }
 
+func TestNetUnits(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	headers := []string{"net_in", "net_out"}
+	serverStatusOld := readBSONFile("test_data/server_status_old.bson", t)
+	serverStatusNew := readBSONFile("test_data/server_status_new.bson", t)
+	serverStatusNew.ShardCursorType = nil
+	serverStatusOld.ShardCursorType = nil
+
+	netFields := func(config *status.ReaderConfig) (string, string) {
+		statsLine := line.NewStatLine(serverStatusOld, serverStatusNew, headers, config)
+		return statsLine.Fields["net_in"], statsLine.Fields["net_out"]
+	}
+
+	Convey("The network columns should be per-second rates in the chosen unit", t, func() {
+		testCases := []struct {
+			config  status.ReaderConfig
+			netIn   string
+			netOut  string
+			comment string
+		}{
+			{status.ReaderConfig{HumanReadable: true, NetUnits: status.NetUnitsAuto},
+				"2.00k", "3.00k", "auto sizes each value"},
+			{status.ReaderConfig{HumanReadable: true, NetUnits: status.NetUnitsBytes},
+				"2000b", "3000b", "bytes"},
+			{status.ReaderConfig{HumanReadable: true, NetUnits: status.NetUnitsKilobytes},
+				"2.00k", "3.00k", "kilobytes"},
+			{status.ReaderConfig{HumanReadable: true, NetUnits: status.NetUnitsMegabytes},
+				"0.00m", "0.00m", "megabytes"},
+			{status.ReaderConfig{HumanReadable: false, NetUnits: status.NetUnitsAuto},
+				"2000", "3000", "auto without --humanReadable"},
+			{status.ReaderConfig{HumanReadable: true, NetUnits: status.NetUnitsKilobytes,
+				RawNetRates: true}, "2000", "3000", "raw rates for --json"},
+		}
+		for _, tc := range testCases {
+			Convey(tc.comment, func() {
+				netIn, netOut := netFields(&tc.config)
+				So(netIn, ShouldEqual, tc.netIn)
+				So(netOut, ShouldEqual, tc.netOut)
+			})
+		}
+	})
+
+	Convey("--netUnits should only accept the known units", t, func() {
+		opts, err := ParseOptions([]string{"--netUnits", "kb"}, "", "")
+		So(err, ShouldBeNil)
+		So(opts.NetUnits, ShouldEqual, status.NetUnitsKilobytes)
+
+		opts, err = ParseOptions([]string{}, "", "")
+		So(err, ShouldBeNil)
+		So(opts.NetUnits, ShouldEqual, status.NetUnitsAuto)
+
+		_, err = ParseOptions([]string{"--netUnits", "gb"}, "", "")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestWiredTigerCacheColumns(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	headers := []string{"cache_evict", "cache_read_ms", "cache_write_ms"}
+	config := &status.ReaderConfig{}
+	sampleTime := time.Date(2015, time.November, 30, 4, 25, 30, 0, time.UTC)
+
+	Convey("With WiredTiger cache statistics", t, func() {
+		oldStat := &status.ServerStatus{
+			SampleTime: sampleTime,
+			WiredTiger: &status.WiredTiger{Cache: status.CacheStats{
+				ModifiedPagesEvicted:   10,
+				UnmodifiedPagesEvicted: 20,
+				AppPageReadCount:       100,
+				AppPageReadMicros:      50000,
+				AppPageWriteCount:      7,
+				AppPageWriteMicros:     1000,
+			}},
+		}
+		newStat := &status.ServerStatus{
+			SampleTime: sampleTime.Add(2 * time.Second),
+			WiredTiger: &status.WiredTiger{Cache: status.CacheStats{
+				ModifiedPagesEvicted:   30,
+				UnmodifiedPagesEvicted: 40,
+				AppPageReadCount:       140,
+				AppPageReadMicros:      150000,
+				AppPageWriteCount:      7,
+				AppPageWriteMicros:     1000,
+			}},
+		}
+
+		statLine := line.NewStatLine(oldStat, newStat, headers, config)
+		Convey("evictions are reported per second", func() {
+			So(statLine.Fields["cache_evict"], ShouldEqual, "20")
+		})
+		Convey("latencies are the average milliseconds per page over the sample", func() {
+			So(statLine.Fields["cache_read_ms"], ShouldEqual, "2.50")
+			So(statLine.Fields["cache_write_ms"], ShouldEqual, "0.00")
+		})
+	})
+
+	Convey("Without WiredTiger the columns show n/a", t, func() {
+		serverStatusOld := readBSONFile("test_data/server_status_old.bson", t)
+		serverStatusNew := readBSONFile("test_data/server_status_new.bson", t)
+
+		statLine := line.NewStatLine(serverStatusOld, serverStatusNew, headers, config)
+		for _, key := range headers {
+			So(statLine.Fields[key], ShouldEqual, "n/a")
+		}
+	})
+}
+
 func TestIsMongos(t *testing.T) {
 	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
 
@@ -136,3 +252,356 @@ func TestIsMongos(t *testing.T) {
 		So(runCheck("mongodb/bin/mongod"), ShouldBeFalse)
 	})
 }
+
+func TestJSONLineFormatter(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	defaultHeaders := make([]string, len(line.CondHeaders))
+	for i, h := range line.CondHeaders {
+		defaultHeaders[i] = h.Key
+	}
+	config := &status.ReaderConfig{TimeFormat: "15:04:05"}
+
+	serverStatusOld := readBSONFile("test_data/server_status_old.bson", t)
+	serverStatusNew := readBSONFile("test_data/server_status_new.bson", t)
+	serverStatusNew.ShardCursorType = nil
+	serverStatusOld.ShardCursorType = nil
+
+	Convey("With a JSON line formatter limited to one row", t, func() {
+		formatter := stat_consumer.FormatterConstructors["json"](1, false)
+		statLine := line.NewStatLine(serverStatusOld, serverStatusNew, defaultHeaders, config)
+
+		Convey("each sample is a single JSON object keyed by host", func() {
+			out := formatter.FormatLines(
+				[]*line.StatLine{statLine},
+				defaultHeaders,
+				line.DefaultKeyMap(),
+			)
+			So(strings.Count(out, "\n"), ShouldEqual, 1)
+
+			var parsed map[string]map[string]interface{}
+			So(json.Unmarshal([]byte(out), &parsed), ShouldBeNil)
+			So(parsed, ShouldContainKey, statLine.Fields["host"])
+
+			fields := parsed[statLine.Fields["host"]]
+			for _, key := range []string{
+				"insert", "query", "update", "delete", "getmore", "command",
+				"dirty", "used", "flushes", "vsize", "res", "qrw", "arw",
+				"net_in", "net_out", "conn", "time",
+			} {
+				So(fields, ShouldContainKey, key)
+			}
+			So(fields["insert"], ShouldEqual, "10")
+
+			Convey("and the row count is respected", func() {
+				So(formatter.IsFinished(), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestTotalStatLine(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	newLine := func(host, repl string, ops ...string) *line.StatLine {
+		return &line.StatLine{Fields: map[string]string{
+			"host":    host,
+			"repl":    repl,
+			"insert":  ops[0],
+			"query":   ops[1],
+			"update":  ops[2],
+			"delete":  ops[3],
+			"command": ops[4],
+			"getmore": ops[5],
+			"conn":    ops[6],
+		}}
+	}
+
+	Convey("With stat lines from a sharded cluster", t, func() {
+		lines := []*line.StatLine{
+			newLine("router:27017", "RTR", "100", "100", "100", "100", "100|0", "100", "100"),
+			newLine("shard0a:27017", "PRI", "10", "*0", "5", "1", "20|0", "2", "30"),
+			newLine("shard0b:27017", "SEC", "*10", "3", "*5", "*1", "4|20", "0", "7"),
+			newLine("shard1a:27017", "PRI", "7", "2", "*0", "*0", "9|1", "1", "20"),
+		}
+
+		Convey("the total sums every host except the router", func() {
+			total := line.NewTotalStatLine(lines)
+			So(total.Total, ShouldBeTrue)
+			So(total.Fields, ShouldResemble, map[string]string{
+				"host":    line.TotalHost,
+				"insert":  "17|10",
+				"query":   "5",
+				"update":  "5|5",
+				"delete":  "1|1",
+				"command": "33|21",
+				"getmore": "3",
+				"conn":    "57",
+			})
+		})
+
+		Convey("lines without new data are left out of the total", func() {
+			lines[1].Printed = true
+			lines[2].Error = fmt.Errorf("no data received")
+			total := line.NewTotalStatLine(lines)
+			So(total.Fields["insert"], ShouldEqual, "7")
+			So(total.Fields["conn"], ShouldEqual, "20")
+		})
+
+		Convey("the total sorts after all of the hosts", func() {
+			lines = append([]*line.StatLine{line.NewTotalStatLine(lines)}, lines...)
+			sort.Sort(line.StatLines(lines))
+			So(lines[len(lines)-1].Fields["host"], ShouldEqual, line.TotalHost)
+			So(lines[0].Fields["host"], ShouldEqual, "router:27017")
+		})
+	})
+}
+
+func TestMovingAverage(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	newLine := func(host, insert, command, getmore string) *line.StatLine {
+		return &line.StatLine{Fields: map[string]string{
+			"host":    host,
+			"insert":  insert,
+			"command": command,
+			"getmore": getmore,
+			"conn":    "10",
+		}}
+	}
+
+	Convey("With a moving average over three samples", t, func() {
+		avg := line.NewMovingAverage(3)
+
+		Convey("fewer than three samples are averaged over what is available", func() {
+			l := newLine("a:27017", "10", "2|0", "4")
+			avg.Apply(l)
+			So(l.Fields["insert"], ShouldEqual, "10")
+
+			l = newLine("a:27017", "20", "4|2", "0")
+			avg.Apply(l)
+			So(l.Fields["insert"], ShouldEqual, "15")
+			So(l.Fields["command"], ShouldEqual, "3|1")
+			So(l.Fields["getmore"], ShouldEqual, "2")
+			So(l.Fields["conn"], ShouldEqual, "10")
+		})
+
+		Convey("the oldest sample is dropped once the buffer is full", func() {
+			for _, insert := range []string{"10", "20", "30", "40"} {
+				l := newLine("a:27017", insert, "0|0", "0")
+				avg.Apply(l)
+				if insert == "40" {
+					So(l.Fields["insert"], ShouldEqual, "30")
+				}
+			}
+		})
+
+		Convey("replicated-only counts keep their prefix", func() {
+			l := newLine("a:27017", "*6", "0|0", "0")
+			avg.Apply(l)
+			l = newLine("a:27017", "*2", "0|0", "0")
+			avg.Apply(l)
+			So(l.Fields["insert"], ShouldEqual, "*4")
+		})
+
+		Convey("each host is averaged separately", func() {
+			avg.Apply(newLine("a:27017", "100", "0|0", "0"))
+			l := newLine("b:27017", "10", "0|0", "0")
+			avg.Apply(l)
+			So(l.Fields["insert"], ShouldEqual, "10")
+		})
+	})
+}
+
+func TestAlerts(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When parsing alerts", t, func() {
+		alert, err := line.ParseAlert(" qrw >= 100 ")
+		So(err, ShouldBeNil)
+		So(*alert, ShouldResemble, line.Alert{
+			Expr: "qrw >= 100", Column: "qrw", Op: ">=", Threshold: 100,
+		})
+
+		alert, err = line.ParseAlert("metrics.document.inserted.rate()<1.5K")
+		So(err, ShouldBeNil)
+		So(alert.Column, ShouldEqual, "metrics.document.inserted.rate()")
+		So(alert.Threshold, ShouldEqual, 1536)
+
+		for _, expr := range []string{"qrw", "qrw>", ">100", "qrw=>100", "qrw>abc"} {
+			_, err := line.ParseAlert(expr)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("When checking alerts against a StatLine", t, func() {
+		l := &line.StatLine{Fields: map[string]string{
+			"host":   "a:27017",
+			"qrw":    "3|120",
+			"insert": "*12",
+			"dirty":  "4.5%",
+			"res":    "1.2G",
+			"time":   "12:00:00",
+		}}
+		check := func(expr string) bool {
+			alert, err := line.ParseAlert(expr)
+			So(err, ShouldBeNil)
+			_, fired := alert.Check(l)
+			return fired
+		}
+
+		So(check("qrw>100"), ShouldBeTrue)
+		So(check("qrw>200"), ShouldBeFalse)
+		So(check("insert==12"), ShouldBeTrue)
+		So(check("dirty<5"), ShouldBeTrue)
+		So(check("dirty!=4.5"), ShouldBeFalse)
+		So(check("res>=1G"), ShouldBeTrue)
+		So(check("res<=1G"), ShouldBeFalse)
+
+		Convey("values that aren't numbers fire", func() {
+			So(check("time>0"), ShouldBeTrue)
+			So(check("missing<1"), ShouldBeTrue)
+		})
+	})
+
+	Convey("With a StatConsumer checking alerts", t, func() {
+		alert, err := line.ParseAlert("conn>=10")
+		So(err, ShouldBeNil)
+		consumer := stat_consumer.NewStatConsumer(0, []string{"host", "conn"},
+			line.DefaultKeyMap(), &status.ReaderConfig{},
+			stat_consumer.NewJSONLineFormatter(0, false), io.Discard)
+		consumer.SetAlerts([]*line.Alert{alert})
+
+		lines := []*line.StatLine{
+			{Fields: map[string]string{"host": "a:27017", "conn": "5"}},
+			{Fields: map[string]string{"host": "b:27017", "conn": "12"}},
+			{Fields: map[string]string{"host": "c:27017"}, Error: fmt.Errorf("down")},
+		}
+		consumer.FormatLines(lines)
+
+		fired := consumer.FiredAlerts()
+		So(fired, ShouldHaveLength, 1)
+		So(fired[0], ShouldContainSubstring, "b:27017")
+
+		Convey("lines that were already printed aren't checked again", func() {
+			consumer.FormatLines(lines[:2])
+			So(consumer.FiredAlerts(), ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestHostHeaders(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("Reading the uptime of a server", t, func() {
+		stat := &status.ServerStatus{Uptime: 3*86400 + 4*3600 + 5*60 + 6}
+		So(status.ReadUptime(&status.ReaderConfig{HumanReadable: true}, stat, nil),
+			ShouldEqual, "3d4h5m")
+		So(status.ReadUptime(&status.ReaderConfig{}, stat, nil), ShouldEqual, "273906")
+
+		stat.Uptime = 2*3600 + 7
+		So(status.ReadUptime(&status.ReaderConfig{HumanReadable: true}, stat, nil),
+			ShouldEqual, "2h0m")
+		stat.Uptime = 65
+		So(status.ReadUptime(&status.ReaderConfig{HumanReadable: true}, stat, nil),
+			ShouldEqual, "1m5s")
+	})
+
+	Convey("With a StatConsumer printing host headers", t, func() {
+		out := &strings.Builder{}
+		consumer := stat_consumer.NewStatConsumer(0, []string{"host", "conn"},
+			line.DefaultKeyMap(), &status.ReaderConfig{},
+			stat_consumer.NewGridLineFormatter(0, true), out)
+		consumer.SetHostHeaders(true)
+
+		lines := []*line.StatLine{
+			{
+				Fields:  map[string]string{"host": "b:27017", "conn": "12"},
+				Version: "7.0.2",
+				Uptime:  "1h2m",
+			},
+			{
+				Fields:  map[string]string{"host": "a:27017", "conn": "5"},
+				Version: "6.0.1",
+				Uptime:  "3d4h5m",
+			},
+			{Fields: map[string]string{"host": "c:27017"}, Error: fmt.Errorf("down")},
+			{Fields: map[string]string{"host": line.TotalHost, "conn": "17"}, Total: true},
+		}
+		consumer.FormatLines(lines)
+
+		output := strings.Split(out.String(), "\n")
+		So(output[0], ShouldEqual, "")
+		So(output[1], ShouldEqual, "a:27017  version 6.0.1  uptime 3d4h5m")
+		So(output[2], ShouldEqual, "b:27017  version 7.0.2  uptime 1h2m")
+		So(strings.Fields(output[3]), ShouldResemble, []string{"host", "conn"})
+		So(out.String(), ShouldNotContainSubstring, "c:27017  version")
+	})
+
+	Convey("Without host headers the output is unchanged", t, func() {
+		out := &strings.Builder{}
+		consumer := stat_consumer.NewStatConsumer(0, []string{"host", "conn"},
+			line.DefaultKeyMap(), &status.ReaderConfig{},
+			stat_consumer.NewGridLineFormatter(0, true), out)
+		consumer.FormatLines([]*line.StatLine{
+			{Fields: map[string]string{"host": "a:27017", "conn": "5"}, Version: "6.0.1"},
+		})
+		So(out.String(), ShouldNotContainSubstring, "version")
+	})
+}
+
+func TestCSVOutput(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	dir, cleanup := testutil.MakeTempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "stats.csv")
+
+	newLines := func() []*line.StatLine {
+		return []*line.StatLine{
+			{Fields: map[string]string{"host": "b:27017", "conn": "12"}},
+			{Fields: map[string]string{"host": "a:27017", "conn": "5"}},
+			{Fields: map[string]string{"host": "c:27017"}, Error: fmt.Errorf("down")},
+		}
+	}
+	writeLines := func(headers []string) {
+		out, err := stat_consumer.OpenCSVOutput(path)
+		So(err, ShouldBeNil)
+		consumer := stat_consumer.NewStatConsumer(0, headers,
+			line.DefaultKeyMap(), &status.ReaderConfig{},
+			stat_consumer.NewGridLineFormatter(0, true), io.Discard)
+		consumer.SetCSVOutput(out)
+		lines := newLines()
+		consumer.FormatLines(lines)
+		// lines without new data are skipped
+		consumer.FormatLines(lines)
+		So(out.Close(), ShouldBeNil)
+	}
+	readFile := func(path string) string {
+		data, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		return string(data)
+	}
+
+	Convey("With a StatConsumer writing CSV output", t, func() {
+		writeLines([]string{"host", "conn"})
+		So(readFile(path), ShouldEqual, "host,conn\na:27017,5\nb:27017,12\n")
+
+		Convey("rows are appended to an existing file with the same columns", func() {
+			writeLines([]string{"host", "conn"})
+			So(readFile(path), ShouldEqual,
+				"host,conn\na:27017,5\nb:27017,12\na:27017,5\nb:27017,12\n")
+		})
+
+		Convey("an existing file with different columns is moved aside", func() {
+			writeLines([]string{"conn", "host"})
+			So(readFile(path), ShouldEqual, "conn,host\n5,a:27017\n12,b:27017\n")
+			So(readFile(path+".1"), ShouldEqual, "host,conn\na:27017,5\nb:27017,12\n")
+		})
+
+		Reset(func() {
+			So(os.RemoveAll(dir), ShouldBeNil)
+			So(os.MkdirAll(dir, 0755), ShouldBeNil)
+		})
+	})
+}
@@ -9,12 +9,14 @@ package mongostat
 import (
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/mongodb/mongo-tools/common/log"
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/mongostat/stat_consumer"
 )
 
-var Usage = `<options> <connection-string> <polling interval in seconds>
+var Usage = `<options> <connection-string> <polling interval in seconds or as a duration, e.g. 500ms>
 
 Monitor basic MongoDB server statistics.
 
@@ -24,17 +26,26 @@ See http://docs.mongodb.com/database-tools/mongostat/ for more information.`
 
 // StatOptions defines the set of options to use for configuring mongostat.
 type StatOptions struct {
-	Columns       string `short:"o" value-name:"<field>[,<field>]*" description:"fields to show. For custom fields, use dot-syntax to index into serverStatus output, and optional methods .diff() and .rate() e.g. metrics.record.moves.diff()"`
-	AppendColumns string `short:"O" value-name:"<field>[,<field>]*" description:"like -o, but preloaded with default fields. Specified fields inserted after default output"`
-	HumanReadable string `long:"humanReadable" default:"true" description:"print sizes and time in human readable format (e.g. 1K 234M 2G). To use the more precise machine readable format, use --humanReadable=false"`
-	NoHeaders     bool   `long:"noheaders" description:"don't output column names"`
-	RowCount      int64  `long:"rowcount" value-name:"<count>" short:"n" description:"number of stats lines to print (0 for indefinite)"`
-	Discover      bool   `long:"discover" description:"discover nodes and display stats for all"`
-	Http          bool   `long:"http" description:"use HTTP instead of raw db connection"`
-	All           bool   `long:"all" description:"all optional fields"`
-	Json          bool   `long:"json" description:"output as JSON rather than a formatted table"`
-	Deprecated    bool   `long:"useDeprecatedJsonKeys" description:"use old key names; only valid with the json output option."`
-	Interactive   bool   `short:"i" long:"interactive" description:"display stats in a non-scrolling interface"`
+	Columns       string   `short:"o" value-name:"<field>[,<field>]*" description:"fields to show. For custom fields, use dot-syntax to index into serverStatus output, and optional methods .diff() and .rate() e.g. metrics.record.moves.diff()"`
+	AppendColumns string   `short:"O" value-name:"<field>[,<field>]*" description:"like -o, but preloaded with default fields. Specified fields inserted after default output"`
+	HumanReadable string   `long:"humanReadable" default:"true" description:"print sizes and time in human readable format (e.g. 1K 234M 2G). To use the more precise machine readable format, use --humanReadable=false"`
+	NoHeaders     bool     `long:"noheaders" description:"don't output column names"`
+	Headers       bool     `long:"headers" description:"before each batch of stats, print a line per host with its server version and uptime; ignored with --json and --interactive"`
+	RowCount      int64    `long:"rowcount" value-name:"<count>" short:"n" description:"number of stats lines to print (0 for indefinite)"`
+	Discover      bool     `long:"discover" description:"discover nodes and display stats for all"`
+	Total         bool     `long:"total" description:"with --discover or multiple hosts, add a row summing the operation counters and connections of all hosts except mongos routers"`
+	Smooth        int      `long:"smooth" value-name:"<samples>" description:"show the rate columns (insert, query, update, delete, getmore, command) as a moving average over the last <samples> intervals; the --json output stays per-interval"`
+	Http          bool     `long:"http" description:"use HTTP instead of raw db connection"`
+	All           bool     `long:"all" description:"all optional fields"`
+	Json          bool     `long:"json" description:"output as JSON rather than a formatted table; prints one object per sample, keyed by host"`
+	Deprecated    bool     `long:"useDeprecatedJsonKeys" description:"use old key names; only valid with the json output option."`
+	Interactive   bool     `short:"i" long:"interactive" description:"display stats in a non-scrolling interface"`
+	Interval      string   `long:"interval" value-name:"<duration>" description:"polling interval, as a number of seconds or a duration such as 500ms or 2s; may also be given as a positional argument (defaults to 1s)"`
+	Alert         []string `long:"alert" value-name:"<column><op><number>" description:"exit with a non-zero code if the condition holds for any host, e.g. --alert 'qrw>100'. The operator is one of <, <=, >, >=, == or !=. Columns such as qrw fire if any of their values does. Implies --rowcount=1 if --rowcount is not given; may be repeated"`
+
+	NetUnits string `long:"netUnits" value-name:"<unit>" choice:"auto" choice:"b" choice:"kb" choice:"mb" default:"auto" description:"unit of the net_in and net_out columns, which show bytes per second: b, kb (1000 bytes), mb (1000000 bytes), or auto to choose one for each value; the --json output always shows bytes"`
+
+	OutputFile string `long:"outputFile" value-name:"<path>" description:"also append the stats to a CSV file, one row per host and interval with the displayed columns; a header row is written to a new file, and an existing file with different columns is first renamed to <path>.N"`
 }
 
 // Name returns a human-readable group name for mongostat options.
@@ -45,7 +56,29 @@ func (*StatOptions) Name() string {
 type Options struct {
 	*options.ToolOptions
 	*StatOptions
-	SleepInterval int
+	SleepInterval time.Duration
+}
+
+// minQuietInterval is the shortest polling interval that doesn't warn about
+// the load that running serverStatus puts on the server.
+const minQuietInterval = 250 * time.Millisecond
+
+// ParseInterval parses a polling interval. A bare integer is a number of
+// seconds; anything else is parsed as a Go duration, e.g. "500ms".
+func ParseInterval(interval string) (time.Duration, error) {
+	var d time.Duration
+	if seconds, err := strconv.Atoi(interval); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else {
+		d, err = time.ParseDuration(interval)
+		if err != nil {
+			return 0, fmt.Errorf("invalid sleep interval: %v", interval)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("sleep interval must be positive")
+	}
+	return d, nil
 }
 
 func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, error) {
@@ -77,16 +110,27 @@ func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, erro
 		)
 	}
 
-	sleepInterval := 1
+	interval := statOpts.Interval
 	if len(args) == 1 {
-		sleepInterval, err = strconv.Atoi(args[0])
-		if err != nil {
-			return Options{}, fmt.Errorf("invalid sleep interval: %v", args[0])
+		if interval != "" {
+			return Options{}, fmt.Errorf(
+				"cannot give the polling interval both with --interval and as a positional argument",
+			)
 		}
-		if sleepInterval < 1 {
-			return Options{}, fmt.Errorf("sleep interval must be at least 1 second")
+		interval = args[0]
+	}
+	sleepInterval := time.Second
+	if interval != "" {
+		sleepInterval, err = ParseInterval(interval)
+		if err != nil {
+			return Options{}, err
 		}
 	}
+	if sleepInterval < minQuietInterval {
+		log.Logvf(log.Always,
+			"warning: polling every %v runs serverStatus very often and may add load to the server",
+			sleepInterval)
+	}
 
 	return Options{opts, statOpts, sleepInterval}, nil
 }
@@ -8,6 +8,7 @@ package mongostat
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/testtype"
@@ -34,7 +35,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							ConnectionString: "mongodb://localhost/",
 						},
 					},
-					SleepInterval: 2,
+					SleepInterval: 2 * time.Second,
 				},
 			},
 			{
@@ -46,7 +47,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 						},
 					},
 
-					SleepInterval: 1,
+					SleepInterval: 1 * time.Second,
 				},
 			},
 			{
@@ -57,7 +58,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							ConnectionString: "mongodb://foo",
 						},
 					},
-					SleepInterval: 2,
+					SleepInterval: 2 * time.Second,
 				},
 			},
 			{
@@ -68,7 +69,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							ConnectionString: "mongodb://foo",
 						},
 					},
-					SleepInterval: 2,
+					SleepInterval: 2 * time.Second,
 				},
 			},
 			{
@@ -79,7 +80,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							ConnectionString: "mongodb://foo",
 						},
 					},
-					SleepInterval: 2,
+					SleepInterval: 2 * time.Second,
 				},
 			},
 			{
@@ -103,7 +104,7 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							Mechanism:       "MONGODB-AWS",
 						},
 					},
-					SleepInterval: 1,
+					SleepInterval: 1 * time.Second,
 				},
 				AuthType: "aws",
 			},
@@ -132,10 +133,55 @@ func TestPositionalArgumentParsing(t *testing.T) {
 							Service: "service",
 						},
 					},
-					SleepInterval: 1,
+					SleepInterval: 1 * time.Second,
 				},
 				AuthType: "kerberos",
 			},
+			{
+				InputArgs: []string{"mongodb://foo", "500ms"},
+				ExpectedOpts: Options{
+					ToolOptions: &options.ToolOptions{
+						URI: &options.URI{
+							ConnectionString: "mongodb://foo",
+						},
+					},
+					SleepInterval: 500 * time.Millisecond,
+				},
+			},
+			{
+				InputArgs: []string{"mongodb://foo", "--interval", "250ms"},
+				ExpectedOpts: Options{
+					ToolOptions: &options.ToolOptions{
+						URI: &options.URI{
+							ConnectionString: "mongodb://foo",
+						},
+					},
+					SleepInterval: 250 * time.Millisecond,
+				},
+			},
+			{
+				InputArgs: []string{"mongodb://foo", "--interval=3"},
+				ExpectedOpts: Options{
+					ToolOptions: &options.ToolOptions{
+						URI: &options.URI{
+							ConnectionString: "mongodb://foo",
+						},
+					},
+					SleepInterval: 3 * time.Second,
+				},
+			},
+			{
+				InputArgs: []string{"mongodb://foo", "--interval=2s", "2"},
+				ExpectErr: "cannot give the polling interval both with --interval and as a positional argument",
+			},
+			{
+				InputArgs: []string{"mongodb://foo", "--interval=soon"},
+				ExpectErr: "invalid sleep interval: soon",
+			},
+			{
+				InputArgs: []string{"mongodb://foo", "0s"},
+				ExpectErr: "sleep interval must be positive",
+			},
 			{
 				InputArgs: []string{"mongodb://foo", "mongodb://bar"},
 				ExpectErr: "too many URIs found in positional arguments: only one URI can be set as a positional argument",
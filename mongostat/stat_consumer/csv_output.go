@@ -0,0 +1,145 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package stat_consumer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/mongostat/stat_consumer/line"
+)
+
+// CSVOutput appends StatLines to a file as CSV rows, alongside the regular
+// output. The header row is written when the file is empty. A file that
+// already starts with a different header is moved aside first, so that each
+// file only holds rows with the same columns.
+type CSVOutput struct {
+	path string
+	file *os.File
+	// existingHeader is the first line of the file when it was opened.
+	existingHeader string
+	wroteHeader    bool
+}
+
+// OpenCSVOutput opens the file at path for appending CSV rows, creating it if
+// it doesn't exist.
+func OpenCSVOutput(path string) (*CSVOutput, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening output file: %v", err)
+	}
+	existingHeader, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		_ = file.Close()
+		return nil, fmt.Errorf("error reading output file: %v", err)
+	}
+	return &CSVOutput{
+		path:           path,
+		file:           file,
+		existingHeader: strings.TrimRight(existingHeader, "\r\n"),
+	}, nil
+}
+
+// WriteLines appends a row for each host in lines with the given columns and
+// flushes them to the file. Lines with an error or without new data are
+// skipped. It must be called before the lines are formatted, since formatting
+// marks them as printed.
+func (co *CSVOutput) WriteLines(
+	lines []*line.StatLine,
+	headerKeys []string,
+	keyNames map[string]string,
+) error {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	if !co.wroteHeader {
+		header := make([]string, len(headerKeys))
+		for i, key := range headerKeys {
+			header[i] = keyNames[key]
+		}
+		if err := co.prepareHeader(header); err != nil {
+			return err
+		}
+		if co.existingHeader == "" {
+			_ = w.Write(header)
+		}
+		co.wroteHeader = true
+	}
+
+	sorted := make([]*line.StatLine, len(lines))
+	copy(sorted, lines)
+	sort.Sort(line.StatLines(sorted))
+	for _, l := range sorted {
+		if l.Error != nil || l.Printed {
+			continue
+		}
+		row := make([]string, len(headerKeys))
+		for i, key := range headerKeys {
+			row[i] = l.Fields[key]
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	if _, err := co.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	return nil
+}
+
+// prepareHeader moves the existing file aside if its header doesn't match the
+// given one.
+func (co *CSVOutput) prepareHeader(header []string) error {
+	if co.existingHeader == "" {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	_ = w.Write(header)
+	w.Flush()
+	if strings.TrimRight(buf.String(), "\n") == co.existingHeader {
+		return nil
+	}
+
+	rotated := co.path
+	for i := 1; ; i++ {
+		rotated = fmt.Sprintf("%v.%v", co.path, i)
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+	}
+	if err := co.file.Close(); err != nil {
+		return fmt.Errorf("error closing output file: %v", err)
+	}
+	if err := os.Rename(co.path, rotated); err != nil {
+		return fmt.Errorf("error moving aside output file: %v", err)
+	}
+	log.Logvf(log.Always, "moved %v to %v because its columns differ", co.path, rotated)
+
+	file, err := os.OpenFile(co.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening output file: %v", err)
+	}
+	co.file = file
+	co.existingHeader = ""
+	return nil
+}
+
+// Close closes the file.
+func (co *CSVOutput) Close() error {
+	return co.file.Close()
+}
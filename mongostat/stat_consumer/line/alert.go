@@ -0,0 +1,119 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package line
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// alertRE matches an alert expression: a column name, a comparison operator
+// and a number, e.g. "qrw>100".
+var alertRE = regexp.MustCompile(`^\s*([^<>=!\s]+)\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+
+// Alert is a threshold on the value of a single column.
+type Alert struct {
+	// Expr is the expression the Alert was parsed from.
+	Expr      string
+	Column    string
+	Op        string
+	Threshold float64
+}
+
+// ParseAlert parses an expression of the form <column><op><number>, where op
+// is one of <, <=, >, >=, == or !=.
+func ParseAlert(expr string) (*Alert, error) {
+	match := alertRE.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf(
+			"invalid alert '%v': expected <column><op><number> with an operator of "+
+				"<, <=, >, >=, == or !=", expr)
+	}
+	threshold, ok := parseStatValue(match[3])
+	if !ok {
+		return nil, fmt.Errorf("invalid alert '%v': '%v' is not a number", expr, match[3])
+	}
+	return &Alert{
+		Expr:      strings.TrimSpace(expr),
+		Column:    match[1],
+		Op:        match[2],
+		Threshold: threshold,
+	}, nil
+}
+
+// Check compares the value of the alert's column in l against the threshold.
+// Columns holding several values, such as qrw ("3|0"), fire if any one of
+// them does. A value that isn't a number also fires the alert, so that a
+// misspelled column can't hide a problem. It returns a description of why
+// the alert fired, or false if it didn't.
+func (a *Alert) Check(l *StatLine) (string, bool) {
+	field := l.Fields[a.Column]
+	for _, part := range strings.Split(field, "|") {
+		value, ok := parseStatValue(part)
+		if !ok {
+			return fmt.Sprintf("%v: %v on %v is not a number (%q)",
+				a.Expr, a.Column, l.Fields["host"], field), true
+		}
+		if a.compare(value) {
+			return fmt.Sprintf("%v: %v on %v is %v",
+				a.Expr, a.Column, l.Fields["host"], field), true
+		}
+	}
+	return "", false
+}
+
+func (a *Alert) compare(value float64) bool {
+	switch a.Op {
+	case "<":
+		return value < a.Threshold
+	case "<=":
+		return value <= a.Threshold
+	case ">":
+		return value > a.Threshold
+	case ">=":
+		return value >= a.Threshold
+	case "==":
+		return value == a.Threshold
+	case "!=":
+		return value != a.Threshold
+	}
+	return false
+}
+
+// statUnits are the unit suffixes used in human readable output, mapped to
+// their multipliers. Sizes use binary units while network traffic, which is
+// shown in bits, uses decimal units.
+var statUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	{"g", 1e9}, {"m", 1e6}, {"k", 1e3}, {"b", 1},
+}
+
+// parseStatValue parses a single value as formatted by mongostat, ignoring
+// the '*' prefix for replicated counts and a trailing '%', and expanding
+// human readable units.
+func parseStatValue(s string) (float64, bool) {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "*"), "%")
+	multiplier := 1.0
+	for _, unit := range statUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * multiplier, true
+}
@@ -7,14 +7,28 @@
 package line
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/mongodb/mongo-tools/mongostat/status"
 )
 
+// TotalHost is the host name of the StatLine built by NewTotalStatLine.
+const TotalHost = "total"
+
 // StatLine is a wrapper for all metrics reported by mongostat for monitored hosts.
 type StatLine struct {
 	Fields  map[string]string
 	Error   error
 	Printed bool
+
+	// Total is true for a line summing the other lines in a snapshot.
+	Total bool
+
+	// Version and Uptime describe the server the line was read from, for
+	// the per-host header lines shown with --headers.
+	Version string
+	Uptime  string
 }
 
 type StatLines []*StatLine
@@ -24,6 +38,10 @@ func (slice StatLines) Len() int {
 }
 
 func (slice StatLines) Less(i, j int) bool {
+	// the total line always sorts after the hosts it sums
+	if slice[i].Total != slice[j].Total {
+		return slice[j].Total
+	}
 	return slice[i].Fields["host"] < slice[j].Fields["host"]
 }
 
@@ -38,18 +56,87 @@ func NewStatLine(
 	c *status.ReaderConfig,
 ) *StatLine {
 	line := &StatLine{
-		Fields: make(map[string]string),
+		Fields:  make(map[string]string),
+		Version: status.ReadVersion(c, newStat, oldStat),
+		Uptime:  status.ReadUptime(c, newStat, oldStat),
 	}
 	for _, key := range headerKeys {
-		_, ok := StatHeaders[key]
-		if ok {
-			line.Fields[key] = StatHeaders[key].ReadField(c, newStat, oldStat)
-		} else {
-			line.Fields[key] = status.InterpretField(key, newStat, oldStat)
-		}
+		line.Fields[key] = ReadField(key, c, newStat, oldStat)
 	}
-	// We always need host and storage_engine, even if they aren't being displayed
+	// We always need host, storage_engine and repl, even if they aren't being displayed
 	line.Fields["host"] = StatHeaders["host"].ReadField(c, newStat, oldStat)
 	line.Fields["storage_engine"] = StatHeaders["storage_engine"].ReadField(c, newStat, oldStat)
+	line.Fields["repl"] = StatHeaders["repl"].ReadField(c, newStat, oldStat)
 	return line
 }
+
+// ReadField returns the value of the column key from two ServerStatus
+// objects. Keys that aren't in StatHeaders are interpreted as custom fields.
+func ReadField(key string, c *status.ReaderConfig, newStat, oldStat *status.ServerStatus) string {
+	if header, ok := StatHeaders[key]; ok {
+		return header.ReadField(c, newStat, oldStat)
+	}
+	return status.InterpretField(key, newStat, oldStat)
+}
+
+// opcountKeys are the opcounter columns, mapped to whether the column always
+// shows both local and replicated counts.
+var opcountKeys = map[string]bool{
+	"insert":  false,
+	"query":   false,
+	"update":  false,
+	"delete":  false,
+	"command": true,
+}
+
+// totalCountKeys are the plain numeric columns summed by NewTotalStatLine.
+var totalCountKeys = []string{"getmore", "conn"}
+
+// NewTotalStatLine sums the operation counters and connection counts of
+// lines into a single StatLine for the host TotalHost. Lines from routers are
+// left out, since the operations sent to a mongos are also counted by the
+// shards it routes them to. Lines that have an error or were already printed
+// are left out too.
+func NewTotalStatLine(lines []*StatLine) *StatLine {
+	opcounts := map[string][2]int64{}
+	counts := map[string]int64{}
+	for _, l := range lines {
+		if l.Total || l.Error != nil || l.Printed || l.Fields["repl"] == "RTR" {
+			continue
+		}
+		for key := range opcountKeys {
+			local, repl := parseOpcount(l.Fields[key])
+			sum := opcounts[key]
+			opcounts[key] = [2]int64{sum[0] + local, sum[1] + repl}
+		}
+		for _, key := range totalCountKeys {
+			n, _ := strconv.ParseInt(l.Fields[key], 10, 64)
+			counts[key] += n
+		}
+	}
+
+	total := &StatLine{
+		Fields: map[string]string{"host": TotalHost},
+		Total:  true,
+	}
+	for key, both := range opcountKeys {
+		total.Fields[key] = status.FormatOpcount(opcounts[key][0], opcounts[key][1], both)
+	}
+	for _, key := range totalCountKeys {
+		total.Fields[key] = strconv.FormatInt(counts[key], 10)
+	}
+	return total
+}
+
+// parseOpcount reverses status.FormatOpcount, returning zero for any count
+// it can't parse.
+func parseOpcount(field string) (local, repl int64) {
+	if strings.HasPrefix(field, "*") {
+		repl, _ = strconv.ParseInt(field[1:], 10, 64)
+		return 0, repl
+	}
+	localStr, replStr, _ := strings.Cut(field, "|")
+	local, _ = strconv.ParseInt(localStr, 10, 64)
+	repl, _ = strconv.ParseInt(replStr, 10, 64)
+	return local, repl
+}
@@ -0,0 +1,98 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package line
+
+import (
+	"strconv"
+
+	"github.com/mongodb/mongo-tools/mongostat/status"
+)
+
+// rateCountKeys are the plain numeric columns, besides the opcounters,
+// that report a per-second rate.
+var rateCountKeys = []string{"getmore", "cache_evict"}
+
+// ringBuffer holds the most recent samples of a single counter.
+type ringBuffer struct {
+	samples [][2]int64
+	next    int
+}
+
+// add records a sample, replacing the oldest one once the buffer is full,
+// and returns the rounded average of the samples held.
+func (r *ringBuffer) add(sample [2]int64, size int) [2]int64 {
+	if len(r.samples) < size {
+		r.samples = append(r.samples, sample)
+	} else {
+		r.samples[r.next] = sample
+	}
+	r.next = (r.next + 1) % size
+
+	var sum [2]int64
+	for _, s := range r.samples {
+		sum[0] += s[0]
+		sum[1] += s[1]
+	}
+	n := int64(len(r.samples))
+	return [2]int64{(sum[0] + n/2) / n, (sum[1] + n/2) / n}
+}
+
+// MovingAverage smooths the rate columns of successive StatLines for each
+// host by replacing them with the average of the last few samples.
+type MovingAverage struct {
+	size  int
+	rings map[string]map[string]*ringBuffer
+}
+
+// NewMovingAverage returns a MovingAverage over the last size samples.
+func NewMovingAverage(size int) *MovingAverage {
+	return &MovingAverage{
+		size:  size,
+		rings: map[string]map[string]*ringBuffer{},
+	}
+}
+
+// Apply records the rate columns of l and replaces them with their average
+// over the samples seen so far for l's host, up to the configured size.
+// It must be called exactly once for each new StatLine.
+func (m *MovingAverage) Apply(l *StatLine) {
+	if l.Error != nil || l.Total {
+		return
+	}
+	host := l.Fields["host"]
+	rings, ok := m.rings[host]
+	if !ok {
+		rings = map[string]*ringBuffer{}
+		m.rings[host] = rings
+	}
+	ring := func(key string) *ringBuffer {
+		r, ok := rings[key]
+		if !ok {
+			r = &ringBuffer{}
+			rings[key] = r
+		}
+		return r
+	}
+
+	for key, both := range opcountKeys {
+		field, ok := l.Fields[key]
+		if !ok {
+			continue
+		}
+		local, repl := parseOpcount(field)
+		avg := ring(key).add([2]int64{local, repl}, m.size)
+		l.Fields[key] = status.FormatOpcount(avg[0], avg[1], both)
+	}
+	for _, key := range rateCountKeys {
+		n, err := strconv.ParseInt(l.Fields[key], 10, 64)
+		if err != nil {
+			continue
+		}
+		avg := ring(key).add([2]int64{n, 0}, m.size)
+		l.Fields[key] = strconv.FormatInt(avg[0], 10)
+	}
+}
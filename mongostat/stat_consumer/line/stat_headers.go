@@ -32,6 +32,8 @@ type StatHeader struct {
 
 // StatHeaders are the complete set of data metrics supported by mongostat.
 var (
+	// keyNames maps each field to its column names. The short names are also
+	// the stable key names used for each field in --json output.
 	keyNames = map[string][]string{ // short, long, deprecated
 		"host":           {"host", "Host", "host"},
 		"storage_engine": {"storage_engine", "Storage engine", "engine"},
@@ -43,6 +45,9 @@ var (
 		"command":        {"command", "Command opcounter (diff)", "command"},
 		"dirty":          {"dirty", "Cache dirty (percentage)", "% dirty"},
 		"used":           {"used", "Cache used (percentage)", "% used"},
+		"cache_evict":    {"cache_evict", "Cache pages evicted (diff)", "evict"},
+		"cache_read_ms":  {"cache_read_ms", "Cache page read from disk latency (ms)", "cacheReadMs"},
+		"cache_write_ms": {"cache_write_ms", "Cache page write to disk latency (ms)", "cacheWriteMs"},
 		"flushes":        {"flushes", "Number of flushes (diff)", "flushes"},
 		"mapped":         {"mapped", "Mapped (size)", "mapped"},
 		"vsize":          {"vsize", "Virtual (size)", "vsize"},
@@ -54,8 +59,8 @@ var (
 		"locked_db":      {"locked_db", "Locked db info, '(db):(percentage)'", "locked"},
 		"qrw":            {"qrw", "Queued accesses, read|write", "qr|qw"},
 		"arw":            {"arw", "Active accesses, read|write", "ar|aw"},
-		"net_in":         {"net_in", "Network input (size)", "netIn"},
-		"net_out":        {"net_out", "Network output (size)", "netOut"},
+		"net_in":         {"net_in", "Network input (bytes/sec)", "netIn"},
+		"net_out":        {"net_out", "Network output (bytes/sec)", "netOut"},
 		"conn":           {"conn", "Current connection count", "conn"},
 		"set":            {"set", "FlagReplica set name", "set"},
 		"repl":           {"repl", "FlagReplica set type", "repl"},
@@ -72,6 +77,9 @@ var (
 		"command":        {status.ReadCommand},
 		"dirty":          {status.ReadDirty},
 		"used":           {status.ReadUsed},
+		"cache_evict":    {status.ReadCacheEvict},
+		"cache_read_ms":  {status.ReadCacheReadMs},
+		"cache_write_ms": {status.ReadCacheWriteMs},
 		"flushes":        {status.ReadFlushes},
 		"mapped":         {status.ReadMapped},
 		"vsize":          {status.ReadVSize},
@@ -103,6 +111,9 @@ var (
 		{"command", FlagAlways},
 		{"dirty", FlagWT},
 		{"used", FlagWT},
+		{"cache_evict", FlagWT | FlagAll},
+		{"cache_read_ms", FlagWT | FlagAll},
+		{"cache_write_ms", FlagWT | FlagAll},
 		{"flushes", FlagAlways},
 		{"mapped", FlagMMAP},
 		{"vsize", FlagAlways},
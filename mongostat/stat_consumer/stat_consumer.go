@@ -7,9 +7,12 @@
 package stat_consumer
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/mongodb/mongo-tools/common/util"
 	"github.com/mongodb/mongo-tools/mongostat/stat_consumer/line"
@@ -27,6 +30,11 @@ type StatConsumer struct {
 	keyNames               map[string]string
 	writer                 io.Writer
 	flags                  int
+	movingAverage          *line.MovingAverage
+	alerts                 []*line.Alert
+	firedAlerts            []string
+	hostHeaders            bool
+	csvOutput              *CSVOutput
 }
 
 // NewStatConsumer creates a new StatConsumer with no previous records.
@@ -53,12 +61,55 @@ func NewStatConsumer(
 	return sc
 }
 
+// SetMovingAverage makes the consumer report the rate columns of each host
+// averaged over its last samples StatLines. A value below 2 disables it.
+func (sc *StatConsumer) SetMovingAverage(samples int) {
+	if samples < 2 {
+		sc.movingAverage = nil
+		return
+	}
+	sc.movingAverage = line.NewMovingAverage(samples)
+}
+
+// SetAlerts makes the consumer check each StatLine it formats against the
+// given alerts. Their columns are read even if they aren't displayed.
+func (sc *StatConsumer) SetAlerts(alerts []*line.Alert) {
+	sc.alerts = alerts
+}
+
+// SetHostHeaders makes the consumer print a line for each host before every
+// batch of StatLines, showing the host's server version and uptime.
+func (sc *StatConsumer) SetHostHeaders(enabled bool) {
+	sc.hostHeaders = enabled
+}
+
+// SetCSVOutput makes the consumer also append each batch of StatLines to out
+// as CSV rows, with the same columns as the formatted output.
+func (sc *StatConsumer) SetCSVOutput(out *CSVOutput) {
+	sc.csvOutput = out
+}
+
+// FiredAlerts returns a description of each alert that fired for the
+// StatLines formatted so far.
+func (sc *StatConsumer) FiredAlerts() []string {
+	return sc.firedAlerts
+}
+
 // Update takes in a ServerStatus and returns a StatLine if it has a previous record.
 func (sc *StatConsumer) Update(newStat *status.ServerStatus) (l *line.StatLine, seen bool) {
 	oldStat, seen := sc.oldStats[newStat.Host]
 	sc.oldStats[newStat.Host] = newStat
 	if seen {
 		l = line.NewStatLine(oldStat, newStat, sc.headers, sc.readerConfig)
+		for _, alert := range sc.alerts {
+			if _, ok := l.Fields[alert.Column]; !ok {
+				l.Fields[alert.Column] = line.ReadField(
+					alert.Column, sc.readerConfig, newStat, oldStat)
+			}
+		}
+		if sc.movingAverage != nil {
+			sc.movingAverage.Apply(l)
+		}
 		return
 	}
 
@@ -90,7 +141,25 @@ func (sc *StatConsumer) Update(newStat *status.ServerStatus) (l *line.StatLine,
 // FormatLines consumes StatLines, formats them, and sends them to its writer
 // It returns true if the formatter should no longer receive data.
 func (sc *StatConsumer) FormatLines(lines []*line.StatLine) bool {
+	sc.checkAlerts(lines)
+	if sc.csvOutput != nil {
+		err := sc.csvOutput.WriteLines(lines, sc.headers, sc.keyNames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing CSV output: %v", err)
+			os.Exit(util.ExitFailure)
+		}
+	}
+	var hostHeader string
+	if sc.hostHeaders {
+		hostHeader = formatHostHeader(lines)
+	}
 	str := sc.formatter.FormatLines(lines, sc.headers, sc.keyNames)
+	// keep the blank line separating batches from multiple hosts first
+	if strings.HasPrefix(str, "\n") {
+		str = "\n" + hostHeader + str[1:]
+	} else {
+		str = hostHeader + str
+	}
 	_, err := fmt.Fprintf(sc.writer, "%s", str)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error writing formatted output: %v", err)
@@ -98,3 +167,36 @@ func (sc *StatConsumer) FormatLines(lines []*line.StatLine) bool {
 	}
 	return sc.formatter.IsFinished()
 }
+
+// checkAlerts records the alerts that fire for lines. Lines that have an
+// error or were already printed are skipped, as is the total line.
+func (sc *StatConsumer) checkAlerts(lines []*line.StatLine) {
+	for _, l := range lines {
+		if l.Error != nil || l.Printed || l.Total {
+			continue
+		}
+		for _, alert := range sc.alerts {
+			if msg, fired := alert.Check(l); fired {
+				sc.firedAlerts = append(sc.firedAlerts, msg)
+			}
+		}
+	}
+}
+
+// formatHostHeader returns a line for each host with data in lines, sorted by
+// host, showing its server version and uptime.
+func formatHostHeader(lines []*line.StatLine) string {
+	var hostLines []*line.StatLine
+	for _, l := range lines {
+		if l.Error == nil && !l.Printed && !l.Total {
+			hostLines = append(hostLines, l)
+		}
+	}
+	sort.Sort(line.StatLines(hostLines))
+
+	buf := &bytes.Buffer{}
+	for _, l := range hostLines {
+		fmt.Fprintf(buf, "%v  version %v  uptime %v\n", l.Fields["host"], l.Version, l.Uptime)
+	}
+	return buf.String()
+}
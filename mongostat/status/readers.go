@@ -19,8 +19,23 @@ import (
 type ReaderConfig struct {
 	HumanReadable bool
 	TimeFormat    string
+
+	// NetUnits is the unit of the net_in and net_out columns, one of the
+	// NetUnits constants. An empty value is the same as NetUnitsAuto.
+	NetUnits string
+	// RawNetRates shows net_in and net_out as plain numbers of bytes per
+	// second, whatever NetUnits is.
+	RawNetRates bool
 }
 
+// Units for the net_in and net_out columns, given with --netUnits.
+const (
+	NetUnitsAuto      = "auto"
+	NetUnitsBytes     = "b"
+	NetUnitsKilobytes = "kb"
+	NetUnitsMegabytes = "mb"
+)
+
 type LockUsage struct {
 	Namespace string
 	Reads     int64
@@ -48,6 +63,23 @@ func formatBits(should bool, amt int64) string {
 	return fmt.Sprintf("%v", amt)
 }
 
+// formatNetRate formats a network rate in bytes per second. With
+// NetUnitsAuto, the unit is chosen to show each rate with three digits.
+func formatNetRate(c *ReaderConfig, bytesPerSec int64) string {
+	if c.RawNetRates {
+		return fmt.Sprintf("%v", bytesPerSec)
+	}
+	switch c.NetUnits {
+	case NetUnitsBytes:
+		return fmt.Sprintf("%vb", bytesPerSec)
+	case NetUnitsKilobytes:
+		return fmt.Sprintf("%.2fk", float64(bytesPerSec)/1000)
+	case NetUnitsMegabytes:
+		return fmt.Sprintf("%.2fm", float64(bytesPerSec)/(1000*1000))
+	}
+	return formatBits(c.HumanReadable, bytesPerSec)
+}
+
 func formatMegabyteAmount(should bool, amt int64) string {
 	if should {
 		return text.FormatMegabyteAmount(amt)
@@ -129,6 +161,14 @@ func diffOp(newStat, oldStat *ServerStatus, f func(*OpcountStats) int64, both bo
 	if newStat.OpcountersRepl != nil && oldStat.OpcountersRepl != nil {
 		opcountRepl = diff(f(newStat.OpcountersRepl), f(oldStat.OpcountersRepl), sampleSecs)
 	}
+	return FormatOpcount(opcount, opcountRepl, both)
+}
+
+// FormatOpcount formats a pair of local and replicated operation counts the
+// way they appear in the opcounter columns: replicated-only counts are
+// prefixed with '*', and both are shown as "local|repl" if both is set or
+// both are non-zero.
+func FormatOpcount(opcount, opcountRepl int64, both bool) string {
 	switch {
 	case both || opcount > 0 && opcountRepl > 0:
 		return fmt.Sprintf("%v|%v", opcount, opcountRepl)
@@ -180,6 +220,28 @@ func ReadHost(_ *ReaderConfig, newStat, _ *ServerStatus) string {
 	return newStat.Host
 }
 
+func ReadVersion(_ *ReaderConfig, newStat, _ *ServerStatus) string {
+	return newStat.Version
+}
+
+// ReadUptime returns how long the server has been running, as a duration like
+// "3d4h5m" when human readable output is enabled, or in seconds otherwise.
+func ReadUptime(c *ReaderConfig, newStat, _ *ServerStatus) string {
+	if !c.HumanReadable {
+		return fmt.Sprintf("%v", newStat.Uptime)
+	}
+	seconds := newStat.Uptime
+	days, hours, minutes := seconds/86400, seconds/3600%24, seconds/60%60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh%dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm%ds", minutes, seconds%60)
+	}
+}
+
 func ReadStorageEngine(_ *ReaderConfig, newStat, _ *ServerStatus) string {
 	return getStorageEngine(newStat)
 }
@@ -250,6 +312,54 @@ func ReadUsed(c *ReaderConfig, newStat, _ *ServerStatus) (val string) {
 	return
 }
 
+// ReadCacheEvict reports the number of pages evicted from the WiredTiger
+// cache per second, or "n/a" if the node doesn't run WiredTiger.
+func ReadCacheEvict(_ *ReaderConfig, newStat, oldStat *ServerStatus) string {
+	if newStat.WiredTiger == nil || oldStat.WiredTiger == nil {
+		return "n/a"
+	}
+	sampleSecs := float64(newStat.SampleTime.Sub(oldStat.SampleTime).Seconds())
+	newCache, oldCache := newStat.WiredTiger.Cache, oldStat.WiredTiger.Cache
+	return fmt.Sprintf("%d", diff(
+		newCache.ModifiedPagesEvicted+newCache.UnmodifiedPagesEvicted,
+		oldCache.ModifiedPagesEvicted+oldCache.UnmodifiedPagesEvicted,
+		sampleSecs,
+	))
+}
+
+// ReadCacheReadMs reports the average time in milliseconds application
+// threads spent reading a page from disk into the WiredTiger cache during
+// the sample, or "n/a" if the node doesn't run WiredTiger.
+func ReadCacheReadMs(_ *ReaderConfig, newStat, oldStat *ServerStatus) string {
+	return readCacheLatency(newStat, oldStat, func(c *CacheStats) (int64, int64) {
+		return c.AppPageReadMicros, c.AppPageReadCount
+	})
+}
+
+// ReadCacheWriteMs reports the average time in milliseconds application
+// threads spent writing a page from the WiredTiger cache to disk during the
+// sample, or "n/a" if the node doesn't run WiredTiger.
+func ReadCacheWriteMs(_ *ReaderConfig, newStat, oldStat *ServerStatus) string {
+	return readCacheLatency(newStat, oldStat, func(c *CacheStats) (int64, int64) {
+		return c.AppPageWriteMicros, c.AppPageWriteCount
+	})
+}
+
+func readCacheLatency(
+	newStat, oldStat *ServerStatus,
+	f func(*CacheStats) (micros, count int64),
+) string {
+	if newStat.WiredTiger == nil || oldStat.WiredTiger == nil {
+		return "n/a"
+	}
+	newMicros, newCount := f(&newStat.WiredTiger.Cache)
+	oldMicros, oldCount := f(&oldStat.WiredTiger.Cache)
+	if newCount <= oldCount {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", float64(newMicros-oldMicros)/float64(newCount-oldCount)/1000)
+}
+
 func ReadFlushes(_ *ReaderConfig, newStat, oldStat *ServerStatus) string {
 	var val int64
 	if newStat.WiredTiger != nil && oldStat.WiredTiger != nil {
@@ -427,16 +537,20 @@ func ReadARW(_ *ReaderConfig, newStat, _ *ServerStatus) string {
 	return fmt.Sprintf("%v|%v", ar, aw)
 }
 
+// ReadNetIn returns the rate of bytes received per second, from the
+// serverStatus network.bytesIn counter.
 func ReadNetIn(c *ReaderConfig, newStat, oldStat *ServerStatus) string {
 	sampleSecs := float64(newStat.SampleTime.Sub(oldStat.SampleTime).Seconds())
 	val := diff(newStat.Network.BytesIn, oldStat.Network.BytesIn, sampleSecs)
-	return formatBits(c.HumanReadable, val)
+	return formatNetRate(c, val)
 }
 
+// ReadNetOut returns the rate of bytes sent per second, from the serverStatus
+// network.bytesOut counter.
 func ReadNetOut(c *ReaderConfig, newStat, oldStat *ServerStatus) string {
 	sampleSecs := float64(newStat.SampleTime.Sub(oldStat.SampleTime).Seconds())
 	val := diff(newStat.Network.BytesOut, oldStat.Network.BytesOut, sampleSecs)
-	return formatBits(c.HumanReadable, val)
+	return formatNetRate(c, val)
 }
 
 func ReadConn(_ *ReaderConfig, newStat, _ *ServerStatus) string {
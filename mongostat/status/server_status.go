@@ -59,9 +59,15 @@ type StorageEngine struct {
 
 // CacheStats stores cache statistics for WiredTiger.
 type CacheStats struct {
-	TrackedDirtyBytes  int64 `bson:"tracked dirty bytes in the cache"`
-	CurrentCachedBytes int64 `bson:"bytes currently in the cache"`
-	MaxBytesConfigured int64 `bson:"maximum bytes configured"`
+	TrackedDirtyBytes      int64 `bson:"tracked dirty bytes in the cache"`
+	CurrentCachedBytes     int64 `bson:"bytes currently in the cache"`
+	MaxBytesConfigured     int64 `bson:"maximum bytes configured"`
+	ModifiedPagesEvicted   int64 `bson:"modified pages evicted"`
+	UnmodifiedPagesEvicted int64 `bson:"unmodified pages evicted"`
+	AppPageReadCount       int64 `bson:"application threads page read from disk to cache count"`
+	AppPageReadMicros      int64 `bson:"application threads page read from disk to cache time (usecs)"`
+	AppPageWriteCount      int64 `bson:"application threads page write from cache to disk count"`
+	AppPageWriteMicros     int64 `bson:"application threads page write from cache to disk time (usecs)"`
 }
 
 // TransactionStats stores transaction checkpoints in WiredTiger.
@@ -23,8 +23,22 @@ type FormattableDiff interface {
 	JSON() string
 	// Generate a table-like representation which can be printed to a terminal
 	Grid() string
+	// Select returns the diff with its namespaces sorted by the given time,
+	// keeping only the limit busiest ones, or all of them if limit is 0
+	Select(sortBy string, limit int) FormattableDiff
 }
 
+// Times that namespaces can be sorted by, for --sortBy.
+const (
+	sortByTotal = "total"
+	sortByRead  = "read"
+	sortByWrite = "write"
+)
+
+// defaultGridLimit is the number of namespaces shown in the grid when --limit
+// isn't set.
+const defaultGridLimit = 10
+
 // ServerStatus represents the results of the "serverStatus" command.
 type ServerStatus struct {
 	Locks map[string]LockStats `bson:"locks,omitempty"`
@@ -50,6 +64,9 @@ type ServerStatusDiff struct {
 	// namespace -> lock times
 	Totals map[string]LockDelta `json:"totals"`
 	Time   time.Time            `json:"time"`
+
+	// sortBy is the time the grid is sorted by.
+	sortBy string
 }
 
 // LockDelta represents the differences in read/write lock times between two samples.
@@ -63,6 +80,9 @@ type TopDiff struct {
 	// namespace -> totals
 	Totals map[string]NSTopInfo `json:"totals"`
 	Time   time.Time            `json:"time"`
+
+	// sortBy is the time the grid is sorted by.
+	sortBy string
 }
 
 // Top holds raw output of the "top" command.
@@ -100,6 +120,40 @@ func (a sortableTotals) Less(i, j int) bool {
 func (a sortableTotals) Len() int      { return len(a) }
 func (a sortableTotals) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
+// busiestFirst sorts the totals in descending order and returns the first
+// limit of them, or all of them if limit is 0.
+func (a sortableTotals) busiestFirst(limit int) sortableTotals {
+	sort.Sort(sort.Reverse(a))
+	if limit > 0 && len(a) > limit {
+		return a[:limit]
+	}
+	return a
+}
+
+// sortTime returns the time of the namespace that --sortBy selects.
+func (info NSTopInfo) sortTime(sortBy string) int64 {
+	switch sortBy {
+	case sortByRead:
+		return int64(info.Read.Time)
+	case sortByWrite:
+		return int64(info.Write.Time)
+	default:
+		return int64(info.Total.Time)
+	}
+}
+
+// sortTime returns the lock time of the database that --sortBy selects.
+func (delta LockDelta) sortTime(sortBy string) int64 {
+	switch sortBy {
+	case sortByRead:
+		return delta.Read
+	case sortByWrite:
+		return delta.Write
+	default:
+		return delta.Read + delta.Write
+	}
+}
+
 // Diff takes an older Top sample, and produces a TopDiff
 // representing the deltas of each metric between the two samples.
 func (top Top) Diff(previous Top) TopDiff {
@@ -134,6 +188,29 @@ func (top Top) Diff(previous Top) TopDiff {
 	return diff
 }
 
+// sorted returns the namespaces of the TopDiff, busiest first.
+func (td TopDiff) sorted(limit int) sortableTotals {
+	totals := make(sortableTotals, 0, len(td.Totals))
+	for ns, diff := range td.Totals {
+		totals = append(totals, sortableTotal{ns, diff.sortTime(td.sortBy)})
+	}
+	return totals.busiestFirst(limit)
+}
+
+// Select returns the TopDiff sorted by the given time, with only the limit
+// busiest namespaces.
+func (td TopDiff) Select(sortBy string, limit int) FormattableDiff {
+	td.sortBy = sortBy
+	if limit > 0 && len(td.Totals) > limit {
+		totals := make(map[string]NSTopInfo, limit)
+		for _, st := range td.sorted(limit) {
+			totals[st.Name] = td.Totals[st.Name]
+		}
+		td.Totals = totals
+	}
+	return td
+}
+
 // Grid returns a tabular representation of the TopDiff.
 func (td TopDiff) Grid() string {
 	buf := &bytes.Buffer{}
@@ -141,14 +218,7 @@ func (td TopDiff) Grid() string {
 	out.WriteCells("ns", "total", "read", "write", time.Now().Format("2006-01-02T15:04:05Z07:00"))
 	out.EndRow()
 
-	//Sort by total time
-	totals := make(sortableTotals, 0, len(td.Totals))
-	for ns, diff := range td.Totals {
-		totals = append(totals, sortableTotal{ns, int64(diff.Total.Time)})
-	}
-
-	sort.Sort(sort.Reverse(totals))
-	for i, st := range totals {
+	for _, st := range td.sorted(0) {
 		diff := td.Totals[st.Name]
 		out.WriteCells(st.Name,
 			fmt.Sprintf("%vms", diff.Total.Time),
@@ -156,9 +226,6 @@ func (td TopDiff) Grid() string {
 			fmt.Sprintf("%vms", diff.Write.Time),
 			"")
 		out.EndRow()
-		if i >= 9 {
-			break
-		}
 	}
 	out.Flush(buf)
 	return buf.String()
@@ -182,6 +249,29 @@ func (ssd ServerStatusDiff) JSON() string {
 	return string(bytes)
 }
 
+// sorted returns the databases of the ServerStatusDiff, busiest first.
+func (ssd ServerStatusDiff) sorted(limit int) sortableTotals {
+	totals := make(sortableTotals, 0, len(ssd.Totals))
+	for ns, diff := range ssd.Totals {
+		totals = append(totals, sortableTotal{ns, diff.sortTime(ssd.sortBy)})
+	}
+	return totals.busiestFirst(limit)
+}
+
+// Select returns the ServerStatusDiff sorted by the given time, with only the
+// limit busiest databases.
+func (ssd ServerStatusDiff) Select(sortBy string, limit int) FormattableDiff {
+	ssd.sortBy = sortBy
+	if limit > 0 && len(ssd.Totals) > limit {
+		totals := make(map[string]LockDelta, limit)
+		for _, st := range ssd.sorted(limit) {
+			totals[st.Name] = ssd.Totals[st.Name]
+		}
+		ssd.Totals = totals
+	}
+	return ssd
+}
+
 // Grid returns a tabular representation of the ServerStatusDiff.
 func (ssd ServerStatusDiff) Grid() string {
 	buf := &bytes.Buffer{}
@@ -189,14 +279,7 @@ func (ssd ServerStatusDiff) Grid() string {
 	out.WriteCells("db", "total", "read", "write", time.Now().Format("2006-01-02T15:04:05Z07:00"))
 	out.EndRow()
 
-	//Sort by total time
-	totals := make(sortableTotals, 0, len(ssd.Totals))
-	for ns, diff := range ssd.Totals {
-		totals = append(totals, sortableTotal{ns, diff.Read + diff.Write})
-	}
-
-	sort.Sort(sort.Reverse(totals))
-	for i, st := range totals {
+	for _, st := range ssd.sorted(0) {
 		diff := ssd.Totals[st.Name]
 		out.WriteCells(st.Name,
 			fmt.Sprintf("%vms", diff.Read+diff.Write),
@@ -204,9 +287,6 @@ func (ssd ServerStatusDiff) Grid() string {
 			fmt.Sprintf("%vms", diff.Write),
 			"")
 		out.EndRow()
-		if i >= 9 {
-			break
-		}
 	}
 
 	out.Flush(buf)
@@ -0,0 +1,63 @@
+// Copyright (C) MongoDB, Inc. 2014-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongotop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/testtype"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func gridNamespaces(diff FormattableDiff) []string {
+	var names []string
+	for _, row := range strings.Split(strings.TrimSpace(diff.Grid()), "\n")[1:] {
+		names = append(names, strings.Fields(row)[0])
+	}
+	return names
+}
+
+func TestSelect(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("When selecting the busiest namespaces of a TopDiff", t, func() {
+		diff := TopDiff{Totals: map[string]NSTopInfo{
+			"db.a": {Total: TopField{Time: 30}, Read: TopField{Time: 25}, Write: TopField{Time: 5}},
+			"db.b": {Total: TopField{Time: 20}, Read: TopField{Time: 2}, Write: TopField{Time: 18}},
+			"db.c": {Total: TopField{Time: 10}, Read: TopField{Time: 10}},
+		}}
+
+		Convey("they are sorted by the chosen time", func() {
+			So(gridNamespaces(diff.Select(sortByTotal, 0)), ShouldResemble,
+				[]string{"db.a", "db.b", "db.c"})
+			So(gridNamespaces(diff.Select(sortByRead, 0)), ShouldResemble,
+				[]string{"db.a", "db.c", "db.b"})
+			So(gridNamespaces(diff.Select(sortByWrite, 0)), ShouldResemble,
+				[]string{"db.b", "db.a", "db.c"})
+		})
+
+		Convey("only the limit busiest are kept", func() {
+			selected := diff.Select(sortByWrite, 1)
+			So(selected.(TopDiff).Totals, ShouldHaveLength, 1)
+			So(selected.(TopDiff).Totals, ShouldContainKey, "db.b")
+			So(selected.JSON(), ShouldNotContainSubstring, "db.a")
+			So(diff.Totals, ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("When selecting the busiest databases of a ServerStatusDiff", t, func() {
+		diff := ServerStatusDiff{Totals: map[string]LockDelta{
+			"a": {Read: 1, Write: 9},
+			"b": {Read: 8, Write: 0},
+			"c": {Read: 2, Write: 2},
+		}}
+
+		So(gridNamespaces(diff.Select(sortByTotal, 2)), ShouldResemble, []string{"a", "b"})
+		So(gridNamespaces(diff.Select(sortByRead, 0)), ShouldResemble, []string{"b", "c", "a"})
+	})
+}
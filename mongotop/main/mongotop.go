@@ -43,6 +43,11 @@ func main() {
 		return
 	}
 
+	// print the resolved configuration, if specified
+	if opts.PrintConfig() {
+		return
+	}
+
 	log.SetVerbosity(opts.Verbosity)
 	signals.Handle()
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/mongodb/mongo-tools/common/db"
 	"github.com/mongodb/mongo-tools/common/log"
+	"github.com/mongodb/mongo-tools/common/ns"
 	"github.com/mongodb/mongo-tools/common/options"
 	"github.com/mongodb/mongo-tools/common/util"
 	"go.mongodb.org/mongo-driver/bson"
@@ -35,6 +36,16 @@ type MongoTop struct {
 
 	previousServerStatus *ServerStatus
 	previousTop          *Top
+
+	// nsMatcher selects the namespaces to report on, or is nil to report on
+	// all of them.
+	nsMatcher *ns.Matcher
+}
+
+// includesNamespace returns whether the given namespace, or database with
+// --locks, is reported on.
+func (mt *MongoTop) includesNamespace(name string) bool {
+	return mt.nsMatcher == nil || mt.nsMatcher.Has(name)
 }
 
 func (mt *MongoTop) runDiff() (outDiff FormattableDiff, err error) {
@@ -67,7 +78,7 @@ func (mt *MongoTop) runTopDiff() (outDiff FormattableDiff, err error) {
 	for _, elem := range totalsElems {
 		// Remove 'note' field that prevents easy decoding, then round-trip
 		// again to simplify unpacking into the nested data structure.
-		if elem.Key() == "note" {
+		if elem.Key() == "note" || !mt.includesNamespace(elem.Key()) {
 			continue
 		}
 
@@ -106,6 +117,11 @@ func (mt *MongoTop) runServerStatusDiff() (outDiff FormattableDiff, err error) {
 			return nil, fmt.Errorf("server does not support reporting lock information")
 		}
 	}
+	for name := range currentServerStatus.Locks {
+		if !mt.includesNamespace(name) {
+			delete(currentServerStatus.Locks, name)
+		}
+	}
 	if mt.previousServerStatus != nil {
 		serverStatusDiff := currentServerStatus.Diff(*mt.previousServerStatus)
 		outDiff = serverStatusDiff
@@ -119,11 +135,13 @@ func (mt *MongoTop) Run() error {
 	hasData := false
 	numPrinted := 0
 
+	var err error
+	mt.nsMatcher, err = mt.OutputOptions.namespaceMatcher()
+	if err != nil {
+		return err
+	}
+
 	for {
-		if mt.OutputOptions.RowCount > 0 && numPrinted > mt.OutputOptions.RowCount {
-			return nil
-		}
-		numPrinted++
 		diff, err := mt.runDiff()
 		if err != nil {
 			// If this is the first time trying to poll the server and it fails,
@@ -149,11 +167,22 @@ func (mt *MongoTop) Run() error {
 		hasData = true
 
 		if diff != nil {
+			limit := mt.OutputOptions.Limit
+			if limit == 0 && !mt.OutputOptions.Json {
+				limit = defaultGridLimit
+			}
+			diff = diff.Select(mt.OutputOptions.SortBy, limit)
 			if mt.OutputOptions.Json {
 				fmt.Println(diff.JSON())
 			} else {
 				fmt.Println(diff.Grid())
 			}
+			numPrinted++
+			// stop as soon as the last row is printed, rather than after
+			// another interval
+			if mt.OutputOptions.RowCount > 0 && numPrinted >= mt.OutputOptions.RowCount {
+				return nil
+			}
 		}
 		time.Sleep(mt.Sleeptime)
 	}
@@ -9,7 +9,9 @@ package mongotop
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/mongodb/mongo-tools/common/ns"
 	"github.com/mongodb/mongo-tools/common/options"
 )
 
@@ -32,6 +34,10 @@ type Output struct {
 	Locks    bool `long:"locks" description:"report on use of per-database locks"`
 	RowCount int  `long:"rowcount" value-name:"<count>" short:"n" description:"number of stats lines to print (0 for indefinite)"`
 	Json     bool `long:"json" description:"format output as JSON"`
+
+	Limit      int    `long:"limit" value-name:"<count>" description:"number of busiest namespaces to show each interval (default: 10, or all of them with --json)"`
+	SortBy     string `long:"sortBy" value-name:"<time>" choice:"total" choice:"read" choice:"write" default:"total" description:"time to sort namespaces by, busiest first: total, read or write"`
+	Namespaces string `long:"namespaces" value-name:"<pattern>[,<pattern>]*" description:"only report on namespaces matching one of the comma-separated patterns, e.g. 'test.*' (with --locks, patterns match database names)"`
 }
 
 // Name returns a human-readable group name for output options.
@@ -39,6 +45,26 @@ func (_ *Output) Name() string {
 	return "output"
 }
 
+// namespaceMatcher returns a Matcher for the patterns of --namespaces, or nil
+// if it isn't set.
+func (output *Output) namespaceMatcher() (*ns.Matcher, error) {
+	if output.Namespaces == "" {
+		return nil, nil
+	}
+	patterns := strings.Split(output.Namespaces, ",")
+	for i, pattern := range patterns {
+		patterns[i] = strings.TrimSpace(pattern)
+		if patterns[i] == "" {
+			return nil, fmt.Errorf("invalid --namespaces value: empty pattern")
+		}
+	}
+	matcher, err := ns.NewMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --namespaces value: %v", err)
+	}
+	return matcher, nil
+}
+
 func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, error) {
 	opts := options.New("mongotop", versionStr, gitCommit, Usage, true,
 		options.EnabledOptions{Auth: true, Connection: true, Namespace: false, URI: true})
@@ -60,6 +86,13 @@ func ParseOptions(rawArgs []string, versionStr, gitCommit string) (Options, erro
 		)
 	}
 
+	if outputOpts.Limit < 0 {
+		return Options{}, fmt.Errorf("invalid value for --limit: %v", outputOpts.Limit)
+	}
+	if _, err := outputOpts.namespaceMatcher(); err != nil {
+		return Options{}, err
+	}
+
 	sleeptime := 1 // default to 1 second sleep time
 	if len(extraArgs) > 0 {
 		sleeptime, err = strconv.Atoi(extraArgs[0])
@@ -195,3 +195,33 @@ func TestPositionalArgumentParsing(t *testing.T) {
 		}
 	})
 }
+
+func TestNamespacesOption(t *testing.T) {
+	testtype.SkipUnlessTestType(t, testtype.UnitTestType)
+
+	Convey("With --namespaces", t, func() {
+		Convey("only matching namespaces are reported", func() {
+			opts, err := ParseOptions([]string{"--namespaces", "test.*, admin.system.users"}, "", "")
+			So(err, ShouldBeNil)
+
+			mt := &MongoTop{OutputOptions: opts.Output}
+			mt.nsMatcher, err = mt.OutputOptions.namespaceMatcher()
+			So(err, ShouldBeNil)
+			So(mt.includesNamespace("test.foo"), ShouldBeTrue)
+			So(mt.includesNamespace("admin.system.users"), ShouldBeTrue)
+			So(mt.includesNamespace("admin.system.roles"), ShouldBeFalse)
+			So(mt.includesNamespace("other.foo"), ShouldBeFalse)
+		})
+
+		Convey("every namespace is reported when it isn't set", func() {
+			mt := &MongoTop{OutputOptions: &Output{}}
+			So(mt.includesNamespace("other.foo"), ShouldBeTrue)
+		})
+
+		Convey("empty patterns are rejected", func() {
+			_, err := ParseOptions([]string{"--namespaces", "test.*,"}, "", "")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid --namespaces value")
+		})
+	})
+}